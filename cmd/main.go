@@ -21,6 +21,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/cblomart/GoProxLB/internal/app"
 	"github.com/spf13/cobra"
@@ -34,7 +35,18 @@ var (
 	forecast     string
 	csvOutput    string
 	force        bool
+	forceMode    string
+	dryRun       bool
+	yes          bool
+	output       string
 	balancerType string
+	topSort      string
+	topLimit     int
+	topInterval  time.Duration
+	topCount     int
+	noColor      bool
+	exportOutput string
+	exportFormat string
 	serviceUser  = "goproxlb"
 	serviceGroup = "goproxlb"
 )
@@ -57,8 +69,14 @@ Examples:
   goproxlb                    # Start with defaults (auto-detects everything)
   goproxlb --config config.yaml  # Use specific config file
   goproxlb list              # List VMs
+  goproxlb top               # Show the busiest VMs
+  goproxlb rules             # Show placement rules and conflicts
   goproxlb capacity          # Show capacity planning
+  goproxlb apply swap.yaml   # Execute a plan of migrations
+  goproxlb drain pve2        # Move every VM off a node
+  goproxlb export -o inventory.json  # Export nodes and VMs
   goproxlb cluster           # Show cluster info
+  goproxlb summary           # Show an at-a-glance balance summary
   goproxlb raft              # Show Raft cluster status`,
 	Version: Version,
 }
@@ -79,8 +97,19 @@ var balanceCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configPath, _ := cmd.Flags().GetString("config") //nolint:errcheck // flag parsing errors are handled by cobra
 		force, _ := cmd.Flags().GetBool("force") //nolint:errcheck // flag parsing errors are handled by cobra
+		forceMode, _ := cmd.Flags().GetString("force-mode") //nolint:errcheck // flag parsing errors are handled by cobra
 		balancerType, _ := cmd.Flags().GetString("balancer-type") //nolint:errcheck // flag parsing errors are handled by cobra
-		return app.ForceBalanceWithBalancerType(configPath, force, balancerType)
+		dryRun, _ := cmd.Flags().GetBool("dry-run") //nolint:errcheck // flag parsing errors are handled by cobra
+		output, _ := cmd.Flags().GetString("output") //nolint:errcheck // flag parsing errors are handled by cobra
+		yes, _ := cmd.Flags().GetBool("yes") //nolint:errcheck // flag parsing errors are handled by cobra
+		return app.ForceBalanceWithBalancerType(configPath, app.BalanceOptions{
+			Force:        force,
+			ForceMode:    forceMode,
+			BalancerType: balancerType,
+			DryRun:       dryRun,
+			Output:       output,
+			Yes:          yes,
+		})
 	},
 }
 
@@ -98,7 +127,28 @@ var clusterCmd = &cobra.Command{
 	Short: "Show cluster information",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configPath, _ := cmd.Flags().GetString("config") //nolint:errcheck // flag parsing errors are handled by cobra
-		return app.ShowClusterInfo(configPath)
+		output, _ := cmd.Flags().GetString("output") //nolint:errcheck // flag parsing errors are handled by cobra
+		return app.ShowClusterInfo(configPath, output)
+	},
+}
+
+var summaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Show an at-a-glance summary of the cluster balance",
+	Long: `Show a one-screen summary of the cluster balance:
+- Balance score
+- Nodes near or above their thresholds
+- VMs migrated most in the last 24 hours
+- VMs that should move but have no valid target
+- Next balancing run
+
+Examples:
+  goproxlb summary           # Text summary
+  goproxlb summary -o json   # JSON summary`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config") //nolint:errcheck // flag parsing errors are handled by cobra
+		output, _ := cmd.Flags().GetString("output") //nolint:errcheck // flag parsing errors are handled by cobra
+		return app.ShowSummary(configPath, output)
 	},
 }
 
@@ -111,6 +161,45 @@ var listCmd = &cobra.Command{
 	},
 }
 
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show the busiest VMs across the cluster",
+	Long: `Show the running VMs using the most CPU or memory across the cluster,
+refreshing periodically, to find what is driving load.
+
+Examples:
+  goproxlb top                       # Sort by CPU, refresh every 5s
+  goproxlb top --sort memory -n 20   # Top 20 VMs by memory
+  goproxlb top --count 1             # Print a single frame and exit`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config") //nolint:errcheck // flag parsing errors are handled by cobra
+		topSort, _ := cmd.Flags().GetString("sort") //nolint:errcheck // flag parsing errors are handled by cobra
+		topLimit, _ := cmd.Flags().GetInt("limit") //nolint:errcheck // flag parsing errors are handled by cobra
+		topInterval, _ := cmd.Flags().GetDuration("interval") //nolint:errcheck // flag parsing errors are handled by cobra
+		topCount, _ := cmd.Flags().GetInt("count") //nolint:errcheck // flag parsing errors are handled by cobra
+		return app.ShowTop(configPath, app.TopOptions{
+			SortBy:   topSort,
+			Limit:    topLimit,
+			Interval: topInterval,
+			Count:    topCount,
+		})
+	},
+}
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Show placement rules and conflicts",
+	Long: `Show the placement rules extracted from VM tags and report
+contradictory or unsatisfiable combinations, such as:
+- Affinity group members pinned to disjoint nodes
+- Anti-affinity groups larger than the number of available nodes
+- VMs pinned only to unavailable nodes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config") //nolint:errcheck // flag parsing errors are handled by cobra
+		return app.ShowRules(configPath)
+	},
+}
+
 var capacityCmd = &cobra.Command{
 	Use:   "capacity",
 	Short: "Show capacity planning information",
@@ -128,6 +217,70 @@ var capacityCmd = &cobra.Command{
 	},
 }
 
+var applyCmd = &cobra.Command{
+	Use:   "apply <plan-file>",
+	Short: "Execute a plan of migrations from a file",
+	Long: `Execute the migrations of a JSON or YAML plan file (picked from the extension),
+one after the other. Each step moves a VM to a node. The whole plan is checked
+first against placement rules, maintenance nodes and held VMs, with each step
+seeing the VMs where the previous ones leave them. An atomic plan moves each VM
+once and must succeed as a whole: when a migration fails, the remaining ones
+are skipped and the executed ones rolled back.
+
+  atomic: true
+  migrations:
+    - vmid: 101
+      node: pve2
+    - vmid: 102
+      node: pve1
+
+Examples:
+  goproxlb apply swap.yaml             # Swap VMs 101 and 102
+  goproxlb apply swap.yaml --dry-run   # Show the migration order only`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config") //nolint:errcheck // flag parsing errors are handled by cobra
+		dryRun, _ := cmd.Flags().GetBool("dry-run") //nolint:errcheck // flag parsing errors are handled by cobra
+		return app.ApplyPlan(configPath, args[0], dryRun)
+	},
+}
+
+var drainCmd = &cobra.Command{
+	Use:   "drain <node>",
+	Short: "Move every VM off a node",
+	Long: `Move every VM off a node, e.g. before maintenance. VMs move one after the
+other: each after the VMs it depends on (plb_depends_on_<vmid> tags), otherwise
+in Proxmox boot order. Ignored and frozen VMs stay.
+
+Examples:
+  goproxlb drain pve2             # Drain node pve2
+  goproxlb drain pve2 --dry-run   # Show the migration order only`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config") //nolint:errcheck // flag parsing errors are handled by cobra
+		dryRun, _ := cmd.Flags().GetBool("dry-run") //nolint:errcheck // flag parsing errors are handled by cobra
+		return app.DrainNode(configPath, args[0], dryRun)
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the node and VM inventory to a file",
+	Long: `Export every node with its VMs, tags and resources, along with the cluster
+name and a timestamp, for offline analysis or backups.
+
+Examples:
+  goproxlb export --output inventory.json   # JSON file
+  goproxlb export --output inventory.yaml   # YAML file, picked from the extension
+  goproxlb export --format yaml             # YAML on stdout`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config") //nolint:errcheck // flag parsing errors are handled by cobra
+		exportOutput, _ := cmd.Flags().GetString("output") //nolint:errcheck // flag parsing errors are handled by cobra
+		exportFormat, _ := cmd.Flags().GetString("format") //nolint:errcheck // flag parsing errors are handled by cobra
+		return app.ExportInventory(configPath, exportOutput, exportFormat)
+	},
+}
+
 var raftCmd = &cobra.Command{
 	Use:   "raft",
 	Short: "Show Raft cluster status",
@@ -168,14 +321,34 @@ Examples:
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Configuration file path (optional - uses defaults with auto-detection)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also off with NO_COLOR set or when not writing to a terminal)")
+	cobra.OnInitialize(func() {
+		if noColor {
+			app.DisableColor()
+		}
+	})
 
 	// Command-specific flags
 	listCmd.Flags().BoolVarP(&detailed, "detailed", "d", false, "Show detailed information")
 	capacityCmd.Flags().BoolVarP(&detailed, "detailed", "d", false, "Show detailed information")
 	capacityCmd.Flags().StringVarP(&forecast, "forecast", "f", "168h", "Forecast period (e.g., 168h for 7 days)")
 	capacityCmd.Flags().StringVarP(&csvOutput, "csv", "", "", "Output to CSV file")
+	topCmd.Flags().StringVarP(&topSort, "sort", "s", "cpu", "Sort by cpu or memory")
+	topCmd.Flags().IntVarP(&topLimit, "limit", "n", 10, "Number of VMs to show (0 shows all)")
+	topCmd.Flags().DurationVarP(&topInterval, "interval", "i", 5*time.Second, "Refresh interval")
+	topCmd.Flags().IntVarP(&topCount, "count", "", 0, "Number of refreshes before exiting (0 runs until interrupted)")
+	clusterCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format: text or json")
+	summaryCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format: text or json")
 	balanceCmd.Flags().BoolVarP(&force, "force", "f", false, "Force balancing even if no improvement")
-	balanceCmd.Flags().StringVarP(&balancerType, "balancer", "b", "", "Balancer type (threshold or advanced)")
+	balanceCmd.Flags().StringVarP(&forceMode, "force-mode", "", "", "Forced balance behavior: always (balance even when balanced) or reevaluate (skip cooldown only)")
+	balanceCmd.Flags().StringVarP(&balancerType, "balancer", "b", "", "Balancer type (threshold, advanced or consolidate)")
+	balanceCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Plan migrations without executing them")
+	balanceCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Execute the planned migrations without asking for confirmation")
+	balanceCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format: text, dot (Graphviz plan) or junit (JUnit XML report); dot and junit require --dry-run")
+	applyCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Show the plan without executing it")
+	drainCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Plan the migrations without executing them")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Inventory file (stdout when empty)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "", "", "Inventory format: json or yaml (default from the file extension, json otherwise)")
 
 	// Install command flags
 	installCmd.Flags().StringVarP(&serviceUser, "user", "u", "goproxlb", "User to run the service as")
@@ -186,16 +359,22 @@ func init() {
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(clusterCmd)
+	rootCmd.AddCommand(summaryCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(topCmd)
 	rootCmd.AddCommand(balanceCmd)
+	rootCmd.AddCommand(rulesCmd)
 	rootCmd.AddCommand(capacityCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(drainCmd)
+	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(raftCmd)
 	rootCmd.AddCommand(installCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintln(os.Stderr, app.FormatError(os.Stderr, err))
 		os.Exit(1)
 	}
 }
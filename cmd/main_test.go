@@ -170,9 +170,12 @@ func TestCommandStructure(t *testing.T) {
 	expectedCommands := map[string]bool{
 		"status":   false,
 		"cluster":  false,
+		"summary":  false,
 		"list":     false,
+		"rules":    false,
 		"balance":  false,
 		"capacity": false,
+		"apply":    false,
 		"raft":     false,
 		"install":  false,
 	}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -20,14 +21,20 @@ import (
 
 	"github.com/cblomart/GoProxLB/internal/balancer"
 	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/metrics"
 	"github.com/cblomart/GoProxLB/internal/models"
 	"github.com/cblomart/GoProxLB/internal/proxmox"
+	"github.com/cblomart/GoProxLB/internal/raft"
+	"github.com/cblomart/GoProxLB/internal/rules"
 )
 
 const (
-	vmStatusRunning   = "running"
-	balancerThreshold = "threshold"
-	balancerAdvanced  = "advanced"
+	vmStatusRunning     = "running"
+	balancerThreshold   = "threshold"
+	balancerAdvanced    = "advanced"
+	balancerConsolidate = "consolidate"
+	// storageMigrationLabel marks migrations copying local disks, far longer than memory-only ones
+	storageMigrationLabel = "storage migration: local disks copied"
 )
 
 // App represents the main application.
@@ -36,7 +43,9 @@ type App struct {
 	client   ClientInterface
 	balancer BalancerInterface
 	ctx      context.Context
-	cancel   context.CancelFunc
+	cancel   context.CancelFunc // Also releases the data directory lock
+	metrics  *metrics.Recorder  // Nil unless metrics are enabled
+	dirLock  *raft.DataDirLock  // Nil unless a data directory is configured
 }
 
 // NewApp creates a new application instance.
@@ -52,18 +61,19 @@ func NewApp(configPath string) (*App, error) {
 		if err := config.AutoDetectClusterName(client); err != nil {
 			return nil, fmt.Errorf("failed to auto-detect cluster name: %w", err)
 		}
-		fmt.Printf("Auto-detected cluster name: %s\n", config.Cluster.Name)
+		fmt.Fprintf(os.Stderr, "Auto-detected cluster name: %s\n", config.Cluster.Name)
 	}
 
 	client := proxmox.NewClient(&config.Proxmox)
 
-	var balancerInstance BalancerInterface
-	if config.IsAdvancedBalancer() {
-		balancerInstance = balancer.NewAdvancedBalancer(client, config)
-	} else {
-		balancerInstance = balancer.NewBalancer(client, config)
+	// Lock the data directory before the balancer loads its history from it
+	dirLock, err := lockDataDir(config)
+	if err != nil {
+		return nil, err
 	}
 
+	balancerInstance := setupBalancer(client, config)
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &App{
@@ -71,7 +81,8 @@ func NewApp(configPath string) (*App, error) {
 		client:   client,
 		balancer: balancerInstance,
 		ctx:      ctx,
-		cancel:   cancel,
+		cancel:   releaseOnCancel(cancel, dirLock),
+		dirLock:  dirLock,
 	}, nil
 }
 
@@ -97,11 +108,7 @@ func NewAppWithDependencies(configPath string, configLoader ConfigLoaderInterfac
 
 	// Create balancer if not provided
 	if balancerInstance == nil {
-		if cfg.IsAdvancedBalancer() {
-			balancerInstance = balancer.NewAdvancedBalancer(client, cfg)
-		} else {
-			balancerInstance = balancer.NewBalancer(client, cfg)
-		}
+		balancerInstance = setupBalancer(client, cfg)
 	}
 
 	// Create context
@@ -128,15 +135,16 @@ func NewAppWithDefaults() (*App, error) {
 	if err := config.AutoDetectClusterName(client); err != nil {
 		return nil, fmt.Errorf("failed to auto-detect cluster name: %w", err)
 	}
-	fmt.Printf("Auto-detected cluster name: %s\n", config.Cluster.Name)
+	fmt.Fprintf(os.Stderr, "Auto-detected cluster name: %s\n", config.Cluster.Name)
 
-	var balancerInstance BalancerInterface
-	if config.IsAdvancedBalancer() {
-		balancerInstance = balancer.NewAdvancedBalancer(client, config)
-	} else {
-		balancerInstance = balancer.NewBalancer(client, config)
+	// Lock the data directory before the balancer loads its history from it
+	dirLock, err := lockDataDir(config)
+	if err != nil {
+		return nil, err
 	}
 
+	balancerInstance := setupBalancer(client, config)
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &App{
@@ -144,15 +152,45 @@ func NewAppWithDefaults() (*App, error) {
 		client:   client,
 		balancer: balancerInstance,
 		ctx:      ctx,
-		cancel:   cancel,
+		cancel:   releaseOnCancel(cancel, dirLock),
+		dirLock:  dirLock,
 	}, nil
 }
 
+// lockDataDir locks the configured data directory so that a second instance fails fast.
+// Without a data directory there is nothing to lock.
+func lockDataDir(cfg *config.Config) (*raft.DataDirLock, error) {
+	if cfg.Raft.DataDir == "" {
+		return nil, nil
+	}
+	return raft.AcquireDataDirLock(cfg.Raft.DataDir)
+}
+
+// releaseOnCancel returns a cancel function that also releases the data directory lock, if any.
+func releaseOnCancel(cancel context.CancelFunc, dirLock *raft.DataDirLock) context.CancelFunc {
+	if dirLock == nil {
+		return cancel
+	}
+	return func() {
+		cancel()
+		_ = dirLock.Release() //nolint:errcheck // cleanup operation, error not actionable
+	}
+}
+
 // Start starts the load balancer daemon with default balancer type.
 func Start(configPath string) error {
 	return StartWithBalancerType(configPath, "")
 }
 
+// printEffectiveConfig dumps the resolved configuration, secrets redacted, when logging at debug level.
+func printEffectiveConfig(w io.Writer, cfg *config.Config) {
+	if cfg.Logging.Level != "debug" {
+		return
+	}
+	fmt.Fprintln(w, "Effective configuration:")
+	cfg.Dump(w)
+}
+
 // StartWithBalancerType starts the load balancer daemon with a specific balancer type.
 func StartWithBalancerType(configPath, balancerType string) error {
 	// Load config to check if Raft is enabled
@@ -187,18 +225,13 @@ func StartWithBalancerType(configPath, balancerType string) error {
 
 	// Override balancer type if specified
 	if balancerType != "" {
-		if balancerType != balancerThreshold && balancerType != balancerAdvanced {
-			return fmt.Errorf("invalid balancer type: %s (must be 'threshold' or 'advanced')", balancerType)
+		if balancerType != balancerThreshold && balancerType != balancerAdvanced && balancerType != balancerConsolidate {
+			return fmt.Errorf("invalid balancer type: %s (must be 'threshold', 'advanced' or 'consolidate')", balancerType)
 		}
 		app.config.Balancing.BalancerType = balancerType
 
 		// Recreate the balancer with the new type
-		client := app.client
-		if app.config.IsAdvancedBalancer() {
-			app.balancer = balancer.NewAdvancedBalancer(client, app.config)
-		} else {
-			app.balancer = balancer.NewBalancer(client, app.config)
-		}
+		app.balancer = setupBalancer(app.client, app.config)
 	}
 
 	fmt.Println("Starting GoProxLB...")
@@ -208,6 +241,9 @@ func StartWithBalancerType(configPath, balancerType string) error {
 	fmt.Printf("Balancing enabled: true\n")
 	fmt.Printf("Balancer type: %s\n", app.config.Balancing.BalancerType)
 	fmt.Printf("Aggressiveness: %s\n", app.config.Balancing.Aggressiveness)
+	if app.config.ReadOnly {
+		fmt.Println("Read-only mode: migrations are planned and reported but never executed")
+	}
 
 	// Get balancing interval
 	interval, err := app.config.GetInterval()
@@ -217,6 +253,12 @@ func StartWithBalancerType(configPath, balancerType string) error {
 
 	fmt.Printf("Balancing interval: %v\n", interval)
 	fmt.Printf("Balancing enabled: true\n")
+	printEffectiveConfig(os.Stdout, app.config)
+
+	// Optionally expose Prometheus metrics
+	if app.metrics, err = startMetrics(app.ctx, app.config); err != nil {
+		return err
+	}
 
 	// Set up signal handling
 	sigChan := make(chan os.Signal, 1)
@@ -249,29 +291,49 @@ func StartWithBalancerType(configPath, balancerType string) error {
 func (app *App) runBalancingCycle() error {
 	fmt.Printf("[%s] Running balancing cycle...\n", time.Now().Format("2006-01-02 15:04:05"))
 
+	start := time.Now()
 	results, err := app.balancer.Run(false)
+	recordCycleMetrics(app.metrics, app.client, app.balancer, time.Since(start), results)
 	if err != nil {
 		return fmt.Errorf("balancing cycle failed: %w", err)
 	}
 
-	if len(results) == 0 {
-		fmt.Println("No balancing actions needed")
-		return nil
-	}
+	printBalancingResults(os.Stdout, results, app.config.ReadOnly)
 
-	fmt.Printf("Executed %d migrations:\n", len(results))
+	return nil
+}
+
+// printBalancingResults writes the outcome of a balance operation; in read-only mode, including
+// dry runs, the migrations were only planned.
+func printBalancingResults(w io.Writer, results []models.BalancingResult, readOnly bool) {
+	switch {
+	case len(results) == 0:
+		fmt.Fprintln(w, "No balancing actions performed")
+		return
+	case readOnly:
+		fmt.Fprintf(w, "Dry run completed. %d migrations planned, none executed:\n", len(results))
+	default:
+		fmt.Fprintf(w, "Balance operation completed. %d migrations executed:\n", len(results))
+	}
 	for i := range results {
-		result := &results[i]
-		if result.Success {
-			fmt.Printf("  ✓ Migrated VM %s (%d) from %s to %s (gain: %.2f)\n",
-				result.VM.Name, result.VM.ID, result.SourceNode, result.TargetNode, result.ResourceGain)
-		} else {
-			fmt.Printf("  ✗ Failed to migrate VM %s (%d): %s\n",
-				result.VM.Name, result.VM.ID, result.ErrorMessage)
-		}
+		printBalancingResult(w, &results[i])
 	}
+}
 
-	return nil
+// printBalancingResult writes one migration outcome: green when done, yellow when only planned
+// (read-only), red when failed.
+func printBalancingResult(w io.Writer, result *models.BalancingResult) {
+	vm := fmt.Sprintf("VM %s (%d)", result.VM.Name, result.VM.ID)
+	switch {
+	case result.Success:
+		fmt.Fprintln(w, colorize(w, colorGreen, fmt.Sprintf("  ✓ Migrated %s from %s to %s (%s)",
+			vm, result.SourceNode, result.TargetNode, describeGain(result))))
+	case result.DryRun:
+		fmt.Fprintln(w, colorize(w, colorYellow, fmt.Sprintf("  ⏸ Would migrate %s from %s to %s (%s) [read-only]",
+			vm, result.SourceNode, result.TargetNode, describeGain(result))))
+	default:
+		fmt.Fprintln(w, colorize(w, colorRed, fmt.Sprintf("  ✗ Failed to migrate %s: %s", vm, result.ErrorMessage)))
+	}
 }
 
 // ShowStatus shows the current status of the load balancer.
@@ -290,28 +352,53 @@ func ShowStatus(configPath string) error {
 	}
 	defer app.cancel()
 
+	return app.showStatus(os.Stdout)
+}
+
+// showStatus writes the load balancer status, coloring the balancing state and balance score.
+func (app *App) showStatus(w io.Writer) error {
 	// Get cluster status
 	status, err := app.balancer.GetClusterStatus()
 	if err != nil {
 		return fmt.Errorf("failed to get cluster status: %w", err)
 	}
 
-	fmt.Println("=== GoProxLB Status ===")
-	fmt.Printf("Total Nodes: %d\n", status.TotalNodes)
-	fmt.Printf("Active Nodes: %d\n", status.ActiveNodes)
-	fmt.Printf("Total VMs: %d\n", status.TotalVMs)
-	fmt.Printf("Running VMs: %d\n", status.RunningVMs)
-	fmt.Printf("Balancing Enabled: %v\n", status.BalancingEnabled)
-	fmt.Printf("Last Balanced: %v\n", status.LastBalanced)
-	fmt.Printf("Average CPU Usage: %.1f%%\n", status.AverageCPU)
-	fmt.Printf("Average Memory Usage: %.1f%%\n", status.AverageMemory)
-	fmt.Printf("Average Storage Usage: %.1f%%\n", status.AverageStorage)
+	enabledColor := colorGreen
+	if !status.BalancingEnabled {
+		enabledColor = colorYellow
+	}
+
+	fmt.Fprintln(w, "=== GoProxLB Status ===")
+	fmt.Fprintf(w, "Cluster: %s\n", status.Cluster)
+	fmt.Fprintf(w, "Total Nodes: %d\n", status.TotalNodes)
+	fmt.Fprintf(w, "Active Nodes: %d\n", status.ActiveNodes)
+	fmt.Fprintf(w, "Total VMs: %d\n", status.TotalVMs)
+	fmt.Fprintf(w, "Running VMs: %d\n", status.RunningVMs)
+	fmt.Fprintf(w, "Balancing Enabled: %s\n", colorize(w, enabledColor, fmt.Sprint(status.BalancingEnabled)))
+	fmt.Fprintf(w, "Last Balanced: %v\n", status.LastBalanced)
+	if !status.LastRun.IsZero() {
+		fmt.Fprintf(w, "Last Run: %v\n", status.LastRun)
+	}
+	if !status.NextRun.IsZero() {
+		fmt.Fprintf(w, "Next Run: %v\n", status.NextRun)
+	}
+	fmt.Fprintf(w, "Average CPU Usage: %.1f%%\n", status.AverageCPU)
+	fmt.Fprintf(w, "Average Memory Usage: %.1f%%\n", status.AverageMemory)
+	fmt.Fprintf(w, "Average Storage Usage: %.1f%%\n", status.AverageStorage)
+	fmt.Fprintf(w, "Balance Score: %s\n", colorize(w, balanceScoreColor(status.BalanceScore), fmt.Sprintf("%.0f/100", status.BalanceScore)))
+	if status.OverCapacity {
+		fmt.Fprintln(w, colorize(w, colorRed, "Cluster over capacity: every node is above its thresholds, add nodes or reduce load"))
+	}
+	for _, breach := range status.SLABreaches {
+		fmt.Fprintln(w, colorize(w, colorRed, fmt.Sprintf("Imbalance SLA breached: %s %s at %.1f%%, %.1f points above the cluster mean %.1f%%",
+			breach.Node, breach.Resource, breach.Usage, breach.Excess, breach.Mean)))
+	}
 
 	return nil
 }
 
 // ShowClusterInfo shows detailed cluster information.
-func ShowClusterInfo(configPath string) error {
+func ShowClusterInfo(configPath, output string) error {
 	var app *App
 	var err error
 
@@ -326,48 +413,133 @@ func ShowClusterInfo(configPath string) error {
 	}
 	defer app.cancel()
 
+	return app.showClusterInfo(os.Stdout, output)
+}
+
+// clusterInfo is the JSON form of the cluster command.
+type clusterInfo struct {
+	Status models.ClusterStatus `json:"status"`
+	Totals models.ClusterTotals `json:"totals"`
+	Nodes  []models.Node        `json:"nodes"`
+}
+
+// showClusterInfo writes the cluster status, totals and node details as text or JSON.
+func (app *App) showClusterInfo(w io.Writer, output string) error {
+	if output != "" && output != outputText && output != outputJSON {
+		return fmt.Errorf("invalid output format: %s (must be '%s' or '%s')", output, outputText, outputJSON)
+	}
+
 	// Get cluster status
 	status, err := app.balancer.GetClusterStatus()
 	if err != nil {
 		return fmt.Errorf("failed to get cluster status: %w", err)
 	}
 
-	fmt.Println("=== Cluster Information ===")
-	fmt.Printf("Total Nodes: %d\n", status.TotalNodes)
-	fmt.Printf("Active Nodes: %d\n", status.ActiveNodes)
-	fmt.Printf("Total VMs: %d\n", status.TotalVMs)
-	fmt.Printf("Running VMs: %d\n", status.RunningVMs)
-	fmt.Printf("Average CPU Usage: %.1f%%\n", status.AverageCPU)
-	fmt.Printf("Average Memory Usage: %.1f%%\n", status.AverageMemory)
-	fmt.Printf("Average Storage Usage: %.1f%%\n", status.AverageStorage)
-
 	// Get detailed node information
 	nodes, err := app.client.GetNodes()
 	if err != nil {
 		return fmt.Errorf("failed to get nodes: %w", err)
 	}
+	totals := clusterTotals(nodes)
+
+	if output == outputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(clusterInfo{Status: *status, Totals: totals, Nodes: nodes}); err != nil {
+			return fmt.Errorf("failed to encode cluster information: %w", err)
+		}
+		return nil
+	}
 
-	fmt.Println("\n=== Node Details ===")
+	fmt.Fprintln(w, "=== Cluster Information ===")
+	fmt.Fprintf(w, "Cluster: %s\n", status.Cluster)
+	fmt.Fprintf(w, "Total Nodes: %d\n", status.TotalNodes)
+	fmt.Fprintf(w, "Active Nodes: %d\n", status.ActiveNodes)
+	fmt.Fprintf(w, "Total VMs: %d\n", status.TotalVMs)
+	fmt.Fprintf(w, "Running VMs: %d\n", status.RunningVMs)
+	fmt.Fprintf(w, "Average CPU Usage: %.1f%%\n", status.AverageCPU)
+	fmt.Fprintf(w, "Average Memory Usage: %.1f%%\n", status.AverageMemory)
+	fmt.Fprintf(w, "Average Storage Usage: %.1f%%\n", status.AverageStorage)
+
+	fmt.Fprintln(w, "\n=== Cluster Totals ===")
+	fmt.Fprintf(w, "CPU: %d cores, %d vCPUs allocated to running VMs\n", totals.CPUCores, totals.AllocatedVCPUs)
+	fmt.Fprintf(w, "Memory: %.1f GB used / %.1f GB total (%.1f GB free), %.1f GB allocated to running VMs\n",
+		float64(totals.MemoryUsed)/1024/1024/1024,
+		float64(totals.MemoryTotal)/1024/1024/1024,
+		float64(totals.MemoryTotal-totals.MemoryUsed)/1024/1024/1024,
+		float64(totals.MemoryAllocated)/1024/1024/1024)
+	fmt.Fprintf(w, "Storage: %.1f GB used / %.1f GB total (%.1f GB free)\n",
+		float64(totals.StorageUsed)/1024/1024/1024,
+		float64(totals.StorageTotal)/1024/1024/1024,
+		float64(totals.StorageTotal-totals.StorageUsed)/1024/1024/1024)
+
+	// Usage above the balancing thresholds shows in red
+	var thresholds config.ResourceThresholds
+	if app.config != nil {
+		thresholds = app.config.Balancing.Thresholds
+	}
+	// Nodes crowded with VMs show in yellow, whatever their usage
+	dense := make(map[string]bool)
+	density := vmDensity(nodes)
+	for _, finding := range density {
+		dense[finding.Node] = true
+	}
+
+	fmt.Fprintln(w, "\n=== Node Details ===")
 	for i := range nodes {
 		node := &nodes[i]
-		fmt.Printf("Node: %s\n", node.Name)
-		fmt.Printf("  Status: %s\n", node.Status)
-		fmt.Printf("  CPU: %.1f%% (%d cores)\n", node.CPU.Usage, node.CPU.Cores)
-		fmt.Printf("  Memory: %.1f%% (%.1f GB used / %.1f GB total)\n",
-			node.Memory.Usage,
+		fmt.Fprintf(w, "Node: %s\n", node.Name)
+		fmt.Fprintf(w, "  Status: %s\n", colorize(w, statusColor(node.Status), node.Status))
+		fmt.Fprintf(w, "  CPU: %s (%d cores)\n",
+			colorize(w, usageColor(node.CPU.Usage, thresholds.CPU), fmt.Sprintf("%.1f%%", node.CPU.Usage)), node.CPU.Cores)
+		fmt.Fprintf(w, "  Memory: %s (%.1f GB used / %.1f GB total)\n",
+			colorize(w, usageColor(node.Memory.Usage, thresholds.Memory), fmt.Sprintf("%.1f%%", node.Memory.Usage)),
 			float64(node.Memory.Used)/1024/1024/1024,
 			float64(node.Memory.Total)/1024/1024/1024)
-		fmt.Printf("  Storage: %.1f%% (%.1f GB used / %.1f GB total)\n",
-			node.Storage.Usage,
+		fmt.Fprintf(w, "  Storage: %s (%.1f GB used / %.1f GB total)\n",
+			colorize(w, usageColor(node.Storage.Usage, thresholds.Storage), fmt.Sprintf("%.1f%%", node.Storage.Usage)),
 			float64(node.Storage.Used)/1024/1024/1024,
 			float64(node.Storage.Total)/1024/1024/1024)
-		fmt.Printf("  VMs: %d\n", len(node.VMs))
-		fmt.Println()
+		vms := fmt.Sprintf("%d", len(node.VMs))
+		if dense[node.Name] {
+			vms = colorize(w, colorYellow, vms+" (dense)")
+		}
+		fmt.Fprintf(w, "  VMs: %s\n", vms)
+		fmt.Fprintln(w)
+	}
+
+	if len(density) > 0 {
+		fmt.Fprintln(w, "=== VM Density ===")
+		for _, finding := range density {
+			fmt.Fprintf(w, "• %s\n", finding.recommendation())
+		}
 	}
 
 	return nil
 }
 
+// clusterTotals sums node capacities and usage, and the vCPUs and memory configured for running VMs.
+func clusterTotals(nodes []models.Node) models.ClusterTotals {
+	var totals models.ClusterTotals
+	for i := range nodes {
+		node := &nodes[i]
+		totals.CPUCores += node.CPU.Cores
+		totals.MemoryTotal += node.Memory.Total
+		totals.MemoryUsed += node.Memory.Used
+		totals.StorageTotal += node.Storage.Total
+		totals.StorageUsed += node.Storage.Used
+
+		for j := range node.VMs {
+			vm := &node.VMs[j]
+			if vm.Status == vmStatusRunning {
+				totals.AllocatedVCPUs += vm.CPUs
+				totals.MemoryAllocated += vm.MaxMemory
+			}
+		}
+	}
+	return totals
+}
+
 // ListVMs lists all VMs in the cluster.
 func ListVMs(configPath string) error {
 	var app *App
@@ -384,27 +556,32 @@ func ListVMs(configPath string) error {
 	}
 	defer app.cancel()
 
+	return app.listVMs(os.Stdout)
+}
+
+// listVMs writes the VMs of every node with a summary, coloring node and VM states.
+func (app *App) listVMs(w io.Writer) error {
 	// Get nodes and their VMs
 	nodes, err := app.client.GetNodes()
 	if err != nil {
 		return fmt.Errorf("failed to get nodes: %w", err)
 	}
 
-	fmt.Println("=== Virtual Machines ===")
+	fmt.Fprintln(w, "=== Virtual Machines ===")
 	totalVMs := 0
 	runningVMs := 0
 
 	for i := range nodes {
 		node := &nodes[i]
-		fmt.Printf("\nNode: %s\n", node.Name)
-		fmt.Printf("  Status: %s\n", node.Status)
+		fmt.Fprintf(w, "\nNode: %s\n", node.Name)
+		fmt.Fprintf(w, "  Status: %s\n", colorize(w, statusColor(node.Status), node.Status))
 
 		if len(node.VMs) == 0 {
-			fmt.Println("  No VMs")
+			fmt.Fprintln(w, "  No VMs")
 			continue
 		}
 
-		fmt.Printf("  VMs (%d):\n", len(node.VMs))
+		fmt.Fprintf(w, "  VMs (%d):\n", len(node.VMs))
 		for j := range node.VMs {
 			vm := &node.VMs[j]
 			totalVMs++
@@ -414,22 +591,161 @@ func ListVMs(configPath string) error {
 				runningVMs++
 			}
 
-			fmt.Printf("    %d: %s (%s) - %s\n", vm.ID, vm.Name, vm.Type, status)
+			fmt.Fprintf(w, "    %d: %s (%s) - %s\n", vm.ID, vm.Name, vm.Type, colorize(w, statusColor(status), status))
 			if vm.Status == vmStatusRunning {
-				fmt.Printf("      CPU: %.1f%%, Memory: %.1f GB\n",
+				fmt.Fprintf(w, "      CPU: %.1f%%, Memory: %.1f GB\n",
 					vm.CPU, float64(vm.Memory)/1024/1024/1024)
 			}
 		}
 	}
 
-	fmt.Printf("\n=== Summary ===\n")
-	fmt.Printf("Total VMs: %d\n", totalVMs)
-	fmt.Printf("Running VMs: %d\n", runningVMs)
-	fmt.Printf("Stopped VMs: %d\n", totalVMs-runningVMs)
+	fmt.Fprintf(w, "\n=== Summary ===\n")
+	fmt.Fprintf(w, "Total VMs: %d\n", totalVMs)
+	fmt.Fprintf(w, "Running VMs: %d\n", runningVMs)
+	fmt.Fprintf(w, "Stopped VMs: %d\n", totalVMs-runningVMs)
+
+	return nil
+}
+
+// ShowRules shows the placement rules extracted from VM tags and any conflicts between them.
+func ShowRules(configPath string) error {
+	app, err := initializeApp(configPath)
+	if err != nil {
+		return err
+	}
+	defer app.cancel()
+
+	nodes, err := app.client.GetNodes()
+	if err != nil {
+		return fmt.Errorf("failed to get nodes: %w", err)
+	}
+
+	engine, conflicts, err := evaluateRules(app.config, nodes)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("=== Placement Rules ===")
+	fmt.Printf("Affinity groups: %d\n", len(engine.GetAffinityGroups()))
+	for tag, group := range engine.GetAffinityGroups() {
+		fmt.Printf("  %s: %d VMs on %v\n", tag, len(group.VMs), group.Nodes)
+	}
+	fmt.Printf("Anti-affinity groups: %d\n", len(engine.GetAntiAffinityGroups()))
+	for tag, group := range engine.GetAntiAffinityGroups() {
+		fmt.Printf("  %s: %d VMs on %v\n", tag, len(group.VMs), group.Nodes)
+	}
+	fmt.Printf("Pinned VMs: %d\n", len(engine.GetPinnedVMs()))
+	for vmID, pinned := range engine.GetPinnedVMs() {
+		fmt.Printf("  %s (%d): %v\n", pinned.VM.Name, vmID, pinned.Nodes)
+	}
+	fmt.Printf("Ignored VMs: %d\n", len(engine.GetIgnoredVMs()))
+
+	fmt.Println("\n=== Rule Conflicts ===")
+	if len(conflicts) == 0 {
+		fmt.Println("✅ No conflicting rules detected")
+	}
+	for i := range conflicts {
+		fmt.Printf("❌ [%s] %s\n", conflicts[i].Type, conflicts[i].Message)
+	}
+
+	fmt.Println("\n=== Unschedulable VMs ===")
+	unschedulable := findUnschedulableVMs(app.config, engine, nodes)
+	if len(unschedulable) == 0 {
+		fmt.Println("✅ Every VM that should move has a valid target")
+	}
+	for i := range unschedulable {
+		vm := &unschedulable[i]
+		fmt.Printf("⚠️  %s (%d) on %s: %s\n", vm.Name, vm.VMID, vm.Node, vm.Reason)
+	}
 
 	return nil
 }
 
+// findUnschedulableVMs lists the VMs that should leave their node (maintenance or over a threshold)
+// but that no other available node may host under the placement rules.
+func findUnschedulableVMs(cfg *config.Config, engine *rules.Engine, nodes []models.Node) []models.UnschedulableVM {
+	maintenance := make(map[string]bool)
+	for _, name := range cfg.Cluster.MaintenanceNodes {
+		maintenance[name] = true
+	}
+
+	var availableNodes []string
+	for i := range nodes {
+		if !maintenance[nodes[i].Name] {
+			availableNodes = append(availableNodes, nodes[i].Name)
+		}
+	}
+
+	var unschedulable []models.UnschedulableVM
+	for i := range nodes {
+		node := &nodes[i]
+		reason := ""
+		switch {
+		case maintenance[node.Name]:
+			reason = "node in maintenance"
+		case node.CPU.Usage > float32(cfg.Balancing.Thresholds.CPU) ||
+			node.Memory.Usage > float32(cfg.Balancing.Thresholds.Memory) ||
+			node.Storage.Usage > float32(cfg.Balancing.Thresholds.Storage):
+			reason = "node over threshold"
+		default:
+			continue
+		}
+
+		var targets []string
+		for _, name := range availableNodes {
+			if name != node.Name {
+				targets = append(targets, name)
+			}
+		}
+
+		for j := range node.VMs {
+			vm := &node.VMs[j]
+			if engine.IsIgnored(vm.ID) || len(engine.GetValidTargetNodes(vm, targets)) > 0 {
+				continue
+			}
+			unschedulable = append(unschedulable, models.UnschedulableVM{
+				VMID:   vm.ID,
+				Name:   vm.Name,
+				Node:   node.Name,
+				Reason: reason + ", placement rules exclude every other node",
+			})
+		}
+	}
+
+	return unschedulable
+}
+
+// evaluateRules processes the VM tags of all nodes and detects conflicts against the non-maintenance nodes.
+func evaluateRules(cfg *config.Config, nodes []models.Node) (*rules.Engine, []models.RuleConflict, error) {
+	var allVMs []models.VM
+	var availableNodes []string
+	for i := range nodes {
+		node := &nodes[i]
+		allVMs = append(allVMs, node.VMs...)
+
+		inMaintenance := false
+		for _, maintenanceNode := range cfg.Cluster.MaintenanceNodes {
+			if maintenanceNode == node.Name {
+				inMaintenance = true
+				break
+			}
+		}
+		if !inMaintenance {
+			availableNodes = append(availableNodes, node.Name)
+		}
+	}
+
+	engine := rules.NewEngine()
+	engine.SetExcludedVMIDs(cfg.Balancing.ExcludeVMIDs)
+	engine.SetNodeZones(cfg.Cluster.NodeZones())
+	engine.SetNodePCIMappings(nodes)
+	if err := engine.ProcessVMs(allVMs); err != nil {
+		return nil, nil, fmt.Errorf("failed to process VM rules: %w", err)
+	}
+
+	return engine, engine.DetectConflicts(availableNodes), nil
+}
+
 // ForceBalance forces a balancing operation.
 func ForceBalance(configPath string, force bool) error {
 	app, err := NewApp(configPath)
@@ -452,63 +768,203 @@ func ForceBalance(configPath string, force bool) error {
 
 	fmt.Printf("Balance operation completed. %d migrations executed:\n", len(results))
 	for i := range results {
-		result := &results[i]
-		if result.Success {
-			fmt.Printf("  ✓ Migrated VM %d from %s to %s\n", result.VM.ID, result.SourceNode, result.TargetNode)
-		} else {
-			fmt.Printf("  ✗ Failed to migrate VM %d: %s\n", result.VM.ID, result.ErrorMessage)
-		}
+		printBalancingResult(os.Stdout, &results[i])
 	}
 
 	return nil
 }
 
-// ForceBalanceWithBalancerType forces a balancing operation with a specific balancer type.
-func ForceBalanceWithBalancerType(configPath string, force bool, balancerType string) error {
+// BalanceOptions holds the command-line overrides for a forced balancing operation.
+type BalanceOptions struct {
+	Force        bool
+	ForceMode    string // "always" or "reevaluate", empty keeps the configured mode
+	BalancerType string // "threshold" or "advanced", empty keeps the configured type
+	DryRun       bool   // Plan migrations without executing them
+	Output       string // "text" (default), "dot" for a Graphviz plan or "junit" for a JUnit XML report; dot and junit require DryRun
+	Yes          bool   // Execute without asking, otherwise a non-forced balance from a terminal asks to confirm the plan
+}
+
+// ForceBalanceWithBalancerType forces a balancing operation with the given overrides.
+func ForceBalanceWithBalancerType(configPath string, opts BalanceOptions) error {
 	app, err := NewApp(configPath)
 	if err != nil {
 		return err
 	}
 	defer app.cancel()
 
+	return app.forceBalance(opts)
+}
+
+// forceBalance runs a forced balancing operation with the given overrides, printing the outcome on
+// stdout. Diagnostics go to stderr, so a DOT or JUnit plan is the only thing on stdout.
+func (app *App) forceBalance(opts BalanceOptions) error {
+	if err := app.applyBalanceOptions(opts); err != nil {
+		return err
+	}
+
+	switch opts.Output {
+	case outputDOT:
+		return app.printMigrationPlanDOT(opts.Force)
+	case outputJUnit:
+		return app.printMigrationPlanJUnit(opts.Force)
+	}
+
+	fmt.Printf("Forcing balance operation (force=%v, mode=%s, balancer=%s)...\n", opts.Force, app.config.Balancing.ForceMode, app.config.Balancing.BalancerType)
+
+	// An interactive, non-forced balance shows the plan and asks before migrating
+	var (
+		results []models.BalancingResult
+		err     error
+	)
+	if needsConfirmation(opts, app.config.ReadOnly, isTerminal(os.Stdin)) {
+		results, err = app.confirmedBalance(os.Stdin, os.Stdout, opts.Force)
+	} else {
+		results, err = app.balancer.Run(opts.Force)
+	}
+	if errors.Is(err, errBalanceDeclined) {
+		fmt.Println("Balance cancelled, no migrations executed")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("balance operation failed: %w", err)
+	}
+
+	printBalancingResults(os.Stdout, results, app.config.ReadOnly)
+	printEmptiedNodes(os.Stdout, app.balancer)
+
+	// A dry run also explains why the other evaluated VMs stay put
+	if opts.DryRun {
+		printSkippedVMs(os.Stdout, app.balancer)
+	}
+
+	return nil
+}
+
+// describeGain summarizes the gain a migration was planned on, what it frees on the source node
+// and, when estimated, how long it takes. Storage migrations, copying local disks, are labeled, and
+// the placement rules that drew the VM to its target listed.
+func describeGain(result *models.BalancingResult) string {
+	description := fmt.Sprintf("gain: %.2f, freed ~%.0f%% CPU and ~%.0f%% memory on %s",
+		result.ResourceGain, result.Freed.CPU, result.Freed.Memory, result.SourceNode)
+	if result.StorageMigration {
+		description += ", " + storageMigrationLabel
+	}
+	if result.EstimatedDuration > 0 {
+		description += fmt.Sprintf(", est. %v", result.EstimatedDuration)
+	}
+	if len(result.Rules) > 0 {
+		description += ", rules: " + strings.Join(result.Rules, ", ")
+	}
+	return description
+}
+
+// printSkippedVMs lists the VMs the balancer evaluated but left in place, with the reasons and the
+// placement rules behind them.
+func printSkippedVMs(w io.Writer, balancerInstance BalancerInterface) {
+	reporter, ok := balancerInstance.(SkippedVMReporter)
+	if !ok {
+		return
+	}
+
+	skipped := reporter.GetSkippedVMs()
+	if len(skipped) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Evaluated but skipped %d VMs:\n", len(skipped))
+	for _, vm := range skipped {
+		reason := vm.Reason
+		if len(vm.Rules) > 0 {
+			reason += " [rules: " + strings.Join(vm.Rules, ", ") + "]"
+		}
+		fmt.Fprintf(w, "  - VM %d (%s) on %s: %s\n", vm.VMID, vm.Name, vm.Node, reason)
+	}
+}
+
+// printEmptiedNodes lists the nodes a consolidation cycle left without VMs, ready to be powered down.
+func printEmptiedNodes(w io.Writer, balancerInstance BalancerInterface) {
+	reporter, ok := balancerInstance.(ConsolidationReporter)
+	if !ok {
+		return
+	}
+
+	if emptied := reporter.GetEmptiedNodes(); len(emptied) > 0 {
+		fmt.Fprintf(w, "Nodes left empty: %s\n", strings.Join(emptied, ", "))
+	}
+}
+
+// applyBalanceOptions validates the balance overrides and applies them to the app.
+func (app *App) applyBalanceOptions(opts BalanceOptions) error {
+	switch opts.Output {
+	case "", outputText:
+	case outputDOT, outputJUnit:
+		if !opts.DryRun {
+			return fmt.Errorf("--output %s requires --dry-run", opts.Output)
+		}
+	default:
+		return fmt.Errorf("invalid output format: %s (must be '%s', '%s' or '%s')", opts.Output, outputText, outputDOT, outputJUnit)
+	}
+
+	// A dry run plans like observer mode: nothing is migrated
+	if opts.DryRun {
+		app.config.ReadOnly = true
+	}
+
+	// Override force mode if specified
+	if opts.ForceMode != "" {
+		if err := config.ValidateForceMode(opts.ForceMode); err != nil {
+			return err
+		}
+		app.config.Balancing.ForceMode = opts.ForceMode
+	}
+
 	// Override balancer type if specified
-	if balancerType != "" {
-		if balancerType != balancerThreshold && balancerType != balancerAdvanced {
-			return fmt.Errorf("invalid balancer type: %s (must be 'threshold' or 'advanced')", balancerType)
+	if opts.BalancerType != "" {
+		if opts.BalancerType != balancerThreshold && opts.BalancerType != balancerAdvanced && opts.BalancerType != balancerConsolidate {
+			return fmt.Errorf("invalid balancer type: %s (must be 'threshold', 'advanced' or 'consolidate')", opts.BalancerType)
 		}
-		app.config.Balancing.BalancerType = balancerType
+		app.config.Balancing.BalancerType = opts.BalancerType
 
 		// Recreate the balancer with the new type
-		client := app.client
-		if app.config.IsAdvancedBalancer() {
-			app.balancer = balancer.NewAdvancedBalancer(client, app.config)
-		} else {
-			app.balancer = balancer.NewBalancer(client, app.config)
-		}
+		app.balancer = setupBalancer(app.client, app.config)
 	}
 
-	fmt.Printf("Forcing balance operation (force=%v, balancer=%s)...\n", force, app.config.Balancing.BalancerType)
+	return nil
+}
 
+// printMigrationPlanDOT computes a dry-run plan and prints it as a Graphviz DOT graph.
+func (app *App) printMigrationPlanDOT(force bool) error {
 	results, err := app.balancer.Run(force)
 	if err != nil {
 		return fmt.Errorf("balance operation failed: %w", err)
 	}
 
-	if len(results) == 0 {
-		fmt.Println("No balancing actions performed")
-		return nil
+	nodes, err := app.client.GetNodes()
+	if err != nil {
+		return fmt.Errorf("failed to get nodes: %w", err)
 	}
 
-	fmt.Printf("Balance operation completed. %d migrations executed:\n", len(results))
-	for i := range results {
-		result := &results[i]
-		if result.Success {
-			fmt.Printf("  ✓ Migrated VM %d from %s to %s\n", result.VM.ID, result.SourceNode, result.TargetNode)
-		} else {
-			fmt.Printf("  ✗ Failed to migrate VM %d: %s\n", result.VM.ID, result.ErrorMessage)
-		}
+	fmt.Print(renderMigrationPlanDOT(nodes, migrationsFromResults(results)))
+	return nil
+}
+
+// printMigrationPlanJUnit computes a dry-run plan and prints it as a JUnit XML report.
+func (app *App) printMigrationPlanJUnit(force bool) error {
+	results, err := app.balancer.Run(force)
+	if err != nil {
+		return fmt.Errorf("balance operation failed: %w", err)
+	}
+
+	var skipped []models.SkippedVM
+	if reporter, ok := app.balancer.(SkippedVMReporter); ok {
+		skipped = reporter.GetSkippedVMs()
 	}
 
+	report, err := renderMigrationPlanJUnit(migrationsFromResults(results), skipped, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Print(report)
 	return nil
 }
 
@@ -528,8 +984,64 @@ func ShowCapacityPlanning(configPath string, detailed bool, forecast, csvOutput
 	return displayCapacityPlanningResults(context, adaptationRecommendations)
 }
 
-// writeCSVFile writes the CSV data to a file.
-func writeCSVFile(filename string, data [][]string) error {
+// csvFormat renders CSV reports in the configured delimiter and decimal separator.
+type csvFormat struct {
+	delimiter rune
+	decimal   string
+}
+
+// newCSVFormat creates the CSV format from the configuration, defaulting to comma and dot.
+func newCSVFormat(cfg config.CSVConfig) csvFormat {
+	format := csvFormat{delimiter: ',', decimal: "."}
+	if cfg.Delimiter != "" {
+		format.delimiter = []rune(cfg.Delimiter)[0]
+	}
+	if cfg.DecimalSeparator != "" {
+		format.decimal = cfg.DecimalSeparator
+	}
+	return format
+}
+
+// number formats a value with one decimal using the configured decimal separator.
+func (f csvFormat) number(value float64) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', 1, 64), ".", f.decimal, 1)
+}
+
+// validateCSVOutputPath checks that the CSV output directory exists and is writable.
+func validateCSVOutputPath(filename string) error {
+	if info, err := os.Stat(filename); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory", filename)
+		}
+		// Open without truncating so an existing report survives the check
+		file, err := os.OpenFile(filename, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("%s is not writable: %w", filename, err)
+		}
+		file.Close() //nolint:errcheck // file was only opened to check permissions
+		return nil
+	}
+
+	dir := filepath.Dir(filepath.Clean(filename))
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("output directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("output directory %s is not a directory", dir)
+	}
+
+	probe, err := os.CreateTemp(dir, ".goproxlb-csv-*")
+	if err != nil {
+		return fmt.Errorf("output directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()           //nolint:errcheck // probe file is removed right away
+	os.Remove(probe.Name()) //nolint:errcheck // best-effort cleanup of the probe file
+	return nil
+}
+
+// writeCSVFile writes the CSV data to a file, separating fields with delimiter.
+func writeCSVFile(filename string, data [][]string, delimiter rune) error {
 	// Validate filename to prevent path traversal attacks
 	cleanFilename := filepath.Clean(filename)
 	if !filepath.IsAbs(cleanFilename) {
@@ -551,6 +1063,7 @@ func writeCSVFile(filename string, data [][]string) error {
 	defer file.Close() //nolint:errcheck // file is being written, close error not actionable
 
 	writer := csv.NewWriter(file)
+	writer.Comma = delimiter
 	defer writer.Flush()
 
 	for _, row := range data {
@@ -666,6 +1179,15 @@ func displayRaftClusterStatus(status map[string]interface{}) {
 	fmt.Printf("Current State: %s\n", status["raft_state"])
 	fmt.Printf("Is Leader: %v\n", status["is_leader"])
 	fmt.Printf("Current Leader: %s\n", status["leader"])
+	if score, ok := status["balance_score"].(float64); ok {
+		fmt.Printf("Balance Score: %.0f/100\n", score)
+	}
+	if lastRun, ok := status["last_run"].(string); ok {
+		fmt.Printf("Last Run: %s\n", lastRun)
+	}
+	if nextRun, ok := status["next_run"].(string); ok {
+		fmt.Printf("Next Run: %s\n", nextRun)
+	}
 }
 
 // displayClusterHealth shows cluster health information including quorum status.
@@ -751,34 +1273,7 @@ func InstallService(user, group, configPath string, enableService bool) error {
 	}
 
 	// Create the service file content
-	serviceContent := fmt.Sprintf(`[Unit]
-Description=%s
-After=network.target
-Wants=network-online.target
-After=network-online.target
-
-[Service]
-Type=simple
-User=%s
-Group=%s
-WorkingDirectory=/var/lib/goproxlb
-ExecStart=%s
-Restart=on-failure
-RestartSec=10
-StandardOutput=journal
-StandardError=journal
-SyslogIdentifier=goproxlb
-
-# Security settings
-NoNewPrivileges=true
-PrivateTmp=true
-ProtectSystem=strict
-ProtectHome=true
-ReadWritePaths=/var/lib/goproxlb
-
-[Install]
-WantedBy=multi-user.target
-`, serviceDescription, user, group, serviceExec)
+	serviceContent := serviceUnitContent(serviceDescription, user, group, serviceExec)
 
 	// Define the service file path
 	serviceFilePath := "/etc/systemd/system/" + serviceName + ".service"
@@ -799,36 +1294,55 @@ WantedBy=multi-user.target
 	// Create user and group if they don't exist
 	createUserAndGroup(user, group)
 
-	// Write the service file
-	//nolint:gosec // Systemd service files need to be readable by systemd (0644 is correct)
-	if err := os.WriteFile(serviceFilePath, []byte(serviceContent), 0644); err != nil {
-		return fmt.Errorf("failed to write service file %s: %w", serviceFilePath, err)
+	// Only rewrite the service file when its content differs
+	state, err := compareUnitFile(serviceFilePath, serviceContent)
+	if err != nil {
+		return err
+	}
+	if state != unitFileUnchanged {
+		//nolint:gosec // Systemd service files need to be readable by systemd (0644 is correct)
+		if err := os.WriteFile(serviceFilePath, []byte(serviceContent), 0644); err != nil {
+			return fmt.Errorf("failed to write service file %s: %w", serviceFilePath, err)
+		}
 	}
 
 	// Set proper ownership
 	setOwnership(user, group, dirs)
 
 	// Reload systemd daemon
-	if err := exec.Command("systemctl", "daemon-reload").Run(); err != nil {
-		return fmt.Errorf("failed to reload systemd daemon: %w", err)
+	if state != unitFileUnchanged {
+		if err := exec.Command("systemctl", "daemon-reload").Run(); err != nil {
+			return fmt.Errorf("failed to reload systemd daemon: %w", err)
+		}
 	}
 
-	// Enable and start service if requested
+	// Enable and start service if requested, restarting it if it runs an outdated unit
 	if enableService {
 		// Enable service
 		if err := exec.Command("systemctl", "enable", serviceName).Run(); err != nil {
 			return fmt.Errorf("failed to enable service: %w", err)
 		}
 
-		// Start service
-		if err := exec.Command("systemctl", "start", serviceName).Run(); err != nil {
-			return fmt.Errorf("failed to start service: %w", err)
+		active := exec.Command("systemctl", "is-active", "--quiet", serviceName).Run() == nil
+		switch action := serviceAction(state, active); action {
+		case "":
+			fmt.Printf("✅ Service already enabled and running.\n")
+		default:
+			if err := exec.Command("systemctl", action, serviceName).Run(); err != nil {
+				return fmt.Errorf("failed to %s service: %w", action, err)
+			}
+			fmt.Printf("✅ Service enabled and %sed successfully.\n", action)
 		}
-
-		fmt.Printf("✅ Service enabled and started successfully.\n")
 	}
 
-	fmt.Printf("✅ Service file %s created successfully.\n", serviceFilePath)
+	switch state {
+	case unitFileMissing:
+		fmt.Printf("✅ Service file %s created successfully.\n", serviceFilePath)
+	case unitFileChanged:
+		fmt.Printf("✅ Service file %s updated.\n", serviceFilePath)
+	case unitFileUnchanged:
+		fmt.Printf("✅ Service file %s is up to date, no changes.\n", serviceFilePath)
+	}
 	fmt.Printf("✅ User '%s' and group '%s' created.\n", user, group)
 	fmt.Printf("✅ Directories created with proper permissions.\n")
 
@@ -848,30 +1362,18 @@ WantedBy=multi-user.target
 	return nil
 }
 
-// installServiceDryRun shows what would be installed without actually doing it.
-func installServiceDryRun(user, group, configPath string, enableService bool) error {
-	serviceName := "goproxlb"
-	serviceDescription := "GoProxLB Load Balancer"
-
-	// Determine executable path
-	execPath := os.Args[0]
-	if !filepath.IsAbs(execPath) {
-		// If relative path, try to find the absolute path
-		if absPath, err := exec.LookPath(execPath); err == nil {
-			execPath = absPath
-		}
-	}
+// unitFileState tells how an installed unit file compares to the one install would write.
+type unitFileState int
 
-	// Build service command
-	var serviceExec string
-	if configPath != "" {
-		serviceExec = fmt.Sprintf("%s start --config %s", execPath, configPath)
-	} else {
-		serviceExec = fmt.Sprintf("%s start", execPath)
-	}
+const (
+	unitFileMissing unitFileState = iota
+	unitFileChanged
+	unitFileUnchanged
+)
 
-	// Create the service file content
-	serviceContent := fmt.Sprintf(`[Unit]
+// serviceUnitContent builds the systemd unit file of the service.
+func serviceUnitContent(description, user, group, serviceExec string) string {
+	return fmt.Sprintf(`[Unit]
 Description=%s
 After=network.target
 Wants=network-online.target
@@ -898,7 +1400,61 @@ ReadWritePaths=/var/lib/goproxlb
 
 [Install]
 WantedBy=multi-user.target
-`, serviceDescription, user, group, serviceExec)
+`, description, user, group, serviceExec)
+}
+
+// compareUnitFile compares the unit file at path with the desired content.
+func compareUnitFile(path, content string) (unitFileState, error) {
+	existing, err := os.ReadFile(path) //nolint:gosec // path is the fixed systemd unit location
+	if os.IsNotExist(err) {
+		return unitFileMissing, nil
+	}
+	if err != nil {
+		return unitFileMissing, fmt.Errorf("failed to read service file %s: %w", path, err)
+	}
+	if string(existing) == content {
+		return unitFileUnchanged, nil
+	}
+	return unitFileChanged, nil
+}
+
+// serviceAction returns the systemctl action bringing an enabled service in line with its unit file:
+// start it when stopped, restart it when running an updated unit, none ("") when it runs the current one.
+func serviceAction(state unitFileState, active bool) string {
+	switch {
+	case !active:
+		return "start"
+	case state == unitFileUnchanged:
+		return ""
+	default:
+		return "restart"
+	}
+}
+
+// installServiceDryRun shows what would be installed without actually doing it.
+func installServiceDryRun(user, group, configPath string, enableService bool) error {
+	serviceName := "goproxlb"
+	serviceDescription := "GoProxLB Load Balancer"
+
+	// Determine executable path
+	execPath := os.Args[0]
+	if !filepath.IsAbs(execPath) {
+		// If relative path, try to find the absolute path
+		if absPath, err := exec.LookPath(execPath); err == nil {
+			execPath = absPath
+		}
+	}
+
+	// Build service command
+	var serviceExec string
+	if configPath != "" {
+		serviceExec = fmt.Sprintf("%s start --config %s", execPath, configPath)
+	} else {
+		serviceExec = fmt.Sprintf("%s start", execPath)
+	}
+
+	// Create the service file content
+	serviceContent := serviceUnitContent(serviceDescription, user, group, serviceExec)
 
 	fmt.Println("🔍 DRY-RUN MODE - What would be installed:")
 	fmt.Println()
@@ -911,7 +1467,17 @@ WantedBy=multi-user.target
 	fmt.Printf("   User: %s\n", user)
 	fmt.Printf("   Group: %s\n", group)
 	fmt.Println()
-	fmt.Printf("📄 Service file to create: /etc/systemd/system/%s.service\n", serviceName)
+	serviceFilePath := "/etc/systemd/system/" + serviceName + ".service"
+	switch state, err := compareUnitFile(serviceFilePath, serviceContent); {
+	case err != nil:
+		fmt.Printf("📄 Service file to write: %s (%v)\n", serviceFilePath, err)
+	case state == unitFileUnchanged:
+		fmt.Printf("📄 Service file %s is up to date, no changes\n", serviceFilePath)
+	case state == unitFileChanged:
+		fmt.Printf("📄 Service file to update: %s\n", serviceFilePath)
+	default:
+		fmt.Printf("📄 Service file to create: %s\n", serviceFilePath)
+	}
 	fmt.Println()
 	fmt.Printf("⚙️  Service configuration:\n")
 	fmt.Printf("   Executable: %s\n", execPath)
@@ -972,10 +1538,18 @@ type capacityPlanningContext struct {
 	forecastDuration time.Duration
 	csvData          [][]string
 	csvOutput        string
+	csvFormat        csvFormat
 }
 
 // setupCapacityPlanningContext initializes the context for capacity planning.
 func setupCapacityPlanningContext(configPath, forecast, csvOutput string) (*capacityPlanningContext, error) {
+	// Check the CSV output path before spending time on the analysis
+	if csvOutput != "" {
+		if err := validateCSVOutputPath(csvOutput); err != nil {
+			return nil, fmt.Errorf("invalid csv output: %w", err)
+		}
+	}
+
 	// Load configuration
 	cfg, err := config.Load(configPath)
 	if err != nil {
@@ -991,8 +1565,13 @@ func setupCapacityPlanningContext(configPath, forecast, csvOutput string) (*capa
 		return nil, fmt.Errorf("failed to get nodes: %w", err)
 	}
 
-	// Create advanced balancer for capacity analysis
-	balancerInstance := balancer.NewAdvancedBalancer(client, cfg)
+	// The advanced balancer brings historical analysis, the threshold balancer uses live metrics
+	var balancerInstance BalancerInterface
+	if cfg.Balancing.BalancerType == balancerThreshold {
+		balancerInstance = balancer.NewBalancer(client, cfg)
+	} else {
+		balancerInstance = balancer.NewAdvancedBalancer(client, cfg)
+	}
 
 	// Parse forecast period
 	forecastDuration := parseForecastDuration(forecast)
@@ -1003,7 +1582,7 @@ func setupCapacityPlanningContext(configPath, forecast, csvOutput string) (*capa
 		csvData = [][]string{{
 			"Type", "Name", "ID", "Status", "WorkloadType", "CurrentCPU%", "CurrentMemory%", "CurrentStorage%",
 			"P90CPU%", "P95CPU%", "P99CPU%", "PredictedCPU%", "PredictedMemory%", "CurrentCPUCores", "CurrentMemoryGB",
-			"RecommendedCPUCores", "RecommendedMemoryGB", "Criticality", "Pattern", "Recommendations",
+			"RecommendedCPUCores", "RecommendedMemoryGB", "Criticality", "Pattern", "Confidence%", "Recommendations",
 		}}
 	}
 
@@ -1015,6 +1594,7 @@ func setupCapacityPlanningContext(configPath, forecast, csvOutput string) (*capa
 		forecastDuration: forecastDuration,
 		csvData:          csvData,
 		csvOutput:        csvOutput,
+		csvFormat:        newCSVFormat(cfg.CSV),
 	}, nil
 }
 
@@ -1072,13 +1652,10 @@ func analyzeNodesForCapacityPlanning(context *capacityPlanningContext, detailed
 func analyzeNodeCapacity(context *capacityPlanningContext, node *models.Node, recommendationCounter *int, detailed bool) []string {
 	var recommendations []string
 
-	// Get capacity metrics (only available on AdvancedBalancer)
+	// Historical capacity metrics are only available on AdvancedBalancer
 	advancedBalancer, ok := context.balancer.(*balancer.AdvancedBalancer)
 	if !ok {
-		fmt.Printf("   Current CPU: %.1f%% | Memory: %.1f%% | Storage: %.1f%%\n",
-			node.CPU.Usage, node.Memory.Usage, node.Storage.Usage)
-		fmt.Printf("   ⚠️  Advanced capacity planning requires advanced balancer\n")
-		return recommendations
+		return analyzeNodeLiveCapacity(context, node, recommendationCounter)
 	}
 
 	metrics, hasMetrics := advancedBalancer.GetCapacityMetrics(node.Name)
@@ -1164,8 +1741,12 @@ func analyzeNodeVMs(context *capacityPlanningContext, node *models.Node, recomme
 	// Get advanced balancer for VM profile analysis
 	advancedBalancer, ok := context.balancer.(*balancer.AdvancedBalancer)
 	if !ok {
-		// Fall back to grouping all VMs as "Standard" if not using advanced balancer
-		workloadGroups["Standard"] = append(workloadGroups["Standard"], node.VMs...)
+		// Without the advanced balancer, classify VMs from their live usage
+		for j := range node.VMs {
+			vm := &node.VMs[j]
+			workloadType := liveVMProfile(vm).WorkloadType
+			workloadGroups[workloadType] = append(workloadGroups[workloadType], *vm)
+		}
 	} else {
 		for j := range node.VMs {
 			vm := &node.VMs[j]
@@ -1197,19 +1778,21 @@ func analyzeVMCapacity(context *capacityPlanningContext, vm *models.VM, nodeName
 	if advancedBalancer, ok := context.balancer.(*balancer.AdvancedBalancer); ok {
 		vmProfile = advancedBalancer.AnalyzeVMProfile(vm, nodeName)
 	} else {
-		// Fallback profile for basic balancer
-		vmProfile = balancer.VMProfile{
-			WorkloadType:    workloadType,
-			Pattern:         "Standard",
-			Criticality:     "Normal",
-			Recommendations: []string{"Using basic balancer - limited analysis available"},
-		}
+		vmProfile = liveVMProfile(vm)
 	}
 	fmt.Printf("       🖥️  %s (ID: %d) - %s\n", vm.Name, vm.ID, vm.Status)
 
 	// Generate VM-specific adaptation recommendations
+	// Prefer the allocated vCPUs and configured memory over the live usage
 	currentCPU := int(vm.CPU)
-	currentMemoryGB := float64(vm.Memory) / 1024 / 1024 / 1024
+	if vm.CPUs > 0 {
+		currentCPU = vm.CPUs
+	}
+	memory := vm.Memory
+	if vm.MaxMemory > 0 {
+		memory = vm.MaxMemory
+	}
+	currentMemoryGB := float64(memory) / 1024 / 1024 / 1024
 
 	// Calculate recommended resources based on workload type
 	recommendedCPU, recommendedMemoryGB := calculateVMRecommendations(currentCPU, currentMemoryGB, workloadType, vmProfile.Criticality)
@@ -1227,7 +1810,8 @@ func analyzeVMCapacity(context *capacityPlanningContext, vm *models.VM, nodeName
 	addVMToCSV(context, vm, workloadType, currentCPU, currentMemoryGB, recommendedCPU, recommendedMemoryGB, vmProfile)
 
 	if detailed {
-		fmt.Printf("         Pattern: %s | Criticality: %s\n", vmProfile.Pattern, vmProfile.Criticality)
+		fmt.Printf("         Pattern: %s | Criticality: %s | Confidence: %.0f%%\n",
+			vmProfile.Pattern, vmProfile.Criticality, vmProfile.Confidence*100)
 		if len(vmProfile.Recommendations) > 0 {
 			fmt.Printf("         Recommendations:\n")
 			for _, rec := range vmProfile.Recommendations {
@@ -1287,19 +1871,16 @@ func displayCapacityPlanningResults(context *capacityPlanningContext, adaptation
 	if advancedBalancer, ok := context.balancer.(*balancer.AdvancedBalancer); ok {
 		clusterRecommendations = advancedBalancer.GetClusterRecommendations(context.forecastDuration)
 	} else {
-		clusterRecommendations = []string{
-			"📊 Monitor resource distribution across nodes for optimal balance",
-			"🔄 Regular capacity planning reviews recommended",
-			"⚠️  Advanced cluster recommendations require advanced balancer",
-		}
+		clusterRecommendations = liveClusterRecommendations(context.cfg, context.nodes)
 	}
+	clusterRecommendations = append(clusterRecommendations, densityRecommendations(context.nodes)...)
 	for _, rec := range clusterRecommendations {
 		fmt.Printf("• %s\n", rec)
 	}
 
 	// Write CSV file if requested
 	if context.csvOutput != "" {
-		if err := writeCSVFile(context.csvOutput, context.csvData); err != nil {
+		if err := writeCSVFile(context.csvOutput, context.csvData, context.csvFormat.delimiter); err != nil {
 			return fmt.Errorf("failed to write CSV file: %w", err)
 		}
 		fmt.Printf("📊 CSV report written to: %s\n", context.csvOutput)
@@ -1313,6 +1894,7 @@ func addNodeToCSV(context *capacityPlanningContext, node *models.Node, metrics i
 	if context.csvOutput == "" {
 		return
 	}
+	format := context.csvFormat
 
 	currentMemoryGB := float64(node.Memory.Total) / 1024 / 1024 / 1024
 	recommendedCores := node.CPU.Cores
@@ -1328,19 +1910,19 @@ func addNodeToCSV(context *capacityPlanningContext, node *models.Node, metrics i
 	// Extract metrics values (using interface{} for compatibility)
 	p90, p95, p99 := "", "", ""
 	if m, ok := metrics.(struct{ P90, P95, P99 float32 }); ok {
-		p90 = fmt.Sprintf("%.1f", m.P90)
-		p95 = fmt.Sprintf("%.1f", m.P95)
-		p99 = fmt.Sprintf("%.1f", m.P99)
+		p90 = format.number(float64(m.P90))
+		p95 = format.number(float64(m.P95))
+		p99 = format.number(float64(m.P99))
 	}
 
 	context.csvData = append(context.csvData, []string{
 		"Node", node.Name, "", node.Status, "",
-		fmt.Sprintf("%.1f", node.CPU.Usage), fmt.Sprintf("%.1f", node.Memory.Usage), fmt.Sprintf("%.1f", node.Storage.Usage),
+		format.number(float64(node.CPU.Usage)), format.number(float64(node.Memory.Usage)), format.number(float64(node.Storage.Usage)),
 		p90, p95, p99,
-		fmt.Sprintf("%.1f", predictedCPU), fmt.Sprintf("%.1f", predictedMemory),
-		fmt.Sprintf("%d", node.CPU.Cores), fmt.Sprintf("%.1f", currentMemoryGB),
-		fmt.Sprintf("%d", recommendedCores), fmt.Sprintf("%.1f", recommendedMemoryGB),
-		"", "", strings.Join(recommendations, "; "),
+		format.number(float64(predictedCPU)), format.number(float64(predictedMemory)),
+		fmt.Sprintf("%d", node.CPU.Cores), format.number(currentMemoryGB),
+		fmt.Sprintf("%d", recommendedCores), format.number(recommendedMemoryGB),
+		"", "", "", strings.Join(recommendations, "; "),
 	})
 }
 
@@ -1349,41 +1931,35 @@ func addNodeToCSVWithoutMetrics(context *capacityPlanningContext, node *models.N
 	if context.csvOutput == "" {
 		return
 	}
+	format := context.csvFormat
 
 	currentMemoryGB := float64(node.Memory.Total) / 1024 / 1024 / 1024
 	context.csvData = append(context.csvData, []string{
 		"Node", node.Name, "", node.Status, "",
-		fmt.Sprintf("%.1f", node.CPU.Usage), fmt.Sprintf("%.1f", node.Memory.Usage), fmt.Sprintf("%.1f", node.Storage.Usage),
+		format.number(float64(node.CPU.Usage)), format.number(float64(node.Memory.Usage)), format.number(float64(node.Storage.Usage)),
 		"", "", "", "", "",
-		fmt.Sprintf("%d", node.CPU.Cores), fmt.Sprintf("%.1f", currentMemoryGB),
-		fmt.Sprintf("%d", node.CPU.Cores), fmt.Sprintf("%.1f", currentMemoryGB),
-		"", "", "No historical data available",
+		fmt.Sprintf("%d", node.CPU.Cores), format.number(currentMemoryGB),
+		fmt.Sprintf("%d", node.CPU.Cores), format.number(currentMemoryGB),
+		"", "", "", "No historical data available",
 	})
 }
 
 // addVMToCSV adds VM data to CSV output.
-func addVMToCSV(context *capacityPlanningContext, vm *models.VM, workloadType string, currentCPU int, currentMemoryGB float64, recommendedCPU int, recommendedMemoryGB float64, vmProfile interface{}) {
+func addVMToCSV(context *capacityPlanningContext, vm *models.VM, workloadType string, currentCPU int, currentMemoryGB float64, recommendedCPU int, recommendedMemoryGB float64, vmProfile balancer.VMProfile) {
 	if context.csvOutput == "" {
 		return
 	}
+	format := context.csvFormat
 
-	// Extract vmProfile values (using interface{} for compatibility)
-	criticality, pattern, recommendations := "", "", ""
-	if p, ok := vmProfile.(struct {
-		Criticality, Pattern string
-		Recommendations      []string
-	}); ok {
-		criticality = p.Criticality
-		pattern = p.Pattern
-		recommendations = strings.Join(p.Recommendations, "; ")
-	}
+	criticality, pattern := vmProfile.Criticality, vmProfile.Pattern
+	recommendations := strings.Join(vmProfile.Recommendations, "; ")
 
 	context.csvData = append(context.csvData, []string{
 		"VM", vm.Name, fmt.Sprintf("%d", vm.ID), vm.Status, workloadType,
-		fmt.Sprintf("%.1f", vm.CPU), fmt.Sprintf("%.1f", float64(vm.Memory)/1024/1024/1024), "",
+		format.number(float64(vm.CPU)), format.number(float64(vm.Memory) / 1024 / 1024 / 1024), "",
 		"", "", "", "", "",
-		fmt.Sprintf("%d", currentCPU), fmt.Sprintf("%.1f", currentMemoryGB),
-		fmt.Sprintf("%d", recommendedCPU), fmt.Sprintf("%.1f", recommendedMemoryGB),
-		criticality, pattern, recommendations,
+		fmt.Sprintf("%d", currentCPU), format.number(currentMemoryGB),
+		fmt.Sprintf("%d", recommendedCPU), format.number(recommendedMemoryGB),
+		criticality, pattern, format.number(vmProfile.Confidence * 100), recommendations,
 	})
 }
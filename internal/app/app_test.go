@@ -1,12 +1,18 @@
 package app
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/metrics"
 	"github.com/cblomart/GoProxLB/internal/models"
 	"github.com/cblomart/GoProxLB/internal/proxmox"
 )
@@ -314,6 +320,60 @@ func TestAppRunBalancingCycle(t *testing.T) {
 	}
 }
 
+func TestAppRunBalancingCycleRecordsMetrics(t *testing.T) {
+	app := &App{
+		config: createTestConfig(),
+		client: &mockClient{nodes: createTestNodes()},
+		balancer: &mockBalancer{
+			results: []models.BalancingResult{
+				{SourceNode: "node1", TargetNode: "node2", VM: models.VM{ID: 100}, Success: true},
+				{SourceNode: "node1", TargetNode: "node3", VM: models.VM{ID: 101}, ErrorMessage: "VM is locked"},
+			},
+			status: &models.ClusterStatus{BalanceScore: 64, LastBalanced: time.Unix(1700000000, 0)},
+		},
+		metrics: metrics.NewRecorder("test-cluster"),
+	}
+
+	if err := app.runBalancingCycle(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	scrape := httptest.NewRecorder()
+	app.metrics.Handler().ServeHTTP(scrape, httptest.NewRequest("GET", metrics.Path, nil))
+	for _, want := range []string{
+		`goproxlb_migrations_total{cluster="test-cluster"} 1`,
+		`goproxlb_migration_failures_total{cluster="test-cluster"} 1`,
+		`goproxlb_balance_score{cluster="test-cluster"} 64`,
+		`goproxlb_last_balanced_timestamp_seconds{cluster="test-cluster"} 1.7e+09`,
+		`goproxlb_node_usage_percent{cluster="test-cluster",node="node1",resource="cpu"} 85`,
+	} {
+		if !strings.Contains(scrape.Body.String(), want) {
+			t.Errorf("Expected %q in the metrics, got:\n%s", want, scrape.Body.String())
+		}
+	}
+}
+
+func TestAppRunBalancingCycleReadOnly(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+	app := &App{
+		config: cfg,
+		client: &mockClient{nodes: createTestNodes()},
+		balancer: &mockBalancer{results: []models.BalancingResult{
+			{SourceNode: "node1", TargetNode: "node2", VM: models.VM{ID: 100, Name: "test-vm-1"}, DryRun: true},
+		}},
+	}
+
+	output := captureStdout(t, func() {
+		if err := app.runBalancingCycle(); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+	if !strings.Contains(output, "1 migrations planned, none executed") || strings.Contains(output, "migrations executed") {
+		t.Errorf("Expected the migrations reported as planned only, got:\n%s", output)
+	}
+}
+
 func TestAppRunBalancingCycleError(t *testing.T) {
 	cfg := createTestConfig()
 	client := &mockClient{err: fmt.Errorf("client error")}
@@ -464,6 +524,72 @@ func TestListVMsError(t *testing.T) {
 	}
 }
 
+func TestEvaluateRules(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Cluster.MaintenanceNodes = []string{"node2"}
+
+	nodes := createTestNodes()
+	nodes[0].VMs = append(nodes[0].VMs, models.VM{
+		ID:   103,
+		Name: "test-vm-pinned",
+		Node: "node1",
+		Tags: []string{"plb_pin_node2"},
+	})
+
+	engine, conflicts, err := evaluateRules(cfg, nodes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(engine.GetAffinityGroups()) != 1 {
+		t.Errorf("Expected 1 affinity group, got %d", len(engine.GetAffinityGroups()))
+	}
+
+	// node2 is in maintenance, so the VM pinned to it has nowhere to go
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", len(conflicts))
+	}
+	if conflicts[0].VMIDs[0] != 103 {
+		t.Errorf("Expected conflict for VM 103, got %v", conflicts[0].VMIDs)
+	}
+}
+
+func TestFindUnschedulableVMs(t *testing.T) {
+	cfg := createTestConfig()
+
+	nodes := createTestNodes()
+	nodes[0].VMs = append(nodes[0].VMs, models.VM{
+		ID:   103,
+		Name: "test-vm-pinned",
+		Node: "node1",
+		Tags: []string{"plb_pin_node1"},
+	})
+
+	engine, _, err := evaluateRules(cfg, nodes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// node1 is overloaded: VM 100 can join its affinity group on node2, the pinned VM can't move
+	unschedulable := findUnschedulableVMs(cfg, engine, nodes)
+	if len(unschedulable) != 1 {
+		t.Fatalf("Expected 1 unschedulable VM, got %v", unschedulable)
+	}
+	if unschedulable[0].VMID != 103 || unschedulable[0].Node != "node1" {
+		t.Errorf("Expected pinned VM 103 on node1 to be unschedulable, got %v", unschedulable[0])
+	}
+
+	// With node2 in maintenance, VM 100 can't join its group; VM 102 can still evacuate to node1
+	cfg.Cluster.MaintenanceNodes = []string{"node2"}
+	engine, _, err = evaluateRules(cfg, nodes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if unschedulable = findUnschedulableVMs(cfg, engine, nodes); len(unschedulable) != 2 {
+		t.Errorf("Expected 2 unschedulable VMs, got %v", unschedulable)
+	}
+}
+
 func TestForceBalance(t *testing.T) {
 	cfg := createTestConfig()
 	client := &mockClient{nodes: createTestNodes()}
@@ -1453,7 +1579,7 @@ func TestWriteCSVFile(t *testing.T) {
 	}
 
 	// Write CSV
-	err = writeCSVFile(tempFile.Name(), data)
+	err = writeCSVFile(tempFile.Name(), data, ',')
 	if err != nil {
 		t.Errorf("Unexpected error writing CSV: %v", err)
 	}
@@ -1469,12 +1595,111 @@ func TestWriteCSVFile(t *testing.T) {
 	}
 
 	// Test with invalid path
-	err = writeCSVFile("/invalid/path/file.csv", data)
+	err = writeCSVFile("/invalid/path/file.csv", data, ',')
 	if err == nil {
 		t.Error("Expected error for invalid file path")
 	}
 }
 
+func TestWriteCSVFileWithLocaleFormat(t *testing.T) {
+	context := &capacityPlanningContext{
+		csvOutput: filepath.Join(t.TempDir(), "capacity.csv"),
+		csvFormat: newCSVFormat(config.CSVConfig{Delimiter: ";", DecimalSeparator: ","}),
+	}
+	node := &models.Node{
+		Name:   "node1",
+		Status: "online",
+		CPU:    models.CPUInfo{Usage: 85, Cores: 8},
+		Memory: models.MemoryInfo{Usage: 62.5, Total: 16 * 1024 * 1024 * 1024},
+	}
+	addNodeToCSVWithoutMetrics(context, node)
+
+	if err := writeCSVFile(context.csvOutput, context.csvData, context.csvFormat.delimiter); err != nil {
+		t.Fatalf("Unexpected error writing CSV: %v", err)
+	}
+
+	content, err := os.ReadFile(context.csvOutput)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	line := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(line, "Node;node1;;online;;85,0;62,5;") {
+		t.Errorf("Expected semicolon-delimited row with comma decimals, got %q", line)
+	}
+	if strings.Contains(line, "85.0") || strings.Contains(line, "\"") {
+		t.Errorf("Expected unquoted comma decimals, got %q", line)
+	}
+}
+
+func TestCSVFormatDefaults(t *testing.T) {
+	format := newCSVFormat(config.CSVConfig{})
+	if format.delimiter != ',' {
+		t.Errorf("Expected comma delimiter by default, got %q", format.delimiter)
+	}
+	if got := format.number(12.34); got != "12.3" {
+		t.Errorf("Expected dot decimals by default, got %q", got)
+	}
+}
+
+func TestValidateCSVOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.csv")
+	if err := os.WriteFile(existing, []byte("keep"), 0o600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"new file", filepath.Join(dir, "report.csv"), false},
+		{"existing file", existing, false},
+		{"missing directory", filepath.Join(dir, "missing", "report.csv"), true},
+		{"parent is a file", filepath.Join(existing, "report.csv"), true},
+		{"directory", dir, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCSVOutputPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCSVOutputPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+
+	if content, err := os.ReadFile(existing); err != nil || string(content) != "keep" {
+		t.Errorf("Expected existing file to be left untouched, got %q (%v)", content, err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("Expected no probe files left behind, got %v (%v)", entries, err)
+	}
+}
+
+func TestValidateCSVOutputPathUnwritableDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced for root")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o500); err != nil {
+		t.Fatalf("Failed to make directory read-only: %v", err)
+	}
+	defer os.Chmod(dir, 0o700) //nolint:errcheck // restore permissions for cleanup
+
+	if err := validateCSVOutputPath(filepath.Join(dir, "report.csv")); err == nil {
+		t.Error("Expected error for unwritable directory")
+	}
+}
+
+func TestCapacityPlanningFailsFastOnBadCSVPath(t *testing.T) {
+	// The configuration does not exist: the CSV path must be rejected before it is loaded
+	err := ShowCapacityPlanning("non-existent-config.yaml", false, "1w", filepath.Join(t.TempDir(), "missing", "report.csv"))
+	if err == nil || !strings.Contains(err.Error(), "invalid csv output") {
+		t.Errorf("Expected csv output error before analysis, got %v", err)
+	}
+}
+
 func TestShowRaftStatus(t *testing.T) {
 	// Test with non-existent config
 	err := ShowRaftStatus("non-existent-config.yaml")
@@ -1505,6 +1730,45 @@ func TestShowRaftStatus(t *testing.T) {
 	}
 }
 
+func TestNewAppLocksDataDir(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configContent := fmt.Sprintf(`
+proxmox:
+  host: "https://localhost:8006"
+  username: "test"
+  password: "test"
+cluster:
+  name: "test-cluster"
+raft:
+  data_dir: "%s/data"
+`, tempDir)
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	app, err := NewApp(configPath)
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	if app.dirLock == nil {
+		t.Fatal("Expected the app to hold the data directory lock")
+	}
+
+	// A second instance on the same data directory fails fast
+	if _, err := NewApp(configPath); err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("Expected the data directory to be in use, got %v", err)
+	}
+
+	// Shutting down releases it
+	app.cancel()
+	second, err := NewApp(configPath)
+	if err != nil {
+		t.Fatalf("Expected the data directory to be free after shutdown, got %v", err)
+	}
+	second.cancel()
+}
+
 func TestNewAppWithDefaults(t *testing.T) {
 	// This test requires default config to be loadable
 	// It may fail if Proxmox API is not accessible, which is expected in test environment
@@ -1633,3 +1897,406 @@ func TestInstallServiceUserChecks(t *testing.T) {
 		})
 	}
 }
+
+// skippedReportingBalancer is a mock balancer that explains the VMs it left in place.
+type skippedReportingBalancer struct {
+	mockBalancer
+	skipped []models.SkippedVM
+}
+
+func (m *skippedReportingBalancer) GetSkippedVMs() []models.SkippedVM {
+	return m.skipped
+}
+
+func TestPrintSkippedVMs(t *testing.T) {
+	reporting := &skippedReportingBalancer{
+		skipped: []models.SkippedVM{
+			{VMID: 101, Name: "ntp", Node: "node1", Reason: "cooldown (migrated within the last hour)"},
+			{VMID: 102, Name: "db", Node: "node1", Reason: "no gain"},
+			{VMID: 103, Name: "license", Node: "node1", Reason: "no valid target: pinned to [node1]", Rules: []string{"pin (node1)"}},
+		},
+	}
+
+	var out bytes.Buffer
+	printSkippedVMs(&out, reporting)
+
+	expected := []string{
+		"Evaluated but skipped 3 VMs:",
+		"  - VM 101 (ntp) on node1: cooldown (migrated within the last hour)",
+		"  - VM 102 (db) on node1: no gain",
+		"  - VM 103 (license) on node1: no valid target: pinned to [node1] [rules: pin (node1)]",
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, out.String())
+		}
+	}
+
+	// Balancers that can't explain their decisions print nothing
+	out.Reset()
+	printSkippedVMs(&out, &mockBalancer{})
+	if out.Len() != 0 {
+		t.Errorf("Expected no output without a reporter, got:\n%s", out.String())
+	}
+}
+
+// emptyingBalancer is a mock balancer reporting the nodes its consolidation emptied.
+type emptyingBalancer struct {
+	mockBalancer
+	emptied []string
+}
+
+func (m *emptyingBalancer) GetEmptiedNodes() []string {
+	return m.emptied
+}
+
+func TestPrintEmptiedNodes(t *testing.T) {
+	var out bytes.Buffer
+	printEmptiedNodes(&out, &emptyingBalancer{emptied: []string{"node1", "node4"}})
+	if out.String() != "Nodes left empty: node1, node4\n" {
+		t.Errorf("Expected the emptied nodes, got %q", out.String())
+	}
+
+	// Nothing emptied, or not a consolidating balancer: nothing printed
+	out.Reset()
+	printEmptiedNodes(&out, &emptyingBalancer{})
+	printEmptiedNodes(&out, &mockBalancer{})
+	if out.Len() != 0 {
+		t.Errorf("Expected no output, got:\n%s", out.String())
+	}
+}
+
+func TestSetupBalancerType(t *testing.T) {
+	cfg := createTestConfig()
+	for balancerType, want := range map[string]string{
+		balancerThreshold:   "*balancer.Balancer",
+		balancerAdvanced:    "*balancer.AdvancedBalancer",
+		balancerConsolidate: "*balancer.ConsolidationBalancer",
+	} {
+		cfg.Balancing.BalancerType = balancerType
+		if got := fmt.Sprintf("%T", setupBalancer(&mockClient{}, cfg)); got != want {
+			t.Errorf("Expected %s for balancer type %s, got %s", want, balancerType, got)
+		}
+	}
+}
+
+// createClusterTotalsTestNodes creates two nodes with known capacities and a mix of running and stopped VMs.
+func createClusterTotalsTestNodes() []models.Node {
+	const gib = 1024 * 1024 * 1024
+	return []models.Node{
+		{
+			Name:    "node1",
+			Status:  "online",
+			CPU:     models.CPUInfo{Usage: 50, Cores: 16},
+			Memory:  models.MemoryInfo{Total: 128 * gib, Used: 64 * gib, Usage: 50},
+			Storage: models.StorageInfo{Total: 1000 * gib, Used: 400 * gib, Usage: 40},
+			VMs: []models.VM{
+				{ID: 100, Status: "running", CPUs: 4, MaxMemory: 16 * gib},
+				{ID: 101, Status: "stopped", CPUs: 8, MaxMemory: 32 * gib},
+			},
+		},
+		{
+			Name:    "node2",
+			Status:  "online",
+			CPU:     models.CPUInfo{Usage: 25, Cores: 8},
+			Memory:  models.MemoryInfo{Total: 64 * gib, Used: 16 * gib, Usage: 25},
+			Storage: models.StorageInfo{Total: 500 * gib, Used: 100 * gib, Usage: 20},
+			VMs: []models.VM{
+				{ID: 200, Status: "running", CPUs: 2, MaxMemory: 8 * gib},
+			},
+		},
+	}
+}
+
+func TestClusterTotals(t *testing.T) {
+	nodes := createClusterTotalsTestNodes()
+	totals := clusterTotals(nodes)
+
+	var expected models.ClusterTotals
+	for i := range nodes {
+		expected.CPUCores += nodes[i].CPU.Cores
+		expected.MemoryTotal += nodes[i].Memory.Total
+		expected.MemoryUsed += nodes[i].Memory.Used
+		expected.StorageTotal += nodes[i].Storage.Total
+		expected.StorageUsed += nodes[i].Storage.Used
+	}
+	// Only running VMs hold their allocation
+	expected.AllocatedVCPUs = 4 + 2
+	expected.MemoryAllocated = (16 + 8) * 1024 * 1024 * 1024
+
+	if totals != expected {
+		t.Errorf("Expected totals %+v, got %+v", expected, totals)
+	}
+	if totals.CPUCores != 24 {
+		t.Errorf("Expected 24 cores, got %d", totals.CPUCores)
+	}
+}
+
+func TestShowClusterInfoOutputs(t *testing.T) {
+	app := &App{
+		client:   &mockClient{nodes: createClusterTotalsTestNodes()},
+		balancer: &mockBalancer{status: &models.ClusterStatus{TotalNodes: 2, ActiveNodes: 2, TotalVMs: 3, RunningVMs: 2}},
+	}
+
+	var text bytes.Buffer
+	if err := app.showClusterInfo(&text, outputText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{
+		"=== Cluster Totals ===",
+		"CPU: 24 cores, 6 vCPUs allocated to running VMs",
+		"Memory: 80.0 GB used / 192.0 GB total (112.0 GB free), 24.0 GB allocated to running VMs",
+		"Storage: 500.0 GB used / 1500.0 GB total (1000.0 GB free)",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text.String(), line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, text.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := app.showClusterInfo(&out, outputJSON); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var info clusterInfo
+	if err := json.Unmarshal(out.Bytes(), &info); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, out.String())
+	}
+	if info.Totals != clusterTotals(createClusterTotalsTestNodes()) || info.Status.TotalVMs != 3 || len(info.Nodes) != 2 {
+		t.Errorf("Expected status, totals and nodes in JSON, got %+v", info)
+	}
+
+	if err := app.showClusterInfo(&bytes.Buffer{}, "yaml"); err == nil {
+		t.Error("Expected an error for an unknown output format")
+	}
+}
+
+func TestPrintEffectiveConfig(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Proxmox.Password = "hunter2"
+
+	var out bytes.Buffer
+	printEffectiveConfig(&out, cfg)
+	if out.Len() != 0 {
+		t.Errorf("Expected no dump outside debug level, got:\n%s", out.String())
+	}
+
+	cfg.Logging.Level = "debug"
+	printEffectiveConfig(&out, cfg)
+	if !strings.HasPrefix(out.String(), "Effective configuration:\n") || !strings.Contains(out.String(), `proxmox.password: "<redacted>"`) {
+		t.Errorf("Expected a redacted dump at debug level, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Errorf("Expected the password to be redacted, got:\n%s", out.String())
+	}
+}
+
+func TestDescribeGain(t *testing.T) {
+	result := &models.BalancingResult{
+		SourceNode:   "node1",
+		TargetNode:   "node2",
+		ResourceGain: 8.77,
+		Freed:        models.Resources{CPU: 12.4, Memory: 6.2},
+	}
+
+	expected := "gain: 8.77, freed ~12% CPU and ~6% memory on node1"
+	if got := describeGain(result); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	result.EstimatedDuration = 82 * time.Second
+	expected += ", est. 1m22s"
+	if got := describeGain(result); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	result.StorageMigration = true
+	expected = "gain: 8.77, freed ~12% CPU and ~6% memory on node1, storage migration: local disks copied, est. 1m22s"
+	if got := describeGain(result); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestPrintBalancingResultsDryRun(t *testing.T) {
+	results := []models.BalancingResult{{
+		SourceNode:   "node1",
+		TargetNode:   "node2",
+		VM:           models.VM{ID: 101, Name: "test-vm-2"},
+		ResourceGain: 8.77,
+		Rules:        []string{"anti-affinity spread (ntp)"},
+		DryRun:       true,
+	}}
+
+	var out bytes.Buffer
+	printBalancingResults(&out, results, true)
+	if !strings.Contains(out.String(), "1 migrations planned, none executed") {
+		t.Errorf("Expected the dry run reported as planned, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "migrations executed") {
+		t.Errorf("Expected no migrations reported as executed, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Would migrate VM test-vm-2 (101) from node1 to node2 (gain: 8.77") {
+		t.Errorf("Expected the planned migration with its gain, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), ", rules: anti-affinity spread (ntp)) [read-only]") {
+		t.Errorf("Expected the planned migration with its governing rules, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	printBalancingResults(&out, nil, true)
+	if out.String() != "No balancing actions performed\n" {
+		t.Errorf("Expected no actions, got %q", out.String())
+	}
+}
+
+func TestCompareUnitFile(t *testing.T) {
+	content := serviceUnitContent("GoProxLB Load Balancer", "goproxlb", "goproxlb", "/usr/local/bin/goproxlb start")
+	path := filepath.Join(t.TempDir(), "goproxlb.service")
+
+	tests := []struct {
+		name     string
+		existing string
+		want     unitFileState
+	}{
+		{"missing unit", "", unitFileMissing},
+		{"identical unit", content, unitFileUnchanged},
+		{"changed settings", strings.Replace(content, "User=goproxlb", "User=root", 1), unitFileChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(path)
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0600); err != nil {
+					t.Fatalf("Failed to write unit file: %v", err)
+				}
+			}
+			state, err := compareUnitFile(path, content)
+			if err != nil {
+				t.Fatalf("compareUnitFile failed: %v", err)
+			}
+			if state != tt.want {
+				t.Errorf("Expected state %d, got %d", tt.want, state)
+			}
+		})
+	}
+}
+
+func TestServiceAction(t *testing.T) {
+	tests := []struct {
+		state  unitFileState
+		active bool
+		want   string
+	}{
+		{unitFileMissing, false, "start"},
+		{unitFileChanged, false, "start"},
+		{unitFileUnchanged, false, "start"},
+		{unitFileChanged, true, "restart"},
+		{unitFileUnchanged, true, ""},
+	}
+
+	for _, tt := range tests {
+		if got := serviceAction(tt.state, tt.active); got != tt.want {
+			t.Errorf("serviceAction(%d, %v) = %q, want %q", tt.state, tt.active, got, tt.want)
+		}
+	}
+}
+
+func TestStatusOutputShowsSchedule(t *testing.T) {
+	lastRun := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+	app := &App{
+		client: &mockClient{nodes: createTestNodes()},
+		balancer: &mockBalancer{status: &models.ClusterStatus{
+			LastRun: lastRun,
+			NextRun: lastRun.Add(5 * time.Minute),
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := app.showStatus(&out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		fmt.Sprintf("Last Run: %v\n", lastRun),
+		fmt.Sprintf("Next Run: %v\n", lastRun.Add(5*time.Minute)),
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the status, got:\n%s", want, out.String())
+		}
+	}
+
+	app.balancer = &mockBalancer{status: &models.ClusterStatus{}}
+	out.Reset()
+	if err := app.showStatus(&out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(out.String(), "Next Run:") {
+		t.Errorf("Expected no next run before the first cycle, got:\n%s", out.String())
+	}
+}
+
+func TestStatusOutputCarriesClusterName(t *testing.T) {
+	app := &App{
+		client:   &mockClient{nodes: createTestNodes()},
+		balancer: &mockBalancer{status: &models.ClusterStatus{Cluster: "prod-east", TotalNodes: 2, ActiveNodes: 2}},
+	}
+
+	var status bytes.Buffer
+	if err := app.showStatus(&status); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(status.String(), "Cluster: prod-east\n") {
+		t.Errorf("Expected the cluster name in the status, got:\n%s", status.String())
+	}
+
+	var text bytes.Buffer
+	if err := app.showClusterInfo(&text, outputText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(text.String(), "Cluster: prod-east\n") {
+		t.Errorf("Expected the cluster name in the cluster information, got:\n%s", text.String())
+	}
+
+	var info bytes.Buffer
+	if err := app.showClusterInfo(&info, outputJSON); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var decoded clusterInfo
+	if err := json.Unmarshal(info.Bytes(), &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if decoded.Status.Cluster != "prod-east" {
+		t.Errorf("Expected cluster 'prod-east' in the JSON status, got %q", decoded.Status.Cluster)
+	}
+}
+
+func TestShowStatusOverCapacity(t *testing.T) {
+	for _, over := range []bool{false, true} {
+		app := &App{balancer: &mockBalancer{status: &models.ClusterStatus{TotalNodes: 3, ActiveNodes: 3, OverCapacity: over}}}
+
+		var buf bytes.Buffer
+		if err := app.showStatus(&buf); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := strings.Contains(buf.String(), "Cluster over capacity"); got != over {
+			t.Errorf("Expected the over capacity line %v, got:\n%s", over, buf.String())
+		}
+	}
+}
+
+func TestShowStatusSLABreaches(t *testing.T) {
+	app := &App{balancer: &mockBalancer{status: &models.ClusterStatus{
+		TotalNodes:  3,
+		ActiveNodes: 3,
+		SLABreaches: []models.SLABreach{{Node: "node1", Resource: "cpu", Usage: 70, Mean: 40, Excess: 30}},
+	}}}
+
+	var buf bytes.Buffer
+	if err := app.showStatus(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "Imbalance SLA breached: node1 cpu at 70.0%, 30.0 points above the cluster mean 40.0%"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("Expected %q, got:\n%s", want, buf.String())
+	}
+}
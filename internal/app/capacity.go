@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/balancer"
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// Live capacity analysis heuristics, used when no balancer history is available.
+const (
+	// livePeakFactor estimates a node's peak usage from its current usage
+	livePeakFactor = 1.2
+	// liveBusyVMCPU and liveIdleVMCPU classify a VM by its current CPU usage (fraction of its vCPUs)
+	liveBusyVMCPU = 0.8
+	liveIdleVMCPU = 0.05
+	// liveFullVMMemory flags a VM using most of its configured memory
+	liveFullVMMemory = 0.9
+	// liveLowUsage marks a node as a consolidation candidate, in percent
+	liveLowUsage = 30
+	// liveSpread is the CPU or memory gap between nodes, in points, worth a rebalance
+	liveSpread = 40
+)
+
+// livePeak estimates the peak usage of a resource from its current usage, capped at 100%.
+func livePeak(usage float32) float32 {
+	return float32(math.Min(float64(usage)*livePeakFactor, 100))
+}
+
+// analyzeNodeLiveCapacity analyzes a node from its current metrics, for balancers without
+// historical data. Peaks are estimated from the current usage.
+func analyzeNodeLiveCapacity(context *capacityPlanningContext, node *models.Node, recommendationCounter *int) []string {
+	fmt.Printf("   Current CPU: %.1f%% | Memory: %.1f%% | Storage: %.1f%%\n",
+		node.CPU.Usage, node.Memory.Usage, node.Storage.Usage)
+
+	peakCPU := livePeak(node.CPU.Usage)
+	peakMemory := livePeak(node.Memory.Usage)
+	fmt.Printf("   Estimated peak CPU: %.1f%% | Memory: %.1f%% (live metrics, no history)\n", peakCPU, peakMemory)
+
+	nodeRecommendations := liveNodeRecommendations(context.cfg, node)
+	fmt.Printf("   Recommendations:\n")
+	for _, rec := range nodeRecommendations {
+		fmt.Printf("     • %s\n", rec)
+	}
+
+	addNodeToCSV(context, node, nil, peakCPU, peakMemory, nodeRecommendations)
+
+	return generateNodeRecommendations(node, peakCPU, peakMemory, recommendationCounter)
+}
+
+// liveNodeRecommendations compares a node's current usage with the balancing thresholds.
+func liveNodeRecommendations(cfg *config.Config, node *models.Node) []string {
+	var recommendations []string
+
+	thresholds := cfg.Balancing.Thresholds
+	resources := []struct {
+		name      string
+		usage     float32
+		threshold int
+	}{
+		{"CPU", node.CPU.Usage, thresholds.CPU},
+		{"Memory", node.Memory.Usage, thresholds.Memory},
+		{"Storage", node.Storage.Usage, thresholds.Storage},
+	}
+	for _, resource := range resources {
+		switch {
+		case resource.usage > float32(resource.threshold):
+			recommendations = append(recommendations, fmt.Sprintf("⚠️  %s above threshold (%.1f%% > %d%%) - Redistribute VMs or add resources",
+				resource.name, resource.usage, resource.threshold))
+		case resource.usage > float32(resource.threshold)*0.9:
+			recommendations = append(recommendations, fmt.Sprintf("⚠️  %s close to threshold (%.1f%%, threshold %d%%) - Monitor closely",
+				resource.name, resource.usage, resource.threshold))
+		}
+	}
+
+	if node.CPU.Usage < liveLowUsage && node.Memory.Usage < liveLowUsage {
+		recommendations = append(recommendations, "💡 Low usage (<30%) - Candidate for consolidation")
+	}
+	if len(recommendations) == 0 {
+		recommendations = append(recommendations, "✅ Usage within thresholds")
+	}
+	return recommendations
+}
+
+// liveVMProfile profiles a VM from its current usage and tags, for balancers without historical data.
+func liveVMProfile(vm *models.VM) balancer.VMProfile {
+	profile := balancer.VMProfile{
+		WorkloadType: "Standard",
+		Pattern:      "Live snapshot (no history)",
+		Criticality:  "Normal",
+	}
+
+	if vm.Status == "running" {
+		switch {
+		case vm.CPU >= liveBusyVMCPU:
+			profile.WorkloadType = "Sustained"
+			profile.Recommendations = append(profile.Recommendations,
+				fmt.Sprintf("High CPU usage (%.0f%%) - Consider more vCPUs", vm.CPU*100))
+		case vm.CPU <= liveIdleVMCPU:
+			profile.WorkloadType = "Idle"
+			profile.Recommendations = append(profile.Recommendations, "Mostly idle - Consider reducing resources")
+		}
+	}
+
+	if vm.MaxMemory > 0 && float64(vm.Memory)/float64(vm.MaxMemory) >= liveFullVMMemory {
+		profile.Recommendations = append(profile.Recommendations,
+			fmt.Sprintf("Memory nearly full (%.0f%% of configured) - Consider more memory", float64(vm.Memory)/float64(vm.MaxMemory)*100))
+	}
+
+	for _, tag := range vm.Tags {
+		if strings.Contains(tag, "critical") || strings.Contains(tag, "essential") {
+			profile.Criticality = "Critical"
+			profile.Recommendations = append(profile.Recommendations, "Critical VM based on tags - high buffer recommended")
+			break
+		}
+	}
+	return profile
+}
+
+// liveClusterRecommendations summarizes the cluster from its current metrics, for balancers
+// without historical data.
+func liveClusterRecommendations(cfg *config.Config, nodes []models.Node) []string {
+	var recommendations []string
+	if len(nodes) == 0 {
+		return []string{"Unable to get cluster data for recommendations"}
+	}
+
+	thresholds := cfg.Balancing.Thresholds
+	overloaded := 0
+	lowUsage := 0
+	minCPU, maxCPU := float32(100), float32(0)
+	minMemory, maxMemory := float32(100), float32(0)
+	for i := range nodes {
+		node := &nodes[i]
+		if node.CPU.Usage > float32(thresholds.CPU) || node.Memory.Usage > float32(thresholds.Memory) ||
+			node.Storage.Usage > float32(thresholds.Storage) {
+			overloaded++
+		}
+		if node.CPU.Usage < liveLowUsage && node.Memory.Usage < liveLowUsage {
+			lowUsage++
+		}
+		minCPU, maxCPU = min(minCPU, node.CPU.Usage), max(maxCPU, node.CPU.Usage)
+		minMemory, maxMemory = min(minMemory, node.Memory.Usage), max(maxMemory, node.Memory.Usage)
+	}
+
+	switch {
+	case overloaded == len(nodes):
+		recommendations = append(recommendations, "🚨 All nodes are above a threshold - Add nodes or reduce load")
+	case overloaded > 0:
+		recommendations = append(recommendations, fmt.Sprintf("⚠️  %d of %d nodes above a threshold - Run a balance to redistribute VMs", overloaded, len(nodes)))
+	}
+	if maxCPU-minCPU > liveSpread || maxMemory-minMemory > liveSpread {
+		recommendations = append(recommendations, "📊 Uneven load across nodes - Run a balance to even it out")
+	}
+	if lowUsage > 1 && overloaded == 0 {
+		recommendations = append(recommendations, fmt.Sprintf("💡 %d nodes below 30%% usage - Consider consolidating VMs", lowUsage))
+	}
+	recommendations = append(recommendations, "📈 Based on live metrics only - Use the advanced balancer for trend-based forecasts")
+	return recommendations
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"io"
+	"os"
+)
+
+// ANSI escape codes coloring terminal output.
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m" // Errors and failures
+	colorGreen  = "\033[32m" // Success
+	colorYellow = "\033[33m" // Warnings
+)
+
+// colorDisabled turns colors off for the whole process, set by the --no-color flag.
+var colorDisabled bool
+
+// isTerminal reports whether w is a terminal. It is a variable so tests can stand in for a TTY.
+var isTerminal = func(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// DisableColor turns colored output off, as the --no-color flag does.
+func DisableColor() {
+	colorDisabled = true
+}
+
+// useColor reports whether output to w is colored: only terminals get colors, unless disabled
+// by --no-color or the NO_COLOR environment variable (https://no-color.org).
+func useColor(w io.Writer) bool {
+	if colorDisabled {
+		return false
+	}
+	if _, set := os.LookupEnv("NO_COLOR"); set {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// colorize wraps text in color when output to w is colored. An empty color leaves text as is.
+func colorize(w io.Writer, color, text string) string {
+	if color == "" || !useColor(w) {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// usageColor flags a resource usage above its threshold in red.
+func usageColor(usage float32, threshold int) string {
+	if threshold > 0 && usage > float32(threshold) {
+		return colorRed
+	}
+	return ""
+}
+
+// statusColor colors a node or VM status: green when up, yellow otherwise.
+func statusColor(status string) string {
+	if status == "online" || status == vmStatusRunning {
+		return colorGreen
+	}
+	return colorYellow
+}
+
+// balanceScoreColor colors the cluster balance score: green when even, yellow when uneven, red when lopsided.
+func balanceScoreColor(score float64) string {
+	switch {
+	case score >= 80:
+		return colorGreen
+	case score >= 50:
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
+
+// FormatError formats a command error for w, in red on a terminal.
+func FormatError(w io.Writer, err error) string {
+	return colorize(w, colorRed, "Error: "+err.Error())
+}
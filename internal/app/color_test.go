@@ -0,0 +1,106 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// withTerminal makes every writer look like a terminal for the duration of the test.
+func withTerminal(t *testing.T) {
+	t.Helper()
+	original := isTerminal
+	isTerminal = func(io.Writer) bool { return true }
+	t.Cleanup(func() { isTerminal = original })
+}
+
+func TestColorize(t *testing.T) {
+	var buf bytes.Buffer
+
+	if got := colorize(&buf, colorRed, "failed"); got != "failed" {
+		t.Errorf("Expected no color when not writing to a terminal, got %q", got)
+	}
+
+	withTerminal(t)
+	if got := colorize(&buf, colorRed, "failed"); got != colorRed+"failed"+colorReset {
+		t.Errorf("Expected red on a terminal, got %q", got)
+	}
+	if got := colorize(&buf, "", "plain"); got != "plain" {
+		t.Errorf("Expected no color without one, got %q", got)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if got := colorize(&buf, colorRed, "failed"); got != "failed" {
+		t.Errorf("Expected NO_COLOR to disable colors, got %q", got)
+	}
+}
+
+func TestDisableColor(t *testing.T) {
+	withTerminal(t)
+	t.Cleanup(func() { colorDisabled = false })
+
+	DisableColor()
+	var buf bytes.Buffer
+	if got := colorize(&buf, colorGreen, "done"); got != "done" {
+		t.Errorf("Expected --no-color to disable colors, got %q", got)
+	}
+}
+
+func TestOutputsHaveNoANSICodesWithoutTerminal(t *testing.T) {
+	cfg := createTestConfig()
+	client := &mockClient{nodes: createTestNodes()}
+	balancer := &mockBalancer{status: &models.ClusterStatus{TotalNodes: 2, BalancingEnabled: true, BalanceScore: 40}}
+	app, err := NewAppWithDependencies("test-config.yaml", &mockConfigLoader{config: cfg}, client, balancer)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	outputs := map[string]func(w io.Writer) error{
+		"status":  app.showStatus,
+		"cluster": func(w io.Writer) error { return app.showClusterInfo(w, outputText) },
+		"list":    app.listVMs,
+		"balance": func(w io.Writer) error {
+			printBalancingResult(w, &models.BalancingResult{Success: true, SourceNode: "node1", TargetNode: "node2"})
+			printBalancingResult(w, &models.BalancingResult{ErrorMessage: "timeout"})
+			return nil
+		},
+	}
+
+	for name, output := range outputs {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := output(&buf); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if strings.Contains(buf.String(), "\033[") {
+				t.Errorf("Expected no ANSI codes when not writing to a terminal, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestBalancingResultColors(t *testing.T) {
+	withTerminal(t)
+
+	tests := []struct {
+		name   string
+		result models.BalancingResult
+		color  string
+	}{
+		{"success", models.BalancingResult{Success: true}, colorGreen},
+		{"dry run", models.BalancingResult{DryRun: true}, colorYellow},
+		{"failure", models.BalancingResult{ErrorMessage: "timeout"}, colorRed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printBalancingResult(&buf, &tt.result)
+			if !strings.HasPrefix(buf.String(), tt.color) {
+				t.Errorf("Expected output colored %q, got %q", tt.color, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// errBalanceDeclined is returned when the user declines the planned migrations.
+var errBalanceDeclined = errors.New("balance declined")
+
+// needsConfirmation reports whether a balance asks to confirm its plan: only interactive, non-forced
+// balances that migrate do, unless --yes skips the question.
+func needsConfirmation(opts BalanceOptions, readOnly, interactive bool) bool {
+	return interactive && !opts.Yes && !opts.Force && !opts.DryRun && !readOnly
+}
+
+// confirmedBalance plans a balancing cycle without migrating, prints the plan and asks on in
+// whether to execute it. Only the confirmed plan is executed; declining returns errBalanceDeclined
+// with nothing migrated. Balancers that can't execute a given plan run unconfirmed.
+func (app *App) confirmedBalance(in io.Reader, w io.Writer, force bool) ([]models.BalancingResult, error) {
+	executor, ok := app.balancer.(PlanExecutor)
+	if !ok {
+		return app.balancer.Run(force)
+	}
+
+	// Plan like a dry run
+	readOnly := app.config.ReadOnly
+	app.config.ReadOnly = true
+	planned, err := app.balancer.Run(force)
+	app.config.ReadOnly = readOnly
+	if err != nil || len(planned) == 0 {
+		return nil, err
+	}
+
+	fmt.Fprintf(w, "Planned migrations (%d):\n", len(planned))
+	for i := range planned {
+		result := &planned[i]
+		fmt.Fprintf(w, "  %d. VM %s (%d) from %s to %s (%s)\n", i+1, result.VM.Name, result.VM.ID,
+			result.SourceNode, result.TargetNode, describeGain(result))
+	}
+	fmt.Fprintf(w, "Execute these %d migrations? [y/N]: ", len(planned))
+
+	if !confirmed(in) {
+		return nil, errBalanceDeclined
+	}
+	return executor.ExecutePlan(&models.MigrationPlan{Migrations: migrationsFromResults(planned)}), nil
+}
+
+// confirmed reads an answer line: only "y" or "yes" confirm, anything else, or no answer, declines.
+func confirmed(in io.Reader) bool {
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
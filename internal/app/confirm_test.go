@@ -0,0 +1,98 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// mockPlanBalancer is a balancer planning fixed migrations, and recording the plans it executes.
+type mockPlanBalancer struct {
+	mockDrainer
+	cfg          *config.Config
+	readOnlyRuns []bool
+}
+
+func (m *mockPlanBalancer) Run(force bool) ([]models.BalancingResult, error) {
+	m.readOnlyRuns = append(m.readOnlyRuns, m.cfg.ReadOnly)
+	return m.results, m.err
+}
+
+func newMockPlanBalancer(cfg *config.Config) *mockPlanBalancer {
+	return &mockPlanBalancer{cfg: cfg, mockDrainer: mockDrainer{mockBalancer: mockBalancer{results: []models.BalancingResult{
+		{VM: models.VM{ID: 100, Name: "web"}, SourceNode: "node1", TargetNode: "node2", ResourceGain: 0.3, DryRun: true},
+		{VM: models.VM{ID: 101, Name: "db"}, SourceNode: "node1", TargetNode: "node3", ResourceGain: 0.2, DryRun: true},
+	}}}}
+}
+
+func TestConfirmedBalanceExecutesConfirmedPlan(t *testing.T) {
+	cfg := createTestConfig()
+	balancer := newMockPlanBalancer(cfg)
+	app := &App{config: cfg, balancer: balancer}
+
+	var buf bytes.Buffer
+	results, err := app.confirmedBalance(strings.NewReader("yes\n"), &buf, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(balancer.readOnlyRuns) != 1 || !balancer.readOnlyRuns[0] || cfg.ReadOnly {
+		t.Errorf("Expected a single read-only planning run, with read-only restored after, got %v (read-only %v)", balancer.readOnlyRuns, cfg.ReadOnly)
+	}
+	if len(balancer.executed) != 2 || balancer.executed[0] != 100 || balancer.executed[1] != 101 {
+		t.Errorf("Expected the planned migrations executed, got %v", balancer.executed)
+	}
+	if len(results) != 2 || !results[0].Success {
+		t.Errorf("Expected the executed results, got %v", results)
+	}
+	for _, line := range []string{"1. VM web (100) from node1 to node2", "2. VM db (101) from node1 to node3", "Execute these 2 migrations? [y/N]"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestConfirmedBalanceDeclined(t *testing.T) {
+	for _, answer := range []string{"n\n", "\n", "", "maybe\n"} {
+		cfg := createTestConfig()
+		balancer := newMockPlanBalancer(cfg)
+		app := &App{config: cfg, balancer: balancer}
+
+		var buf bytes.Buffer
+		results, err := app.confirmedBalance(strings.NewReader(answer), &buf, false)
+		if !errors.Is(err, errBalanceDeclined) {
+			t.Errorf("Expected the balance declined for answer %q, got %v", answer, err)
+		}
+		if len(results) != 0 || len(balancer.executed) != 0 {
+			t.Errorf("Expected no migrations executed for answer %q, got %v", answer, balancer.executed)
+		}
+	}
+}
+
+func TestNeedsConfirmation(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        BalanceOptions
+		readOnly    bool
+		interactive bool
+		want        bool
+	}{
+		{"interactive balance", BalanceOptions{}, false, true, true},
+		{"yes flag", BalanceOptions{Yes: true}, false, true, false},
+		{"not a terminal", BalanceOptions{}, false, false, false},
+		{"forced balance", BalanceOptions{Force: true}, false, true, false},
+		{"dry run", BalanceOptions{DryRun: true}, false, true, false},
+		{"read-only mode", BalanceOptions{}, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsConfirmation(tt.opts, tt.readOnly, tt.interactive); got != tt.want {
+				t.Errorf("Expected confirmation %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
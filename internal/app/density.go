@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// VM density heuristics: many small VMs still cost management overhead and IO, whatever their usage.
+const (
+	// denseFactor is how many times the cluster average VM count makes a node dense
+	denseFactor = 1.5
+	// denseMinExcess is how many VMs above the average a dense node hosts at least, so small clusters aren't flagged
+	denseMinExcess = 5
+)
+
+// densityFinding is a node hosting disproportionately many VMs.
+type densityFinding struct {
+	Node    string
+	VMs     int
+	Average float64
+	// Sparsest is the online node hosting the fewest VMs, where VMs are best spread to
+	Sparsest    string
+	SparsestVMs int
+}
+
+// recommendation describes the finding as a spreading recommendation.
+func (f densityFinding) recommendation() string {
+	return fmt.Sprintf("Node %s hosts %d VMs, %.1fx the cluster average of %.1f - spread VMs to less dense nodes such as %s (%d VMs)",
+		f.Node, f.VMs, float64(f.VMs)/f.Average, f.Average, f.Sparsest, f.SparsestVMs)
+}
+
+// vmDensity flags the online nodes hosting disproportionately many VMs compared to the cluster average,
+// densest first.
+func vmDensity(nodes []models.Node) []densityFinding {
+	var online []*models.Node
+	total := 0
+	for i := range nodes {
+		if nodes[i].Status != "online" {
+			continue
+		}
+		online = append(online, &nodes[i])
+		total += len(nodes[i].VMs)
+	}
+	if len(online) < 2 || total == 0 {
+		return nil
+	}
+
+	average := float64(total) / float64(len(online))
+	sparsest := online[0]
+	for _, node := range online[1:] {
+		if len(node.VMs) < len(sparsest.VMs) {
+			sparsest = node
+		}
+	}
+
+	var findings []densityFinding
+	for _, node := range online {
+		count := float64(len(node.VMs))
+		if count >= average*denseFactor && count-average >= denseMinExcess {
+			findings = append(findings, densityFinding{
+				Node:        node.Name,
+				VMs:         len(node.VMs),
+				Average:     average,
+				Sparsest:    sparsest.Name,
+				SparsestVMs: len(sparsest.VMs),
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].VMs > findings[j].VMs
+	})
+	return findings
+}
+
+// densityRecommendations returns the spreading recommendations for the dense nodes.
+func densityRecommendations(nodes []models.Node) []string {
+	var recommendations []string
+	for _, finding := range vmDensity(nodes) {
+		recommendations = append(recommendations, "📦 "+finding.recommendation())
+	}
+	return recommendations
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// createDensityTestNodes returns three online nodes hosting the given numbers of idle VMs.
+func createDensityTestNodes(counts ...int) []models.Node {
+	var nodes []models.Node
+	id := 100
+	for i, count := range counts {
+		node := models.Node{Name: "node" + string(rune('1'+i)), Status: "online", CPU: models.CPUInfo{Cores: 8, Usage: 10}}
+		for j := 0; j < count; j++ {
+			node.VMs = append(node.VMs, models.VM{ID: id, Name: "idle", Node: node.Name, Status: "running"})
+			id++
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func TestVMDensity(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		dense  []string
+	}{
+		{"crowded node", []int{30, 4, 2}, []string{"node1"}},
+		{"even spread", []int{10, 9, 11}, nil},
+		// Twice the average, but only 2 VMs above it
+		{"small cluster", []int{4, 1, 1}, nil},
+		{"empty cluster", []int{0, 0, 0}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := vmDensity(createDensityTestNodes(tt.counts...))
+			if len(findings) != len(tt.dense) {
+				t.Fatalf("Expected dense nodes %v, got %+v", tt.dense, findings)
+			}
+			for i, node := range tt.dense {
+				if findings[i].Node != node {
+					t.Errorf("Expected dense node %s, got %s", node, findings[i].Node)
+				}
+			}
+		})
+	}
+
+	// Offline nodes neither count in the average nor get flagged
+	nodes := createDensityTestNodes(30, 4, 2)
+	nodes[0].Status = "offline"
+	if findings := vmDensity(nodes); len(findings) != 0 {
+		t.Errorf("Expected no finding for an offline node, got %+v", findings)
+	}
+}
+
+func TestVMDensityRecommendation(t *testing.T) {
+	nodes := createDensityTestNodes(30, 4, 2)
+
+	recommendations := densityRecommendations(nodes)
+	want := "Node node1 hosts 30 VMs, 2.5x the cluster average of 12.0 - spread VMs to less dense nodes such as node3 (2 VMs)"
+	if len(recommendations) != 1 || !strings.Contains(recommendations[0], want) {
+		t.Errorf("Expected recommendation %q, got %v", want, recommendations)
+	}
+
+	app := &App{
+		client:   &mockClient{nodes: nodes},
+		balancer: &mockBalancer{status: &models.ClusterStatus{TotalNodes: 3, ActiveNodes: 3, TotalVMs: 36}},
+	}
+	var out bytes.Buffer
+	if err := app.showClusterInfo(&out, outputText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, line := range []string{"VMs: 30 (dense)", "VMs: 4\n", "=== VM Density ===", want} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected cluster output to contain %q, got:\n%s", line, out.String())
+		}
+	}
+}
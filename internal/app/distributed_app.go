@@ -1,22 +1,35 @@
 package app
 
 import (
+	"bufio"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/cblomart/GoProxLB/internal/balancer"
 	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/metrics"
+	"github.com/cblomart/GoProxLB/internal/models"
 	"github.com/cblomart/GoProxLB/internal/proxmox"
 	"github.com/cblomart/GoProxLB/internal/raft"
 )
 
+// maxStatusConnections bounds the status connections served at once on a listener.
+const maxStatusConnections = 16
+
+// statusRequestTimeout is how long a status client has to send its request and read the response.
+var statusRequestTimeout = 5 * time.Second
+
 // DistributedApp represents a distributed load balancer application with leader election.
 type DistributedApp struct {
 	config   *config.Config
@@ -27,6 +40,16 @@ type DistributedApp struct {
 	cancel   context.CancelFunc
 	isLeader bool
 	listener *net.UnixListener
+
+	// Optional TCP listener serving the same status endpoints to remote monitoring
+	tcpListener net.Listener
+
+	// Prometheus metrics, nil unless enabled; only the leader records cycles
+	metrics *metrics.Recorder
+
+	// Stop runs once, whether from a failed start or at shutdown
+	stopOnce sync.Once
+	stopErr  error
 }
 
 // NewDistributedApp creates a new distributed load balancer application.
@@ -86,36 +109,39 @@ func (d *DistributedApp) Start() error {
 	fmt.Printf("Raft Address: %s\n", d.config.Raft.Address)
 	fmt.Printf("Raft Peers: %v\n", d.config.Raft.Peers)
 	fmt.Printf("Status socket: %s\n", d.listener.Addr())
+	if d.config.ReadOnly {
+		fmt.Println("Read-only mode: migrations are planned and reported but never executed")
+	}
+	printEffectiveConfig(os.Stdout, d.config)
 
 	// Start Unix socket server in background
-	go func() {
-		for {
-			conn, err := d.listener.Accept()
-			if err != nil {
-				if d.ctx.Err() != nil {
-					// Context cancelled, shutting down
-					return
-				}
-				fmt.Printf("Socket accept error: %v\n", err)
-				continue
-			}
+	go d.serveStatus(d.listener, "")
 
-			// Handle connection in goroutine
-			go d.handleStatusRequest(conn)
-		}
-	}()
+	// Optionally expose status over TCP for remote monitoring
+	if err := d.startStatusTCP(); err != nil {
+		_ = d.Stop() //nolint:errcheck // cleanup operation, the start error is reported
+		return err
+	}
+
+	// Optionally expose Prometheus metrics
+	recorder, err := startMetrics(d.ctx, d.config)
+	if err != nil {
+		_ = d.Stop() //nolint:errcheck // cleanup operation, the start error is reported
+		return err
+	}
+	d.metrics = recorder
 
 	// Start Raft node
 	if err := d.raftNode.Start(); err != nil {
+		_ = d.Stop() //nolint:errcheck // cleanup operation, the start error is reported
 		return fmt.Errorf("failed to start raft node: %w", err)
 	}
 
-	// Wait for leader election
-	fmt.Println("Waiting for leader election...")
-	ctx, cancel := context.WithTimeout(d.ctx, 30*time.Second)
-	defer cancel()
-
-	if err := d.raftNode.WaitForLeader(ctx); err != nil {
+	// Wait for leader election, a slow-forming cluster gets retried rather than failing startup
+	timeout, _ := d.config.GetElectionTimeout()       //nolint:errcheck // validated at load time
+	maxTimeout, _ := d.config.GetElectionMaxTimeout() //nolint:errcheck // validated at load time
+	if err := waitForLeader(d.ctx, d.raftNode.WaitForLeader, timeout, maxTimeout); err != nil {
+		_ = d.Stop() //nolint:errcheck // cleanup operation, the start error is reported
 		return fmt.Errorf("failed to elect leader: %w", err)
 	}
 
@@ -152,19 +178,53 @@ func (d *DistributedApp) Start() error {
 	return d.Stop()
 }
 
-// Stop stops the distributed application.
-func (d *DistributedApp) Stop() error {
-	fmt.Println("Stopping distributed load balancer...")
-	d.cancel()
+// waitForLeader waits for a leader, retrying with a doubled timeout (up to maxTimeout) each
+// time an attempt times out. Only cancelling ctx ends the wait without a leader.
+func waitForLeader(ctx context.Context, wait func(context.Context) error, timeout, maxTimeout time.Duration) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		fmt.Printf("Waiting for leader election (attempt %d, timeout %v)...\n", attempt, timeout)
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := wait(attemptCtx)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
 
-	// Close Unix socket gracefully
-	if d.listener != nil {
-		_ = d.listener.Close() //nolint:errcheck // cleanup operation, error not actionable
-		// Remove socket file
-		_ = os.Remove("/var/lib/goproxlb/status.sock") //nolint:errcheck // cleanup operation, error not actionable
+		timeout *= 2
+		if timeout > maxTimeout {
+			timeout = maxTimeout
+		}
+		fmt.Printf("Warning: no leader elected after %v, retrying\n", time.Since(start).Round(time.Second))
 	}
+}
+
+// Stop stops the distributed application. Calling it again returns the first result.
+func (d *DistributedApp) Stop() error {
+	d.stopOnce.Do(func() {
+		fmt.Println("Stopping distributed load balancer...")
+		d.cancel()
+
+		d.closeStatusTCP()
+
+		// Close Unix socket gracefully
+		if d.listener != nil {
+			_ = d.listener.Close() //nolint:errcheck // cleanup operation, error not actionable
+			// Remove socket file
+			_ = os.Remove("/var/lib/goproxlb/status.sock") //nolint:errcheck // cleanup operation, error not actionable
+		}
 
-	return d.raftNode.Stop()
+		d.stopErr = d.raftNode.Stop()
+	})
+	return d.stopErr
 }
 
 // monitorLeadership monitors for leadership changes.
@@ -251,56 +311,145 @@ func (d *DistributedApp) runBalancingCycle() error {
 	fmt.Printf("[%s] Running balancing cycle (Leader: %s)...\n",
 		time.Now().Format("2006-01-02 15:04:05"), d.config.Raft.NodeID)
 
+	start := time.Now()
 	results, err := d.balancer.Run(false)
+	recordCycleMetrics(d.metrics, d.client, d.balancer, time.Since(start), results)
 	if err != nil {
 		return fmt.Errorf("balancing cycle failed: %w", err)
 	}
 
-	if len(results) == 0 {
-		fmt.Println("No balancing actions needed")
+	printBalancingResults(os.Stdout, results, d.config.ReadOnly)
+	printEmptiedNodes(os.Stdout, d.balancer)
+
+	return nil
+}
+
+// startStatusTCP starts serving status over TCP when a status address is configured.
+func (d *DistributedApp) startStatusTCP() error {
+	address := d.config.Status.TCPAddress
+	if address == "" {
 		return nil
 	}
 
-	fmt.Printf("Executed %d migrations:\n", len(results))
-	for i := range results {
-		result := &results[i]
-		if result.Success {
-			fmt.Printf("  ✓ Migrated VM %s (%d) from %s to %s (gain: %.2f)\n",
-				result.VM.Name, result.VM.ID, result.SourceNode, result.TargetNode, result.ResourceGain)
-		} else {
-			fmt.Printf("  ✗ Failed to migrate VM %s (%d): %s\n",
-				result.VM.Name, result.VM.ID, result.ErrorMessage)
-		}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen for status on %s: %w", address, err)
 	}
+	d.tcpListener = listener
 
+	if d.config.Status.Token == "" {
+		fmt.Printf("Warning: status is served on %s without authentication\n", listener.Addr())
+	} else {
+		fmt.Printf("Status TCP listener: %s\n", listener.Addr())
+	}
+
+	go d.serveStatus(listener, d.config.Status.Token)
 	return nil
 }
 
+// closeStatusTCP stops serving status over TCP, if it was.
+func (d *DistributedApp) closeStatusTCP() {
+	if d.tcpListener != nil {
+		_ = d.tcpListener.Close() //nolint:errcheck // cleanup operation, error not actionable
+	}
+}
+
+// serveStatus accepts status connections until the listener is closed.
+// When token is set, every request must present it as a bearer token.
+// Connections beyond maxStatusConnections are closed right away.
+func (d *DistributedApp) serveStatus(listener net.Listener, token string) {
+	slots := make(chan struct{}, maxStatusConnections)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if d.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				// Shutting down
+				return
+			}
+			fmt.Printf("Socket accept error: %v\n", err)
+			continue
+		}
+
+		// Handle connection in goroutine
+		select {
+		case slots <- struct{}{}:
+			go func() {
+				defer func() { <-slots }()
+				d.serveStatusRequest(conn, token)
+			}()
+		default:
+			_ = conn.Close() //nolint:errcheck // connection refused, error not actionable
+		}
+	}
+}
+
 // handleStatusRequest handles status requests from Unix socket clients.
 func (d *DistributedApp) handleStatusRequest(conn net.Conn) {
+	d.serveStatusRequest(conn, "")
+}
+
+// serveStatusRequest answers a single status request, enforcing the bearer token when set.
+func (d *DistributedApp) serveStatusRequest(conn net.Conn, token string) {
 	defer conn.Close() //nolint:errcheck // connection cleanup, error not actionable
 
-	// Get current status
-	status := d.GetStatus()
+	// A client that stalls gives up its connection instead of holding it forever
+	_ = conn.SetDeadline(time.Now().Add(statusRequestTimeout)) //nolint:errcheck // a failed deadline leaves the request unbounded
+
+	// Route on the request path; anything unrecognized gets the status document
+	path := "/status"
+	authorization := ""
+	if req, err := http.ReadRequest(bufio.NewReader(conn)); err == nil {
+		path = req.URL.Path
+		authorization = req.Header.Get("Authorization")
+	}
+
+	if token != "" && subtle.ConstantTimeCompare([]byte(authorization), []byte("Bearer "+token)) != 1 {
+		writeStatusResponse(conn, "401 Unauthorized", map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	switch path {
+	case "/scores":
+		breakdown, err := d.getScoreBreakdown()
+		if err != nil {
+			writeStatusResponse(conn, "503 Service Unavailable", map[string]string{"error": err.Error()})
+			return
+		}
+		writeStatusResponse(conn, "200 OK", breakdown)
+	default:
+		writeStatusResponse(conn, "200 OK", d.GetStatus())
+	}
+}
 
-	// Encode status as JSON
-	statusData, err := json.Marshal(status)
+// writeStatusResponse writes payload as a JSON HTTP response.
+func writeStatusResponse(conn net.Conn, statusLine string, payload interface{}) {
+	// Encode payload as JSON
+	data, err := json.Marshal(payload)
 	if err != nil {
 		fmt.Printf("Error marshaling status: %v\n", err)
 		return
 	}
 
-	// Send status response
-	response := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(statusData), string(statusData))
-	_, err = io.WriteString(conn, response)
-	if err != nil {
+	// Send response
+	response := fmt.Sprintf("HTTP/1.1 %s\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", statusLine, len(data), string(data))
+	if _, err := io.WriteString(conn, response); err != nil {
 		fmt.Printf("Error writing status response: %v\n", err)
 	}
 }
 
+// getScoreBreakdown returns the balancer's per-node score breakdown when supported.
+func (d *DistributedApp) getScoreBreakdown() ([]models.NodeScoreBreakdown, error) {
+	provider, ok := d.balancer.(ScoreBreakdownProvider)
+	if !ok {
+		return nil, fmt.Errorf("score breakdown requires the advanced balancer")
+	}
+	return provider.GetNodeScoreBreakdown()
+}
+
 // GetStatus returns the current status of the distributed application.
 func (d *DistributedApp) GetStatus() map[string]interface{} {
-	return map[string]interface{}{
+	status := map[string]interface{}{
+		"cluster":           d.config.Cluster.Name,
 		"node_id":           d.config.Raft.NodeID,
 		"address":           d.config.Raft.Address,
 		"is_leader":         d.isLeader,
@@ -308,7 +457,37 @@ func (d *DistributedApp) GetStatus() map[string]interface{} {
 		"leader":            d.raftNode.GetLeader(),
 		"peers":             d.raftNode.GetPeers(),
 		"balancing_enabled": true, // Always enabled when running
+		"read_only":         d.config.ReadOnly,
 	}
+
+	if clusterStatus, err := d.balancer.GetClusterStatus(); err == nil {
+		status["balance_score"] = clusterStatus.BalanceScore
+		if len(clusterStatus.SLABreaches) > 0 {
+			status["sla_breaches"] = clusterStatus.SLABreaches
+		}
+		// Only the leader runs cycles, followers have no schedule to report
+		if !clusterStatus.LastRun.IsZero() {
+			status["last_run"] = clusterStatus.LastRun
+			status["next_run"] = clusterStatus.NextRun
+		}
+	}
+
+	// VMs that should move but can't mean balancing is stuck
+	if reporter, ok := d.balancer.(UnschedulableReporter); ok {
+		status["unschedulable_vms"] = reporter.GetUnschedulableVMs()
+	}
+
+	// Where the last cycle spent its time, to tune large clusters
+	if reporter, ok := d.balancer.(PhaseTimingReporter); ok {
+		status["phase_timings"] = reporter.GetPhaseTimings()
+	}
+
+	// Nodes consolidation emptied, ready to be powered down
+	if reporter, ok := d.balancer.(ConsolidationReporter); ok {
+		status["emptied_nodes"] = reporter.GetEmptiedNodes()
+	}
+
+	return status
 }
 
 // setupDistributedConfig loads and validates configuration for distributed app.
@@ -451,6 +630,9 @@ func performAutoDiscoveryWithPeers(config *config.Config, discoveryService *prox
 
 // setupBalancer creates the appropriate balancer instance.
 func setupBalancer(client ClientInterface, config *config.Config) BalancerInterface {
+	if config.IsConsolidateBalancer() {
+		return balancer.NewConsolidationBalancer(client, config)
+	}
 	if config.IsAdvancedBalancer() {
 		return balancer.NewAdvancedBalancer(client, config)
 	}
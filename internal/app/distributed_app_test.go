@@ -1,14 +1,22 @@
 package app
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cblomart/GoProxLB/internal/balancer"
+	"github.com/cblomart/GoProxLB/internal/config"
 	"github.com/cblomart/GoProxLB/internal/models"
 	"github.com/cblomart/GoProxLB/internal/proxmox"
+	"github.com/cblomart/GoProxLB/internal/raft"
 )
 
 // MockClient implements ClientInterface for testing.
@@ -118,6 +126,13 @@ func (m *MockDistributedBalancer) GetClusterRecommendations() (map[string]interf
 	}, m.err
 }
 
+func (m *MockDistributedBalancer) GetNodeScoreBreakdown() ([]models.NodeScoreBreakdown, error) {
+	return []models.NodeScoreBreakdown{
+		{Node: "node2", Resource: 30.0, Stability: 80.0, Capacity: 40.0, MigrationCost: 10.0, Final: 37.0},
+		{Node: "node1", Resource: 85.0, Stability: 60.0, Capacity: 70.0, MigrationCost: 20.0, Final: 69.0},
+	}, m.err
+}
+
 // createTestDistributedApp creates a distributed app for testing with temporary directories.
 //
 //nolint:unparam // tempDir is used internally but not needed by callers
@@ -267,7 +282,7 @@ func TestDistributedAppGetStatus(t *testing.T) {
 	}
 
 	// Check required fields
-	requiredFields := []string{"node_id", "address", "is_leader", "raft_state", "leader", "peers", "balancing_enabled"}
+	requiredFields := []string{"cluster", "node_id", "address", "is_leader", "raft_state", "leader", "peers", "balancing_enabled", "phase_timings"}
 	for _, field := range requiredFields {
 		if _, exists := status[field]; !exists {
 			t.Errorf("Status missing required field: %s", field)
@@ -375,3 +390,266 @@ func TestDistributedAppStopBalancingLoop(t *testing.T) {
 
 	// Should not panic or error
 }
+
+// querySocket sends a GET request for path through handleStatusRequest and returns the raw response.
+func querySocket(t *testing.T, app *DistributedApp, path string) string {
+	t.Helper()
+
+	server, client := net.Pipe()
+	go app.handleStatusRequest(server)
+
+	request := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: localhost\r\n\r\n", path)
+	if _, err := client.Write([]byte(request)); err != nil {
+		t.Fatalf("Failed to write request: %v", err)
+	}
+
+	response, err := io.ReadAll(client)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return string(response)
+}
+
+func TestDistributedAppScoreBreakdownEndpoint(t *testing.T) {
+	app, _ := createTestDistributedApp(t, 7955)
+	defer func() { _ = app.Stop() }()
+
+	app.balancer = &MockDistributedBalancer{}
+
+	response := querySocket(t, app, "/scores")
+	if !strings.HasPrefix(response, "HTTP/1.1 200 OK") {
+		t.Fatalf("Expected 200 response, got %q", response)
+	}
+
+	parts := strings.SplitN(response, "\r\n\r\n", 2)
+	if len(parts) != 2 {
+		t.Fatalf("Invalid response format: %q", response)
+	}
+
+	var breakdown []models.NodeScoreBreakdown
+	if err := json.Unmarshal([]byte(parts[1]), &breakdown); err != nil {
+		t.Fatalf("Failed to decode score breakdown: %v", err)
+	}
+
+	if len(breakdown) != 2 {
+		t.Fatalf("Expected 2 nodes in breakdown, got %d", len(breakdown))
+	}
+	if breakdown[0].Node != "node2" || breakdown[0].Final != 37.0 {
+		t.Errorf("Expected node2 with final score 37, got %+v", breakdown[0])
+	}
+	if breakdown[1].Resource != 85.0 || breakdown[1].Stability != 60.0 || breakdown[1].Capacity != 70.0 || breakdown[1].MigrationCost != 20.0 {
+		t.Errorf("Expected component scores to round-trip, got %+v", breakdown[1])
+	}
+
+	// Other paths still return the status document
+	status, err := parseHTTPResponse([]byte(querySocket(t, app, "/status")))
+	if err != nil {
+		t.Fatalf("Failed to parse status response: %v", err)
+	}
+	if status["node_id"] != "test-node" {
+		t.Errorf("Expected status document for /status, got %v", status)
+	}
+}
+
+func TestDistributedAppScoreBreakdownUnsupported(t *testing.T) {
+	app, _ := createTestDistributedApp(t, 7956)
+	defer func() { _ = app.Stop() }()
+
+	// The threshold balancer does not expose a score breakdown
+	app.balancer = &mockBalancer{}
+
+	response := querySocket(t, app, "/scores")
+	if !strings.HasPrefix(response, "HTTP/1.1 503") {
+		t.Errorf("Expected 503 response, got %q", response)
+	}
+}
+
+// queryTCPStatus sends a GET request for path to the TCP status listener and returns the raw response.
+func queryTCPStatus(t *testing.T, address, path, token string) string {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Failed to dial status listener: %v", err)
+	}
+	defer conn.Close()
+
+	request := fmt.Sprintf("GET %s HTTP/1.1\r\nHost: localhost\r\n", path)
+	if token != "" {
+		request += "Authorization: Bearer " + token + "\r\n"
+	}
+	if _, err := conn.Write([]byte(request + "\r\n")); err != nil {
+		t.Fatalf("Failed to write request: %v", err)
+	}
+
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return string(response)
+}
+
+func TestDistributedAppStatusOverTCP(t *testing.T) {
+	app, _ := createTestDistributedApp(t, 7957)
+	defer func() { _ = app.Stop() }()
+
+	app.config.Status.TCPAddress = "127.0.0.1:0"
+	if err := app.startStatusTCP(); err != nil {
+		t.Fatalf("Failed to start TCP status listener: %v", err)
+	}
+
+	status, err := parseHTTPResponse([]byte(queryTCPStatus(t, app.tcpListener.Addr().String(), "/status", "")))
+	if err != nil {
+		t.Fatalf("Failed to parse status response: %v", err)
+	}
+
+	if status["node_id"] != "test-node" {
+		t.Errorf("Expected node_id 'test-node', got %v", status["node_id"])
+	}
+	if status["balancing_enabled"] != true {
+		t.Errorf("Expected balancing_enabled true, got %v", status["balancing_enabled"])
+	}
+}
+
+func TestDistributedAppStatusOverTCPRequiresToken(t *testing.T) {
+	app, _ := createTestDistributedApp(t, 7958)
+	defer func() { _ = app.Stop() }()
+
+	app.config.Status = config.StatusConfig{TCPAddress: "127.0.0.1:0", Token: "s3cret"}
+	if err := app.startStatusTCP(); err != nil {
+		t.Fatalf("Failed to start TCP status listener: %v", err)
+	}
+	address := app.tcpListener.Addr().String()
+
+	for _, token := range []string{"", "wrong"} {
+		if response := queryTCPStatus(t, address, "/status", token); !strings.HasPrefix(response, "HTTP/1.1 401") {
+			t.Errorf("Expected 401 with token %q, got %q", token, response)
+		}
+	}
+
+	status, err := parseHTTPResponse([]byte(queryTCPStatus(t, address, "/status", "s3cret")))
+	if err != nil {
+		t.Fatalf("Failed to parse status response: %v", err)
+	}
+	if status["node_id"] != "test-node" {
+		t.Errorf("Expected node_id 'test-node', got %v", status["node_id"])
+	}
+}
+
+func TestDistributedAppStatusTCPDisabledByDefault(t *testing.T) {
+	app, _ := createTestDistributedApp(t, 7959)
+	defer func() { _ = app.Stop() }()
+
+	if err := app.startStatusTCP(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if app.tcpListener != nil {
+		t.Error("Expected no TCP listener without a status address")
+	}
+}
+
+// leaderElectedAt returns a wait function that finds a leader once the given time has passed.
+func leaderElectedAt(electedAt time.Time, attempts *int) func(context.Context) error {
+	return func(ctx context.Context) error {
+		*attempts++
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			if !time.Now().Before(electedAt) {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+func TestWaitForLeaderRetriesSlowElection(t *testing.T) {
+	attempts := 0
+	wait := leaderElectedAt(time.Now().Add(120*time.Millisecond), &attempts)
+
+	if err := waitForLeader(context.Background(), wait, 40*time.Millisecond, 60*time.Millisecond); err != nil {
+		t.Fatalf("Expected the election to succeed on retry, got %v", err)
+	}
+	if attempts < 2 {
+		t.Errorf("Expected the first attempt to time out and be retried, got %d attempts", attempts)
+	}
+}
+
+func TestWaitForLeaderStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	attempts := 0
+	wait := leaderElectedAt(time.Now().Add(time.Hour), &attempts)
+
+	if err := waitForLeader(ctx, wait, 10*time.Millisecond, 20*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to end with the parent context, got %v", err)
+	}
+	if attempts < 2 {
+		t.Errorf("Expected retries until the parent context ended, got %d attempts", attempts)
+	}
+}
+
+func TestWaitForLeaderReturnsOtherErrors(t *testing.T) {
+	failure := errors.New("raft shut down")
+	wait := func(ctx context.Context) error { return failure }
+
+	if err := waitForLeader(context.Background(), wait, time.Second, time.Second); !errors.Is(err, failure) {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+}
+
+func TestDistributedAppStatusRequestTimesOut(t *testing.T) {
+	app, _ := createTestDistributedApp(t, 7960)
+	defer func() { _ = app.Stop() }()
+
+	timeout := statusRequestTimeout
+	statusRequestTimeout = 50 * time.Millisecond
+	defer func() { statusRequestTimeout = timeout }()
+
+	// A client that connects and sends nothing gets disconnected
+	server, client := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		app.serveStatusRequest(server, "s3cret")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a silent client to be disconnected")
+	}
+	if _, err := client.Read(make([]byte, 1)); err == nil {
+		t.Error("Expected the connection to be closed")
+	}
+}
+
+func TestDistributedAppFailedStartStopsComponents(t *testing.T) {
+	app, _ := createTestDistributedApp(t, 7961)
+	defer func() { _ = app.Stop() }()
+
+	// The metrics port is taken, so the start fails after the status socket is up
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = taken.Close() }()
+	app.config.Metrics.Enabled = true
+	app.config.Metrics.Address = taken.Addr().String()
+
+	if err := app.Start(); err == nil {
+		t.Fatal("Expected the start to fail on the metrics port")
+	}
+	if conn, err := net.Dial("unix", app.listener.Addr().String()); err == nil {
+		_ = conn.Close()
+		t.Error("Expected the status socket to be closed")
+	}
+	if raft.HoldsDataDirLock(app.config.Raft.DataDir) {
+		t.Error("Expected the Raft node to release the data directory")
+	}
+}
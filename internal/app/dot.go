@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// Command output formats.
+const (
+	outputText = "text"
+	outputDOT  = "dot"
+	outputJSON = "json"
+)
+
+// renderMigrationPlanDOT renders a migration plan as a Graphviz DOT digraph.
+// Every node is drawn, and each planned VM move becomes an edge from source to target.
+func renderMigrationPlanDOT(nodes []models.Node, migrations []models.Migration) string {
+	var b strings.Builder
+
+	b.WriteString("digraph migration_plan {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for i := range nodes {
+		node := &nodes[i]
+		label := fmt.Sprintf("%s\nCPU %.0f%% / Mem %.0f%%", node.Name, node.CPU.Usage, node.Memory.Usage)
+		fmt.Fprintf(&b, "  %s [label=%s];\n", strconv.Quote(node.Name), strconv.Quote(label))
+	}
+
+	for i := range migrations {
+		migration := &migrations[i]
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n",
+			strconv.Quote(migration.FromNode), strconv.Quote(migration.ToNode), strconv.Quote(vmLabel(migration.VM.ID, migration.VM.Name)))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// vmLabel names a VM by ID, with its name when known.
+func vmLabel(id int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("VM %d", id)
+	}
+	return fmt.Sprintf("VM %d (%s)", id, name)
+}
+
+// migrationsFromResults rebuilds the planned migrations from balancing results.
+func migrationsFromResults(results []models.BalancingResult) []models.Migration {
+	migrations := make([]models.Migration, 0, len(results))
+	for i := range results {
+		result := &results[i]
+		migrations = append(migrations, models.Migration{
+			VM:        result.VM,
+			FromNode:  result.SourceNode,
+			ToNode:    result.TargetNode,
+			Gain:      result.ResourceGain,
+			Freed:     result.Freed,
+			Status:    "pending",
+			StartTime: result.Timestamp,
+
+			EstimatedDuration: result.EstimatedDuration,
+		})
+	}
+	return migrations
+}
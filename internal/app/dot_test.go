@@ -0,0 +1,201 @@
+package app
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+func TestRenderMigrationPlanDOT(t *testing.T) {
+	nodes := []models.Node{
+		{Name: "node1", CPU: models.CPUInfo{Usage: 85}, Memory: models.MemoryInfo{Usage: 75}},
+		{Name: "node2", CPU: models.CPUInfo{Usage: 30}, Memory: models.MemoryInfo{Usage: 25}},
+		{Name: "node3", CPU: models.CPUInfo{Usage: 40}, Memory: models.MemoryInfo{Usage: 50}},
+	}
+	migrations := []models.Migration{
+		{VM: models.VM{ID: 100, Name: "web-1"}, FromNode: "node1", ToNode: "node2"},
+		{VM: models.VM{ID: 101, Name: `db "primary"`}, FromNode: "node1", ToNode: "node3"},
+		{VM: models.VM{ID: 102}, FromNode: "node1", ToNode: "node2"},
+	}
+
+	dot := renderMigrationPlanDOT(nodes, migrations)
+
+	if !strings.HasPrefix(dot, "digraph migration_plan {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("Expected a complete digraph, got:\n%s", dot)
+	}
+
+	expected := []string{
+		`"node1" [label="node1\nCPU 85% / Mem 75%"];`,
+		`"node2" [label="node2\nCPU 30% / Mem 25%"];`,
+		`"node3" [label="node3\nCPU 40% / Mem 50%"];`,
+		`"node1" -> "node2" [label="VM 100 (web-1)"];`,
+		`"node1" -> "node3" [label="VM 101 (db \"primary\")"];`,
+		`"node1" -> "node2" [label="VM 102"];`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(dot, "  "+line+"\n") {
+			t.Errorf("Expected DOT output to contain %q, got:\n%s", line, dot)
+		}
+	}
+
+	// Every statement ends with a semicolon and braces are balanced
+	if strings.Count(dot, "{") != strings.Count(dot, "}") {
+		t.Errorf("Expected balanced braces, got:\n%s", dot)
+	}
+	lines := strings.Split(strings.TrimSpace(dot), "\n")
+	for _, line := range lines[1 : len(lines)-1] {
+		if !strings.HasSuffix(line, ";") {
+			t.Errorf("Expected statement to end with ';', got %q", line)
+		}
+	}
+	if edges := strings.Count(dot, " -> "); edges != len(migrations) {
+		t.Errorf("Expected %d edges, got %d", len(migrations), edges)
+	}
+}
+
+func TestRenderMigrationPlanDOTEmptyPlan(t *testing.T) {
+	nodes := []models.Node{{Name: "node1"}, {Name: "node2"}}
+
+	dot := renderMigrationPlanDOT(nodes, nil)
+
+	if strings.Contains(dot, "->") {
+		t.Errorf("Expected no edges for an empty plan, got:\n%s", dot)
+	}
+	if !strings.Contains(dot, `"node2" [label=`) {
+		t.Errorf("Expected nodes to be drawn even without moves, got:\n%s", dot)
+	}
+}
+
+func TestMigrationsFromResults(t *testing.T) {
+	now := time.Now()
+	results := []models.BalancingResult{
+		{SourceNode: "node1", TargetNode: "node2", VM: models.VM{ID: 100}, DryRun: true, Timestamp: now},
+	}
+
+	migrations := migrationsFromResults(results)
+
+	if len(migrations) != 1 {
+		t.Fatalf("Expected 1 migration, got %d", len(migrations))
+	}
+	migration := migrations[0]
+	if migration.VM.ID != 100 || migration.FromNode != "node1" || migration.ToNode != "node2" || !migration.StartTime.Equal(now) {
+		t.Errorf("Expected migration to mirror the result, got %+v", migration)
+	}
+}
+
+func TestApplyBalanceOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         BalanceOptions
+		wantErr      bool
+		wantReadOnly bool
+	}{
+		{"defaults", BalanceOptions{}, false, false},
+		{"dry run", BalanceOptions{DryRun: true}, false, true},
+		{"dot with dry run", BalanceOptions{DryRun: true, Output: outputDOT}, false, true},
+		{"dot without dry run", BalanceOptions{Output: outputDOT}, true, false},
+		{"junit with dry run", BalanceOptions{DryRun: true, Output: outputJUnit}, false, true},
+		{"junit without dry run", BalanceOptions{Output: outputJUnit}, true, false},
+		{"unknown output", BalanceOptions{DryRun: true, Output: "svg"}, true, false},
+		{"invalid force mode", BalanceOptions{ForceMode: "sometimes"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{config: &config.Config{}, client: &mockClient{}, balancer: &mockBalancer{}}
+
+			err := app.applyBalanceOptions(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyBalanceOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if app.config.ReadOnly != tt.wantReadOnly {
+				t.Errorf("Expected read-only %v, got %v", tt.wantReadOnly, app.config.ReadOnly)
+			}
+		})
+	}
+}
+
+func TestPrintMigrationPlanDOT(t *testing.T) {
+	app := &App{
+		config: &config.Config{},
+		client: &mockClient{nodes: []models.Node{{Name: "node1"}, {Name: "node2"}}},
+		balancer: &mockBalancer{results: []models.BalancingResult{
+			{SourceNode: "node1", TargetNode: "node2", VM: models.VM{ID: 100}, DryRun: true},
+		}},
+	}
+
+	if err := app.printMigrationPlanDOT(true); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// captureStdout returns what fn writes to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r) //nolint:errcheck // test capture, a short read fails the assertions
+		output <- string(data)
+	}()
+
+	fn()
+	_ = w.Close() //nolint:errcheck // pipe cleanup, error not actionable
+	return <-output
+}
+
+// newDryRunApp creates an app planning with the advanced balancer on an unbalanced cluster, logging
+// at debug level so the balancer is as chatty as it gets.
+func newDryRunApp(t *testing.T) *App {
+	t.Helper()
+
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = balancerAdvanced
+	cfg.Logging.Level = "debug"
+
+	app, err := NewAppWithDependencies("test-config.yaml", &mockConfigLoader{config: cfg}, &mockClient{nodes: createTestNodes()}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	t.Cleanup(app.cancel)
+	return app
+}
+
+func TestForceBalanceDOTOutputIsOnlyTheGraph(t *testing.T) {
+	app := newDryRunApp(t)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = app.forceBalance(BalanceOptions{Force: true, DryRun: true, Output: outputDOT})
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// stdout must parse as a single digraph: header, statements, closing brace
+	if !strings.HasPrefix(output, "digraph migration_plan {\n") || !strings.HasSuffix(output, "}\n") {
+		t.Fatalf("Expected stdout to be only the DOT graph, got:\n%s", output)
+	}
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	for _, line := range lines[1 : len(lines)-1] {
+		if !strings.HasPrefix(line, "  ") || !strings.HasSuffix(line, ";") {
+			t.Errorf("Expected a DOT statement, got %q in:\n%s", line, output)
+		}
+	}
+	if !strings.Contains(output, `"node1" [label=`) || !strings.Contains(output, `"node2" [label=`) {
+		t.Errorf("Expected both nodes in the graph, got:\n%s", output)
+	}
+}
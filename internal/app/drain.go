@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// DrainNode moves every VM off a node, each after the VMs it depends on and in boot order.
+// A dry run only prints the plan.
+func DrainNode(configPath, node string, dryRun bool) error {
+	app, err := initializeApp(configPath)
+	if err != nil {
+		return err
+	}
+	defer app.cancel()
+
+	// A dry run plans like observer mode: nothing is migrated
+	if dryRun {
+		app.config.ReadOnly = true
+	}
+
+	return app.drainNode(os.Stdout, node)
+}
+
+// drainNode plans the drain of a node, prints the migration order and executes it.
+func (app *App) drainNode(w io.Writer, node string) error {
+	drainer, ok := app.balancer.(NodeDrainer)
+	if !ok {
+		return fmt.Errorf("the balancer can't drain nodes")
+	}
+
+	plan, err := drainer.PlanDrain(node)
+	if err != nil {
+		return fmt.Errorf("failed to plan the drain of %s: %w", node, err)
+	}
+	if len(plan.Migrations) == 0 {
+		fmt.Fprintf(w, "Nothing to move off %s\n", node)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Draining %s, %d migrations in order:\n", node, len(plan.Migrations))
+	for i := range plan.Migrations {
+		migration := &plan.Migrations[i]
+		line := fmt.Sprintf("  %d. VM %s (%d) to %s", i+1, migration.VM.Name, migration.VM.ID, migration.ToNode)
+		if migration.StorageMigration {
+			line += " (" + storageMigrationLabel + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	results := drainer.ExecutePlan(plan)
+	for i := range results {
+		printBalancingResult(w, &results[i])
+	}
+	return nil
+}
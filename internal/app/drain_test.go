@@ -0,0 +1,58 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// mockDrainer is a balancer that drains nodes along a fixed plan.
+type mockDrainer struct {
+	mockBalancer
+	plan     *models.MigrationPlan
+	executed []int
+}
+
+func (m *mockDrainer) PlanDrain(node string) (*models.MigrationPlan, error) {
+	return m.plan, nil
+}
+
+func (m *mockDrainer) ExecutePlan(plan *models.MigrationPlan) []models.BalancingResult {
+	results := make([]models.BalancingResult, 0, len(plan.Migrations))
+	for _, migration := range plan.Migrations {
+		m.executed = append(m.executed, migration.VM.ID)
+		results = append(results, models.BalancingResult{Success: true, VM: migration.VM, SourceNode: migration.FromNode, TargetNode: migration.ToNode})
+	}
+	return results
+}
+
+func TestDrainNode(t *testing.T) {
+	drainer := &mockDrainer{plan: &models.MigrationPlan{Migrations: []models.Migration{
+		{VM: models.VM{ID: 111, Name: "db"}, FromNode: "node1", ToNode: "node2"},
+		{VM: models.VM{ID: 110, Name: "web"}, FromNode: "node1", ToNode: "node3"},
+	}}}
+	app := &App{balancer: drainer}
+
+	var buf bytes.Buffer
+	if err := app.drainNode(&buf, "node1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(drainer.executed) != 2 || drainer.executed[0] != 111 || drainer.executed[1] != 110 {
+		t.Errorf("Expected the plan executed in order, got %v", drainer.executed)
+	}
+	for _, line := range []string{"1. VM db (111) to node2", "2. VM web (110) to node3"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestDrainNodeUnsupportedBalancer(t *testing.T) {
+	app := &App{balancer: &mockBalancer{}}
+	var buf bytes.Buffer
+	if err := app.drainNode(&buf, "node1"); err == nil {
+		t.Error("Expected an error for a balancer that can't drain nodes")
+	}
+}
@@ -12,6 +12,53 @@ type BalancerInterface interface {
 	GetClusterStatus() (*models.ClusterStatus, error)
 }
 
+// ScoreBreakdownProvider is implemented by balancers that can explain their node scores.
+type ScoreBreakdownProvider interface {
+	GetNodeScoreBreakdown() ([]models.NodeScoreBreakdown, error)
+}
+
+// UnschedulableReporter is implemented by balancers that track VMs stuck without a valid target.
+type UnschedulableReporter interface {
+	GetUnschedulableVMs() []models.UnschedulableVM
+}
+
+// SkippedVMReporter is implemented by balancers that explain which VMs they evaluated but left in place.
+type SkippedVMReporter interface {
+	GetSkippedVMs() []models.SkippedVM
+}
+
+// PlanExecutor is implemented by balancers that can execute a migration plan computed beforehand.
+type PlanExecutor interface {
+	ExecutePlan(plan *models.MigrationPlan) []models.BalancingResult
+}
+
+// PhaseTimingReporter is implemented by balancers that measure the time spent in each phase of a cycle.
+type PhaseTimingReporter interface {
+	GetPhaseTimings() []models.PhaseTiming
+}
+
+// MigrationHistoryReporter is implemented by balancers that remember their recent migrations.
+type MigrationHistoryReporter interface {
+	GetMigrationHistory() []models.MigrationHistory
+}
+
+// ConsolidationReporter is implemented by balancers that empty nodes by packing VMs onto the others.
+type ConsolidationReporter interface {
+	GetEmptiedNodes() []string
+}
+
+// PlanApplier is implemented by balancers that can check a plan written by hand before executing it.
+type PlanApplier interface {
+	PlanExecutor
+	ValidatePlan(plan *models.MigrationPlan) error
+}
+
+// NodeDrainer is implemented by balancers that can move every VM off a node in a safe order.
+type NodeDrainer interface {
+	PlanExecutor
+	PlanDrain(node string) (*models.MigrationPlan, error)
+}
+
 // ClientInterface defines the interface for Proxmox API operations.
 type ClientInterface interface {
 	GetClusterInfo() (*models.Cluster, error)
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Inventory file formats.
+const (
+	inventoryJSON = "json"
+	inventoryYAML = "yaml"
+)
+
+// Inventory is a snapshot of the cluster's nodes and their VMs, for offline analysis or backups.
+type Inventory struct {
+	Cluster    string        `json:"cluster"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Nodes      []models.Node `json:"nodes"`
+}
+
+// ExportInventory writes the cluster's nodes and VMs to a JSON or YAML file, or stdout when
+// outputPath is empty. An empty format is taken from the file extension, JSON by default.
+func ExportInventory(configPath, outputPath, format string) error {
+	app, err := initializeApp(configPath)
+	if err != nil {
+		return err
+	}
+	defer app.cancel()
+
+	if format == "" {
+		format = inventoryFormat(outputPath)
+	}
+	if outputPath == "" {
+		return app.exportInventory(os.Stdout, format, time.Now())
+	}
+
+	file, err := os.Create(filepath.Clean(outputPath))
+	if err != nil {
+		return fmt.Errorf("failed to create inventory file: %w", err)
+	}
+	if err := app.exportInventory(file, format, time.Now()); err != nil {
+		file.Close() //nolint:errcheck // the export error is reported instead
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Inventory exported to %s\n", outputPath)
+	return nil
+}
+
+// exportInventory writes the current inventory to w in the given format.
+func (app *App) exportInventory(w io.Writer, format string, now time.Time) error {
+	nodes, err := app.client.GetNodes()
+	if err != nil {
+		return fmt.Errorf("failed to get nodes: %w", err)
+	}
+
+	inventory := &Inventory{ExportedAt: now, Nodes: nodes}
+	if app.config != nil {
+		inventory.Cluster = app.config.Cluster.Name
+	}
+	return writeInventory(w, inventory, format)
+}
+
+// inventoryFormat returns the format matching a file's extension: YAML for .yaml and .yml, JSON otherwise.
+func inventoryFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return inventoryYAML
+	default:
+		return inventoryJSON
+	}
+}
+
+// writeInventory encodes an inventory as indented JSON or as YAML. Both use the JSON field names.
+func writeInventory(w io.Writer, inventory *Inventory, format string) error {
+	data, err := json.Marshal(inventory)
+	if err != nil {
+		return fmt.Errorf("failed to encode inventory: %w", err)
+	}
+
+	switch format {
+	case inventoryJSON:
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return fmt.Errorf("failed to encode inventory: %w", err)
+		}
+		indented.WriteByte('\n')
+		_, err = indented.WriteTo(w)
+		return err
+	case inventoryYAML:
+		// Go through the JSON form so the keys match, keeping integers as integers
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var generic interface{}
+		if err := decoder.Decode(&generic); err != nil {
+			return fmt.Errorf("failed to encode inventory: %w", err)
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(plainNumbers(generic)); err != nil {
+			return fmt.Errorf("failed to encode inventory: %w", err)
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("invalid inventory format %q: must be %q or %q", format, inventoryJSON, inventoryYAML)
+	}
+}
+
+// plainNumbers replaces the JSON numbers of a decoded document by integers or floats, which YAML
+// writes unquoted.
+func plainNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = plainNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = plainNumbers(item)
+		}
+	case json.Number:
+		if integer, err := v.Int64(); err == nil {
+			return integer
+		}
+		if float, err := v.Float64(); err == nil {
+			return float
+		}
+	}
+	return value
+}
+
+// LoadInventory reads an inventory exported as JSON or YAML, picking the format from the file extension.
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory file: %w", err)
+	}
+	return readInventory(data, inventoryFormat(path))
+}
+
+// readInventory decodes an inventory from JSON or YAML.
+func readInventory(data []byte, format string) (*Inventory, error) {
+	if format == inventoryYAML {
+		var generic interface{}
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("failed to decode inventory: %w", err)
+		}
+		converted, err := json.Marshal(generic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode inventory: %w", err)
+		}
+		data = converted
+	}
+
+	var inventory Inventory
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory: %w", err)
+	}
+	return &inventory, nil
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInventoryRoundTrip(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Cluster.Name = "lab"
+	nodes := createTestNodes()
+	nodes[0].VMs[0].Tags = []string{"plb_affinity_web"}
+	app, err := NewAppWithDependencies("test-config.yaml", &mockConfigLoader{config: cfg}, &mockClient{nodes: nodes}, &mockBalancer{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	exportedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, name := range []string{"inventory.json", "inventory.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := app.exportInventory(&buf, inventoryFormat(name), exportedAt); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+				t.Fatalf("Failed to write inventory: %v", err)
+			}
+
+			inventory, err := LoadInventory(path)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if inventory.Cluster != "lab" || !inventory.ExportedAt.Equal(exportedAt) {
+				t.Errorf("Expected cluster lab exported at %v, got %q at %v", exportedAt, inventory.Cluster, inventory.ExportedAt)
+			}
+			if !reflect.DeepEqual(inventory.Nodes, nodes) {
+				t.Errorf("Expected the nodes to survive the round trip, got %+v", inventory.Nodes)
+			}
+		})
+	}
+}
+
+func TestWriteInventoryYAMLKeys(t *testing.T) {
+	var buf bytes.Buffer
+	inventory := &Inventory{Cluster: "lab", Nodes: createTestNodes()}
+	if err := writeInventory(&buf, inventory, inventoryYAML); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// YAML uses the JSON field names, with integers left unquoted
+	for _, expected := range []string{"cluster: lab", "exported_at:", "nodes:", "vms:"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected YAML to contain %q, got:\n%s", expected, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "e+") {
+		t.Errorf("Expected integers in plain notation, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteInventoryInvalidFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeInventory(&buf, &Inventory{}, "xml"); err == nil {
+		t.Error("Expected an error for an unknown inventory format")
+	}
+}
+
+func TestInventoryFormat(t *testing.T) {
+	tests := map[string]string{
+		"inventory.json": inventoryJSON,
+		"inventory.yaml": inventoryYAML,
+		"inventory.YML":  inventoryYAML,
+		"inventory":      inventoryJSON,
+		"":               inventoryJSON,
+	}
+	for path, want := range tests {
+		if got := inventoryFormat(path); got != want {
+			t.Errorf("inventoryFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
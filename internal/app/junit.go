@@ -0,0 +1,92 @@
+package app
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// outputJUnit renders a dry-run plan as a JUnit XML report, so CI can fail on proposed migrations.
+const outputJUnit = "junit"
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one balancing plan.
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single check, failed or skipped when the matching element is set.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+// junitFailure describes why a test case failed.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped describes why a test case was skipped.
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// renderMigrationPlanJUnit renders a migration plan as a JUnit XML report.
+// Every planned move is a failed test case and every VM left in place a skipped one;
+// a plan without moves reports a single passing case.
+func renderMigrationPlanJUnit(migrations []models.Migration, skipped []models.SkippedVM, now time.Time) (string, error) {
+	suite := junitTestSuite{
+		Name:      "goproxlb.balance",
+		Timestamp: now.UTC().Format(time.RFC3339),
+	}
+
+	for i := range migrations {
+		migration := &migrations[i]
+		message := fmt.Sprintf("migration planned from %s to %s", migration.FromNode, migration.ToNode)
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      fmt.Sprintf("%s stays on %s", vmLabel(migration.VM.ID, migration.VM.Name), migration.FromNode),
+			ClassName: "goproxlb.balance.migrations",
+			Failure:   &junitFailure{Message: message, Type: "migration", Text: message},
+		})
+		suite.Failures++
+	}
+
+	if len(migrations) == 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      "no migrations planned",
+			ClassName: "goproxlb.balance.migrations",
+		})
+	}
+
+	for _, vm := range skipped {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      fmt.Sprintf("%s stays on %s", vmLabel(vm.VMID, vm.Name), vm.Node),
+			ClassName: "goproxlb.balance.skipped",
+			Skipped:   &junitSkipped{Message: vm.Reason},
+		})
+		suite.Skipped++
+	}
+	suite.Tests = len(suite.Cases)
+
+	report, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to render JUnit report: %w", err)
+	}
+	return xml.Header + string(report) + "\n", nil
+}
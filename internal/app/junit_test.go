@@ -0,0 +1,154 @@
+package app
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// parseJUnitReport checks the report is well-formed XML with a single suite and returns it.
+func parseJUnitReport(t *testing.T, report string) junitTestSuite {
+	t.Helper()
+
+	if !strings.HasPrefix(report, xml.Header) {
+		t.Errorf("Expected an XML declaration, got:\n%s", report)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal([]byte(report), &suites); err != nil {
+		t.Fatalf("Expected valid XML, got %v:\n%s", err, report)
+	}
+	if suites.XMLName.Local != "testsuites" || len(suites.Suites) != 1 {
+		t.Fatalf("Expected a <testsuites> root with one <testsuite>, got:\n%s", report)
+	}
+
+	suite := suites.Suites[0]
+	if suite.Name != "goproxlb.balance" || suite.Tests != len(suite.Cases) {
+		t.Errorf("Expected suite goproxlb.balance counting its %d cases, got %+v", len(suite.Cases), suite)
+	}
+	if _, err := time.Parse(time.RFC3339, suite.Timestamp); err != nil {
+		t.Errorf("Expected an RFC 3339 timestamp, got %q", suite.Timestamp)
+	}
+	for _, testCase := range suite.Cases {
+		if testCase.Name == "" || testCase.ClassName == "" {
+			t.Errorf("Expected every test case to have a name and classname, got %+v", testCase)
+		}
+	}
+	return suite
+}
+
+func TestRenderMigrationPlanJUnit(t *testing.T) {
+	migrations := []models.Migration{
+		{VM: models.VM{ID: 100, Name: "web-1"}, FromNode: "node1", ToNode: "node2"},
+		{VM: models.VM{ID: 102}, FromNode: "node1", ToNode: "node3"},
+	}
+	skipped := []models.SkippedVM{
+		{VMID: 101, Name: `ntp <&>`, Node: "node1", Reason: "cooldown (migrated within the last hour)"},
+	}
+
+	report, err := renderMigrationPlanJUnit(migrations, skipped, time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	suite := parseJUnitReport(t, report)
+
+	if suite.Tests != 3 || suite.Failures != 2 || suite.Skipped != 1 {
+		t.Errorf("Expected 3 tests, 2 failures and 1 skipped, got %d/%d/%d", suite.Tests, suite.Failures, suite.Skipped)
+	}
+	if suite.Timestamp != "2025-07-01T09:30:00Z" {
+		t.Errorf("Expected the plan time as timestamp, got %q", suite.Timestamp)
+	}
+
+	first := suite.Cases[0]
+	if first.Name != "VM 100 (web-1) stays on node1" || first.Failure == nil ||
+		first.Failure.Message != "migration planned from node1 to node2" {
+		t.Errorf("Expected VM 100's move as a failure, got %+v", first)
+	}
+	if suite.Cases[1].Name != "VM 102 stays on node1" || suite.Cases[1].Failure == nil {
+		t.Errorf("Expected VM 102's move as a failure, got %+v", suite.Cases[1])
+	}
+
+	last := suite.Cases[2]
+	if last.Name != "VM 101 (ntp <&>) stays on node1" || last.Failure != nil || last.Skipped == nil ||
+		last.Skipped.Message != "cooldown (migrated within the last hour)" {
+		t.Errorf("Expected VM 101 as skipped with its reason, got %+v", last)
+	}
+}
+
+func TestRenderMigrationPlanJUnitNoMigrations(t *testing.T) {
+	report, err := renderMigrationPlanJUnit(nil, nil, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	suite := parseJUnitReport(t, report)
+
+	if suite.Tests != 1 || suite.Failures != 0 || suite.Skipped != 0 {
+		t.Errorf("Expected a single passing test, got %d/%d/%d", suite.Tests, suite.Failures, suite.Skipped)
+	}
+	if testCase := suite.Cases[0]; testCase.Name != "no migrations planned" || testCase.Failure != nil || testCase.Skipped != nil {
+		t.Errorf("Expected a passing 'no migrations planned' case, got %+v", testCase)
+	}
+	if strings.Contains(report, "<failure") || strings.Contains(report, "<skipped") {
+		t.Errorf("Expected no failure or skipped elements, got:\n%s", report)
+	}
+}
+
+func TestPrintMigrationPlanJUnit(t *testing.T) {
+	app := &App{
+		config: &config.Config{},
+		client: &mockClient{nodes: []models.Node{{Name: "node1"}, {Name: "node2"}}},
+		balancer: &skippedReportingBalancer{
+			mockBalancer: mockBalancer{results: []models.BalancingResult{
+				{SourceNode: "node1", TargetNode: "node2", VM: models.VM{ID: 100}, DryRun: true},
+			}},
+			skipped: []models.SkippedVM{{VMID: 101, Node: "node1", Reason: "no gain"}},
+		},
+	}
+
+	if err := app.printMigrationPlanJUnit(true); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestForceBalanceJUnitOutputIsWellFormedXML(t *testing.T) {
+	app := newDryRunApp(t)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = app.forceBalance(BalanceOptions{Force: true, DryRun: true, Output: outputJUnit})
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	parseJUnitReport(t, output)
+
+	// Nothing but whitespace may surround the root element
+	decoder := xml.NewDecoder(strings.NewReader(output))
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected well-formed XML on stdout, got %v:\n%s", err, output)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && strings.TrimSpace(string(token)) != "" {
+				t.Errorf("Expected only the report on stdout, got stray text %q", token)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/metrics"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// startMetrics serves Prometheus metrics when enabled, until the context is done. Without metrics
+// it returns a nil recorder, which records nothing.
+func startMetrics(ctx context.Context, cfg *config.Config) (*metrics.Recorder, error) {
+	if !cfg.Metrics.Enabled {
+		return nil, nil
+	}
+
+	recorder := metrics.NewRecorder(cfg.Cluster.Name)
+	address, err := recorder.Serve(ctx, cfg.Metrics.Address)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Metrics: http://%s%s\n", address, metrics.Path)
+	return recorder, nil
+}
+
+// recordCycleMetrics records a balancing cycle, then the cluster status and node usage it left.
+// Failing to read them back only leaves the previous values in place.
+func recordCycleMetrics(recorder *metrics.Recorder, client ClientInterface, balancerInstance BalancerInterface, duration time.Duration, results []models.BalancingResult) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.ObserveCycle(duration, results)
+	if status, err := balancerInstance.GetClusterStatus(); err == nil {
+		recorder.ObserveStatus(status)
+	} else {
+		fmt.Printf("Warning: failed to get cluster status for metrics: %v\n", err)
+	}
+	if nodes, err := client.GetNodes(); err == nil {
+		recorder.ObserveNodes(nodes)
+	} else {
+		fmt.Printf("Warning: failed to get nodes for metrics: %v\n", err)
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// PlanFile is a plan of migrations written by hand, e.g. to swap two VMs between nodes.
+type PlanFile struct {
+	Atomic     bool       `json:"atomic" yaml:"atomic"` // All migrations must succeed, or the executed ones are rolled back
+	Migrations []PlanStep `json:"migrations" yaml:"migrations"`
+}
+
+// PlanStep moves a VM to a node.
+type PlanStep struct {
+	VMID int    `json:"vmid" yaml:"vmid"`
+	Node string `json:"node" yaml:"node"`
+}
+
+// ApplyPlan executes the migrations of a JSON or YAML plan file, in order. An atomic plan rolls the
+// executed migrations back when one fails. A dry run only prints the plan.
+func ApplyPlan(configPath, planPath string, dryRun bool) error {
+	plan, err := readPlanFile(planPath)
+	if err != nil {
+		return err
+	}
+
+	app, err := initializeApp(configPath)
+	if err != nil {
+		return err
+	}
+	defer app.cancel()
+
+	// A dry run plans like observer mode: nothing is migrated
+	if dryRun {
+		app.config.ReadOnly = true
+	}
+
+	return app.applyPlan(os.Stdout, plan)
+}
+
+// readPlanFile decodes a plan file, as YAML for .yaml and .yml files and JSON otherwise.
+func readPlanFile(path string) (*PlanFile, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	plan := &PlanFile{}
+	if inventoryFormat(path) == inventoryYAML {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(plan)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(plan)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan file %s: %w", path, err)
+	}
+	if len(plan.Migrations) == 0 {
+		return nil, fmt.Errorf("invalid plan file %s: no migrations", path)
+	}
+	return plan, nil
+}
+
+// applyPlan resolves the plan against the cluster, validates it against the placement rules, prints
+// the migration order and executes it. It fails when a migration fails.
+func (app *App) applyPlan(w io.Writer, file *PlanFile) error {
+	applier, ok := app.balancer.(PlanApplier)
+	if !ok {
+		return fmt.Errorf("the balancer can't execute plans")
+	}
+
+	nodes, err := app.client.GetNodes()
+	if err != nil {
+		return fmt.Errorf("failed to get nodes: %w", err)
+	}
+	plan, err := resolvePlan(nodes, file, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := applier.ValidatePlan(plan); err != nil {
+		return fmt.Errorf("invalid plan: %w", err)
+	}
+
+	kind := "Applying"
+	if plan.Atomic {
+		kind = "Applying atomic"
+	}
+	fmt.Fprintf(w, "%s plan, %d migrations in order:\n", kind, len(plan.Migrations))
+	for i := range plan.Migrations {
+		migration := &plan.Migrations[i]
+		fmt.Fprintf(w, "  %d. VM %s (%d) from %s to %s\n", i+1, migration.VM.Name, migration.VM.ID, migration.FromNode, migration.ToNode)
+	}
+
+	results := applier.ExecutePlan(plan)
+	failed := 0
+	for i := range results {
+		printBalancingResult(w, &results[i])
+		if !results[i].Success && !results[i].DryRun {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d migrations failed", failed, len(results))
+	}
+	return nil
+}
+
+// resolvePlan turns the steps of a plan file into migrations of the cluster's VMs. Each step moves
+// the VM from where the previous steps left it, so a VM may move more than once, but only once in
+// an atomic plan so that its rollback is unambiguous.
+func resolvePlan(nodes []models.Node, file *PlanFile, now time.Time) (*models.MigrationPlan, error) {
+	online := make(map[string]bool, len(nodes))
+	vms := make(map[int]models.VM)
+	placement := make(map[int]string)
+	for i := range nodes {
+		online[nodes[i].Name] = nodes[i].Status == "online"
+		for _, vm := range nodes[i].VMs {
+			vms[vm.ID] = vm
+			placement[vm.ID] = nodes[i].Name
+		}
+	}
+
+	plan := &models.MigrationPlan{Atomic: file.Atomic}
+	moved := make(map[int]bool, len(file.Migrations))
+	for i, step := range file.Migrations {
+		vm, ok := vms[step.VMID]
+		if !ok {
+			return nil, fmt.Errorf("plan step %d: VM %d not found", i+1, step.VMID)
+		}
+		if file.Atomic && moved[vm.ID] {
+			return nil, fmt.Errorf("plan step %d: VM %d moves more than once in an atomic plan", i+1, vm.ID)
+		}
+		isOnline, ok := online[step.Node]
+		if !ok {
+			return nil, fmt.Errorf("plan step %d: node %s not found", i+1, step.Node)
+		}
+		if !isOnline {
+			return nil, fmt.Errorf("plan step %d: node %s is not online", i+1, step.Node)
+		}
+		if placement[vm.ID] == step.Node {
+			return nil, fmt.Errorf("plan step %d: VM %d is already on %s", i+1, vm.ID, step.Node)
+		}
+
+		plan.Migrations = append(plan.Migrations, models.Migration{
+			VM:        vm,
+			FromNode:  placement[vm.ID],
+			ToNode:    step.Node,
+			Status:    "pending",
+			StartTime: now,
+		})
+		placement[vm.ID] = step.Node
+		moved[vm.ID] = true
+	}
+	return plan, nil
+}
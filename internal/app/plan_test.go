@@ -0,0 +1,193 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/balancer"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// writePlanFile writes a plan file in a temporary directory and returns its path.
+func writePlanFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write plan file: %v", err)
+	}
+	return path
+}
+
+func TestReadPlanFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr bool
+	}{
+		{"yaml", "swap.yaml", "atomic: true\nmigrations:\n  - vmid: 100\n    node: node2\n  - vmid: 102\n    node: node1\n", false},
+		{"json", "swap.json", `{"atomic": true, "migrations": [{"vmid": 100, "node": "node2"}, {"vmid": 102, "node": "node1"}]}`, false},
+		{"unknown field", "swap.yaml", "atomic: true\nmigrations:\n  - vmid: 100\n    target: node2\n", true},
+		{"no migrations", "empty.json", `{"atomic": true}`, true},
+		{"malformed", "swap.json", `{"migrations": [`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := readPlanFile(writePlanFile(t, tt.file, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readPlanFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !plan.Atomic || len(plan.Migrations) != 2 || plan.Migrations[0] != (PlanStep{VMID: 100, Node: "node2"}) {
+				t.Errorf("Expected an atomic swap of VMs 100 and 102, got %+v", plan)
+			}
+		})
+	}
+}
+
+func TestResolvePlan(t *testing.T) {
+	nodes := createTestNodes()
+
+	// A VM moving twice leaves from where the previous step put it
+	twice := &PlanFile{Migrations: []PlanStep{
+		{VMID: 100, Node: "node2"},
+		{VMID: 100, Node: "node1"},
+	}}
+	plan, err := resolvePlan(nodes, twice, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(plan.Migrations) != 2 {
+		t.Fatalf("Expected a plan with 2 migrations, got %+v", plan)
+	}
+	if plan.Migrations[1].FromNode != "node2" || plan.Migrations[1].ToNode != "node1" {
+		t.Errorf("Expected the second move from node2 to node1, got %s to %s", plan.Migrations[1].FromNode, plan.Migrations[1].ToNode)
+	}
+
+	// An atomic plan moves each VM once
+	twice.Atomic = true
+	if _, err := resolvePlan(nodes, twice, time.Now()); err == nil {
+		t.Error("Expected an error for a VM moving twice in an atomic plan")
+	}
+
+	nodes[1].Status = "offline"
+	invalid := map[string]PlanStep{
+		"unknown VM":   {VMID: 999, Node: "node2"},
+		"unknown node": {VMID: 100, Node: "node9"},
+		"offline node": {VMID: 100, Node: "node2"},
+		"same node":    {VMID: 100, Node: "node1"},
+	}
+	for name, step := range invalid {
+		if _, err := resolvePlan(nodes, &PlanFile{Migrations: []PlanStep{step}}, time.Now()); err == nil {
+			t.Errorf("%s: expected an error for %+v", name, step)
+		}
+	}
+}
+
+// untaggedTestNodes returns the test nodes without the affinity of their VMs, which a swap would break.
+func untaggedTestNodes() []models.Node {
+	nodes := createTestNodes()
+	for i := range nodes {
+		for j := range nodes[i].VMs {
+			nodes[i].VMs[j].Tags = nil
+		}
+	}
+	return nodes
+}
+
+func TestApplyAtomicPlanRollsBack(t *testing.T) {
+	cfg := createTestConfig()
+	client := &mockClient{
+		nodes:           untaggedTestNodes(),
+		migrationErrors: map[int]error{102: errors.New("migration refused")},
+	}
+	app := &App{config: cfg, client: client, balancer: balancer.NewBalancer(client, cfg)}
+
+	plan, err := readPlanFile(writePlanFile(t, "swap.yaml", "atomic: true\nmigrations:\n  - vmid: 100\n    node: node2\n  - vmid: 102\n    node: node1\n"))
+	if err != nil {
+		t.Fatalf("Failed to read plan: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := app.applyPlan(&buf, plan); err == nil {
+		t.Error("Expected an error for the failed migration")
+	}
+
+	output := buf.String()
+	for _, line := range []string{
+		"Applying atomic plan, 2 migrations in order:",
+		"1. VM test-vm-1 (100) from node1 to node2",
+		"2. VM test-vm-3 (102) from node2 to node1",
+		"Migrated VM test-vm-1 (100) from node1 to node2",
+		"Failed to migrate VM test-vm-3 (102): migration refused",
+		"Migrated VM test-vm-1 (100) from node2 to node1",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestApplyPlanDryRun(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+	client := &mockClient{nodes: createTestNodes()}
+	app := &App{config: cfg, client: client, balancer: balancer.NewBalancer(client, cfg)}
+
+	var buf bytes.Buffer
+	if err := app.applyPlan(&buf, &PlanFile{Atomic: true, Migrations: []PlanStep{{VMID: 100, Node: "node2"}}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "Would migrate VM test-vm-1 (100) from node1 to node2") {
+		t.Errorf("Expected the migration planned only, got:\n%s", buf.String())
+	}
+}
+
+func TestApplyPlanRejectsInvalidSteps(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(app *App)
+		steps []PlanStep
+	}{
+		// VM 100 leaves node1 for node2, away from VM 102 which joined node1 first
+		{"breaks affinity", nil, []PlanStep{{VMID: 102, Node: "node1"}, {VMID: 100, Node: "node2"}}},
+		{"maintenance target", func(app *App) {
+			app.config.Cluster.MaintenanceNodes = []string{"node2"}
+		}, []PlanStep{{VMID: 100, Node: "node2"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			client := &mockClient{nodes: createTestNodes()}
+			app := &App{config: cfg, client: client, balancer: balancer.NewBalancer(client, cfg)}
+			if tt.setup != nil {
+				tt.setup(app)
+			}
+
+			var buf bytes.Buffer
+			if err := app.applyPlan(&buf, &PlanFile{Migrations: tt.steps}); err == nil {
+				t.Error("Expected an error for an invalid plan")
+			}
+			if buf.Len() > 0 {
+				t.Errorf("Expected nothing applied, got:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestApplyPlanUnsupportedBalancer(t *testing.T) {
+	app := &App{balancer: &mockBalancer{}}
+	var buf bytes.Buffer
+	if err := app.applyPlan(&buf, &PlanFile{Migrations: []PlanStep{{VMID: 100, Node: "node2"}}}); err == nil {
+		t.Error("Expected an error for a balancer that can't execute plans")
+	}
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+const (
+	// nearThresholdMargin is how close to a threshold, in percentage points, node usage is worth a look.
+	nearThresholdMargin = 10.0
+
+	// summaryMovers is the number of most migrated VMs in the summary.
+	summaryMovers = 5
+)
+
+// thresholdWarning is a node resource close to or above its balancing threshold.
+type thresholdWarning struct {
+	Node      string  `json:"node"`
+	Resource  string  `json:"resource"`
+	Usage     float64 `json:"usage"`
+	Threshold int     `json:"threshold"`
+	Over      bool    `json:"over"`
+}
+
+// mover is a VM migrated recently, with its number of migrations.
+type mover struct {
+	VMID       int       `json:"vmid"`
+	Name       string    `json:"name,omitempty"`
+	Migrations int       `json:"migrations"`
+	LastMove   time.Time `json:"last_move"`
+	LastTarget string    `json:"last_target"`
+}
+
+// clusterSummary is the at-a-glance report of the summary command.
+type clusterSummary struct {
+	Cluster       string                   `json:"cluster"`
+	BalanceScore  float64                  `json:"balance_score"`
+	NearThreshold []thresholdWarning       `json:"near_threshold"`
+	TopMovers     []mover                  `json:"top_movers"`
+	Unschedulable []models.UnschedulableVM `json:"unschedulable"`
+	NextRun       *time.Time               `json:"next_run,omitempty"`
+}
+
+// ShowSummary shows a one-screen summary of the cluster balance, as text or JSON.
+func ShowSummary(configPath, output string) error {
+	app, err := initializeApp(configPath)
+	if err != nil {
+		return err
+	}
+	defer app.cancel()
+
+	return app.showSummary(os.Stdout, output)
+}
+
+// showSummary gathers the balance score, nodes near their thresholds, recent movers, unschedulable
+// VMs and next run, and writes them as text or JSON.
+func (app *App) showSummary(w io.Writer, output string) error {
+	if output != "" && output != outputText && output != outputJSON {
+		return fmt.Errorf("invalid output format: %s (must be '%s' or '%s')", output, outputText, outputJSON)
+	}
+
+	status, err := app.balancer.GetClusterStatus()
+	if err != nil {
+		return fmt.Errorf("failed to get cluster status: %w", err)
+	}
+	nodes, err := app.client.GetNodes()
+	if err != nil {
+		return fmt.Errorf("failed to get nodes: %w", err)
+	}
+	engine, _, err := evaluateRules(app.config, nodes)
+	if err != nil {
+		return err
+	}
+
+	// Only the advanced balancer keeps a migration history
+	var history []models.MigrationHistory
+	if reporter, ok := app.balancer.(MigrationHistoryReporter); ok {
+		history = reporter.GetMigrationHistory()
+	}
+
+	summary := clusterSummary{
+		Cluster:       status.Cluster,
+		BalanceScore:  status.BalanceScore,
+		NearThreshold: nearThreshold(app.config.Balancing.Thresholds, nodes),
+		TopMovers:     topMovers(history, nodes, summaryMovers),
+		Unschedulable: findUnschedulableVMs(app.config, engine, nodes),
+	}
+	if !status.NextRun.IsZero() {
+		summary.NextRun = &status.NextRun
+	}
+
+	if output == outputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(summary); err != nil {
+			return fmt.Errorf("failed to encode summary: %w", err)
+		}
+		return nil
+	}
+
+	printSummary(w, &summary)
+	return nil
+}
+
+// printSummary writes the summary as text, one section per line group.
+func printSummary(w io.Writer, summary *clusterSummary) {
+	fmt.Fprintf(w, "=== %s Summary ===\n", summary.Cluster)
+	fmt.Fprintf(w, "Balance Score: %s\n", colorize(w, balanceScoreColor(summary.BalanceScore), fmt.Sprintf("%.0f/100", summary.BalanceScore)))
+	if summary.NextRun != nil {
+		fmt.Fprintf(w, "Next Run: %v\n", *summary.NextRun)
+	} else {
+		fmt.Fprintln(w, "Next Run: not scheduled in this process")
+	}
+
+	fmt.Fprintln(w, "\nNodes Near Threshold:")
+	if len(summary.NearThreshold) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, warning := range summary.NearThreshold {
+		line := fmt.Sprintf("  %s %s %.1f%% (threshold %d%%)", warning.Node, warning.Resource, warning.Usage, warning.Threshold)
+		if warning.Over {
+			line = colorize(w, colorRed, line+" over")
+		} else {
+			line = colorize(w, colorYellow, line)
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintln(w, "\nTop Movers (24h):")
+	if len(summary.TopMovers) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, m := range summary.TopMovers {
+		fmt.Fprintf(w, "  %s (%d): %d migrations, last to %s at %s\n",
+			m.Name, m.VMID, m.Migrations, m.LastTarget, m.LastMove.Format("15:04"))
+	}
+
+	fmt.Fprintln(w, "\nUnschedulable VMs:")
+	if len(summary.Unschedulable) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for i := range summary.Unschedulable {
+		vm := &summary.Unschedulable[i]
+		fmt.Fprintf(w, "  %s (%d) on %s: %s\n", vm.Name, vm.VMID, vm.Node, vm.Reason)
+	}
+}
+
+// nearThreshold lists the node resources within nearThresholdMargin points of their threshold or above it,
+// in node order.
+func nearThreshold(thresholds config.ResourceThresholds, nodes []models.Node) []thresholdWarning {
+	var warnings []thresholdWarning
+	for i := range nodes {
+		node := &nodes[i]
+		resources := []struct {
+			name      string
+			usage     float32
+			threshold int
+		}{
+			{"cpu", node.CPU.Usage, thresholds.CPU},
+			{"memory", node.Memory.Usage, thresholds.Memory},
+			{"storage", node.Storage.Usage, thresholds.Storage},
+		}
+		for _, resource := range resources {
+			if resource.threshold <= 0 || float64(resource.usage) < float64(resource.threshold)-nearThresholdMargin {
+				continue
+			}
+			warnings = append(warnings, thresholdWarning{
+				Node:      node.Name,
+				Resource:  resource.name,
+				Usage:     float64(resource.usage),
+				Threshold: resource.threshold,
+				Over:      float64(resource.usage) > float64(resource.threshold),
+			})
+		}
+	}
+	return warnings
+}
+
+// topMovers returns up to limit VMs migrated most often in the history, latest first among equals,
+// named after the current inventory.
+func topMovers(history []models.MigrationHistory, nodes []models.Node, limit int) []mover {
+	names := make(map[int]string)
+	for i := range nodes {
+		for j := range nodes[i].VMs {
+			names[nodes[i].VMs[j].ID] = nodes[i].VMs[j].Name
+		}
+	}
+
+	byVM := make(map[int]*mover)
+	for _, migration := range history {
+		m, ok := byVM[migration.VMID]
+		if !ok {
+			m = &mover{VMID: migration.VMID, Name: names[migration.VMID]}
+			byVM[migration.VMID] = m
+		}
+		m.Migrations++
+		if migration.Timestamp.After(m.LastMove) {
+			m.LastMove = migration.Timestamp
+			m.LastTarget = migration.ToNode
+		}
+	}
+
+	movers := make([]mover, 0, len(byVM))
+	for _, m := range byVM {
+		movers = append(movers, *m)
+	}
+	sort.Slice(movers, func(i, j int) bool {
+		if movers[i].Migrations != movers[j].Migrations {
+			return movers[i].Migrations > movers[j].Migrations
+		}
+		if !movers[i].LastMove.Equal(movers[j].LastMove) {
+			return movers[i].LastMove.After(movers[j].LastMove)
+		}
+		return movers[i].VMID < movers[j].VMID
+	})
+	if len(movers) > limit {
+		movers = movers[:limit]
+	}
+	return movers
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// historyBalancer is a mock balancer that remembers its recent migrations.
+type historyBalancer struct {
+	mockBalancer
+	history []models.MigrationHistory
+}
+
+func (m *historyBalancer) GetMigrationHistory() []models.MigrationHistory {
+	return m.history
+}
+
+// newSummaryApp returns an app over the test nodes, with VM 100 pinned to the overloaded node1
+// and a history where VM 102 moved twice and VM 100 once.
+func newSummaryApp(t *testing.T) *App {
+	t.Helper()
+
+	nodes := createTestNodes()
+	nodes[0].VMs[0].Tags = []string{"plb_pin_node1"}
+	now := time.Now()
+	balancer := &historyBalancer{
+		mockBalancer: mockBalancer{status: &models.ClusterStatus{
+			Cluster:      "test-cluster",
+			BalanceScore: 72,
+			NextRun:      now.Add(5 * time.Minute),
+		}},
+		history: []models.MigrationHistory{
+			{VMID: 102, FromNode: "node1", ToNode: "node2", Timestamp: now.Add(-3 * time.Hour)},
+			{VMID: 100, FromNode: "node2", ToNode: "node1", Timestamp: now.Add(-2 * time.Hour)},
+			{VMID: 102, FromNode: "node2", ToNode: "node1", Timestamp: now.Add(-90 * time.Minute)},
+		},
+	}
+
+	app, err := NewAppWithDependencies("test-config.yaml", &mockConfigLoader{config: createTestConfig()}, &mockClient{nodes: nodes}, balancer)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return app
+}
+
+func TestShowSummaryText(t *testing.T) {
+	app := newSummaryApp(t)
+
+	var out bytes.Buffer
+	if err := app.showSummary(&out, outputText); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, want := range []string{
+		"=== test-cluster Summary ===",
+		"Balance Score: 72/100",
+		"Next Run: ",
+		"Nodes Near Threshold:",
+		"node1 cpu 85.0% (threshold 80%) over",
+		"node1 memory 75.0% (threshold 85%)",
+		"Top Movers (24h):",
+		"test-vm-3 (102): 2 migrations, last to node1",
+		"test-vm-1 (100): 1 migrations, last to node1",
+		"Unschedulable VMs:",
+		"test-vm-1 (100) on node1: node over threshold",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "node2 ") {
+		t.Errorf("Expected node2 to be well below its thresholds, got:\n%s", out.String())
+	}
+	if strings.Index(out.String(), "test-vm-3 (102)") > strings.Index(out.String(), "test-vm-1 (100): 1") {
+		t.Errorf("Expected the VM moved most first, got:\n%s", out.String())
+	}
+}
+
+func TestShowSummaryJSON(t *testing.T) {
+	app := newSummaryApp(t)
+
+	var out bytes.Buffer
+	if err := app.showSummary(&out, outputJSON); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var summary map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, out.String())
+	}
+	for _, section := range []string{"cluster", "balance_score", "near_threshold", "top_movers", "unschedulable", "next_run"} {
+		if _, ok := summary[section]; !ok {
+			t.Errorf("Expected section %q in the JSON summary, got %v", section, summary)
+		}
+	}
+	if movers := summary["top_movers"].([]interface{}); len(movers) != 2 {
+		t.Errorf("Expected 2 movers, got %v", movers)
+	}
+}
+
+func TestShowSummaryWithoutHistory(t *testing.T) {
+	// The threshold balancer keeps no history and no cycle ran yet
+	balancer := &mockBalancer{status: &models.ClusterStatus{Cluster: "test-cluster", BalanceScore: 95}}
+	app, err := NewAppWithDependencies("test-config.yaml", &mockConfigLoader{config: createTestConfig()}, &mockClient{nodes: createTestNodes()}, balancer)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := app.showSummary(&out, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{"Next Run: not scheduled", "Top Movers (24h):\n  none", "Unschedulable VMs:\n  none"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	if err := app.showSummary(&out, "yaml"); err == nil {
+		t.Error("Expected an error for an unsupported output format")
+	}
+}
+
+func TestTopMoversLimit(t *testing.T) {
+	now := time.Now()
+	var history []models.MigrationHistory
+	for vmid := 100; vmid < 110; vmid++ {
+		history = append(history, models.MigrationHistory{VMID: vmid, ToNode: "node2", Timestamp: now.Add(time.Duration(vmid) * time.Minute)})
+	}
+
+	movers := topMovers(history, nil, 3)
+	if len(movers) != 3 {
+		t.Fatalf("Expected 3 movers, got %d", len(movers))
+	}
+	// With one migration each, the latest come first
+	for i, want := range []int{109, 108, 107} {
+		if movers[i].VMID != want {
+			t.Errorf("Expected VM %d at %d, got %d", want, i, movers[i].VMID)
+		}
+	}
+}
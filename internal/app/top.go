@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// Sort orders for the top view.
+const (
+	topSortCPU    = "cpu"
+	topSortMemory = "memory"
+)
+
+// clearScreen moves the cursor home and clears the terminal before each refresh.
+const clearScreen = "\033[H\033[2J"
+
+// TopOptions holds the command-line options for the top view.
+type TopOptions struct {
+	SortBy   string        // "cpu" (default) or "memory"
+	Limit    int           // Number of VMs shown, 0 shows all
+	Interval time.Duration // Delay between refreshes
+	Count    int           // Number of frames to show, 0 refreshes until interrupted
+}
+
+// ShowTop shows the busiest VMs across the cluster, refreshing periodically.
+func ShowTop(configPath string, opts TopOptions) error {
+	app, err := initializeApp(configPath)
+	if err != nil {
+		return err
+	}
+	defer app.cancel()
+
+	return app.runTop(os.Stdout, opts)
+}
+
+// runTop renders frames of the top view until Count frames were shown or the app is stopped.
+func (app *App) runTop(w io.Writer, opts TopOptions) error {
+	if opts.SortBy == "" {
+		opts.SortBy = topSortCPU
+	}
+	if opts.SortBy != topSortCPU && opts.SortBy != topSortMemory {
+		return fmt.Errorf("invalid sort order %q: must be %q or %q", opts.SortBy, topSortCPU, topSortMemory)
+	}
+	if opts.Interval <= 0 {
+		return fmt.Errorf("refresh interval must be positive, got %v", opts.Interval)
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for frame := 1; ; frame++ {
+		nodes, err := app.client.GetNodes()
+		if err != nil {
+			return fmt.Errorf("failed to get nodes: %w", err)
+		}
+
+		fmt.Fprint(w, clearScreen)
+		fmt.Fprint(w, renderTop(nodes, opts, time.Now()))
+
+		if opts.Count > 0 && frame >= opts.Count {
+			return nil
+		}
+
+		select {
+		case <-app.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// renderTop renders one frame of the top view: running VMs sorted by CPU or memory, busiest first.
+// CPU is the percentage of the VM's own vCPUs.
+func renderTop(nodes []models.Node, opts TopOptions, now time.Time) string {
+	var vms []models.VM
+	for i := range nodes {
+		for j := range nodes[i].VMs {
+			vm := nodes[i].VMs[j]
+			if vm.Status != vmStatusRunning {
+				continue
+			}
+			if vm.Node == "" {
+				vm.Node = nodes[i].Name
+			}
+			vms = append(vms, vm)
+		}
+	}
+
+	sort.SliceStable(vms, func(i, j int) bool {
+		if opts.SortBy == topSortMemory {
+			return vms[i].Memory > vms[j].Memory
+		}
+		return vms[i].CPU > vms[j].CPU
+	})
+
+	shown := len(vms)
+	if opts.Limit > 0 && shown > opts.Limit {
+		shown = opts.Limit
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== GoProxLB Top (%s, by %s) ===\n", now.Format("15:04:05"), opts.SortBy)
+	fmt.Fprintf(&b, "Running VMs: %d, showing %d\n\n", len(vms), shown)
+	fmt.Fprintf(&b, "%-8s %-24s %-12s %-5s %7s %10s\n", "VMID", "NAME", "NODE", "TYPE", "CPU", "MEMORY")
+	for i := range vms[:shown] {
+		vm := &vms[i]
+		fmt.Fprintf(&b, "%-8d %-24s %-12s %-5s %6.1f%% %7.1f GB\n",
+			vm.ID, vm.Name, vm.Node, vm.Type, vm.CPU*100, float64(vm.Memory)/1024/1024/1024)
+	}
+	return b.String()
+}
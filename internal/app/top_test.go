@@ -0,0 +1,141 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// createTopTestNodes creates nodes whose busiest VM by CPU differs from the busiest by memory.
+func createTopTestNodes() []models.Node {
+	const gib = 1024 * 1024 * 1024
+	return []models.Node{
+		{
+			Name: "node1",
+			VMs: []models.VM{
+				{ID: 100, Name: "web-1", Node: "node1", Type: "qemu", Status: "running", CPU: 0.85, Memory: 2 * gib},
+				{ID: 101, Name: "backup", Node: "node1", Type: "qemu", Status: "stopped", CPU: 0, Memory: 32 * gib},
+			},
+		},
+		{
+			Name: "node2",
+			VMs: []models.VM{
+				{ID: 200, Name: "db-1", Node: "node2", Type: "qemu", Status: "running", CPU: 0.40, Memory: 16 * gib},
+				{ID: 201, Name: "dns", Node: "node2", Type: "lxc", Status: "running", CPU: 0.05, Memory: 1 * gib},
+			},
+		},
+	}
+}
+
+// topVMIDs returns the VM IDs of a rendered frame's rows, in order.
+func topVMIDs(frame string) []string {
+	var ids []string
+	lines := strings.Split(frame, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "VMID") {
+			for _, row := range lines[i+1:] {
+				if fields := strings.Fields(row); len(fields) > 0 {
+					ids = append(ids, fields[0])
+				}
+			}
+			break
+		}
+	}
+	return ids
+}
+
+func TestRenderTop(t *testing.T) {
+	now := time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		opts     TopOptions
+		expected []string
+	}{
+		{"by cpu", TopOptions{SortBy: topSortCPU}, []string{"100", "200", "201"}},
+		{"by memory", TopOptions{SortBy: topSortMemory}, []string{"200", "100", "201"}},
+		{"limited", TopOptions{SortBy: topSortCPU, Limit: 2}, []string{"100", "200"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := renderTop(createTopTestNodes(), tt.opts, now)
+
+			if !strings.HasPrefix(frame, "=== GoProxLB Top (09:30:00, by "+tt.opts.SortBy+") ===\n") {
+				t.Errorf("Expected a header with time and sort order, got:\n%s", frame)
+			}
+			if got := topVMIDs(frame); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected VMs %v, got %v:\n%s", tt.expected, got, frame)
+			}
+		})
+	}
+
+	frame := renderTop(createTopTestNodes(), TopOptions{SortBy: topSortCPU}, now)
+	if !strings.Contains(frame, "web-1") || !strings.Contains(frame, "85.0%") || !strings.Contains(frame, "2.0 GB") {
+		t.Errorf("Expected VM 100 row with CPU and memory, got:\n%s", frame)
+	}
+	if strings.Contains(frame, "backup") {
+		t.Errorf("Expected stopped VMs to be hidden, got:\n%s", frame)
+	}
+}
+
+func TestRunTopRefreshes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	app := &App{client: &mockClient{nodes: createTopTestNodes()}, ctx: ctx, cancel: cancel}
+
+	var out bytes.Buffer
+	if err := app.runTop(&out, TopOptions{Interval: time.Millisecond, Count: 2}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	frames := strings.Split(out.String(), clearScreen)[1:]
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %d:\n%s", len(frames), out.String())
+	}
+	for _, frame := range frames {
+		if got := topVMIDs(frame); len(got) != 3 || got[0] != "100" {
+			t.Errorf("Expected every frame sorted by CPU by default, got %v", got)
+		}
+	}
+}
+
+func TestRunTopStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	app := &App{client: &mockClient{nodes: createTopTestNodes()}, ctx: ctx, cancel: cancel}
+	cancel()
+
+	var out bytes.Buffer
+	if err := app.runTop(&out, TopOptions{Interval: time.Hour}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if frames := strings.Count(out.String(), clearScreen); frames != 1 {
+		t.Errorf("Expected a single frame before stopping, got %d", frames)
+	}
+}
+
+func TestRunTopInvalidOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	app := &App{client: &mockClient{nodes: createTopTestNodes()}, ctx: ctx, cancel: cancel}
+
+	tests := []struct {
+		name string
+		opts TopOptions
+	}{
+		{"unknown sort", TopOptions{SortBy: "disk", Interval: time.Second}},
+		{"no interval", TopOptions{SortBy: topSortCPU}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := app.runTop(&bytes.Buffer{}, tt.opts); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
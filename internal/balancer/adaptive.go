@@ -0,0 +1,74 @@
+package balancer
+
+import (
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+const (
+	// adaptiveWindow is how far back migrations count toward churn: the migration history kept.
+	adaptiveWindow = 24 * time.Hour
+
+	// adaptiveStableDensity is the migrations per node over the window up to which the cluster is
+	// stable enough for high aggressiveness.
+	adaptiveStableDensity = 0.5
+
+	// adaptiveChurnDensity is the migrations per node over the window from which the cluster is
+	// churning and gets low aggressiveness.
+	adaptiveChurnDensity = 2.0
+)
+
+// migrationDensity returns the migrations per node within the adaptive window.
+func migrationDensity(history []models.MigrationHistory, nodeCount int, now time.Time) float64 {
+	if nodeCount == 0 {
+		return 0
+	}
+
+	since := now.Add(-adaptiveWindow)
+	recent := 0
+	for i := range history {
+		if history[i].Timestamp.After(since) {
+			recent++
+		}
+	}
+	return float64(recent) / float64(nodeCount)
+}
+
+// adaptiveLevel picks the aggressiveness level for a migration density: high when the cluster has
+// been stable, low during churn, medium in between.
+func adaptiveLevel(density float64) string {
+	switch {
+	case density <= adaptiveStableDensity:
+		return "high"
+	case density >= adaptiveChurnDensity:
+		return "low"
+	default:
+		return "medium"
+	}
+}
+
+// adaptAggressiveness picks the aggressiveness level from the recent migrations with adaptive
+// aggressiveness, logging when it changes.
+func (b *AdvancedBalancer) adaptAggressiveness(nodes []models.Node, now time.Time) {
+	if !b.config.IsAdaptiveAggressiveness() {
+		return
+	}
+
+	density := migrationDensity(b.migrationHistory, len(nodes), now)
+	level := adaptiveLevel(density)
+	if level != b.adaptiveLevel {
+		logf("Adaptive aggressiveness: %s (%.1f migrations per node in the last %v)\n", level, density, adaptiveWindow)
+	}
+	b.adaptiveLevel = level
+}
+
+// aggressivenessConfig returns the effective aggressiveness settings: the configured level, or the
+// level adapted to recent migrations with adaptive aggressiveness.
+func (b *AdvancedBalancer) aggressivenessConfig() config.AggressivenessConfig {
+	if b.config.IsAdaptiveAggressiveness() {
+		return config.AggressivenessLevelConfig(b.adaptiveLevel)
+	}
+	return b.config.GetAggressivenessConfig()
+}
@@ -1,22 +1,32 @@
 package balancer
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cblomart/GoProxLB/internal/config"
 	"github.com/cblomart/GoProxLB/internal/models"
 	"github.com/cblomart/GoProxLB/internal/proxmox"
 	"github.com/cblomart/GoProxLB/internal/rules"
+	"github.com/cblomart/GoProxLB/internal/telemetry"
 )
 
 const (
 	vmStatusRunning          = "running"
 	defaultTimeframe         = "day"
 	criticalityLevelCritical = "Critical"
+
+	// migrationCostPerGiB is the churn cost, in score points over an hour, of copying 1 GiB of VM memory.
+	migrationCostPerGiB = 0.5
+	// storageMigrationCostPerGiB is the extra churn cost of copying 1 GiB of local disk on a storage migration.
+	storageMigrationCostPerGiB = 0.25
 )
 
 // AdvancedBalancer represents the advanced load balancer with profiling and capacity planning.
@@ -25,30 +35,97 @@ type AdvancedBalancer struct {
 	config           *config.Config
 	engine           *rules.Engine
 	lastRun          time.Time
+	lastCycle        time.Time // Start of the last cycle, balancing or not
 	migrationHistory []models.MigrationHistory
 	loadProfiles     map[int]*models.LoadProfile
-	capacityMetrics  map[string]*models.CapacityMetrics
+	capacityMetrics  map[string]*models.CapacityMetrics // CPU percentiles per node
+	memoryMetrics    map[string]*models.CapacityMetrics // Memory percentiles per node
+	pairStats        map[string]*models.MigrationPairStats
+	historyPath      string
+	historyLocked    bool // This process holds the data directory lock, so it may save history
+	powerSource      telemetry.PowerSource
+	nodePower        map[string]models.NodePower
+	unschedulable    *unschedulableTracker
+	skipped          *skipLog
+	observations     nodeObservations
+	periodLoads      map[int]periodLoad // Business/off-hours CPU per VM
+	phases           *phaseLog
+	capacityAlarm    *capacityAlarm
+	sla              *slaAlarm
+	overloads        *overloadState
+	nodeCount        *nodeCountGuard
+	breakIn          *breakIn
+	adaptiveLevel    string // Aggressiveness level picked from recent migrations, with adaptive aggressiveness
 }
 
 // NewAdvancedBalancer creates a new advanced load balancer.
 func NewAdvancedBalancer(client proxmox.ClientInterface, cfg *config.Config) *AdvancedBalancer {
-	return &AdvancedBalancer{
+	b := &AdvancedBalancer{
 		client:           client,
 		config:           cfg,
-		engine:           rules.NewEngine(),
+		engine:           newRulesEngine(cfg),
 		migrationHistory: make([]models.MigrationHistory, 0),
 		loadProfiles:     make(map[int]*models.LoadProfile),
 		capacityMetrics:  make(map[string]*models.CapacityMetrics),
+		memoryMetrics:    make(map[string]*models.CapacityMetrics),
+		pairStats:        make(map[string]*models.MigrationPairStats),
+		unschedulable:    newUnschedulableTracker(),
+		skipped:          &skipLog{},
+		observations:     make(nodeObservations),
+		periodLoads:      make(map[int]periodLoad),
+		phases:           &phaseLog{},
+		capacityAlarm:    &capacityAlarm{},
+		sla:              &slaAlarm{},
+		overloads:        newOverloadState(),
+		nodeCount:        &nodeCountGuard{},
+		breakIn:          &breakIn{},
+	}
+
+	// Optional power/thermal telemetry
+	powerSource, err := telemetry.NewPowerSource(&cfg.Balancing.Power)
+	if err != nil {
+		logf("Warning: %v\n", err)
+	}
+	b.powerSource = powerSource
+
+	// Migration history survives restarts when a data directory is configured. Only the process
+	// holding the directory lock saves it, so concurrent instances can't clobber each other's
+	if cfg.Raft.DataDir != "" {
+		b.historyPath = filepath.Join(cfg.Raft.DataDir, migrationHistoryFile)
+		b.historyLocked = lockHistoryDir(cfg.Raft.DataDir)
+		if err := b.loadMigrationHistory(); err != nil {
+			logf("Warning: %v\n", err)
+		}
 	}
+
+	return b
 }
 
 // Run executes the advanced load balancing algorithm.
 func (b *AdvancedBalancer) Run(force bool) ([]models.BalancingResult, error) {
+	b.lastCycle = time.Now()
+
+	// Balancing is disabled until the configured start date, even when forced
+	if balancingDeferred(b.config, time.Now()) {
+		return []models.BalancingResult{}, nil
+	}
+
+	deadline := cycleDeadline(b.config, time.Now())
+	timer := newPhaseTimer()
+	defer b.phases.record(timer, b.config.Logging.Level == "debug")
+
 	// Get current cluster state
 	nodes, err := b.client.GetNodes()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get nodes: %w", err)
 	}
+	applyKSMSavings(nodes)
+	timer.mark(phaseGetNodes)
+
+	// Nodes vanishing all at once are more likely an API glitch than reality: don't act on it
+	if b.nodeCount.suddenDrop(b.config, len(nodes)) {
+		return []models.BalancingResult{}, nil
+	}
 
 	// Filter available nodes
 	availableNodes := b.filterAvailableNodes(nodes)
@@ -56,47 +133,145 @@ func (b *AdvancedBalancer) Run(force bool) ([]models.BalancingResult, error) {
 		return nil, fmt.Errorf("insufficient available nodes for balancing")
 	}
 
+	// Process placement rules
+	var allVMs []models.VM
+	for i := range nodes {
+		allVMs = append(allVMs, nodes[i].VMs...)
+	}
+	b.engine.SetNodePCIMappings(nodes)
+	if err := b.engine.ProcessVMs(allVMs); err != nil {
+		return nil, fmt.Errorf("failed to process VM rules: %w", err)
+	}
+	logRuleConflicts(b.engine, availableNodes)
+	timer.mark(phaseRules)
+
 	// Update load profiles if enabled
 	if b.config.Balancing.LoadProfiles.Enabled {
 		b.updateLoadProfiles(availableNodes)
+		timer.mark(phaseLoadProfiles)
 	}
 
 	// Update capacity metrics if enabled
 	if b.config.Balancing.Capacity.Enabled {
 		b.updateCapacityMetrics(availableNodes)
+		timer.mark(phaseCapacityMetrics)
 	}
 
-	// Check if balancing is needed
-	if !force && !b.needsBalancing(availableNodes) {
+	// With every node above its thresholds no migration can help: alert instead of finding no target
+	if b.capacityAlarm.check(b.config, availableNodes) {
+		b.unschedulable.update(nil, time.Now())
+		b.skipped.set(nil)
 		return []models.BalancingResult{}, nil
 	}
 
-	// Get aggressiveness configuration
-	aggConfig := b.config.GetAggressivenessConfig()
+	// Nodes beyond the imbalance SLA need relief, even below their thresholds
+	breaches := b.sla.check(b.config, availableNodes)
 
-	// Check cooldown period
-	if !force && time.Since(b.lastRun) < aggConfig.CooldownPeriod {
+	// Check if balancing is needed; only an "always" force balances a balanced cluster, and never
+	// one within the tolerance band. New VMs that landed on the wrong node are placed regardless
+	always := forcedAlways(b.config, force)
+	landed := hasMisplacedNewVMs(b.config, b.engine, availableNodes)
+	if !landed && len(breaches) == 0 && (withinTolerance(b.config, availableNodes) || (!always && !b.needsBalancing(availableNodes))) {
+		b.unschedulable.update(nil, time.Now())
+		b.skipped.set(nil)
+		if force {
+			logf("Cluster already balanced, nothing to re-evaluate\n")
+		}
 		return []models.BalancingResult{}, nil
 	}
 
+	// Get aggressiveness configuration, adapted to recent migrations if so configured
+	b.adaptAggressiveness(availableNodes, time.Now())
+	aggConfig := b.aggressivenessConfig()
+
+	// Check cooldown period, unless a node is critically overloaded
+	panicking := b.logPanicMode(availableNodes)
+	cooldown := aggConfig.CooldownPeriod
+	if configured, ok := b.configuredCooldown(); ok {
+		cooldown = configured
+	}
+	if !force && !panicking && time.Since(b.lastRun) < cooldown {
+		return []models.BalancingResult{}, nil
+	}
+
+	// Refresh power telemetry before scoring
+	b.refreshPowerTelemetry()
+
 	// Calculate node scores with advanced scoring
-	nodeScores := b.calculateAdvancedNodeScores(availableNodes)
+	breakdowns := b.calculateScoreBreakdowns(availableNodes)
+	b.logDecisionMatrix(breakdowns, balanceScoreOf(availableNodes))
+	nodeScores := scoresFromBreakdowns(availableNodes, breakdowns)
+	timer.mark(phaseScoring)
 
 	// Find optimal migrations
-	migrations := b.findOptimalMigrations(availableNodes, nodeScores, aggConfig)
+	migrations := b.findOptimalMigrations(availableNodes, nodeScores, aggConfig, always)
+	estimateMigrationDurations(b.config, migrations)
+
+	// Keep only the migrations whose gain over the horizon outweighs their cost
+	horizon, _ := b.config.GetBenefitHorizon() //nolint:errcheck // validated at load time
+	if horizon > 0 {
+		plan := b.buildMigrationPlan(migrations, availableNodes, nodeScores, horizon)
+		logf("Migration plan over %v: gain %.1f, cost %.1f, net benefit %.1f (%d of %d migrations kept, ~%v of migration)\n",
+			horizon, plan.TotalGain, plan.TotalCost, plan.NetBenefit, len(plan.Migrations), len(migrations), plan.EstimatedDuration)
+		b.skipDroppedMigrations(migrations, plan.Migrations)
+		migrations = plan.Migrations
+	}
+
+	// A new deployment runs a single migration per cycle until its break-in ends
+	migrations, deferred := b.breakIn.limit(b.config, migrations, time.Now())
+	b.skipped.add(deferred...)
+	timer.mark(phasePlanning)
 
 	// Execute migrations
-	results := b.executeMigrations(migrations)
+	results := b.executeMigrations(migrations, deadline)
+	timer.mark(phaseExecution)
+	b.breakIn.completed(b.config, results, time.Now())
 
 	// Update migration history
 	b.updateMigrationHistory(results)
 
-	// Update last run time
-	b.lastRun = time.Now()
+	// Start the observation window on the nodes that just took part in a migration
+	window, _ := b.config.GetObservationWindow() //nolint:errcheck // validated at load time
+	b.observations.record(results, window, time.Now())
+
+	// Update last run time (observer cycles don't start a cooldown, nothing moved)
+	if !b.config.ReadOnly {
+		b.lastRun = time.Now()
+	}
 
 	return results, nil
 }
 
+// logDecisionMatrix emits every node's sub-scores for this cycle, to a file or at debug level.
+func (b *AdvancedBalancer) logDecisionMatrix(breakdowns []models.NodeScoreBreakdown, balanceScore float64) {
+	path := b.config.Logging.DecisionMatrix
+	if path == "" && b.config.Logging.Level != "debug" {
+		return
+	}
+
+	data, err := json.Marshal(models.DecisionMatrix{Timestamp: time.Now(), BalanceScore: balanceScore, Nodes: breakdowns})
+	if err != nil {
+		logf("Warning: failed to encode decision matrix: %v\n", err)
+		return
+	}
+
+	if path == "" {
+		logf("DEBUG: decision matrix: %s\n", data)
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //nolint:gosec // path comes from configuration
+	if err != nil {
+		logf("Warning: failed to open decision matrix file: %v\n", err)
+		return
+	}
+	defer file.Close() //nolint:errcheck // file cleanup, error not actionable
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		logf("Warning: failed to write decision matrix: %v\n", err)
+	}
+}
+
 // GetClusterStatus returns the advanced cluster status.
 func (b *AdvancedBalancer) GetClusterStatus() (*models.ClusterStatus, error) {
 	nodes, err := b.client.GetNodes()
@@ -134,6 +309,7 @@ func (b *AdvancedBalancer) GetClusterStatus() (*models.ClusterStatus, error) {
 	storageMetrics := b.calculatePercentiles(storageValues)
 
 	return &models.ClusterStatus{
+		Cluster:          b.config.Cluster.Name,
 		TotalNodes:       len(nodes),
 		ActiveNodes:      len(availableNodes),
 		TotalVMs:         totalVMs,
@@ -142,7 +318,12 @@ func (b *AdvancedBalancer) GetClusterStatus() (*models.ClusterStatus, error) {
 		AverageMemory:    memoryMetrics.Mean,
 		AverageStorage:   storageMetrics.Mean,
 		LastBalanced:     b.lastRun,
+		LastRun:          b.lastCycle,
+		NextRun:          nextRun(b.config, b.lastCycle),
 		BalancingEnabled: true, // Always enabled when running
+		BalanceScore:     clusterBalanceScore(cpuMetrics, memoryMetrics),
+		OverCapacity:     overCapacity(b.config, availableNodes),
+		SLABreaches:      slaBreaches(b.config, availableNodes),
 	}, nil
 }
 
@@ -154,6 +335,10 @@ func (b *AdvancedBalancer) updateLoadProfiles(nodes []models.Node) {
 			vm := &node.VMs[j]
 			if vm.Status == vmStatusRunning {
 				profile := b.analyzeLoadProfile(vm)
+				profile.Samples = b.profileSamples(vm, node.Name)
+				if b.config.Balancing.LoadProfiles.BusinessHours.Enabled() {
+					b.updateSeasonalProfile(vm, node.Name, profile)
+				}
 				b.loadProfiles[vm.ID] = profile
 			}
 		}
@@ -297,18 +482,15 @@ func (b *AdvancedBalancer) updateCapacityMetrics(nodes []models.Node) {
 		var cpuValues, memoryValues []float32
 		for _, metric := range historicalData {
 			cpuValues = append(cpuValues, float32(metric.CPU))
-			memoryValues = append(memoryValues, float32(metric.Memory))
+			memoryValues = append(memoryValues, memoryUsagePercent(metric.Memory, node.Memory.Total))
 		}
 
 		// Calculate percentiles from historical data
 		cpuMetrics := b.calculatePercentiles(cpuValues)
-		// Memory percentiles calculated for future use
-		memoryPercentiles := b.calculatePercentiles(memoryValues)
-		// Store memory percentiles for potential future use
-		// Currently not used in scoring but available for analysis
-		_ = memoryPercentiles
+		memoryMetrics := b.calculatePercentiles(memoryValues)
+		b.memoryMetrics[node.Name] = &memoryMetrics
 
-		// Store metrics (currently using CPU metrics as primary)
+		// Store metrics (CPU metrics drive scoring)
 		b.capacityMetrics[node.Name] = &models.CapacityMetrics{
 			P50:    cpuMetrics.P50,
 			P90:    cpuMetrics.P90,
@@ -324,13 +506,14 @@ func (b *AdvancedBalancer) updateCapacityMetrics(nodes []models.Node) {
 
 // updateCapacityMetricsSimplified provides simplified capacity metrics when historical data is not available.
 func (b *AdvancedBalancer) updateCapacityMetricsSimplified(node *models.Node) {
-	var cpuValues []float32
-
 	// Use current data as fallback
-	cpuValues = append(cpuValues, node.CPU.Usage)
+	cpuValues := []float32{node.CPU.Usage}
+	memoryValues := []float32{node.Memory.Usage}
 
 	// Calculate percentiles (simplified - in reality, you'd store historical data)
 	cpuMetrics := b.calculatePercentiles(cpuValues)
+	memoryMetrics := b.calculatePercentiles(memoryValues)
+	b.memoryMetrics[node.Name] = &memoryMetrics
 
 	// Store metrics
 	b.capacityMetrics[node.Name] = &models.CapacityMetrics{
@@ -345,8 +528,53 @@ func (b *AdvancedBalancer) updateCapacityMetricsSimplified(node *models.Node) {
 	}
 }
 
-// calculatePercentiles calculates percentile metrics (optimized for performance).
+// memoryUsagePercent converts a historical memory sample in bytes to a percentage of the node's memory.
+func memoryUsagePercent(usedBytes float64, totalBytes int64) float32 {
+	if totalBytes <= 0 {
+		return 0
+	}
+	return float32(usedBytes / float64(totalBytes) * 100)
+}
+
+// calculatePercentiles calculates percentile metrics (optimized for performance), interpolated
+// between ranks when configured.
 func (b *AdvancedBalancer) calculatePercentiles(values []float32) models.CapacityMetrics {
+	if b.config.Balancing.Capacity.Percentiles == config.PercentilesInterpolate {
+		return calculateInterpolatedPercentiles(values)
+	}
+	return calculatePercentiles(values)
+}
+
+// calculateInterpolatedPercentiles calculates percentile metrics like calculatePercentiles, the
+// percentiles interpolated linearly between the two closest ranks (type 7 quantiles) rather
+// than rounded to the nearest: small samples get accurate percentiles.
+func calculateInterpolatedPercentiles(values []float32) models.CapacityMetrics {
+	metrics := calculatePercentiles(values)
+	if len(values) == 0 {
+		return metrics
+	}
+
+	metrics.P50 = percentileAt(values, 0.5)
+	metrics.P90 = percentileAt(values, 0.9)
+	metrics.P95 = percentileAt(values, 0.95)
+	metrics.P99 = percentileAt(values, 0.99)
+	metrics.MinP90 = percentileAt(values, 0.1)
+	metrics.MaxP90 = metrics.P90
+	return metrics
+}
+
+// percentileAt returns the p quantile of sorted values, interpolated between the closest ranks.
+func percentileAt(sorted []float32, p float32) float32 {
+	rank := p * float32(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float32(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// calculatePercentiles calculates percentile metrics for a set of values, sorting them in place.
+func calculatePercentiles(values []float32) models.CapacityMetrics {
 	if len(values) == 0 {
 		return models.CapacityMetrics{}
 	}
@@ -420,9 +648,38 @@ func (b *AdvancedBalancer) calculatePercentiles(values []float32) models.Capacit
 	}
 }
 
+// GetNodeScoreBreakdown returns the current per-node component scores, ordered from least to most loaded.
+func (b *AdvancedBalancer) GetNodeScoreBreakdown() ([]models.NodeScoreBreakdown, error) {
+	nodes, err := b.client.GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+
+	availableNodes := b.filterAvailableNodes(nodes)
+	if len(availableNodes) == 0 {
+		return nil, fmt.Errorf("no available nodes")
+	}
+
+	b.refreshPowerTelemetry()
+
+	breakdowns := b.calculateScoreBreakdowns(availableNodes)
+	sort.SliceStable(breakdowns, func(i, j int) bool {
+		return breakdowns[i].Final < breakdowns[j].Final
+	})
+
+	return breakdowns, nil
+}
+
 // calculateAdvancedNodeScores calculates node scores with advanced algorithms including capacity planning.
 func (b *AdvancedBalancer) calculateAdvancedNodeScores(nodes []models.Node) []models.NodeScore {
-	var scores []models.NodeScore
+	return scoresFromBreakdowns(nodes, b.calculateScoreBreakdowns(nodes))
+}
+
+// calculateScoreBreakdowns calculates every node's sub-scores and final blended score.
+func (b *AdvancedBalancer) calculateScoreBreakdowns(nodes []models.Node) []models.NodeScoreBreakdown {
+	breakdowns := make([]models.NodeScoreBreakdown, 0, len(nodes))
+	weights := b.config.GetScoreWeights()
+	maxWatts := b.maxNodeWatts(nodes)
 
 	for i := range nodes {
 		node := &nodes[i]
@@ -438,16 +695,88 @@ func (b *AdvancedBalancer) calculateAdvancedNodeScores(nodes []models.Node) []mo
 		// Calculate capacity planning score
 		capacityScore := b.calculateCapacityScore(node)
 
-		// Calculate final score with capacity planning weighting
-		// Capacity planning gets 30% weight, stability gets 20%, resource gets 40%, migration cost gets 10%
-		finalScore := resourceScore*0.4 +
-			stabilityScore*0.2 +
-			capacityScore*0.3 +
-			migrationCost*0.1
+		// Calculate final score with the configured weighting
+		// Defaults: resource 40%, capacity planning 30%, stability 20%, migration cost 10%
+		finalScore := resourceScore*weights.Resource +
+			stabilityScore*weights.Stability +
+			capacityScore*weights.Capacity +
+			migrationCost*weights.MigrationCost
+
+		// Bias by power/thermal telemetry when available
+		powerScore := b.calculatePowerScore(node, maxWatts)
+		finalScore += powerScore * b.config.Balancing.Power.Weight
+
+		breakdowns = append(breakdowns, models.NodeScoreBreakdown{
+			Node:          node.Name,
+			Resource:      resourceScore,
+			Stability:     stabilityScore,
+			Capacity:      capacityScore,
+			MigrationCost: migrationCost,
+			Power:         powerScore,
+			Final:         finalScore,
+		})
+	}
+
+	return breakdowns
+}
+
+// refreshPowerTelemetry reads the latest power/thermal readings, dropping stale data on failure.
+func (b *AdvancedBalancer) refreshPowerTelemetry() {
+	if b.powerSource == nil {
+		return
+	}
+
+	readings, err := b.powerSource.NodePower()
+	if err != nil {
+		logf("Warning: %v\n", err)
+		b.nodePower = nil
+		return
+	}
+	b.nodePower = readings
+}
+
+// maxNodeWatts returns the highest power draw among the nodes, used to normalize power scores.
+func (b *AdvancedBalancer) maxNodeWatts(nodes []models.Node) float64 {
+	maxWatts := 0.0
+	for i := range nodes {
+		if reading, exists := b.nodePower[nodes[i].Name]; exists && reading.Watts > maxWatts {
+			maxWatts = reading.Watts
+		}
+	}
+	return maxWatts
+}
+
+// calculatePowerScore calculates the power/thermal bias for a node (lower is better).
+// In spread mode hot or power-hungry nodes are penalized to avoid hotspots, in consolidate
+// mode nodes already drawing power are preferred so idle ones can stay idle.
+func (b *AdvancedBalancer) calculatePowerScore(node *models.Node, maxWatts float64) float64 {
+	reading, exists := b.nodePower[node.Name]
+	if !exists {
+		return 0.0
+	}
+
+	relativePower := 0.0
+	if maxWatts > 0 {
+		relativePower = reading.Watts / maxWatts * 100
+	}
+
+	if b.config.Balancing.Power.Mode == "consolidate" {
+		return 100.0 - relativePower
+	}
+
+	// Temperature in Celsius is used directly as a 0-100 hotspot indicator
+	return math.Max(relativePower, reading.Temperature)
+}
 
+// scoresFromBreakdowns turns score breakdowns into node scores sorted best first.
+func scoresFromBreakdowns(nodes []models.Node, breakdowns []models.NodeScoreBreakdown) []models.NodeScore {
+	scores := make([]models.NodeScore, 0, len(breakdowns))
+
+	for i := range breakdowns {
+		node := &nodes[i]
 		scores = append(scores, models.NodeScore{
 			Node:    node.Name,
-			Score:   finalScore,
+			Score:   breakdowns[i].Final,
 			CPU:     node.CPU.Usage,
 			Memory:  node.Memory.Usage,
 			Storage: node.Storage.Usage,
@@ -475,13 +804,19 @@ func (b *AdvancedBalancer) calculateResourceScore(node *models.Node) float64 {
 
 	// If capacity metrics are available, use predictive scoring
 	if exists && metrics.P90 > 0 {
-		// Calculate predictive scores based on P90 capacity
-		predictiveCPU := float64(metrics.P90) * 100 // P90 as predictive indicator
-		predictiveMemory := float64(metrics.P90) * 100
+		// Calculate predictive scores based on P90 capacity, in percent like current usage
+		predictiveCPU := float64(metrics.P90)
 
-		// Blend current usage with predictive capacity (70% current, 30% predictive)
-		cpuInt = int((float64(node.CPU.Usage)*0.7 + predictiveCPU*0.3) * 100)
-		memoryInt = int((float64(node.Memory.Usage)*0.7 + predictiveMemory*0.3) * 100)
+		// Memory predicts from its own percentiles or stays at current usage
+		predictiveMemory := float64(node.Memory.Usage)
+		if memory, ok := b.memoryMetrics[node.Name]; ok && memory.P90 > 0 {
+			predictiveMemory = float64(memory.P90)
+		}
+
+		// Blend current usage with predictive capacity (default 70% current, 30% predictive)
+		weights := b.config.GetScoreWeights()
+		cpuInt = int((float64(node.CPU.Usage)*weights.Current + predictiveCPU*weights.Predictive) * 100)
+		memoryInt = int((float64(node.Memory.Usage)*weights.Current + predictiveMemory*weights.Predictive) * 100)
 	}
 
 	// Use integer weights (multiply by 1000 for precision)
@@ -576,7 +911,7 @@ func (b *AdvancedBalancer) calculateCapacityScore(node *models.Node) float64 {
 	}
 
 	// Get aggressiveness configuration
-	aggConfig := b.config.GetAggressivenessConfig()
+	aggConfig := b.aggressivenessConfig()
 
 	// Calculate score based on percentile usage
 	cpuScore := 0.0
@@ -585,8 +920,8 @@ func (b *AdvancedBalancer) calculateCapacityScore(node *models.Node) float64 {
 	}
 
 	memoryScore := 0.0
-	if metrics.P90 > 0 {
-		memoryScore = 100.0 - float64(metrics.P90) // Lower P90 is better
+	if memory, ok := b.memoryMetrics[node.Name]; ok && memory.P90 > 0 {
+		memoryScore = 100.0 - float64(memory.P90) // Lower P90 is better
 	}
 
 	// Combine scores, with capacity planning getting more weight
@@ -613,58 +948,166 @@ func (b *AdvancedBalancer) calculateCapacityScoreSimplified(node *models.Node) f
 	capacityScore := cpuScore*0.6 + memoryScore*0.4
 
 	// Apply aggressiveness weighting
-	aggConfig := b.config.GetAggressivenessConfig()
+	aggConfig := b.aggressivenessConfig()
 	return capacityScore * aggConfig.CapacityWeight
 }
 
 // findOptimalMigrations finds optimal migration plan (optimized for performance).
-func (b *AdvancedBalancer) findOptimalMigrations(nodes []models.Node, nodeScores []models.NodeScore, aggConfig config.AggressivenessConfig) []models.Migration {
+// With always set, the most loaded node is drained when none is overloaded and any positive gain is accepted.
+// VMs on overloaded nodes without any valid target are tracked as unschedulable.
+func (b *AdvancedBalancer) findOptimalMigrations(nodes []models.Node, nodeScores []models.NodeScore, aggConfig config.AggressivenessConfig, always bool) []models.Migration {
 	// Pre-allocate slice with reasonable capacity to reduce allocations
 	migrations := make([]models.Migration, 0, 5) // Most clusters won't need more than 5 migrations
-
-	// Pre-calculate thresholds as float32 for consistent comparison
-	cpuThreshold := float32(b.config.Balancing.Thresholds.CPU)
-	memoryThreshold := float32(b.config.Balancing.Thresholds.Memory)
-	storageThreshold := float32(b.config.Balancing.Thresholds.Storage)
-
-	// Find overloaded nodes (optimized loop)
+	var unschedulable []models.UnschedulableVM
+	var skipped []models.SkippedVM
+	defer func() {
+		b.unschedulable.update(unschedulable, time.Now())
+		b.skipped.set(withoutPlanned(skipped, migrations))
+	}()
+
+	// Find overloaded nodes, within their hysteresis band when they were already overloaded
 	overloadedNodes := make([]models.Node, 0, len(nodes)/2) // Pre-allocate with reasonable capacity
 	for i := range nodes {
 		node := &nodes[i]
-		// Use float32 comparisons for better precision
-		if node.CPU.Usage > cpuThreshold ||
-			node.Memory.Usage > memoryThreshold ||
-			node.Storage.Usage > storageThreshold {
+		if b.overloads.overloaded(b.config, node) {
 			overloadedNodes = append(overloadedNodes, *node)
 		}
 	}
 
+	// Nodes beyond the imbalance SLA are relieved first, worst first
+	breaches := slaBreaches(b.config, nodes)
+	breaching := make(map[string]bool, len(breaches))
+	for _, breach := range breaches {
+		breaching[breach.Node] = true
+	}
+	overloadedNodes = slaFirst(breaches, nodes, overloadedNodes)
+	overloaded := len(overloadedNodes) > 0
+	if !overloaded && always {
+		overloadedNodes = mostLoadedNode(nodes, nodeScores)
+	}
+	overloadedNodes = filterSourceRoles(b.config, overloadedNodes)
+
+	// Recently rebooted nodes, and nodes failing their health check, can't receive VMs yet
+	minUptime, _ := b.config.GetMinTargetUptime() //nolint:errcheck // validated at load time
+	targets := filterHealthyTargets(b.config, filterTargetScores(nodes, nodeScores, minUptime))
+
+	// Nodes involved in a recent migration are left alone until their metrics settle
+	overloadedNodes, targets = b.observations.exclude(overloadedNodes, targets, time.Now())
+
+	// During rolling upgrades, VMs only move between nodes on the same major version
+	versions := nodeVersions(b.config, nodes)
+
+	// New VMs that landed on the wrong node move first, whatever the load of their node
+	migrations = append(migrations, landingMigrations(b.config, b.engine, nodes, filterSourceRoles(b.config, nodes), filterTargetRoles(b.config, targets), versions, nil)...)
+	landing := plannedVMs(migrations)
+
 	// For each overloaded node, find VMs to migrate
 	for i := range overloadedNodes {
 		overloadedNode := &overloadedNodes[i]
-		for j := range overloadedNode.VMs {
-			vm := &overloadedNode.VMs[j]
-			// Early exit for non-running VMs
+		sourceTargets := filterVersionTargets(versions, overloadedNode.Name, targets)
+		candidates := b.orderMigrationCandidates(overloadedNode)
+		for j := range candidates {
+			vm := &candidates[j]
+			if landing[vm.ID] {
+				continue
+			}
+			// Cycle limit reached, the remaining candidates wait for the next cycle
+			if len(migrations) >= 5 {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipCycleLimit))
+				continue
+			}
+
+			// Early exit for non-running VMs; paused or suspended ones may still move offline later
+			if heldSuspended(b.config, vm) {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipSuspended))
+				continue
+			}
 			if vm.Status != "running" {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipNotRunning))
+				continue
+			}
+			if b.engine.IsDuplicate(vm.ID) {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipDuplicate))
 				continue
 			}
 
-			// Check if VM can be migrated
-			if !b.canMigrateVM(vm, overloadedNode.Name) {
+			// Check if VM can be migrated; a panicking node sheds load despite per-VM cooldowns
+			panicking := inPanic(b.config, overloadedNode)
+			if !panicking && b.recentlyMigrated(vm) {
+				if cooldown, ok := b.configuredCooldown(); ok {
+					skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipCooldownFor, cooldown))
+				} else {
+					skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipCooldown))
+				}
 				continue
 			}
+			if b.engine.IsFrozen(vm.ID, time.Now()) {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipFrozen))
+				continue
+			}
+			if inBackupWindow(b.config, vm, time.Now()) {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipBackup))
+				continue
+			}
+			if heldPassthrough(b.config, vm) {
+				skipped = append(skipped, governedBy(skippedVM(vm, overloadedNode.Name, skipPassthrough), rules.RulePassthrough))
+				continue
+			}
+			if downtime, exceeded := downtimeExceeded(b.config, vm); exceeded {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipDowntime, downtime, vm.MigrateDowntime))
+				continue
+			}
+			correction := ruleCorrection(b.config, b.engine, vm, overloadedNode.Name)
+			if !correction && !b.canMigrateVM(vm, overloadedNode.Name, panicking) {
+				skipped = append(skipped, governedBy(skippedVM(vm, overloadedNode.Name, skipRules),
+					rules.GoverningRule(b.engine.ValidatePlacement(vm, overloadedNode.Name))))
+				continue
+			}
+
+			// The target needs room for the VM, anticipating the upcoming period's load when profiled,
+			// for its memory and its local disks, and must stay within the overcommit limits
+			var vmTargets []models.NodeScore
+			if b.config.Balancing.LoadProfiles.BusinessHours.Enabled() {
+				vmTargets = b.filterSeasonalTargets(vm, nodes, sourceTargets, time.Now())
+			} else {
+				vmTargets = filterCPUFitTargets(vm, nodes, sourceTargets, float64(b.config.Balancing.Thresholds.CPU))
+			}
+			vmTargets = b.filterMemoryFitTargets(vm, nodes, vmTargets)
+			vmTargets = filterStorageFitTargets(vm, nodes, vmTargets, float64(b.config.Balancing.Thresholds.Storage))
+			vmTargets = filterOvercommitTargets(b.config.Balancing.Overcommit, vm, nodes, vmTargets)
 
 			// Find best target node
-			targetNode := b.findBestTargetNode(vm, nodeScores, overloadedNode.Name)
+			targetNode := b.findBestTargetNode(vm, vmTargets, overloadedNode.Name)
 			if targetNode == "" {
+				stuck := newUnschedulableVM(b.engine, vm, overloadedNode.Name, vmTargets)
+				if overloaded {
+					unschedulable = append(unschedulable, stuck)
+				}
+				skipped = append(skipped, governedBy(skippedVM(vm, overloadedNode.Name, skipNoTarget, stuck.Reason),
+					excludingRules(b.engine, vm, overloadedNode.Name, vmTargets)...))
 				continue
 			}
 
 			// Calculate resource gain
 			gain := b.calculateResourceGain(overloadedNode.Name, targetNode, nodeScores)
 
-			// Check if gain meets minimum improvement threshold
-			if gain < aggConfig.MinImprovement {
+			// Check if gain meets minimum improvement threshold, waived when forced, panicking or
+			// beyond the imbalance SLA. A rule correction needs its own, lower bound instead, which always holds
+			minGain := aggConfig.MinImprovement
+			if correction {
+				minGain = b.config.Balancing.RuleCorrections.MinGain
+			} else if gain <= 0 {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipNoGain))
+				continue
+			}
+			if (correction || (!always && !panicking && !breaching[overloadedNode.Name])) && gain < minGain {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipLowGain, gain, minGain))
+				continue
+			}
+
+			// Protected VMs need a larger gain, even when forced
+			if !protectedGainMet(b.config, vm, gain) {
+				skipped = append(skipped, skippedVM(vm, overloadedNode.Name, skipProtected))
 				continue
 			}
 
@@ -673,46 +1116,191 @@ func (b *AdvancedBalancer) findOptimalMigrations(nodes []models.Node, nodeScores
 				VM:        *vm,
 				FromNode:  overloadedNode.Name,
 				ToNode:    targetNode,
+				Gain:      gain,
+				Freed:     freedResources(vm, overloadedNode),
 				Status:    "pending",
 				StartTime: time.Now(),
 			}
 
 			migrations = append(migrations, migration)
+		}
+	}
 
-			// Limit number of migrations per cycle
-			if len(migrations) >= 5 {
-				return migrations
-			}
+	// Limit number of migrations per cycle
+	if len(migrations) >= 5 {
+		return migrations
+	}
+
+	// Load-based candidates are exhausted: stopped or idle VMs may still move to fix their placement
+	if b.config.Balancing.ZeroFootprint == config.ZeroFootprintRules {
+		migrations = append(migrations, ruleComplianceMigrations(b.config, b.engine, nodes, overloadedNodes, filterTargetRoles(b.config, targets), versions, migrations, func(vm *models.VM) bool {
+			return !b.recentlyMigrated(vm)
+		})...)
+		if len(migrations) > 5 {
+			migrations = migrations[:5]
 		}
 	}
 
 	return migrations
 }
 
+// GetUnschedulableVMs returns the VMs that should move but had no valid target in the last cycle.
+func (b *AdvancedBalancer) GetUnschedulableVMs() []models.UnschedulableVM {
+	return b.unschedulable.list()
+}
+
+// GetSkippedVMs returns the VMs evaluated but left in place in the last cycle, with the reasons.
+func (b *AdvancedBalancer) GetSkippedVMs() []models.SkippedVM {
+	return b.skipped.list()
+}
+
+// GetPhaseTimings returns the time the last cycle spent in each of its phases.
+func (b *AdvancedBalancer) GetPhaseTimings() []models.PhaseTiming {
+	return b.phases.list()
+}
+
+// GetMigrationHistory returns the successful migrations of the last 24 hours, oldest first.
+func (b *AdvancedBalancer) GetMigrationHistory() []models.MigrationHistory {
+	return append([]models.MigrationHistory(nil), b.migrationHistory...)
+}
+
+// skipDroppedMigrations logs the migrations the benefit plan dropped as skipped.
+func (b *AdvancedBalancer) skipDroppedMigrations(migrations, kept []models.Migration) {
+	planned := make(map[int]bool, len(kept))
+	for i := range kept {
+		planned[kept[i].VM.ID] = true
+	}
+	for i := range migrations {
+		if !planned[migrations[i].VM.ID] {
+			b.skipped.add(skippedVM(&migrations[i].VM, migrations[i].FromNode, skipNetBenefit))
+		}
+	}
+}
+
+// orderMigrationCandidates returns the node's VMs ordered by the CPU relief moving them would bring,
+// discounted for active sessions when known, then by pool policy, with the VMs that prefer this node last.
+func (b *AdvancedBalancer) orderMigrationCandidates(node *models.Node) []models.VM {
+	candidates := make([]models.VM, len(node.VMs))
+	copy(candidates, node.VMs)
+
+	// A VM with many active sessions is a soft no: its relief counts for up to half less
+	loads := b.sessionLoads(node)
+	relief := func(vm *models.VM) float64 {
+		return estimateCPURelief(vm, node) * (1 - loads[vm.ID]/2)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return relief(&candidates[i]) > relief(&candidates[j])
+	})
+
+	// Movable pools go first and protected pools last, VMs on a node they prefer only move
+	// when the others weren't enough
+	return movePreferredLast(b.engine, node.Name, orderByPoolPolicy(b.config, candidates))
+}
+
 // canMigrateVM checks if a VM can be migrated (optimized for performance).
-func (b *AdvancedBalancer) canMigrateVM(vm *models.VM, sourceNode string) bool {
-	// Cache current time to avoid multiple calls
-	now := time.Now()
-	oneHourAgo := now.Add(-1 * time.Hour)
+// ignoreCooldown lets a recently migrated VM move again.
+func (b *AdvancedBalancer) canMigrateVM(vm *models.VM, sourceNode string, ignoreCooldown bool) bool {
+	if !ignoreCooldown && b.recentlyMigrated(vm) {
+		return false
+	}
+
+	// Check rules engine
+	return b.engine.ValidatePlacement(vm, sourceNode) == nil
+}
+
+// configuredCooldown returns the cooldown set in balancing.cooldown, which takes precedence over
+// the aggressiveness default.
+func (b *AdvancedBalancer) configuredCooldown() (time.Duration, bool) {
+	if b.config.Balancing.Cooldown == "" {
+		return 0, false
+	}
+	cooldown, err := b.config.GetCooldown()
+	if err != nil {
+		return 0, false // validated at load time
+	}
+	return cooldown, true
+}
+
+// recentlyMigrated checks if a VM moved within the configured cooldown, or the last hour without
+// one, to avoid flip-flopping.
+func (b *AdvancedBalancer) recentlyMigrated(vm *models.VM) bool {
+	window := time.Hour
+	if cooldown, ok := b.configuredCooldown(); ok {
+		window = cooldown
+	}
+	since := time.Now().Add(-window)
 
 	// Check if VM was recently migrated
-	if !vm.LastMoved.IsZero() && vm.LastMoved.After(oneHourAgo) {
-		return false
+	if !vm.LastMoved.IsZero() && vm.LastMoved.After(since) {
+		return true
 	}
 
 	// Check migration history for flip-flopping (optimized loop)
 	for _, migration := range b.migrationHistory {
-		if migration.VMID == vm.ID && migration.Timestamp.After(oneHourAgo) {
-			return false
+		if migration.VMID == vm.ID && migration.Timestamp.After(since) {
+			return true
 		}
 	}
 
-	// Check rules engine
-	return b.engine.ValidatePlacement(vm, sourceNode) == nil
+	return false
+}
+
+// inPanic reports whether a node's CPU or memory usage is past the panic threshold.
+func inPanic(cfg *config.Config, node *models.Node) bool {
+	threshold := float32(cfg.Balancing.PanicThreshold)
+	return threshold > 0 && (node.CPU.Usage > threshold || node.Memory.Usage > threshold)
+}
+
+// logPanicMode reports whether any node is past the panic threshold, logging each one that is.
+func (b *AdvancedBalancer) logPanicMode(nodes []models.Node) bool {
+	panicking := false
+	for i := range nodes {
+		node := &nodes[i]
+		if inPanic(b.config, node) {
+			logf("Panic mode engaged: node %s at %.1f%% CPU and %.1f%% memory, past the %d%% panic threshold; ignoring cooldowns and minimum improvement\n",
+				node.Name, node.CPU.Usage, node.Memory.Usage, b.config.Balancing.PanicThreshold)
+			panicking = true
+		}
+	}
+	return panicking
+}
+
+// canHostVM reports whether the target has the memory the VM uses available, plus the configured headroom.
+// Nodes with unknown memory are assumed to fit.
+func (b *AdvancedBalancer) canHostVM(target *models.Node, vm *models.VM) bool {
+	if target.Memory.Total <= 0 {
+		return true
+	}
+	available := target.Memory.Available
+	if available <= 0 {
+		available = target.Memory.Total - target.Memory.Used
+	}
+	headroom := int64(b.config.Balancing.MemoryHeadroom * 1024 * 1024)
+	return vm.Memory+headroom <= available
+}
+
+// filterMemoryFitTargets drops the target nodes that can't host the VM's memory.
+func (b *AdvancedBalancer) filterMemoryFitTargets(vm *models.VM, nodes []models.Node, targets []models.NodeScore) []models.NodeScore {
+	nodesByName := make(map[string]*models.Node, len(nodes))
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
+	}
+
+	kept := make([]models.NodeScore, 0, len(targets))
+	for _, score := range targets {
+		node, exists := nodesByName[score.Node]
+		if exists && score.Node != vm.Node && !b.canHostVM(node, vm) {
+			continue
+		}
+		kept = append(kept, score)
+	}
+	return kept
 }
 
 // findBestTargetNode finds the best target node for a VM.
 func (b *AdvancedBalancer) findBestTargetNode(vm *models.VM, nodeScores []models.NodeScore, sourceNode string) string {
+	nodeScores = filterTargetRoles(b.config, nodeScores)
+
 	// Get available nodes for validation
 	var availableNodes []string
 	for _, score := range nodeScores {
@@ -724,8 +1312,15 @@ func (b *AdvancedBalancer) findBestTargetNode(vm *models.VM, nodeScores []models
 	// Get valid target nodes from rules engine
 	validNodes := b.engine.GetValidTargetNodes(vm, availableNodes)
 
-	// Find the best valid node
-	for _, score := range nodeScores {
+	// A preferred or replica node wins over the score unless it is overloaded
+	if preferred := preferredTarget(b.engine, vm, validNodes, nodeScores, func(score *models.NodeScore) bool {
+		return overThresholds(b.config, score.CPU, score.Memory, score.Storage)
+	}); preferred != "" {
+		return preferred
+	}
+
+	// Find the best valid node, avoiding pairs that keep failing
+	for _, score := range b.rankTargetsByReliability(sourceNode, nodeScores) {
 		if score.Node == sourceNode {
 			continue
 		}
@@ -768,39 +1363,139 @@ func (b *AdvancedBalancer) calculateResourceGain(sourceNode, targetNode string,
 	return sourceScore - targetScore
 }
 
-// executeMigrations executes the migration plan.
-func (b *AdvancedBalancer) executeMigrations(migrations []models.Migration) []models.BalancingResult {
-	var results []models.BalancingResult
+// buildMigrationPlan weighs each migration's gain over the horizon, scaled by the source node's trend,
+// against its modeled churn cost. Only net-positive migrations make it into the plan.
+func (b *AdvancedBalancer) buildMigrationPlan(migrations []models.Migration, nodes []models.Node, nodeScores []models.NodeScore, horizon time.Duration) models.MigrationPlan {
+	nodesByName := make(map[string]*models.Node, len(nodes))
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
+	}
 
+	plan := models.MigrationPlan{Migrations: make([]models.Migration, 0, len(migrations))}
 	for i := range migrations {
 		migration := &migrations[i]
-		// Execute migration via Proxmox API
-		err := b.client.MigrateVM(migration.VM.ID, migration.FromNode, migration.ToNode)
-
-		result := models.BalancingResult{
-			SourceNode:   migration.FromNode,
-			TargetNode:   migration.ToNode,
-			VM:           migration.VM,
-			Reason:       "load_balancing",
-			ResourceGain: 10.0, // Simplified
-			Timestamp:    time.Now(),
-			Success:      err == nil,
+		source, target := nodesByName[migration.FromNode], nodesByName[migration.ToNode]
+		if source == nil || target == nil {
+			continue
 		}
 
-		if err != nil {
-			result.ErrorMessage = err.Error()
+		gain := b.calculateResourceGain(migration.FromNode, migration.ToNode, nodeScores) *
+			horizon.Hours() * b.trendFactor(source, horizon)
+		cost := b.migrationChurnCost(&migration.VM, source, target)
+		if gain <= cost {
+			logf("Skipping migration of VM %s (%d) from %s to %s: gain %.1f over %v does not outweigh cost %.1f\n",
+				migration.VM.Name, migration.VM.ID, migration.FromNode, migration.ToNode, gain, horizon, cost)
+			continue
+		}
+
+		plan.Migrations = append(plan.Migrations, *migration)
+		plan.TotalGain += gain
+		plan.TotalCost += cost
+		plan.EstimatedDuration += migration.EstimatedDuration
+	}
+	plan.NetBenefit = plan.TotalGain - plan.TotalCost
+
+	return plan
+}
+
+// trendFactor scales a gain by how the node's CPU load is predicted to evolve over the horizon:
+// a growing load makes relief worth more. Without capacity metrics the load is assumed flat.
+func (b *AdvancedBalancer) trendFactor(node *models.Node, horizon time.Duration) float64 {
+	predicted := b.PredictResourceEvolution(node.Name, "cpu", horizon)
+	if predicted <= 0 || node.CPU.Usage <= 0 {
+		return 1.0
+	}
+	return math.Min(2.0, predicted/float64(node.CPU.Usage))
+}
+
+// migrationChurnCost models the one-off cost of a migration, in score points over an hour:
+// copying the VM's memory, and its local disks on a storage migration, plus the extra load on both ends.
+func (b *AdvancedBalancer) migrationChurnCost(vm *models.VM, source, target *models.Node) float64 {
+	memoryGiB := float64(vm.Memory) / (1 << 30)
+	cost := memoryGiB*migrationCostPerGiB + b.calculateMigrationCost(source) + b.calculateMigrationCost(target)
+	if storageMigration(vm) {
+		cost += float64(diskFootprint(vm)) / (1 << 30) * storageMigrationCostPerGiB
+	}
+	return cost
+}
+
+// executeMigrations executes the migration plan in waves bounded by the per-node concurrency limits,
+// pausing the migration delay between waves and starting no new wave past the deadline.
+func (b *AdvancedBalancer) executeMigrations(migrations []models.Migration, deadline time.Time) []models.BalancingResult {
+	results := make([]models.BalancingResult, 0, len(migrations))
+
+	started := 0
+	for _, wave := range planMigrationWaves(migrations, b.config.Balancing.Concurrency) {
+		// Waves run one after the other, each leaving the previous one time to settle
+		if started > 0 && !migrationPause(b.config, deadline, len(migrations)-started) {
+			break
 		}
+		if budgetExhausted(deadline, len(migrations)-started) {
+			break
+		}
+
+		// Run the wave concurrently and wait for it before starting the next one
+		waveResults := make([]models.BalancingResult, len(wave))
+		var wg sync.WaitGroup
+		for j, i := range wave {
+			wg.Add(1)
+			go func(j int, migration *models.Migration) {
+				defer wg.Done()
+				waveResults[j] = b.executeMigration(migration)
+			}(j, &migrations[i])
+		}
+		wg.Wait()
 
-		results = append(results, result)
+		results = append(results, waveResults...)
+		started += len(wave)
 	}
 
 	return results
 }
 
+// executeMigration executes a single migration.
+func (b *AdvancedBalancer) executeMigration(migration *models.Migration) models.BalancingResult {
+	result := models.BalancingResult{
+		SourceNode:   migration.FromNode,
+		TargetNode:   migration.ToNode,
+		VM:           migration.VM,
+		Reason:       "load_balancing",
+		Rules:        b.engine.GoverningRules(&migration.VM, migration.ToNode),
+		ResourceGain: migration.Gain,
+		Freed:        migration.Freed,
+		Timestamp:    time.Now(),
+
+		EstimatedDuration: migration.EstimatedDuration,
+		StorageMigration:  migration.StorageMigration,
+	}
+
+	// Read-only mode publishes the plan without touching the cluster
+	if b.config.ReadOnly {
+		result.DryRun = true
+		return result
+	}
+
+	// Execute migration via Proxmox API
+	err := b.client.MigrateVM(migration.VM.ID, migration.FromNode, migration.ToNode)
+	result.Success = err == nil
+	if err != nil {
+		result.ErrorMessage = err.Error()
+	}
+
+	return result
+}
+
 // updateMigrationHistory updates migration history.
 func (b *AdvancedBalancer) updateMigrationHistory(results []models.BalancingResult) {
+	recorded := false
 	for i := range results {
 		result := &results[i]
+		if result.DryRun {
+			continue
+		}
+		b.recordMigrationOutcome(result)
+		recorded = true
+
 		if result.Success {
 			history := models.MigrationHistory{
 				VMID:      result.VM.ID,
@@ -808,6 +1503,7 @@ func (b *AdvancedBalancer) updateMigrationHistory(results []models.BalancingResu
 				ToNode:    result.TargetNode,
 				Timestamp: result.Timestamp,
 				Reason:    result.Reason,
+				Rules:     result.Rules,
 			}
 			b.migrationHistory = append(b.migrationHistory, history)
 		}
@@ -822,6 +1518,12 @@ func (b *AdvancedBalancer) updateMigrationHistory(results []models.BalancingResu
 		}
 	}
 	b.migrationHistory = recentHistory
+
+	if recorded {
+		if err := b.saveMigrationHistory(); err != nil {
+			logf("Warning: %v\n", err)
+		}
+	}
 }
 
 // filterAvailableNodes filters out maintenance nodes.
@@ -848,17 +1550,16 @@ func (b *AdvancedBalancer) isInMaintenance(nodeName string) bool {
 	return false
 }
 
-// needsBalancing checks if balancing is needed.
+// needsBalancing checks if balancing is needed. Every node is evaluated, keeping their
+// hysteresis state current.
 func (b *AdvancedBalancer) needsBalancing(nodes []models.Node) bool {
+	needed := false
 	for i := range nodes {
-		node := &nodes[i]
-		if node.CPU.Usage > float32(b.config.Balancing.Thresholds.CPU) ||
-			node.Memory.Usage > float32(b.config.Balancing.Thresholds.Memory) ||
-			node.Storage.Usage > float32(b.config.Balancing.Thresholds.Storage) {
-			return true
+		if b.overloads.overloaded(b.config, &nodes[i]) {
+			needed = true
 		}
 	}
-	return false
+	return needed
 }
 
 // GetCapacityMetrics returns capacity metrics for a specific node.
@@ -869,7 +1570,12 @@ func (b *AdvancedBalancer) GetCapacityMetrics(nodeName string) (*models.Capacity
 
 // PredictResourceEvolution predicts resource usage evolution for a given period.
 func (b *AdvancedBalancer) PredictResourceEvolution(nodeName, resourceType string, forecastDuration time.Duration) float64 {
-	metrics, exists := b.capacityMetrics[nodeName]
+	metricsByNode := b.capacityMetrics
+	if resourceType == "memory" {
+		metricsByNode = b.memoryMetrics
+	}
+
+	metrics, exists := metricsByNode[nodeName]
 	if !exists {
 		return 0.0
 	}
@@ -950,6 +1656,9 @@ type VMProfile struct {
 	CPUBuffer       float64
 	MemoryBuffer    float64
 	Recommendations []string
+
+	// Confidence is how much the profile goes by history rather than tags, from 0 (none) to 1
+	Confidence float64
 }
 
 // AnalyzeVMProfile analyzes a VM's workload profile and provides recommendations.
@@ -962,13 +1671,28 @@ func (b *AdvancedBalancer) AnalyzeVMProfile(vm *models.VM, nodeName string) VMPr
 		MemoryBuffer: 50.0,
 	}
 
-	// Get VM's load profile
+	// Get VM's load profile, trusted as far as its history goes
+	var confidence float64
 	loadProfile, exists := b.loadProfiles[vm.ID]
 	if exists {
+		confidence = profileConfidence(loadProfile.Samples)
+	}
+
+	switch {
+	case confidence >= 1:
 		b.analyzeLoadProfileMetrics(&profile, loadProfile)
-	} else {
+	case confidence > 0:
+		history, tags := profile, profile
+		b.analyzeLoadProfileMetrics(&history, loadProfile)
+		b.analyzeTagProfile(&tags, vm)
+		profile = blendProfiles(&history, &tags, confidence)
+	default:
 		b.analyzeFallbackProfile(&profile, vm)
 	}
+	profile.Confidence = confidence
+
+	// An explicit plb_class_ tag overrides the inferred workload type and buffers
+	b.applyWorkloadClass(&profile, vm)
 
 	// Cap buffers at 100%
 	b.capBufferValues(&profile)
@@ -987,10 +1711,12 @@ func (b *AdvancedBalancer) GetClusterRecommendations(forecastDuration time.Durat
 		return recommendations
 	}
 
-	// Analyze cluster-wide patterns
+	// Analyze cluster-wide patterns, tracking each resource independently
 	nodesWithData := 0
 	highUsageNodes := 0
 	lowUsageNodes := 0
+	cpuPressureNodes := 0
+	memoryPressureNodes := 0
 
 	for i := range nodes {
 		node := &nodes[i]
@@ -1002,7 +1728,17 @@ func (b *AdvancedBalancer) GetClusterRecommendations(forecastDuration time.Durat
 			predictedCPU := b.PredictResourceEvolution(node.Name, "cpu", forecastDuration)
 			predictedMemory := b.PredictResourceEvolution(node.Name, "memory", forecastDuration)
 
-			if predictedCPU > 90 || predictedMemory > 90 {
+			cpuPressure := predictedCPU > 90
+			memoryPressure := predictedMemory > 90
+			if cpuPressure {
+				cpuPressureNodes++
+			}
+			if memoryPressure {
+				memoryPressureNodes++
+			}
+
+			// A node under pressure counts once, whichever resource is short
+			if cpuPressure || memoryPressure {
 				highUsageNodes++
 			} else if predictedCPU < 30 && predictedMemory < 30 {
 				lowUsageNodes++
@@ -1025,6 +1761,12 @@ func (b *AdvancedBalancer) GetClusterRecommendations(forecastDuration time.Durat
 		recommendations = append(recommendations, "⚠️  Elevated predicted usage on significant portion of nodes - plan for capacity expansion")
 	}
 
+	if constraint := constrainingResource(cpuPressureNodes, memoryPressureNodes); constraint != "" {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"🔎 Constraining resource: %s (%d/%d nodes predicted above 90%% CPU, %d/%d above 90%% memory)",
+			constraint, cpuPressureNodes, nodesWithData, memoryPressureNodes, nodesWithData))
+	}
+
 	if lowUsagePercentage > 50 {
 		recommendations = append(recommendations, "💡 Low predicted usage on majority of nodes - consider VM consolidation")
 	}
@@ -1043,6 +1785,20 @@ func (b *AdvancedBalancer) GetClusterRecommendations(forecastDuration time.Durat
 	return recommendations
 }
 
+// constrainingResource names the resource most nodes are predicted to run short of.
+func constrainingResource(cpuPressureNodes, memoryPressureNodes int) string {
+	switch {
+	case cpuPressureNodes == 0 && memoryPressureNodes == 0:
+		return ""
+	case memoryPressureNodes > cpuPressureNodes:
+		return "memory"
+	case cpuPressureNodes > memoryPressureNodes:
+		return "cpu"
+	default:
+		return "cpu and memory"
+	}
+}
+
 // analyzeLoadProfileMetrics analyzes VM load profile metrics and updates the profile.
 func (b *AdvancedBalancer) analyzeLoadProfileMetrics(profile *VMProfile, loadProfile *models.LoadProfile) {
 	b.analyzeCPUPattern(profile, loadProfile)
@@ -1113,6 +1869,12 @@ func (b *AdvancedBalancer) analyzePriorityAndCriticality(profile *VMProfile, loa
 
 // analyzeFallbackProfile analyzes VM profile based on tags when no load profile exists.
 func (b *AdvancedBalancer) analyzeFallbackProfile(profile *VMProfile, vm *models.VM) {
+	b.analyzeTagProfile(profile, vm)
+	profile.Recommendations = append(profile.Recommendations, "No historical data available - using tag-based analysis")
+}
+
+// analyzeTagProfile analyzes VM profile based on its tags.
+func (b *AdvancedBalancer) analyzeTagProfile(profile *VMProfile, vm *models.VM) {
 	// Fallback analysis based on VM tags and type
 	profile.WorkloadType = "Standard"
 	profile.Pattern = "Unknown (no historical data)"
@@ -1136,8 +1898,40 @@ func (b *AdvancedBalancer) analyzeFallbackProfile(profile *VMProfile, vm *models
 			profile.Recommendations = append(profile.Recommendations, "Database VM - memory-focused buffer recommended")
 		}
 	}
+}
 
-	profile.Recommendations = append(profile.Recommendations, "No historical data available - using tag-based analysis")
+// workloadClass is the profile set by an explicit plb_class_ tag.
+type workloadClass struct {
+	workloadType   string
+	cpuBuffer      float64
+	memoryBuffer   float64
+	recommendation string
+}
+
+// workloadClasses are the classes a plb_class_ tag may name.
+var workloadClasses = map[string]workloadClass{
+	"db":  {workloadType: "Database", cpuBuffer: 40.0, memoryBuffer: 50.0, recommendation: "Database VM - memory-focused buffer recommended"},
+	"web": {workloadType: "Web/Application", cpuBuffer: 50.0, memoryBuffer: 40.0, recommendation: "Web/Application VM - moderate buffer recommended"},
+}
+
+// applyWorkloadClass overrides the inferred profile with the class of the VM's plb_class_ tag, if any.
+func (b *AdvancedBalancer) applyWorkloadClass(profile *VMProfile, vm *models.VM) {
+	name := rules.WorkloadClass(vm)
+	if name == "" {
+		return
+	}
+
+	class, known := workloadClasses[name]
+	if !known {
+		profile.Recommendations = append(profile.Recommendations,
+			fmt.Sprintf("Unknown workload class %q in plb_class_ tag - using inferred profile", name))
+		return
+	}
+
+	profile.WorkloadType = class.workloadType
+	profile.CPUBuffer = class.cpuBuffer
+	profile.MemoryBuffer = class.memoryBuffer
+	profile.Recommendations = append(profile.Recommendations, class.recommendation+" (explicit plb_class_"+name+" tag)")
 }
 
 // capBufferValues ensures buffer values don't exceed 100%.
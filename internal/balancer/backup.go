@@ -0,0 +1,48 @@
+package balancer
+
+import (
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// inBackupWindow reports whether a VM is being backed up: holding a backup lock, or within
+// backup_window of the last start of one of its backup jobs. Migrating it would fail on the lock
+// or fight the backup for I/O.
+func inBackupWindow(cfg *config.Config, vm *models.VM, now time.Time) bool {
+	if vm.BackingUp {
+		return true
+	}
+	window, _ := cfg.GetBackupWindow() //nolint:errcheck // validated at load time
+	if window <= 0 {
+		return false
+	}
+	for _, schedule := range vm.Backups {
+		if start, ok := lastBackupStart(schedule, now); ok && now.Sub(start) < window {
+			return true
+		}
+	}
+	return false
+}
+
+// lastBackupStart returns the last time a backup schedule started, at or before now, within the
+// past week.
+func lastBackupStart(schedule models.BackupSchedule, now time.Time) (time.Time, bool) {
+	for days := 0; days <= 7; days++ {
+		day := now.AddDate(0, 0, -days)
+		start := time.Date(day.Year(), day.Month(), day.Day(), schedule.Start/60, schedule.Start%60, 0, 0, now.Location())
+		if start.After(now) {
+			continue
+		}
+		if len(schedule.Days) == 0 {
+			return start, true
+		}
+		for _, weekday := range schedule.Days {
+			if weekday == start.Weekday() {
+				return start, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
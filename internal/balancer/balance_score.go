@@ -0,0 +1,33 @@
+package balancer
+
+import "github.com/cblomart/GoProxLB/internal/models"
+
+// clusterBalanceScore rates how evenly CPU and memory are spread across nodes, from 0 to 100.
+// It is 100 minus the average coefficient of variation (StdDev/Mean) as a percentage, so a
+// perfectly even cluster scores 100 and one whose spread reaches its mean scores 0.
+func clusterBalanceScore(cpu, memory models.CapacityMetrics) float64 {
+	variation := (coefficientOfVariation(cpu) + coefficientOfVariation(memory)) / 2
+	if variation > 1 {
+		variation = 1
+	}
+	return 100 * (1 - variation)
+}
+
+// coefficientOfVariation returns StdDev/Mean, or 0 for an idle resource.
+func coefficientOfVariation(metrics models.CapacityMetrics) float64 {
+	if metrics.Mean <= 0 {
+		return 0
+	}
+	return float64(metrics.StdDev) / float64(metrics.Mean)
+}
+
+// balanceScoreOf computes the cluster balance score for a set of nodes.
+func balanceScoreOf(nodes []models.Node) float64 {
+	cpuValues := make([]float32, 0, len(nodes))
+	memoryValues := make([]float32, 0, len(nodes))
+	for i := range nodes {
+		cpuValues = append(cpuValues, nodes[i].CPU.Usage)
+		memoryValues = append(memoryValues, nodes[i].Memory.Usage)
+	}
+	return clusterBalanceScore(calculatePercentiles(cpuValues), calculatePercentiles(memoryValues))
+}
@@ -15,29 +15,74 @@ import (
 
 // Balancer represents the load balancer.
 type Balancer struct {
-	client  proxmox.ClientInterface
-	config  *config.Config
-	engine  *rules.Engine
-	lastRun time.Time
+	client        proxmox.ClientInterface
+	config        *config.Config
+	engine        *rules.Engine
+	lastRun       time.Time
+	lastCycle     time.Time // Start of the last cycle, balancing or not
+	unschedulable *unschedulableTracker
+	skipped       *skipLog
+	observations  nodeObservations
+	phases        *phaseLog
+	capacityAlarm *capacityAlarm
+	sla           *slaAlarm
+	overloads     *overloadState
+	nodeCount     *nodeCountGuard
+	breakIn       *breakIn
 }
 
 // NewBalancer creates a new load balancer.
 func NewBalancer(client proxmox.ClientInterface, cfg *config.Config) *Balancer {
 	return &Balancer{
-		client:  client,
-		config:  cfg,
-		engine:  rules.NewEngine(),
-		lastRun: time.Time{},
+		client:        client,
+		config:        cfg,
+		engine:        newRulesEngine(cfg),
+		lastRun:       time.Time{},
+		unschedulable: newUnschedulableTracker(),
+		skipped:       &skipLog{},
+		observations:  make(nodeObservations),
+		phases:        &phaseLog{},
+		capacityAlarm: &capacityAlarm{},
+		sla:           &slaAlarm{},
+		overloads:     newOverloadState(),
+		nodeCount:     &nodeCountGuard{},
+		breakIn:       &breakIn{},
 	}
 }
 
+// newRulesEngine creates a rules engine honoring the configured VM exclusions.
+func newRulesEngine(cfg *config.Config) *rules.Engine {
+	engine := rules.NewEngine()
+	engine.SetExcludedVMIDs(cfg.Balancing.ExcludeVMIDs)
+	engine.SetNodeZones(cfg.Cluster.NodeZones())
+	return engine
+}
+
 // Run performs a load balancing cycle.
 func (b *Balancer) Run(force bool) ([]models.BalancingResult, error) {
+	b.lastCycle = time.Now()
+
+	// Balancing is disabled until the configured start date, even when forced
+	if balancingDeferred(b.config, time.Now()) {
+		return nil, nil
+	}
+
+	deadline := cycleDeadline(b.config, time.Now())
+	timer := newPhaseTimer()
+	defer b.phases.record(timer, b.config.Logging.Level == "debug")
+
 	// Get current cluster state
 	nodes, err := b.client.GetNodes()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get nodes: %w", err)
 	}
+	applyKSMSavings(nodes)
+	timer.mark(phaseGetNodes)
+
+	// Nodes vanishing all at once are more likely an API glitch than reality: don't act on it
+	if b.nodeCount.suddenDrop(b.config, len(nodes)) {
+		return nil, nil
+	}
 
 	// Filter out maintenance nodes
 	availableNodes := b.filterAvailableNodes(nodes)
@@ -53,29 +98,69 @@ func (b *Balancer) Run(force bool) ([]models.BalancingResult, error) {
 	}
 
 	// Process rules
+	b.engine.SetNodePCIMappings(nodes)
 	if err := b.engine.ProcessVMs(allVMs); err != nil {
 		return nil, fmt.Errorf("failed to process VM rules: %w", err)
 	}
+	logRuleConflicts(b.engine, availableNodes)
+	timer.mark(phaseRules)
 
-	// Check if balancing is needed
-	if !force && !b.needsBalancing(nodes) {
+	// With every node above its thresholds no migration can help: alert instead of finding no target
+	if b.capacityAlarm.check(b.config, availableNodes) {
+		b.unschedulable.update(nil, time.Now())
+		b.skipped.set(nil)
+		return nil, nil
+	}
+
+	// Nodes beyond the imbalance SLA need relief, even below their thresholds
+	breaches := b.sla.check(b.config, availableNodes)
+
+	// Check if balancing is needed; only an "always" force balances a balanced cluster, and never
+	// one within the tolerance band. New VMs that landed on the wrong node are placed regardless
+	always := forcedAlways(b.config, force)
+	landed := hasMisplacedNewVMs(b.config, b.engine, nodes)
+	if !landed && len(breaches) == 0 && (withinTolerance(b.config, availableNodes) || (!always && !b.needsBalancing(nodes))) {
+		b.unschedulable.update(nil, time.Now())
+		b.skipped.set(nil)
+		if force {
+			logf("Cluster already balanced, nothing to re-evaluate\n")
+		}
 		return nil, nil
 	}
 
 	// Calculate node scores
 	nodeScores := b.calculateNodeScores(availableNodes)
+	timer.mark(phaseScoring)
 
 	// Find VMs that need to be moved
-	migrations := b.findMigrations(nodes, nodeScores)
+	migrations := b.findMigrations(nodes, nodeScores, always)
+	estimateMigrationDurations(b.config, migrations)
+
+	// A new deployment runs a single migration per cycle until its break-in ends
+	migrations, deferred := b.breakIn.limit(b.config, migrations, time.Now())
+	b.skipped.add(deferred...)
+	timer.mark(phasePlanning)
 
 	// Execute migrations
 	var results []models.BalancingResult
 	for i := range migrations {
+		if i > 0 && !migrationPause(b.config, deadline, len(migrations)-i) {
+			break
+		}
+		if budgetExhausted(deadline, len(migrations)-i) {
+			break
+		}
 		result := b.executeMigration(&migrations[i])
 		results = append(results, result)
 	}
+	timer.mark(phaseExecution)
+	b.breakIn.completed(b.config, results, time.Now())
+	window, _ := b.config.GetObservationWindow() //nolint:errcheck // validated at load time
+	b.observations.record(results, window, time.Now())
 
-	b.lastRun = time.Now()
+	if !b.config.ReadOnly {
+		b.lastRun = time.Now()
+	}
 	return results, nil
 }
 
@@ -93,6 +178,18 @@ func (b *Balancer) filterAvailableNodes(nodes []models.Node) []models.Node {
 	return available
 }
 
+// logRuleConflicts reports contradictory placement rules instead of silently finding no valid targets.
+func logRuleConflicts(engine *rules.Engine, availableNodes []models.Node) {
+	nodeNames := make([]string, 0, len(availableNodes))
+	for i := range availableNodes {
+		nodeNames = append(nodeNames, availableNodes[i].Name)
+	}
+
+	for _, conflict := range engine.DetectConflicts(nodeNames) {
+		logf("Warning: rule conflict (%s): %s\n", conflict.Type, conflict.Message)
+	}
+}
+
 // isInMaintenance checks if a node is in maintenance mode.
 func (b *Balancer) isInMaintenance(nodeName string) bool {
 	for _, maintenanceNode := range b.config.Cluster.MaintenanceNodes {
@@ -103,21 +200,21 @@ func (b *Balancer) isInMaintenance(nodeName string) bool {
 	return false
 }
 
-// needsBalancing checks if the cluster needs balancing.
+// needsBalancing checks if the cluster needs balancing. Every node is evaluated, keeping
+// their hysteresis state current.
 func (b *Balancer) needsBalancing(nodes []models.Node) bool {
+	needed := false
 	for i := range nodes {
 		node := &nodes[i]
 		if b.isInMaintenance(node.Name) {
 			continue
 		}
 
-		if node.CPU.Usage > float32(b.config.Balancing.Thresholds.CPU) ||
-			node.Memory.Usage > float32(b.config.Balancing.Thresholds.Memory) ||
-			node.Storage.Usage > float32(b.config.Balancing.Thresholds.Storage) {
-			return true
+		if b.overloads.overloaded(b.config, node) {
+			needed = true
 		}
 	}
-	return false
+	return needed
 }
 
 // calculateNodeScores calculates scores for all nodes.
@@ -167,8 +264,14 @@ func (b *Balancer) calculateNodeScore(node *models.Node) models.NodeScore {
 }
 
 // findMigrations finds VMs that should be migrated.
-func (b *Balancer) findMigrations(nodes []models.Node, nodeScores []models.NodeScore) []models.Migration {
+// With always set and no node over a threshold, the most loaded node is drained instead.
+// VMs on overloaded nodes without any valid target are tracked as unschedulable,
+// and every VM evaluated but left in place is logged with the reason.
+func (b *Balancer) findMigrations(nodes []models.Node, nodeScores []models.NodeScore, always bool) []models.Migration {
 	var migrations []models.Migration
+	var unschedulable []models.UnschedulableVM
+	var skipped []models.SkippedVM
+	defer func() { b.skipped.set(withoutPlanned(skipped, migrations)) }()
 
 	// Find overloaded nodes (source nodes)
 	var sourceNodes []models.Node
@@ -178,32 +281,106 @@ func (b *Balancer) findMigrations(nodes []models.Node, nodeScores []models.NodeS
 			continue
 		}
 
-		if node.CPU.Usage > float32(b.config.Balancing.Thresholds.CPU) ||
-			node.Memory.Usage > float32(b.config.Balancing.Thresholds.Memory) ||
-			node.Storage.Usage > float32(b.config.Balancing.Thresholds.Storage) {
+		if b.overloads.overloaded(b.config, node) {
 			sourceNodes = append(sourceNodes, *node)
 		}
 	}
 
+	// Nodes beyond the imbalance SLA are relieved first, worst first
+	sourceNodes = slaFirst(slaBreaches(b.config, b.filterAvailableNodes(nodes)), nodes, sourceNodes)
+	overloaded := len(sourceNodes) > 0
+	if !overloaded && always {
+		sourceNodes = mostLoadedNode(nodes, nodeScores)
+	}
+	sourceNodes = filterSourceRoles(b.config, sourceNodes)
+
+	// Recently rebooted nodes, and nodes failing their health check, can't receive VMs yet
+	minUptime, _ := b.config.GetMinTargetUptime() //nolint:errcheck // validated at load time
+	targets := filterHealthyTargets(b.config, filterTargetScores(nodes, nodeScores, minUptime))
+
+	// Nodes involved in a recent migration are left alone until their metrics settle
+	sourceNodes, targets = b.observations.exclude(sourceNodes, targets, time.Now())
+
+	// During rolling upgrades, VMs only move between nodes on the same major version
+	versions := nodeVersions(b.config, nodes)
+
+	// New VMs that landed on the wrong node move first, whatever the load of their node
+	migrations = landingMigrations(b.config, b.engine, nodes, filterSourceRoles(b.config, nodes), filterTargetRoles(b.config, targets), versions, nil)
+	landing := plannedVMs(migrations)
+
 	// For each overloaded node, find VMs to migrate
 	for i := range sourceNodes {
 		sourceNode := &sourceNodes[i]
-		for j := range sourceNode.VMs {
-			vm := &sourceNode.VMs[j]
-			// Skip ignored VMs
+		sourceTargets := filterVersionTargets(versions, sourceNode.Name, targets)
+		candidates := movePreferredLast(b.engine, sourceNode.Name, orderByPoolPolicy(b.config, sourceNode.VMs))
+		for j := range candidates {
+			vm := &candidates[j]
+			if landing[vm.ID] {
+				continue
+			}
+			// Skip ignored VMs, and those whose VMID is ambiguous
+			if b.engine.IsDuplicate(vm.ID) {
+				skipped = append(skipped, skippedVM(vm, sourceNode.Name, skipDuplicate))
+				continue
+			}
 			if b.engine.IsIgnored(vm.ID) {
+				skipped = append(skipped, governedBy(skippedVM(vm, sourceNode.Name, skipIgnored), rules.RuleIgnore))
+				continue
+			}
+			if b.engine.IsFrozen(vm.ID, time.Now()) {
+				skipped = append(skipped, skippedVM(vm, sourceNode.Name, skipFrozen))
+				continue
+			}
+			if heldSuspended(b.config, vm) {
+				skipped = append(skipped, skippedVM(vm, sourceNode.Name, skipSuspended))
+				continue
+			}
+			if inBackupWindow(b.config, vm, time.Now()) {
+				skipped = append(skipped, skippedVM(vm, sourceNode.Name, skipBackup))
+				continue
+			}
+			if heldPassthrough(b.config, vm) {
+				skipped = append(skipped, governedBy(skippedVM(vm, sourceNode.Name, skipPassthrough), rules.RulePassthrough))
+				continue
+			}
+			if downtime, exceeded := downtimeExceeded(b.config, vm); exceeded {
+				skipped = append(skipped, skippedVM(vm, sourceNode.Name, skipDowntime, downtime, vm.MigrateDowntime))
 				continue
 			}
 
+			// The target needs room for the VM's CPU demand, relative to its own core count, and for its
+			// local disks, within the overcommit limits
+			vmTargets := filterCPUFitTargets(vm, nodes, sourceTargets, float64(b.config.Balancing.Thresholds.CPU))
+			vmTargets = filterStorageFitTargets(vm, nodes, vmTargets, float64(b.config.Balancing.Thresholds.Storage))
+			vmTargets = filterOvercommitTargets(b.config.Balancing.Overcommit, vm, nodes, vmTargets)
+
 			// Find best target node
-			targetNode := b.findBestTargetNode(vm, nodeScores)
+			targetNode := b.findBestTargetNode(vm, vmTargets)
 			if targetNode == "" {
+				stuck := newUnschedulableVM(b.engine, vm, sourceNode.Name, vmTargets)
+				if overloaded {
+					unschedulable = append(unschedulable, stuck)
+				}
+				skipped = append(skipped, governedBy(skippedVM(vm, sourceNode.Name, skipNoTarget, stuck.Reason),
+					excludingRules(b.engine, vm, sourceNode.Name, vmTargets)...))
 				continue
 			}
 
-			// Calculate resource gain
+			// Calculate resource gain; fixing a broken rule only needs the rule correction gain (in points)
 			gain := b.calculateResourceGain(sourceNode.Name, targetNode, nodeScores)
-			if gain <= 0 {
+			if ruleCorrection(b.config, b.engine, vm, sourceNode.Name) {
+				if minGain := b.config.Balancing.RuleCorrections.MinGain; gain*100 < minGain {
+					skipped = append(skipped, skippedVM(vm, sourceNode.Name, skipLowGain, gain*100, minGain))
+					continue
+				}
+			} else if gain <= 0 {
+				skipped = append(skipped, skippedVM(vm, sourceNode.Name, skipNoGain))
+				continue
+			}
+
+			// Protected VMs need a larger gain (scores here are fractions, the setting is in points)
+			if !protectedGainMet(b.config, vm, gain*100) {
+				skipped = append(skipped, skippedVM(vm, sourceNode.Name, skipProtected))
 				continue
 			}
 
@@ -211,6 +388,8 @@ func (b *Balancer) findMigrations(nodes []models.Node, nodeScores []models.NodeS
 				VM:        *vm,
 				FromNode:  sourceNode.Name,
 				ToNode:    targetNode,
+				Gain:      gain,
+				Freed:     freedResources(vm, sourceNode),
 				Status:    "pending",
 				StartTime: time.Now(),
 			}
@@ -219,11 +398,34 @@ func (b *Balancer) findMigrations(nodes []models.Node, nodeScores []models.NodeS
 		}
 	}
 
+	// Stopped or idle VMs bring no gain, move them only to fix their placement
+	if b.config.Balancing.ZeroFootprint == config.ZeroFootprintRules {
+		migrations = append(migrations, ruleComplianceMigrations(b.config, b.engine, nodes, sourceNodes, filterTargetRoles(b.config, targets), versions, migrations, nil)...)
+	}
+
+	b.unschedulable.update(unschedulable, time.Now())
 	return migrations
 }
 
+// GetUnschedulableVMs returns the VMs that should move but had no valid target in the last cycle.
+func (b *Balancer) GetUnschedulableVMs() []models.UnschedulableVM {
+	return b.unschedulable.list()
+}
+
+// GetSkippedVMs returns the VMs evaluated but left in place in the last cycle, with the reasons.
+func (b *Balancer) GetSkippedVMs() []models.SkippedVM {
+	return b.skipped.list()
+}
+
+// GetPhaseTimings returns the time the last cycle spent in each of its phases.
+func (b *Balancer) GetPhaseTimings() []models.PhaseTiming {
+	return b.phases.list()
+}
+
 // findBestTargetNode finds the best target node for a VM.
 func (b *Balancer) findBestTargetNode(vm *models.VM, nodeScores []models.NodeScore) string {
+	nodeScores = filterTargetRoles(b.config, nodeScores)
+
 	// Get valid target nodes
 	var validNodes []string
 	for _, score := range nodeScores {
@@ -238,6 +440,13 @@ func (b *Balancer) findBestTargetNode(vm *models.VM, nodeScores []models.NodeSco
 		return ""
 	}
 
+	// A preferred or replica node wins over the score unless it is overloaded (scores here are fractions)
+	if preferred := preferredTarget(b.engine, vm, validNodes, nodeScores, func(score *models.NodeScore) bool {
+		return overThresholds(b.config, score.CPU*100, score.Memory*100, score.Storage*100)
+	}); preferred != "" {
+		return preferred
+	}
+
 	// Return the node with the best score
 	for _, score := range nodeScores {
 		for _, validNode := range validNodes {
@@ -250,6 +459,574 @@ func (b *Balancer) findBestTargetNode(vm *models.VM, nodeScores []models.NodeSco
 	return ""
 }
 
+// filterSourceRoles drops the nodes whose role forbids migrating VMs off them.
+func filterSourceRoles(cfg *config.Config, nodes []models.Node) []models.Node {
+	var sources []models.Node
+	for i := range nodes {
+		if cfg.Cluster.CanBeSource(nodes[i].Name) {
+			sources = append(sources, nodes[i])
+		}
+	}
+	return sources
+}
+
+// filterTargetRoles drops the nodes whose role forbids migrating VMs onto them.
+func filterTargetRoles(cfg *config.Config, nodeScores []models.NodeScore) []models.NodeScore {
+	var targets []models.NodeScore
+	for _, score := range nodeScores {
+		if cfg.Cluster.CanBeTarget(score.Node) {
+			targets = append(targets, score)
+		}
+	}
+	return targets
+}
+
+// overThresholds reports whether any resource usage, in percent, exceeds its configured threshold.
+func overThresholds(cfg *config.Config, cpu, memory, storage float32) bool {
+	return cpu > float32(cfg.Balancing.Thresholds.CPU) ||
+		memory > float32(cfg.Balancing.Thresholds.Memory) ||
+		storage > float32(cfg.Balancing.Thresholds.Storage)
+}
+
+// preferredTarget returns the first of the VM's preferred nodes (plb_prefer_ tags), then of its
+// replica nodes, that is a valid, not overloaded target, or "" to fall back to the best score.
+func preferredTarget(engine *rules.Engine, vm *models.VM, validNodes []string, nodeScores []models.NodeScore, overloaded func(score *models.NodeScore) bool) string {
+	candidates := append(append([]string{}, engine.GetPreferredNodes(vm.ID)...), vm.ReplicaNodes...)
+	for _, preferred := range candidates {
+		valid := false
+		for _, validNode := range validNodes {
+			if validNode == preferred {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		for i := range nodeScores {
+			if nodeScores[i].Node == preferred && !overloaded(&nodeScores[i]) {
+				return preferred
+			}
+		}
+	}
+	return ""
+}
+
+// movePreferredLast orders a node's VMs so those already on a preferred node are considered last,
+// keeping them there when moving others is enough.
+func movePreferredLast(engine *rules.Engine, nodeName string, vms []models.VM) []models.VM {
+	ordered := make([]models.VM, len(vms))
+	copy(ordered, vms)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return !engine.IsPreferredNode(ordered[i].ID, nodeName) && engine.IsPreferredNode(ordered[j].ID, nodeName)
+	})
+	return ordered
+}
+
+// poolPolicyRank orders VMs by their pool policy: movable pools first, protected pools last.
+func poolPolicyRank(cfg *config.Config, vm *models.VM) int {
+	switch cfg.Balancing.PoolPolicy(vm.Pool) {
+	case config.PoolPolicyMovable:
+		return 0
+	case config.PoolPolicyProtected:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// orderByPoolPolicy returns the VMs with those of movable pools first and those of protected pools
+// last, keeping their order otherwise.
+func orderByPoolPolicy(cfg *config.Config, vms []models.VM) []models.VM {
+	ordered := make([]models.VM, len(vms))
+	copy(ordered, vms)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return poolPolicyRank(cfg, &ordered[i]) < poolPolicyRank(cfg, &ordered[j])
+	})
+	return ordered
+}
+
+// idleCPUThreshold is the CPU usage, as a fraction of the VM's vCPUs, below which a running VM is idle.
+const idleCPUThreshold = 0.01
+
+// zeroFootprint reports whether a VM is stopped or idle, so moving it frees next to nothing.
+func zeroFootprint(vm *models.VM) bool {
+	return vm.Status != "running" || vm.CPU < idleCPUThreshold
+}
+
+// heldSuspended reports whether a VM is paused or suspended and suspended_vms keeps such VMs in
+// place. Their memory state can't be live-migrated; with "offline" they move like stopped VMs.
+func heldSuspended(cfg *config.Config, vm *models.VM) bool {
+	suspended := vm.Status == models.VMStatusPaused || vm.Status == models.VMStatusSuspended
+	return suspended && cfg.Balancing.SuspendedVMs != config.SuspendedVMsOffline
+}
+
+// heldPassthrough reports whether a VM's PCI passthrough devices keep it in place: a raw host
+// device ties it to its node, and with passthrough "skip" any device does. Otherwise VMs using
+// resource mappings may move to nodes with the mapped devices, which the rules engine enforces.
+func heldPassthrough(cfg *config.Config, vm *models.VM) bool {
+	if vm.PassthroughPinned {
+		return true
+	}
+	return len(vm.Passthrough) > 0 && cfg.Balancing.Passthrough == config.PassthroughSkip
+}
+
+// ruleComplianceMigrations plans moves for the zero-footprint VMs of the source nodes whose current
+// placement breaks a rule (e.g. split from their affinity group), to the best valid target.
+// No gain is required since they cost nothing to host. VMs already planned are skipped,
+// as are those the optional eligible check rejects.
+func ruleComplianceMigrations(cfg *config.Config, engine *rules.Engine, nodes, sourceNodes []models.Node, targets []models.NodeScore, versions map[string]string, planned []models.Migration, eligible func(vm *models.VM) bool) []models.Migration {
+	return placementMigrations(cfg, engine, nodes, sourceNodes, targets, versions, planned, "idle", func(vm *models.VM) bool {
+		return zeroFootprint(vm) && (eligible == nil || eligible(vm))
+	})
+}
+
+// landedVM reports whether a VM was created within the new VM window and never migrated since,
+// so it still sits on the node it landed on.
+func landedVM(vm *models.VM, window time.Duration, now time.Time) bool {
+	return window > 0 && vm.LastMoved.IsZero() && !vm.Created.IsZero() && now.Sub(vm.Created) <= window
+}
+
+// landingMigrations plans moves for the newly landed VMs of the source nodes whose placement breaks
+// a rule (e.g. pinned to another node), to the best valid target. They move whatever the load.
+func landingMigrations(cfg *config.Config, engine *rules.Engine, nodes, sourceNodes []models.Node, targets []models.NodeScore, versions map[string]string, planned []models.Migration) []models.Migration {
+	window, _ := cfg.GetNewVMWindow() //nolint:errcheck // validated at load time
+	if window == 0 {
+		return nil
+	}
+	now := time.Now()
+	return placementMigrations(cfg, engine, nodes, sourceNodes, targets, versions, planned, "new", func(vm *models.VM) bool {
+		return landedVM(vm, window, now)
+	})
+}
+
+// hasMisplacedNewVMs reports whether a newly landed VM breaks a placement rule on its node,
+// which calls for a cycle even on a balanced cluster.
+func hasMisplacedNewVMs(cfg *config.Config, engine *rules.Engine, nodes []models.Node) bool {
+	window, _ := cfg.GetNewVMWindow() //nolint:errcheck // validated at load time
+	if window == 0 {
+		return false
+	}
+	now := time.Now()
+	for i := range nodes {
+		for j := range nodes[i].VMs {
+			vm := &nodes[i].VMs[j]
+			if landedVM(vm, window, now) && !engine.IsIgnored(vm.ID) && engine.ValidatePlacement(vm, nodes[i].Name) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// plannedVMs returns the IDs of the VMs the migrations move.
+func plannedVMs(migrations []models.Migration) map[int]bool {
+	planned := make(map[int]bool, len(migrations))
+	for i := range migrations {
+		planned[migrations[i].VM.ID] = true
+	}
+	return planned
+}
+
+// placementMigrations plans moves for the VMs of the source nodes that the eligible check accepts
+// and whose current placement breaks a rule, to the best valid target. Members of a split affinity
+// group go to the node the group is consolidated on, or stay when it has no room for them.
+// The kind of VM is logged. Ignored, frozen, held suspended, backed up, held passthrough and
+// already planned VMs are skipped, as are VMs whose migration would exceed their migrate_downtime.
+func placementMigrations(cfg *config.Config, engine *rules.Engine, nodes, sourceNodes []models.Node, targets []models.NodeScore, versions map[string]string, planned []models.Migration, kind string, eligible func(vm *models.VM) bool) []models.Migration {
+	moving := plannedVMs(planned)
+	consolidation := affinityConsolidation(cfg, engine, nodes, targets)
+
+	var migrations []models.Migration
+	for i := range sourceNodes {
+		sourceNode := &sourceNodes[i]
+		sourceTargets := filterVersionTargets(versions, sourceNode.Name, targets)
+
+		var candidates []string
+		for _, score := range sourceTargets {
+			if score.Node != sourceNode.Name {
+				candidates = append(candidates, score.Node)
+			}
+		}
+
+		for j := range sourceNode.VMs {
+			vm := &sourceNode.VMs[j]
+			if moving[vm.ID] || engine.IsIgnored(vm.ID) || engine.IsFrozen(vm.ID, time.Now()) || heldSuspended(cfg, vm) || inBackupWindow(cfg, vm, time.Now()) || heldPassthrough(cfg, vm) || !eligible(vm) {
+				continue
+			}
+			if _, exceeded := downtimeExceeded(cfg, vm); exceeded {
+				continue
+			}
+			if engine.ValidatePlacement(vm, sourceNode.Name) == nil {
+				continue
+			}
+
+			validNodes := engine.GetValidTargetNodes(vm, candidates)
+			if len(validNodes) == 0 {
+				continue
+			}
+
+			// Targets are ordered best first
+			targetNode := ""
+			for _, score := range sourceTargets {
+				for _, validNode := range validNodes {
+					if score.Node == validNode {
+						targetNode = validNode
+						break
+					}
+				}
+				if targetNode != "" {
+					break
+				}
+			}
+			if groupNode, grouped := consolidation[vm.ID]; grouped {
+				targetNode = ""
+				for _, validNode := range validNodes {
+					if validNode == groupNode && groupNode != sourceNode.Name {
+						targetNode = groupNode
+					}
+				}
+				if targetNode == "" {
+					continue
+				}
+			}
+
+			logf("Relocating %s VM %s (%d) from %s to %s for rule compliance\n", kind, vm.Name, vm.ID, sourceNode.Name, targetNode)
+			migrations = append(migrations, models.Migration{
+				VM:        *vm,
+				FromNode:  sourceNode.Name,
+				ToNode:    targetNode,
+				Freed:     freedResources(vm, sourceNode),
+				Status:    "pending",
+				StartTime: time.Now(),
+			})
+			moving[vm.ID] = true
+		}
+	}
+
+	return migrations
+}
+
+// ruleCorrection reports whether moving the VM off its node fixes a broken placement rule
+// and rule corrections are enabled. Ignored VMs are never corrected.
+func ruleCorrection(cfg *config.Config, engine *rules.Engine, vm *models.VM, node string) bool {
+	return cfg.Balancing.RuleCorrections.Enabled && !engine.IsIgnored(vm.ID) && engine.ValidatePlacement(vm, node) != nil
+}
+
+// forcedAlways reports whether a forced cycle balances regardless of thresholds and minimum gain.
+// Any other force mode only bypasses the cooldown.
+func forcedAlways(cfg *config.Config, force bool) bool {
+	return force && cfg.Balancing.ForceMode != config.ForceModeReevaluate
+}
+
+// withinTolerance reports whether every node's CPU and memory usage lies within the configured
+// tolerance band around the cluster average, so the cluster is balanced enough to leave alone.
+func withinTolerance(cfg *config.Config, nodes []models.Node) bool {
+	tolerance := cfg.Balancing.Tolerance
+	if tolerance <= 0 || len(nodes) == 0 {
+		return false
+	}
+
+	var cpuTotal, memoryTotal float64
+	for i := range nodes {
+		cpuTotal += float64(nodes[i].CPU.Usage)
+		memoryTotal += float64(nodes[i].Memory.Usage)
+	}
+	cpuMean := cpuTotal / float64(len(nodes))
+	memoryMean := memoryTotal / float64(len(nodes))
+
+	for i := range nodes {
+		if math.Abs(float64(nodes[i].CPU.Usage)-cpuMean) > tolerance ||
+			math.Abs(float64(nodes[i].Memory.Usage)-memoryMean) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// protectedGainMet reports whether a gain, in percentage points, justifies moving the VM.
+// Protected VMs (protection flag, boot ordering or protected pool) must clear the configured minimum gain.
+func protectedGainMet(cfg *config.Config, vm *models.VM, gain float64) bool {
+	protected := vm.Protected || cfg.Balancing.PoolPolicy(vm.Pool) == config.PoolPolicyProtected
+	return !protected || gain >= cfg.Balancing.ProtectedMinGain
+}
+
+// mostLoadedNode returns the node with the worst score, as a single source candidate.
+func mostLoadedNode(nodes []models.Node, nodeScores []models.NodeScore) []models.Node {
+	if len(nodeScores) == 0 {
+		return nil
+	}
+
+	worst := nodeScores[len(nodeScores)-1].Node
+	for i := range nodes {
+		if nodes[i].Name == worst {
+			return []models.Node{nodes[i]}
+		}
+	}
+	return nil
+}
+
+// balancingDeferred reports whether now is before the configured start date, logging the remaining wait.
+func balancingDeferred(cfg *config.Config, now time.Time) bool {
+	startAfter, _ := cfg.GetStartAfter() //nolint:errcheck // validated at load time
+	if startAfter.IsZero() || !now.Before(startAfter) {
+		return false
+	}
+	logf("Balancing disabled until %s (%v remaining)\n",
+		startAfter.Format(time.RFC3339), startAfter.Sub(now).Round(time.Second))
+	return true
+}
+
+// nextRun returns when the cycle after one started at last is due: an interval later, or the first
+// cycle on or after the start date when balancing is deferred, as earlier cycles do nothing.
+// It is zero before the first cycle.
+func nextRun(cfg *config.Config, last time.Time) time.Time {
+	interval, err := cfg.GetInterval()
+	if last.IsZero() || err != nil || interval <= 0 {
+		return time.Time{}
+	}
+
+	next := last.Add(interval)
+	startAfter, _ := cfg.GetStartAfter() //nolint:errcheck // validated at load time
+	if next.Before(startAfter) {
+		cycles := (startAfter.Sub(last) + interval - 1) / interval
+		next = last.Add(cycles * interval)
+	}
+	return next
+}
+
+// cycleDeadline returns when a cycle started at start must stop starting migrations,
+// or the zero time when no cycle budget is configured.
+func cycleDeadline(cfg *config.Config, start time.Time) time.Time {
+	budget, _ := cfg.GetCycleBudget() //nolint:errcheck // validated at load time
+	if budget <= 0 {
+		return time.Time{}
+	}
+	return start.Add(budget)
+}
+
+// budgetExhausted reports whether the cycle deadline has passed, deferring the remaining migrations.
+// In-flight migrations are never interrupted, this only gates starting new ones.
+func budgetExhausted(deadline time.Time, remaining int) bool {
+	if deadline.IsZero() || time.Now().Before(deadline) {
+		return false
+	}
+	logf("Cycle budget exhausted, deferring %d migration(s) to the next cycle\n", remaining)
+	return true
+}
+
+// sleep pauses between migrations; tests replace it to run without waiting.
+var sleep = time.Sleep
+
+// migrationPause waits the configured migration delay before starting the next migration, so the
+// previous one settles. It reports false, deferring the remaining migrations, when the delay would
+// run past the cycle deadline. Read-only cycles don't wait, nothing moved.
+func migrationPause(cfg *config.Config, deadline time.Time, remaining int) bool {
+	delay, _ := cfg.GetMigrationDelay() //nolint:errcheck // validated at load time
+	if delay <= 0 || cfg.ReadOnly {
+		return true
+	}
+	if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+		logf("Migration delay of %v would exceed the cycle budget, deferring %d migration(s) to the next cycle\n", delay, remaining)
+		return false
+	}
+	sleep(delay)
+	return true
+}
+
+// planMigrationWaves groups migrations into waves that respect the per-node concurrency limits.
+// Migrations keep their planned order; those that would exceed a limit move to a later wave.
+// Without limits every migration gets its own wave, so they run one at a time.
+func planMigrationWaves(migrations []models.Migration, limits config.MigrationConcurrencyConfig) [][]int {
+	var waves [][]int
+	if !limits.Enabled() {
+		for i := range migrations {
+			waves = append(waves, []int{i})
+		}
+		return waves
+	}
+
+	pending := make([]int, len(migrations))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		var wave, deferred []int
+		fromNode := make(map[string]int)
+		toNode := make(map[string]int)
+		for _, i := range pending {
+			migration := &migrations[i]
+			if (limits.PerSource > 0 && fromNode[migration.FromNode] >= limits.PerSource) ||
+				(limits.PerTarget > 0 && toNode[migration.ToNode] >= limits.PerTarget) {
+				deferred = append(deferred, i)
+				continue
+			}
+			fromNode[migration.FromNode]++
+			toNode[migration.ToNode]++
+			wave = append(wave, i)
+		}
+		waves = append(waves, wave)
+		pending = deferred
+	}
+
+	return waves
+}
+
+// filterTargetScores drops nodes that booted less than minUptime ago from the target candidates.
+// Nodes with unknown uptime are kept.
+func filterTargetScores(nodes []models.Node, nodeScores []models.NodeScore, minUptime time.Duration) []models.NodeScore {
+	if minUptime <= 0 {
+		return nodeScores
+	}
+
+	fresh := make(map[string]bool)
+	for i := range nodes {
+		node := &nodes[i]
+		uptime := time.Duration(node.Uptime) * time.Second
+		if node.Uptime > 0 && uptime < minUptime {
+			logf("Skipping node %s as migration target: up for %v (minimum %v)\n", node.Name, uptime, minUptime)
+			fresh[node.Name] = true
+		}
+	}
+
+	if len(fresh) == 0 {
+		return nodeScores
+	}
+
+	targets := make([]models.NodeScore, 0, len(nodeScores))
+	for _, score := range nodeScores {
+		if !fresh[score.Node] {
+			targets = append(targets, score)
+		}
+	}
+	return targets
+}
+
+// estimateCPURelief estimates the node CPU percentage freed by migrating a VM away.
+// The VM's contribution is capped by its cpulimit, as it can't consume more regardless of host load,
+// then scaled by its plb_weight_ tag.
+func estimateCPURelief(vm *models.VM, node *models.Node) float64 {
+	vcpus := vm.CPUs
+	if vcpus <= 0 {
+		vcpus = 1
+	}
+
+	usedCores := float64(vm.CPU) * float64(vcpus)
+	if vm.CPULimit > 0 && usedCores > vm.CPULimit {
+		usedCores = vm.CPULimit
+	}
+	usedCores *= rules.LoadWeight(vm)
+
+	if node.CPU.Cores <= 0 {
+		return usedCores * 100
+	}
+	return usedCores / float64(node.CPU.Cores) * 100
+}
+
+// storageMigration reports whether migrating the VM copies local disks along with its memory,
+// which takes far longer than a memory-only live migration.
+func storageMigration(vm *models.VM) bool {
+	return vm.MaxDisk > 0
+}
+
+// estimateMigrationDuration estimates how long copying the VM's memory, and its local disks on a
+// storage migration, takes at the configured migration bandwidth, rounded up to the second, or 0
+// without a bandwidth.
+func estimateMigrationDuration(cfg *config.Config, vm *models.VM) time.Duration {
+	copied := vm.MaxMemory
+	if copied <= 0 {
+		copied = vm.Memory
+	}
+	if storageMigration(vm) {
+		copied += diskFootprint(vm)
+	}
+	bandwidth := cfg.Balancing.MigrationBandwidth * 1024 * 1024
+	if bandwidth <= 0 || copied <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(float64(copied)/bandwidth)) * time.Second
+}
+
+// estimateMigrationDurations sets the estimated duration of each migration, and flags the storage migrations.
+func estimateMigrationDurations(cfg *config.Config, migrations []models.Migration) {
+	for i := range migrations {
+		migrations[i].EstimatedDuration = estimateMigrationDuration(cfg, &migrations[i].VM)
+		migrations[i].StorageMigration = storageMigration(&migrations[i].VM)
+	}
+}
+
+// freedResources estimates the share of the source node's CPU and memory, in percentage points,
+// moving the VM away frees, both scaled by the VM's load weight.
+func freedResources(vm *models.VM, source *models.Node) models.Resources {
+	freed := models.Resources{CPU: estimateCPURelief(vm, source)}
+	if source.Memory.Total > 0 {
+		freed.Memory = float64(vm.Memory) / float64(source.Memory.Total) * 100 * rules.LoadWeight(vm)
+	}
+	return freed
+}
+
+// projectedTargetCPU estimates the target node's CPU percentage once the VM runs there.
+// The VM's demand in cores is normalized against the target's core count (maxcpu), so the
+// same VM weighs more on a small node than on a large one.
+func projectedTargetCPU(vm *models.VM, target *models.Node) float64 {
+	return float64(target.CPU.Usage) + estimateCPURelief(vm, target)
+}
+
+// filterCPUFitTargets drops the target nodes that would cross the CPU threshold once the VM runs there.
+// Nodes with an unknown core count are kept, as the VM's share of them can't be estimated.
+func filterCPUFitTargets(vm *models.VM, nodes []models.Node, targets []models.NodeScore, threshold float64) []models.NodeScore {
+	nodesByName := make(map[string]*models.Node, len(nodes))
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
+	}
+
+	kept := make([]models.NodeScore, 0, len(targets))
+	for _, score := range targets {
+		node, exists := nodesByName[score.Node]
+		if exists && score.Node != vm.Node && node.CPU.Cores > 0 && projectedTargetCPU(vm, node) > threshold {
+			continue
+		}
+		kept = append(kept, score)
+	}
+	return kept
+}
+
+// diskFootprint returns the local storage a VM's disks may take on a target. A thin disk can grow
+// up to its configured size, so it counts in full rather than by its current usage.
+func diskFootprint(vm *models.VM) int64 {
+	if vm.ThinDisk {
+		return vm.MaxDisk
+	}
+	return vm.Disk
+}
+
+// filterStorageFitTargets drops the target nodes whose local storage would cross the storage threshold
+// once the VM's disks are copied there. Nodes with unknown storage are kept, as are VMs without local disks.
+func filterStorageFitTargets(vm *models.VM, nodes []models.Node, targets []models.NodeScore, threshold float64) []models.NodeScore {
+	footprint := diskFootprint(vm)
+	if footprint == 0 {
+		return targets
+	}
+
+	nodesByName := make(map[string]*models.Node, len(nodes))
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
+	}
+
+	kept := make([]models.NodeScore, 0, len(targets))
+	for _, score := range targets {
+		node, exists := nodesByName[score.Node]
+		if exists && score.Node != vm.Node && node.Storage.Total > 0 &&
+			float64(node.Storage.Used+footprint)/float64(node.Storage.Total)*100 > threshold {
+			continue
+		}
+		kept = append(kept, score)
+	}
+	return kept
+}
+
 // calculateResourceGain calculates the resource gain from migrating a VM.
 func (b *Balancer) calculateResourceGain(sourceNode, targetNode string, nodeScores []models.NodeScore) float64 {
 	var sourceScore, targetScore models.NodeScore
@@ -272,27 +1049,28 @@ func (b *Balancer) calculateResourceGain(sourceNode, targetNode string, nodeScor
 // executeMigration executes a VM migration.
 func (b *Balancer) executeMigration(migration *models.Migration) models.BalancingResult {
 	result := models.BalancingResult{
-		SourceNode: migration.FromNode,
-		TargetNode: migration.ToNode,
-		VM:         migration.VM,
-		Reason:     "load balancing",
-		Timestamp:  time.Now(),
-		Success:    false,
+		SourceNode:   migration.FromNode,
+		TargetNode:   migration.ToNode,
+		VM:           migration.VM,
+		Reason:       "load balancing",
+		Rules:        b.engine.GoverningRules(&migration.VM, migration.ToNode),
+		ResourceGain: migration.Gain,
+		Freed:        migration.Freed,
+		Timestamp:    time.Now(),
+		Success:      false,
+
+		EstimatedDuration: migration.EstimatedDuration,
+		StorageMigration:  migration.StorageMigration,
 	}
 
-	// Get current nodes for scoring
-	currentNodes, err := b.client.GetNodes()
-	if err != nil {
-		result.ErrorMessage = fmt.Sprintf("failed to get nodes for scoring: %v", err)
+	// Read-only mode publishes the plan without touching the cluster
+	if b.config.ReadOnly {
+		result.DryRun = true
 		return result
 	}
 
-	// Calculate resource gain
-	nodeScores := b.calculateNodeScores(currentNodes)
-	result.ResourceGain = b.calculateResourceGain(migration.FromNode, migration.ToNode, nodeScores)
-
 	// Execute migration
-	err = b.client.MigrateVM(migration.VM.ID, migration.FromNode, migration.ToNode)
+	err := b.client.MigrateVM(migration.VM.ID, migration.FromNode, migration.ToNode)
 	if err != nil {
 		result.ErrorMessage = err.Error()
 		return result
@@ -310,6 +1088,7 @@ func (b *Balancer) GetClusterStatus() (*models.ClusterStatus, error) {
 	}
 
 	status := &models.ClusterStatus{
+		Cluster:          b.config.Cluster.Name,
 		TotalNodes:       len(nodes),
 		ActiveNodes:      0,
 		TotalVMs:         0,
@@ -318,17 +1097,21 @@ func (b *Balancer) GetClusterStatus() (*models.ClusterStatus, error) {
 		AverageMemory:    0,
 		AverageStorage:   0,
 		LastBalanced:     b.lastRun,
+		LastRun:          b.lastCycle,
+		NextRun:          nextRun(b.config, b.lastCycle),
 		BalancingEnabled: true, // Always enabled when running
 	}
 
 	var totalCPU, totalMemory, totalStorage float64
 	var activeNodeCount int
+	var activeNodes []models.Node
 
 	for i := range nodes {
 		node := &nodes[i]
 		if !b.isInMaintenance(node.Name) {
 			status.ActiveNodes++
 			activeNodeCount++
+			activeNodes = append(activeNodes, *node)
 			totalCPU += float64(node.CPU.Usage)
 			totalMemory += float64(node.Memory.Usage)
 			totalStorage += float64(node.Storage.Usage)
@@ -347,6 +1130,9 @@ func (b *Balancer) GetClusterStatus() (*models.ClusterStatus, error) {
 		status.AverageCPU = float32(totalCPU / float64(activeNodeCount))
 		status.AverageMemory = float32(totalMemory / float64(activeNodeCount))
 		status.AverageStorage = float32(totalStorage / float64(activeNodeCount))
+		status.BalanceScore = balanceScoreOf(activeNodes)
+		status.OverCapacity = overCapacity(b.config, activeNodes)
+		status.SLABreaches = slaBreaches(b.config, activeNodes)
 	}
 
 	return status, nil
@@ -1,13 +1,22 @@
 package balancer
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/cblomart/GoProxLB/internal/config"
 	"github.com/cblomart/GoProxLB/internal/models"
 	"github.com/cblomart/GoProxLB/internal/proxmox"
+	"github.com/cblomart/GoProxLB/internal/raft"
 )
 
 // Mock client for testing.
@@ -18,6 +27,21 @@ type mockClient struct {
 	// For advanced balancer tests
 	historicalData   map[string][]proxmox.HistoricalMetric
 	vmHistoricalData map[string][]proxmox.HistoricalMetric
+
+	// Number of MigrateVM calls
+	migrateCalls int
+
+	// Simulated migration duration
+	migrateDelay time.Duration
+
+	// Per-VM migration errors, and the migrations attempted as "vmID:source->target"
+	migrateErrs map[int]error
+	migrated    []string
+
+	// Peak simultaneous migrations per source and target node
+	mu          sync.Mutex
+	inFlight    map[string]int
+	maxInFlight map[string]int
 }
 
 func (m *mockClient) GetClusterInfo() (*models.Cluster, error) {
@@ -29,6 +53,34 @@ func (m *mockClient) GetNodes() ([]models.Node, error) {
 }
 
 func (m *mockClient) MigrateVM(vmID int, sourceNode, targetNode string) error {
+	keys := []string{"from:" + sourceNode, "to:" + targetNode}
+
+	m.mu.Lock()
+	m.migrateCalls++
+	m.migrated = append(m.migrated, fmt.Sprintf("%d:%s->%s", vmID, sourceNode, targetNode))
+	if m.inFlight == nil {
+		m.inFlight = make(map[string]int)
+		m.maxInFlight = make(map[string]int)
+	}
+	for _, key := range keys {
+		m.inFlight[key]++
+		if m.inFlight[key] > m.maxInFlight[key] {
+			m.maxInFlight[key] = m.inFlight[key]
+		}
+	}
+	m.mu.Unlock()
+
+	time.Sleep(m.migrateDelay)
+
+	m.mu.Lock()
+	for _, key := range keys {
+		m.inFlight[key]--
+	}
+	m.mu.Unlock()
+
+	if err, failing := m.migrateErrs[vmID]; failing {
+		return err
+	}
 	return m.err
 }
 
@@ -314,7 +366,7 @@ func TestFindMigrations(t *testing.T) {
 	_ = balancer.engine.ProcessVMs(allVMs)
 
 	nodeScores := balancer.calculateNodeScores(client.nodes)
-	migrations := balancer.findMigrations(client.nodes, nodeScores)
+	migrations := balancer.findMigrations(client.nodes, nodeScores, false)
 
 	// Should find migrations from overloaded node1 to underloaded nodes
 	if len(migrations) == 0 {
@@ -446,6 +498,10 @@ func testStatusProperties(t *testing.T, status *models.ClusterStatus) {
 	if status.TotalVMs != 3 {
 		t.Errorf("Expected 3 total VMs, got %d", status.TotalVMs)
 	}
+
+	if status.Cluster != "test-cluster" {
+		t.Errorf("Expected cluster 'test-cluster', got %q", status.Cluster)
+	}
 }
 
 func TestAdvancedBalancerRun(t *testing.T) {
@@ -664,6 +720,16 @@ func TestAdvancedBalancerMigrationHistory(t *testing.T) {
 	if len(balancer.migrationHistory) != 1 {
 		t.Errorf("Expected 1 migration history entry, got %d", len(balancer.migrationHistory))
 	}
+
+	// The reported history is a copy
+	reported := balancer.GetMigrationHistory()
+	if len(reported) != 1 || !reflect.DeepEqual(reported[0], history) {
+		t.Fatalf("Expected the recorded migration reported, got %v", reported)
+	}
+	reported[0].ToNode = "node3"
+	if balancer.migrationHistory[0].ToNode != "node2" {
+		t.Error("Expected the reported history not to alias the balancer's")
+	}
 }
 
 func TestAdvancedBalancerStabilityScoring(t *testing.T) {
@@ -730,7 +796,7 @@ func TestAdvancedBalancerAntiFlipFlop(t *testing.T) {
 		Status: "running",
 	}
 
-	canMigrate := balancer.canMigrateVM(&vm, "node2")
+	canMigrate := balancer.canMigrateVM(&vm, "node2", false)
 	if canMigrate {
 		t.Error("Expected VM with recent migration to be blocked from migrating")
 	}
@@ -769,6 +835,50 @@ func TestAdvancedBalancerPercentileCalculation(t *testing.T) {
 	}
 }
 
+func TestInterpolatedPercentiles(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	cfg.Balancing.Capacity.Percentiles = config.PercentilesInterpolate
+	balancer := NewAdvancedBalancer(&mockClient{nodes: createTestNodes()}, cfg)
+
+	values := []float32{100, 90, 80, 70, 60, 50, 40, 30, 20, 10}
+	interpolated := balancer.calculatePercentiles(append([]float32(nil), values...))
+	rounded := calculatePercentiles(append([]float32(nil), values...))
+
+	// Between ranks, rounding picks a neighbouring sample: P50 of 10 to 100 is 60, not 55
+	tests := []struct {
+		name                  string
+		rounded, interpolated float32
+		wantRounded, want     float32
+	}{
+		{"P50", rounded.P50, interpolated.P50, 60, 55},
+		{"P90", rounded.P90, interpolated.P90, 90, 91},
+		{"P95", rounded.P95, interpolated.P95, 100, 95.5},
+		{"P99", rounded.P99, interpolated.P99, 100, 99.1},
+		{"P10", rounded.MinP90, interpolated.MinP90, 20, 19},
+	}
+	for _, tt := range tests {
+		if tt.rounded != tt.wantRounded {
+			t.Errorf("Expected rounded %s %.1f, got %.2f", tt.name, tt.wantRounded, tt.rounded)
+		}
+		if math.Abs(float64(tt.interpolated-tt.want)) > 0.001 {
+			t.Errorf("Expected interpolated %s %.2f, got %.4f", tt.name, tt.want, tt.interpolated)
+		}
+	}
+	if interpolated.Mean != rounded.Mean || interpolated.StdDev != rounded.StdDev {
+		t.Errorf("Expected the same mean and deviation, got %+v and %+v", interpolated, rounded)
+	}
+
+	// A single sample is every percentile
+	single := calculateInterpolatedPercentiles([]float32{42})
+	if single.P50 != 42 || single.P99 != 42 || single.MinP90 != 42 {
+		t.Errorf("Expected every percentile of a single sample to be 42, got %+v", single)
+	}
+	if empty := calculateInterpolatedPercentiles(nil); empty != (models.CapacityMetrics{}) {
+		t.Errorf("Expected no metrics without samples, got %+v", empty)
+	}
+}
+
 func TestAdvancedBalancerResourceGainCalculation(t *testing.T) {
 	client := &mockClient{
 		nodes: createTestNodes(),
@@ -828,6 +938,9 @@ func TestAdvancedBalancerGetClusterStatus(t *testing.T) {
 	if status.RunningVMs != 3 {
 		t.Errorf("Expected 3 running VMs, got %d", status.RunningVMs)
 	}
+	if status.Cluster != "test-cluster" {
+		t.Errorf("Expected cluster 'test-cluster', got %q", status.Cluster)
+	}
 }
 
 func TestAdvancedBalancerWithHistoricalDataError(t *testing.T) {
@@ -1151,3 +1264,4264 @@ func TestCapBufferValues(t *testing.T) {
 		t.Error("Expected CPU buffer to be non-negative after capping")
 	}
 }
+
+func TestEstimateCPURelief(t *testing.T) {
+	node := &models.Node{
+		Name: "node1",
+		CPU:  models.CPUInfo{Cores: 8, Usage: 90.0},
+	}
+
+	unlimited := &models.VM{ID: 100, CPU: 1.0, CPUs: 4}
+	limited := &models.VM{ID: 101, CPU: 1.0, CPUs: 4, CPULimit: 1}
+
+	rawRelief := estimateCPURelief(unlimited, node)
+	if rawRelief != 50.0 {
+		t.Errorf("Expected relief of 50%% for unlimited VM, got %.2f", rawRelief)
+	}
+
+	// The cpulimited VM can only ever use one core, so moving it frees less
+	limitedRelief := estimateCPURelief(limited, node)
+	if limitedRelief != 12.5 {
+		t.Errorf("Expected relief of 12.5%% for cpulimited VM, got %.2f", limitedRelief)
+	}
+	if limitedRelief >= rawRelief {
+		t.Errorf("Expected cpulimited VM relief (%.2f) to be lower than raw usage relief (%.2f)", limitedRelief, rawRelief)
+	}
+}
+
+func TestOrderMigrationCandidatesRespectsCPULimit(t *testing.T) {
+	node := &models.Node{
+		Name: "node1",
+		CPU:  models.CPUInfo{Cores: 8, Usage: 90.0},
+		VMs: []models.VM{
+			{ID: 100, Name: "limited", CPU: 1.0, CPUs: 4, CPULimit: 1},
+			{ID: 101, Name: "unlimited", CPU: 0.5, CPUs: 4},
+		},
+	}
+
+	balancer := NewAdvancedBalancer(&mockClient{}, createTestConfig())
+	candidates := balancer.orderMigrationCandidates(node)
+
+	if candidates[0].ID != 101 {
+		t.Errorf("Expected unlimited VM 101 to be the first candidate, got %d", candidates[0].ID)
+	}
+}
+
+func TestReadOnlyModeNeverMigrates(t *testing.T) {
+	advancedNodes := []models.Node{
+		{
+			Name:    "node1",
+			Status:  "online",
+			CPU:     models.CPUInfo{Usage: 90.0},
+			Memory:  models.MemoryInfo{Usage: 85.0},
+			Storage: models.StorageInfo{Usage: 80.0},
+			VMs: []models.VM{
+				{ID: 100, Name: "test-vm-1", Status: "running", Node: "node1", CPU: 50.0, Memory: 1024 * 1024 * 1024},
+			},
+		},
+		{
+			Name:    "node2",
+			Status:  "online",
+			CPU:     models.CPUInfo{Usage: 30.0},
+			Memory:  models.MemoryInfo{Usage: 25.0},
+			Storage: models.StorageInfo{Usage: 20.0},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		balancerType string
+		nodes        []models.Node
+	}{
+		{"threshold", "threshold", createTestNodes()},
+		{"advanced", "advanced", advancedNodes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClient{nodes: tt.nodes}
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = tt.balancerType
+			cfg.ReadOnly = true
+
+			var run func(force bool) ([]models.BalancingResult, error)
+			if tt.balancerType == "advanced" {
+				run = NewAdvancedBalancer(client, cfg).Run
+			} else {
+				run = NewBalancer(client, cfg).Run
+			}
+
+			// Run twice with force to make sure observer cycles keep publishing plans
+			for i := 0; i < 2; i++ {
+				results, err := run(true)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(results) == 0 {
+					t.Fatal("Expected planned migrations to be reported in read-only mode")
+				}
+				for _, result := range results {
+					if !result.DryRun {
+						t.Errorf("Expected result for VM %d to be marked dry-run", result.VM.ID)
+					}
+					if result.Success {
+						t.Errorf("Expected result for VM %d not to be reported as executed", result.VM.ID)
+					}
+				}
+			}
+
+			if client.migrateCalls != 0 {
+				t.Errorf("Expected no MigrateVM calls in read-only mode, got %d", client.migrateCalls)
+			}
+		})
+	}
+}
+
+func TestFilterTargetScores(t *testing.T) {
+	nodes := []models.Node{
+		{Name: "node1", Uptime: 30 * 24 * 3600},
+		{Name: "node2", Uptime: 120}, // Rebooted two minutes ago
+		{Name: "node3"},              // Unknown uptime
+	}
+	nodeScores := []models.NodeScore{
+		{Node: "node1", Score: 10},
+		{Node: "node2", Score: 20},
+		{Node: "node3", Score: 30},
+	}
+
+	tests := []struct {
+		name      string
+		minUptime time.Duration
+		expected  []string
+	}{
+		{"disabled", 0, []string{"node1", "node2", "node3"}},
+		{"fresh node skipped", 10 * time.Minute, []string{"node1", "node3"}},
+		{"below minimum kept", time.Minute, []string{"node1", "node2", "node3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets := filterTargetScores(nodes, nodeScores, tt.minUptime)
+			if len(targets) != len(tt.expected) {
+				t.Fatalf("Expected %d targets, got %d", len(tt.expected), len(targets))
+			}
+			for i, target := range targets {
+				if target.Node != tt.expected[i] {
+					t.Errorf("Expected target %d to be %s, got %s", i, tt.expected[i], target.Node)
+				}
+			}
+		})
+	}
+}
+
+func TestFindMigrationsSkipsRecentlyBootedTarget(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.MinTargetUptime = "10m"
+
+	nodes := createTestNodes()
+	nodes[1].Uptime = 60    // node2 just came back
+	nodes[2].Uptime = 86400 // node3 has been up for a day
+	client := &mockClient{nodes: nodes}
+	balancer := NewBalancer(client, cfg)
+
+	allVMs := []models.VM{}
+	for _, node := range nodes {
+		allVMs = append(allVMs, node.VMs...)
+	}
+	_ = balancer.engine.ProcessVMs(allVMs)
+
+	nodeScores := balancer.calculateNodeScores(nodes)
+	migrations := balancer.findMigrations(nodes, nodeScores, false)
+
+	if len(migrations) == 0 {
+		t.Fatal("Expected migrations to the node that has been up long enough")
+	}
+	for _, migration := range migrations {
+		if migration.ToNode == "node2" {
+			t.Errorf("Expected freshly booted node2 to be skipped, VM %d was sent there", migration.VM.ID)
+		}
+	}
+}
+
+func TestUnreliableNodePairIsDeprioritized(t *testing.T) {
+	cfg := createTestConfig()
+	balancer := NewAdvancedBalancer(&mockClient{}, cfg)
+	_ = balancer.engine.ProcessVMs(nil)
+
+	vm := models.VM{ID: 100, Name: "test-vm", Node: "node1", Status: "running"}
+	nodeScores := []models.NodeScore{
+		{Node: "node2", Score: 20.0}, // Best target on paper
+		{Node: "node3", Score: 30.0},
+		{Node: "node1", Score: 85.0},
+	}
+
+	if target := balancer.findBestTargetNode(&vm, nodeScores, "node1"); target != "node2" {
+		t.Fatalf("Expected node2 before any failures, got %s", target)
+	}
+
+	// Seed node1 -> node2 with repeated failures
+	for i := 0; i < 3; i++ {
+		balancer.updateMigrationHistory([]models.BalancingResult{{
+			SourceNode:   "node1",
+			TargetNode:   "node2",
+			VM:           vm,
+			Timestamp:    time.Now(),
+			Success:      false,
+			ErrorMessage: "storage unavailable",
+		}})
+	}
+
+	if rate := balancer.pairFailureRate("node1", "node2"); rate != 1.0 {
+		t.Errorf("Expected failure rate 1.0, got %.2f", rate)
+	}
+	if target := balancer.findBestTargetNode(&vm, nodeScores, "node1"); target != "node3" {
+		t.Errorf("Expected unreliable pair to be deprioritized in favor of node3, got %s", target)
+	}
+
+	// The reverse direction and other sources are unaffected
+	if rate := balancer.pairFailureRate("node3", "node2"); rate != 0 {
+		t.Errorf("Expected no failure rate for untested pair, got %.2f", rate)
+	}
+}
+
+func TestMigrationHistoryPersistence(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Raft.DataDir = t.TempDir()
+	lock, err := raft.AcquireDataDirLock(cfg.Raft.DataDir)
+	if err != nil {
+		t.Fatalf("Failed to lock data directory: %v", err)
+	}
+	defer func() { _ = lock.Release() }()
+
+	balancer := NewAdvancedBalancer(&mockClient{}, cfg)
+	balancer.updateMigrationHistory([]models.BalancingResult{
+		{SourceNode: "node1", TargetNode: "node2", VM: models.VM{ID: 100}, Timestamp: time.Now(), Success: true},
+		{SourceNode: "node1", TargetNode: "node3", VM: models.VM{ID: 101}, Timestamp: time.Now(), Success: false},
+		{SourceNode: "node1", TargetNode: "node3", VM: models.VM{ID: 102}, Timestamp: time.Now(), DryRun: true},
+	})
+
+	// A new balancer on the same data directory picks up the history
+	restored := NewAdvancedBalancer(&mockClient{}, cfg)
+
+	if len(restored.migrationHistory) != 1 {
+		t.Errorf("Expected 1 restored migration, got %d", len(restored.migrationHistory))
+	}
+
+	stats, exists := restored.pairStats[pairKey("node1", "node3")]
+	if !exists {
+		t.Fatal("Expected node1 -> node3 pair stats to be restored")
+	}
+	if stats.Failures != 1 || stats.Successes != 0 {
+		t.Errorf("Expected 1 failure and 0 successes (dry-run ignored), got %d/%d", stats.Failures, stats.Successes)
+	}
+	if stats := restored.pairStats[pairKey("node1", "node2")]; stats == nil || stats.Successes != 1 {
+		t.Error("Expected node1 -> node2 success to be restored")
+	}
+}
+
+func TestMigrationHistoryRequiresDataDirLock(t *testing.T) {
+	// The balancer never takes the data directory lock itself
+	cfg := createTestConfig()
+	cfg.Raft.DataDir = t.TempDir()
+	if balancer := NewAdvancedBalancer(&mockClient{}, cfg); balancer.historyLocked || raft.HoldsDataDirLock(cfg.Raft.DataDir) {
+		t.Error("Expected the balancer to leave the data directory unlocked")
+	}
+
+	// Under the app or a Raft node, the process already holds it
+	daemon := createTestConfig()
+	daemon.Raft.DataDir = t.TempDir()
+	lock, err := raft.AcquireDataDirLock(daemon.Raft.DataDir)
+	if err != nil {
+		t.Fatalf("Failed to lock data directory: %v", err)
+	}
+	defer func() { _ = lock.Release() }()
+	if balancer := NewAdvancedBalancer(&mockClient{}, daemon); !balancer.historyLocked {
+		t.Error("Expected the balancer to save history under the process's lock")
+	}
+
+	// Without the lock, another instance owns the history: it is not written
+	balancer := NewAdvancedBalancer(&mockClient{}, cfg)
+	balancer.updateMigrationHistory([]models.BalancingResult{
+		{SourceNode: "node1", TargetNode: "node2", VM: models.VM{ID: 100}, Timestamp: time.Now(), Success: true},
+	})
+	if _, err := os.Stat(filepath.Join(cfg.Raft.DataDir, migrationHistoryFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no history written without the data directory lock, got %v", err)
+	}
+}
+
+func TestDecisionMatrixEmitsSubScores(t *testing.T) {
+	matrixPath := filepath.Join(t.TempDir(), "decision-matrix.jsonl")
+
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	cfg.Logging.DecisionMatrix = matrixPath
+
+	nodes := createTestNodes()
+	balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+
+	// Two forced cycles produce two matrix lines
+	for i := 0; i < 2; i++ {
+		if _, err := balancer.Run(true); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	file, err := os.Open(matrixPath)
+	if err != nil {
+		t.Fatalf("Expected decision matrix file to be written: %v", err)
+	}
+	defer file.Close()
+
+	var matrices []models.DecisionMatrix
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var matrix models.DecisionMatrix
+		if err := json.Unmarshal(scanner.Bytes(), &matrix); err != nil {
+			t.Fatalf("Failed to decode decision matrix line: %v", err)
+		}
+		matrices = append(matrices, matrix)
+	}
+
+	if len(matrices) != 2 {
+		t.Fatalf("Expected 2 decision matrices, got %d", len(matrices))
+	}
+
+	matrix := matrices[0]
+	if len(matrix.Nodes) != len(nodes) {
+		t.Fatalf("Expected %d nodes in matrix, got %d", len(nodes), len(matrix.Nodes))
+	}
+
+	for _, breakdown := range matrix.Nodes {
+		if breakdown.Resource == 0 {
+			t.Errorf("Expected resource sub-score for %s", breakdown.Node)
+		}
+		expected := breakdown.Resource*0.4 + breakdown.Stability*0.2 + breakdown.Capacity*0.3 + breakdown.MigrationCost*0.1
+		if math.Abs(breakdown.Final-expected) > 1e-9 {
+			t.Errorf("Expected final score %.4f for %s to blend its sub-scores, got %.4f", expected, breakdown.Node, breakdown.Final)
+		}
+	}
+}
+
+func TestScoreWeightsChangeNodeOrdering(t *testing.T) {
+	nodes := []models.Node{
+		{
+			Name:    "busy-stable",
+			CPU:     models.CPUInfo{Usage: 40.0},
+			Memory:  models.MemoryInfo{Usage: 40.0},
+			Storage: models.StorageInfo{Usage: 40.0},
+		},
+		{
+			Name:    "idle-churning",
+			CPU:     models.CPUInfo{Usage: 30.0},
+			Memory:  models.MemoryInfo{Usage: 30.0},
+			Storage: models.StorageInfo{Usage: 30.0},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		weights  config.ScoreWeights
+		expected string
+	}{
+		{
+			name:     "resource only prefers the idle node",
+			weights:  config.ScoreWeights{Resource: 1.0, Current: 0.7, Predictive: 0.3},
+			expected: "idle-churning",
+		},
+		{
+			name:     "stability only prefers the node without recent migrations",
+			weights:  config.ScoreWeights{Stability: 1.0, Current: 0.7, Predictive: 0.3},
+			expected: "busy-stable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.ScoreWeights = tt.weights
+			balancer := NewAdvancedBalancer(&mockClient{}, cfg)
+
+			// The idle node has seen a lot of recent migrations
+			for i := 0; i < 3; i++ {
+				balancer.migrationHistory = append(balancer.migrationHistory, models.MigrationHistory{
+					VMID:      200 + i,
+					FromNode:  "node9",
+					ToNode:    "idle-churning",
+					Timestamp: time.Now(),
+				})
+			}
+
+			scores := balancer.calculateAdvancedNodeScores(nodes)
+			if scores[0].Node != tt.expected {
+				t.Errorf("Expected %s to rank first, got %s (scores: %+v)", tt.expected, scores[0].Node, scores)
+			}
+		})
+	}
+}
+
+func TestExcludedVMIDsAreNeverMigrated(t *testing.T) {
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		t.Run(balancerType, func(t *testing.T) {
+			nodes := createTestNodes()
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = balancerType
+			cfg.Balancing.ExcludeVMIDs = []int{100, 101}
+
+			// Make sure the excluded VMs would otherwise be eligible
+			for i := range nodes[0].VMs {
+				nodes[0].VMs[i].Tags = nil
+			}
+
+			var run func(force bool) ([]models.BalancingResult, error)
+			if balancerType == "advanced" {
+				run = NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg).Run
+			} else {
+				run = NewBalancer(&mockClient{nodes: nodes}, cfg).Run
+			}
+
+			results, err := run(true)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, result := range results {
+				if result.VM.ID == 100 || result.VM.ID == 101 {
+					t.Errorf("Expected excluded VM %d never to be migrated", result.VM.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestCycleBudgetStopsStartingMigrations(t *testing.T) {
+	t.Run("threshold", func(t *testing.T) {
+		cfg := createTestConfig()
+		cfg.Balancing.CycleBudget = "30ms"
+		client := &mockClient{nodes: createTestNodes(), migrateDelay: 50 * time.Millisecond}
+
+		results, err := NewBalancer(client, cfg).Run(true)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		// The first migration runs to completion, the second would start past the budget
+		if len(results) != 1 || client.migrateCalls != 1 {
+			t.Errorf("Expected budget to cut off after 1 migration, got %d results and %d calls", len(results), client.migrateCalls)
+		}
+	})
+
+	t.Run("advanced", func(t *testing.T) {
+		cfg := createTestConfig()
+		client := &mockClient{migrateDelay: 20 * time.Millisecond}
+		balancer := NewAdvancedBalancer(client, cfg)
+
+		migrations := []models.Migration{
+			{VM: models.VM{ID: 100}, FromNode: "node1", ToNode: "node2"},
+			{VM: models.VM{ID: 101}, FromNode: "node1", ToNode: "node2"},
+			{VM: models.VM{ID: 102}, FromNode: "node1", ToNode: "node3"},
+		}
+
+		results := balancer.executeMigrations(migrations, time.Now().Add(30*time.Millisecond))
+		if len(results) != 2 || client.migrateCalls != 2 {
+			t.Errorf("Expected budget to cut off after 2 migrations, got %d results and %d calls", len(results), client.migrateCalls)
+		}
+	})
+
+	t.Run("no budget", func(t *testing.T) {
+		cfg := createTestConfig()
+		client := &mockClient{}
+		balancer := NewAdvancedBalancer(client, cfg)
+
+		migrations := []models.Migration{
+			{VM: models.VM{ID: 100}, FromNode: "node1", ToNode: "node2"},
+			{VM: models.VM{ID: 101}, FromNode: "node1", ToNode: "node2"},
+		}
+
+		if results := balancer.executeMigrations(migrations, time.Time{}); len(results) != 2 {
+			t.Errorf("Expected all migrations without a budget, got %d", len(results))
+		}
+	})
+}
+
+// fakeSleep replaces the pause between migrations, recording the delays instead of waiting.
+func fakeSleep(t *testing.T) *[]time.Duration {
+	var slept []time.Duration
+	original := sleep
+	sleep = func(delay time.Duration) { slept = append(slept, delay) }
+	t.Cleanup(func() { sleep = original })
+	return &slept
+}
+
+func TestMigrationDelay(t *testing.T) {
+	migrations := []models.Migration{
+		{VM: models.VM{ID: 100}, FromNode: "node1", ToNode: "node2"},
+		{VM: models.VM{ID: 101}, FromNode: "node1", ToNode: "node2"},
+		{VM: models.VM{ID: 102}, FromNode: "node1", ToNode: "node3"},
+	}
+
+	t.Run("threshold", func(t *testing.T) {
+		slept := fakeSleep(t)
+		cfg := createTestConfig()
+		cfg.Balancing.MigrationDelay = "30s"
+		client := &mockClient{nodes: createTestNodes()}
+
+		results, err := NewBalancer(client, cfg).Run(true)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// One pause between the two migrations, none before the first
+		if len(results) != 2 || fmt.Sprint(*slept) != "[30s]" {
+			t.Errorf("Expected a 30s pause between 2 migrations, got %d migrations and pauses %v", len(results), *slept)
+		}
+	})
+
+	t.Run("advanced", func(t *testing.T) {
+		slept := fakeSleep(t)
+		cfg := createTestConfig()
+		cfg.Balancing.MigrationDelay = "1m"
+		client := &mockClient{}
+
+		results := NewAdvancedBalancer(client, cfg).executeMigrations(migrations, time.Time{})
+		if len(results) != 3 || fmt.Sprint(*slept) != "[1m0s 1m0s]" {
+			t.Errorf("Expected a 1m pause between each of 3 migrations, got %d migrations and pauses %v", len(results), *slept)
+		}
+	})
+
+	t.Run("cycle budget", func(t *testing.T) {
+		slept := fakeSleep(t)
+		cfg := createTestConfig()
+		cfg.Balancing.MigrationDelay = "1m"
+		client := &mockClient{}
+
+		// Waiting would run past the deadline: the remaining migrations wait for the next cycle
+		results := NewAdvancedBalancer(client, cfg).executeMigrations(migrations, time.Now().Add(30*time.Second))
+		if len(results) != 1 || len(*slept) != 0 {
+			t.Errorf("Expected the delay to cut the cycle after 1 migration without waiting, got %d migrations and pauses %v", len(results), *slept)
+		}
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		slept := fakeSleep(t)
+		cfg := createTestConfig()
+		cfg.ReadOnly = true
+		cfg.Balancing.MigrationDelay = "1m"
+
+		results := NewAdvancedBalancer(&mockClient{}, cfg).executeMigrations(migrations, time.Time{})
+		if len(results) != 3 || len(*slept) != 0 {
+			t.Errorf("Expected no pause when nothing moves, got %d results and pauses %v", len(results), *slept)
+		}
+	})
+}
+
+func TestClusterRecommendationsMemoryConstrained(t *testing.T) {
+	// CPU is comfortable everywhere, memory is nearly exhausted
+	nodes := []models.Node{
+		{Name: "node1", CPU: models.CPUInfo{Usage: 35.0}, Memory: models.MemoryInfo{Usage: 95.0}},
+		{Name: "node2", CPU: models.CPUInfo{Usage: 40.0}, Memory: models.MemoryInfo{Usage: 94.0}},
+		{Name: "node3", CPU: models.CPUInfo{Usage: 30.0}, Memory: models.MemoryInfo{Usage: 50.0}},
+	}
+
+	balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, createTestConfig())
+	for i := range nodes {
+		balancer.updateCapacityMetricsSimplified(&nodes[i])
+	}
+
+	// Memory is predicted from memory metrics, not CPU
+	if predicted := balancer.PredictResourceEvolution("node1", "memory", 0); predicted < 90 {
+		t.Errorf("Expected node1 memory prediction above 90%%, got %.1f", predicted)
+	}
+	if predicted := balancer.PredictResourceEvolution("node1", "cpu", 0); predicted > 50 {
+		t.Errorf("Expected node1 CPU prediction to stay low, got %.1f", predicted)
+	}
+
+	recommendations := balancer.GetClusterRecommendations(0)
+
+	foundExpansion := false
+	foundConstraint := false
+	for _, recommendation := range recommendations {
+		if strings.Contains(recommendation, "majority of nodes - consider cluster expansion") {
+			foundExpansion = true
+		}
+		if strings.Contains(recommendation, "Constraining resource: memory") {
+			foundConstraint = true
+			if !strings.Contains(recommendation, "0/3 nodes predicted above 90% CPU") ||
+				!strings.Contains(recommendation, "2/3 above 90% memory") {
+				t.Errorf("Unexpected constraint breakdown: %s", recommendation)
+			}
+		}
+	}
+
+	if !foundExpansion {
+		t.Errorf("Expected expansion recommendation for memory pressure, got %v", recommendations)
+	}
+	if !foundConstraint {
+		t.Errorf("Expected memory to be reported as the constraint, got %v", recommendations)
+	}
+}
+
+func TestConstrainingResource(t *testing.T) {
+	tests := []struct {
+		cpu, memory int
+		expected    string
+	}{
+		{0, 0, ""},
+		{0, 2, "memory"},
+		{3, 1, "cpu"},
+		{2, 2, "cpu and memory"},
+	}
+
+	for _, tt := range tests {
+		if got := constrainingResource(tt.cpu, tt.memory); got != tt.expected {
+			t.Errorf("constrainingResource(%d, %d) = %q, expected %q", tt.cpu, tt.memory, got, tt.expected)
+		}
+	}
+}
+
+func TestCapacityMetricsStoreMemorySeparately(t *testing.T) {
+	client := &mockClient{
+		nodes: createTestNodes(),
+		historicalData: map[string][]proxmox.HistoricalMetric{
+			"node1": {
+				{Timestamp: time.Now().Add(-1 * time.Hour), CPU: 20.0, Memory: 6 * 1024 * 1024 * 1024},
+				{Timestamp: time.Now(), CPU: 25.0, Memory: 7 * 1024 * 1024 * 1024},
+			},
+		},
+	}
+	balancer := NewAdvancedBalancer(client, createTestConfig())
+	balancer.updateCapacityMetrics(client.nodes[:1])
+
+	cpuMetrics, _ := balancer.GetCapacityMetrics("node1")
+	memoryMetrics, exists := balancer.memoryMetrics["node1"]
+	if !exists {
+		t.Fatal("Expected memory metrics for node1")
+	}
+
+	// Memory samples are converted from bytes to a share of the node's 8GB
+	if cpuMetrics.P90 != 25.0 {
+		t.Errorf("Expected CPU P90 25, got %.1f", cpuMetrics.P90)
+	}
+	if memoryMetrics.P90 < 87 || memoryMetrics.P90 > 88 {
+		t.Errorf("Expected memory P90 of ~87.5%%, got %.1f", memoryMetrics.P90)
+	}
+}
+
+func TestScoringUsesMemoryPercentiles(t *testing.T) {
+	// Same load now and same CPU history, only the memory history differs
+	nodes := []models.Node{
+		{Name: "steady", CPU: models.CPUInfo{Usage: 40.0}, Memory: models.MemoryInfo{Usage: 40.0}},
+		{Name: "spiky", CPU: models.CPUInfo{Usage: 40.0}, Memory: models.MemoryInfo{Usage: 40.0}},
+	}
+	balancer := NewAdvancedBalancer(&mockClient{}, createTestConfig())
+	for _, name := range []string{"steady", "spiky"} {
+		balancer.capacityMetrics[name] = &models.CapacityMetrics{P90: 40.0}
+	}
+	balancer.memoryMetrics["steady"] = &models.CapacityMetrics{P90: 40.0}
+	balancer.memoryMetrics["spiky"] = &models.CapacityMetrics{P90: 90.0}
+
+	// Higher resource scores are more loaded, higher capacity scores have more headroom
+	if steady, spiky := balancer.calculateResourceScore(&nodes[0]), balancer.calculateResourceScore(&nodes[1]); spiky <= steady {
+		t.Errorf("Expected memory P90 to raise the resource score, got steady %.2f and spiky %.2f", steady, spiky)
+	}
+	if steady, spiky := balancer.calculateCapacityScore(&nodes[0]), balancer.calculateCapacityScore(&nodes[1]); spiky >= steady {
+		t.Errorf("Expected memory P90 to lower the capacity score, got steady %.2f and spiky %.2f", steady, spiky)
+	}
+}
+
+func TestResourceScoreScalesCPUAndMemoryAlike(t *testing.T) {
+	// CPU and memory at 40% now and at P90: both predict 40%, so the score is 40
+	node := models.Node{
+		Name:    "node1",
+		CPU:     models.CPUInfo{Usage: 40.0},
+		Memory:  models.MemoryInfo{Usage: 40.0},
+		Storage: models.StorageInfo{Usage: 40.0},
+	}
+	balancer := NewAdvancedBalancer(&mockClient{}, createTestConfig())
+	balancer.capacityMetrics[node.Name] = &models.CapacityMetrics{P90: 40.0}
+	balancer.memoryMetrics[node.Name] = &models.CapacityMetrics{P90: 40.0}
+
+	if score := balancer.calculateResourceScore(&node); math.Abs(score-40) > 0.1 {
+		t.Errorf("Expected a resource score of 40 with CPU and memory on the same scale, got %.2f", score)
+	}
+}
+
+// fakePowerSource provides synthetic power telemetry.
+type fakePowerSource struct {
+	readings map[string]models.NodePower
+	err      error
+}
+
+func (f *fakePowerSource) NodePower() (map[string]models.NodePower, error) {
+	return f.readings, f.err
+}
+
+func TestPowerTelemetryInfluencesScoring(t *testing.T) {
+	// Two identical nodes, only their power draw differs
+	nodes := []models.Node{
+		{Name: "hot", CPU: models.CPUInfo{Usage: 40.0}, Memory: models.MemoryInfo{Usage: 40.0}},
+		{Name: "cool", CPU: models.CPUInfo{Usage: 40.0}, Memory: models.MemoryInfo{Usage: 40.0}},
+	}
+	readings := map[string]models.NodePower{
+		"hot":  {Watts: 500, Temperature: 78},
+		"cool": {Watts: 200, Temperature: 45},
+	}
+
+	tests := []struct {
+		mode     string
+		expected string
+	}{
+		{"spread", "cool"},
+		{"consolidate", "hot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.Power = config.PowerConfig{Enabled: true, Mode: tt.mode, Weight: 0.2}
+
+			balancer := NewAdvancedBalancer(&mockClient{}, cfg)
+			balancer.powerSource = &fakePowerSource{readings: readings}
+			balancer.refreshPowerTelemetry()
+
+			scores := balancer.calculateAdvancedNodeScores(nodes)
+			if scores[0].Node != tt.expected {
+				t.Errorf("Expected %s to rank first in %s mode, got %s (scores: %+v)", tt.expected, tt.mode, scores[0].Node, scores)
+			}
+
+			// The power sub-score is exposed and favors the expected node
+			breakdowns := balancer.calculateScoreBreakdowns(nodes)
+			powerScores := map[string]float64{}
+			for _, breakdown := range breakdowns {
+				powerScores[breakdown.Node] = breakdown.Power
+			}
+			if powerScores[tt.expected] >= powerScores[scores[1].Node] {
+				t.Errorf("Expected lower power sub-score for %s, got %+v", tt.expected, powerScores)
+			}
+		})
+	}
+}
+
+func TestPowerTelemetryFailureIsIgnored(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.Power = config.PowerConfig{Enabled: true, Mode: "spread", Weight: 0.2}
+
+	balancer := NewAdvancedBalancer(&mockClient{}, cfg)
+	balancer.nodePower = map[string]models.NodePower{"node1": {Watts: 300}}
+	balancer.powerSource = &fakePowerSource{err: fmt.Errorf("sensor offline")}
+	balancer.refreshPowerTelemetry()
+
+	// Stale readings are dropped so scoring falls back to the regular blend
+	if balancer.nodePower != nil {
+		t.Errorf("Expected stale power readings to be dropped, got %+v", balancer.nodePower)
+	}
+	node := models.Node{Name: "node1"}
+	if score := balancer.calculatePowerScore(&node, 300); score != 0 {
+		t.Errorf("Expected no power bias without telemetry, got %.1f", score)
+	}
+}
+
+func TestStartAfterSuppressesBalancing(t *testing.T) {
+	tests := []struct {
+		name          string
+		startAfter    string
+		expectMigrate bool
+	}{
+		{"future start date", time.Now().Add(48 * time.Hour).Format(time.RFC3339), false},
+		{"past start date", time.Now().Add(-48 * time.Hour).Format(time.RFC3339), true},
+		{"no start date", "", true},
+	}
+
+	for _, tt := range tests {
+		for _, balancerType := range []string{"threshold", "advanced"} {
+			t.Run(tt.name+"/"+balancerType, func(t *testing.T) {
+				cfg := createTestConfig()
+				cfg.Balancing.BalancerType = balancerType
+				cfg.Balancing.StartAfter = tt.startAfter
+				client := &mockClient{nodes: createTestNodes()}
+
+				var run func(force bool) ([]models.BalancingResult, error)
+				if balancerType == "advanced" {
+					run = NewAdvancedBalancer(client, cfg).Run
+				} else {
+					run = NewBalancer(client, cfg).Run
+				}
+
+				// Even a forced cycle is a no-op before the start date
+				results, err := run(true)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				if tt.expectMigrate && client.migrateCalls == 0 {
+					t.Error("Expected migrations once the start date has passed")
+				}
+				if !tt.expectMigrate && (len(results) != 0 || client.migrateCalls != 0) {
+					t.Errorf("Expected no balancing before the start date, got %d results", len(results))
+				}
+			})
+		}
+	}
+}
+
+func TestBalancingDeferred(t *testing.T) {
+	cfg := createTestConfig()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cfg.Balancing.StartAfter = "2025-06-02T12:00:00Z"
+	if !balancingDeferred(cfg, now) {
+		t.Error("Expected balancing to be deferred before the start date")
+	}
+	if balancingDeferred(cfg, now.Add(24*time.Hour)) {
+		t.Error("Expected balancing to resume exactly at the start date")
+	}
+}
+
+func TestGetNodeScoreBreakdown(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+
+	nodes := createTestNodes()
+	balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+
+	breakdown, err := balancer.GetNodeScoreBreakdown()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(breakdown) != len(nodes) {
+		t.Fatalf("Expected %d nodes in breakdown, got %d", len(nodes), len(breakdown))
+	}
+
+	for i := 1; i < len(breakdown); i++ {
+		if breakdown[i-1].Final > breakdown[i].Final {
+			t.Errorf("Expected breakdown ordered by final score, got %v before %v", breakdown[i-1].Final, breakdown[i].Final)
+		}
+	}
+
+	// The final score must match what the balancer uses for decisions
+	scores := balancer.calculateAdvancedNodeScores(balancer.filterAvailableNodes(nodes))
+	finals := make(map[string]float64)
+	for i := range scores {
+		finals[scores[i].Node] = scores[i].Score
+	}
+	for _, entry := range breakdown {
+		if math.Abs(finals[entry.Node]-entry.Final) > 0.0001 {
+			t.Errorf("Expected final score %v for %s, got %v", finals[entry.Node], entry.Node, entry.Final)
+		}
+	}
+
+	data, err := json.Marshal(breakdown)
+	if err != nil {
+		t.Fatalf("Failed to marshal breakdown: %v", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal breakdown: %v", err)
+	}
+
+	for _, field := range []string{"node", "resource", "stability", "capacity", "migration_cost", "final"} {
+		if _, exists := decoded[0][field]; !exists {
+			t.Errorf("Expected serialized breakdown to contain %q, got %v", field, decoded[0])
+		}
+	}
+	if _, exists := decoded[0]["power"]; exists {
+		t.Errorf("Expected power to be omitted without telemetry, got %v", decoded[0])
+	}
+}
+
+func TestGetNodeScoreBreakdownNoNodes(t *testing.T) {
+	balancer := NewAdvancedBalancer(&mockClient{nodes: []models.Node{}}, createTestConfig())
+
+	if _, err := balancer.GetNodeScoreBreakdown(); err == nil {
+		t.Error("Expected error when no nodes are available")
+	}
+}
+
+func TestPlanMigrationWaves(t *testing.T) {
+	migrations := []models.Migration{
+		{VM: models.VM{ID: 100}, FromNode: "node1", ToNode: "node2"},
+		{VM: models.VM{ID: 101}, FromNode: "node1", ToNode: "node3"},
+		{VM: models.VM{ID: 102}, FromNode: "node1", ToNode: "node2"},
+		{VM: models.VM{ID: 103}, FromNode: "node4", ToNode: "node2"},
+	}
+
+	tests := []struct {
+		name   string
+		limits config.MigrationConcurrencyConfig
+		want   [][]int
+	}{
+		{"sequential without limits", config.MigrationConcurrencyConfig{}, [][]int{{0}, {1}, {2}, {3}}},
+		{"per source", config.MigrationConcurrencyConfig{PerSource: 2}, [][]int{{0, 1, 3}, {2}}},
+		{"per target", config.MigrationConcurrencyConfig{PerTarget: 1}, [][]int{{0, 1}, {2}, {3}}},
+		{"both", config.MigrationConcurrencyConfig{PerSource: 1, PerTarget: 1}, [][]int{{0}, {1, 3}, {2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planMigrationWaves(migrations, tt.limits)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected waves %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExecuteMigrationsRespectsPerNodeConcurrency(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	cfg.Balancing.Concurrency = config.MigrationConcurrencyConfig{PerSource: 2, PerTarget: 2}
+
+	client := &mockClient{nodes: createTestNodes(), migrateDelay: 20 * time.Millisecond}
+	balancer := NewAdvancedBalancer(client, cfg)
+
+	var migrations []models.Migration
+	for i := 0; i < 6; i++ {
+		target := "node2"
+		if i%2 == 1 {
+			target = "node3"
+		}
+		migrations = append(migrations, models.Migration{VM: models.VM{ID: 200 + i}, FromNode: "node1", ToNode: target})
+	}
+
+	results := balancer.executeMigrations(migrations, time.Time{})
+
+	if len(results) != len(migrations) {
+		t.Fatalf("Expected %d results, got %d", len(migrations), len(results))
+	}
+	if client.migrateCalls != len(migrations) {
+		t.Errorf("Expected %d MigrateVM calls, got %d", len(migrations), client.migrateCalls)
+	}
+
+	if peak := client.maxInFlight["from:node1"]; peak != 2 {
+		t.Errorf("Expected at most 2 simultaneous migrations off node1 (and some overlap), got %d", peak)
+	}
+	for _, target := range []string{"node2", "node3"} {
+		if peak := client.maxInFlight["to:"+target]; peak > 2 {
+			t.Errorf("Expected at most 2 simultaneous migrations onto %s, got %d", target, peak)
+		}
+	}
+
+	seen := make(map[int]bool)
+	for i := range results {
+		if !results[i].Success {
+			t.Errorf("Expected migration of VM %d to succeed: %s", results[i].VM.ID, results[i].ErrorMessage)
+		}
+		seen[results[i].VM.ID] = true
+	}
+	if len(seen) != len(migrations) {
+		t.Errorf("Expected every migration to run exactly once, got %v", seen)
+	}
+}
+
+// createBalancedTestNodes returns the test nodes with node1 brought back under every threshold.
+func createBalancedTestNodes() []models.Node {
+	nodes := createTestNodes()
+	nodes[0].CPU.Usage = 70.0
+	return nodes
+}
+
+func TestForceModes(t *testing.T) {
+	tests := []struct {
+		name           string
+		balancerType   string
+		forceMode      string
+		wantMigrations bool
+	}{
+		{"threshold always", "threshold", config.ForceModeAlways, true},
+		{"threshold default is always", "threshold", "", true},
+		{"threshold reevaluate", "threshold", config.ForceModeReevaluate, false},
+		{"advanced always", "advanced", config.ForceModeAlways, true},
+		{"advanced reevaluate", "advanced", config.ForceModeReevaluate, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = tt.balancerType
+			cfg.Balancing.ForceMode = tt.forceMode
+
+			client := &mockClient{nodes: createBalancedTestNodes()}
+			var (
+				results []models.BalancingResult
+				err     error
+			)
+			if tt.balancerType == "advanced" {
+				results, err = NewAdvancedBalancer(client, cfg).Run(true)
+			} else {
+				results, err = NewBalancer(client, cfg).Run(true)
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if tt.wantMigrations && len(results) == 0 {
+				t.Error("Expected a forced balance to migrate from the most loaded node")
+			}
+			if !tt.wantMigrations && (len(results) != 0 || client.migrateCalls != 0) {
+				t.Errorf("Expected no migrations on a balanced cluster, got %d results", len(results))
+			}
+			for i := range results {
+				if results[i].SourceNode != "node1" {
+					t.Errorf("Expected migrations off the most loaded node node1, got %s", results[i].SourceNode)
+				}
+			}
+		})
+	}
+}
+
+func TestForceModeReevaluateSkipsCooldown(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	cfg.Balancing.Aggressiveness = "low"
+	cfg.Balancing.ForceMode = config.ForceModeReevaluate
+
+	client := &mockClient{nodes: createTestNodes()}
+	balancer := NewAdvancedBalancer(client, cfg)
+	balancer.lastRun = time.Now() // Well within the cooldown
+
+	if results, err := balancer.Run(false); err != nil || len(results) != 0 {
+		t.Fatalf("Expected an unforced run to respect the cooldown, got %d results (err %v)", len(results), err)
+	}
+
+	results, err := balancer.Run(true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) == 0 {
+		t.Error("Expected a reevaluate force to ignore the cooldown on an overloaded cluster")
+	}
+}
+
+func TestConfiguredCooldown(t *testing.T) {
+	tests := []struct {
+		name           string
+		aggressiveness string
+		cooldown       string
+		sinceLastRun   time.Duration
+		wantRun        bool
+	}{
+		{"aggressiveness default elapsed", "high", "", 45 * time.Minute, true},
+		{"configured cooldown longer than default", "high", "90m", 45 * time.Minute, false},
+		{"aggressiveness default pending", "low", "", 2 * time.Hour, false},
+		{"configured cooldown shorter than default", "low", "90m", 2 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = "advanced"
+			cfg.Balancing.Aggressiveness = tt.aggressiveness
+			cfg.Balancing.Cooldown = tt.cooldown
+
+			balancer := NewAdvancedBalancer(&mockClient{nodes: createTestNodes()}, cfg)
+			balancer.lastRun = time.Now().Add(-tt.sinceLastRun)
+
+			results, err := balancer.Run(false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if ran := len(results) > 0; ran != tt.wantRun {
+				t.Errorf("Expected run %v %v after the last one, got %d results", tt.wantRun, tt.sinceLastRun, len(results))
+			}
+		})
+	}
+}
+
+func TestConfiguredCooldownPerVM(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	balancer := NewAdvancedBalancer(&mockClient{nodes: createTestNodes()}, cfg)
+
+	movedEarlier := &models.VM{ID: 100, LastMoved: time.Now().Add(-2 * time.Hour)}
+	movedLately := &models.VM{ID: 101, LastMoved: time.Now().Add(-45 * time.Minute)}
+
+	// Without a configured cooldown, a VM stays put for an hour
+	if balancer.recentlyMigrated(movedEarlier) || !balancer.recentlyMigrated(movedLately) {
+		t.Error("Expected the one hour default per-VM cooldown")
+	}
+
+	cfg.Balancing.Cooldown = "3h"
+	if !balancer.recentlyMigrated(movedEarlier) {
+		t.Error("Expected a VM moved 2h ago held by a 3h configured cooldown")
+	}
+
+	cfg.Balancing.Cooldown = "30m"
+	if balancer.recentlyMigrated(movedLately) {
+		t.Error("Expected a VM moved 45m ago free to move past a 30m configured cooldown")
+	}
+}
+
+func TestFindOptimalMigrationsMinimumGain(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	cfg.Balancing.Aggressiveness = "low"
+
+	nodes := createTestNodes()
+	balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+	aggConfig := cfg.GetAggressivenessConfig()
+
+	// Every node sits just above the next one: positive but tiny gains
+	nodeScores := []models.NodeScore{
+		{Node: "node3", Score: 50.0},
+		{Node: "node2", Score: 51.0},
+		{Node: "node1", Score: 52.0},
+	}
+
+	if migrations := balancer.findOptimalMigrations(nodes, nodeScores, aggConfig, false); len(migrations) != 0 {
+		t.Errorf("Expected gains below the minimum improvement to be rejected, got %d migrations", len(migrations))
+	}
+	if migrations := balancer.findOptimalMigrations(nodes, nodeScores, aggConfig, true); len(migrations) == 0 {
+		t.Error("Expected an always force to accept any positive gain")
+	}
+}
+
+func TestProtectedVMNeedsLargerGain(t *testing.T) {
+	tests := []struct {
+		name           string
+		protected      bool
+		sourceScore    float64
+		wantMigrations bool
+	}{
+		{"unprotected with modest gain", false, 80.0, true},
+		{"protected with modest gain", true, 80.0, false},
+		{"protected with large gain", true, 90.0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.Aggressiveness = "low" // 15 point minimum improvement
+			cfg.Balancing.ProtectedMinGain = 25.0
+
+			nodes := createTestNodes()
+			for i := range nodes[0].VMs {
+				nodes[0].VMs[i].Protected = tt.protected
+			}
+
+			// Gain is source score minus the best target's 60 points
+			nodeScores := []models.NodeScore{
+				{Node: "node3", Score: 60.0},
+				{Node: "node2", Score: 65.0},
+				{Node: "node1", Score: tt.sourceScore},
+			}
+
+			advanced := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+			migrations := advanced.findOptimalMigrations(nodes, nodeScores, cfg.GetAggressivenessConfig(), false)
+			if got := len(migrations) > 0; got != tt.wantMigrations {
+				t.Errorf("Advanced balancer: expected migrations %v, got %d", tt.wantMigrations, len(migrations))
+			}
+
+			// The threshold balancer scores as fractions of 1
+			thresholdScores := make([]models.NodeScore, len(nodeScores))
+			for i, score := range nodeScores {
+				thresholdScores[i] = models.NodeScore{Node: score.Node, Score: score.Score / 100}
+			}
+			threshold := NewBalancer(&mockClient{nodes: nodes}, cfg)
+			migrations = threshold.findMigrations(nodes, thresholdScores, false)
+			if got := len(migrations) > 0; got != tt.wantMigrations {
+				t.Errorf("Threshold balancer: expected migrations %v, got %d", tt.wantMigrations, len(migrations))
+			}
+		})
+	}
+}
+
+func TestUnschedulableVMsAreReported(t *testing.T) {
+	cfg := createTestConfig()
+
+	nodes := createTestNodes()
+	nodes[0].VMs = append(nodes[0].VMs, models.VM{
+		ID:     103,
+		Name:   "test-vm-pinned",
+		Status: "running",
+		Node:   "node1",
+		Tags:   []string{"plb_pin_node1"},
+	})
+	allVMs := []models.VM{}
+	for _, node := range nodes {
+		allVMs = append(allVMs, node.VMs...)
+	}
+
+	type reporter interface {
+		GetUnschedulableVMs() []models.UnschedulableVM
+	}
+
+	threshold := NewBalancer(&mockClient{nodes: nodes}, cfg)
+	_ = threshold.engine.ProcessVMs(allVMs)
+	advanced := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+	_ = advanced.engine.ProcessVMs(allVMs)
+
+	tests := []struct {
+		name     string
+		balancer reporter
+		find     func()
+	}{
+		{"threshold", threshold, func() {
+			threshold.findMigrations(nodes, threshold.calculateNodeScores(nodes), false)
+		}},
+		{"advanced", advanced, func() {
+			advanced.findOptimalMigrations(nodes, advanced.calculateAdvancedNodeScores(nodes), cfg.GetAggressivenessConfig(), false)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.find()
+			stuck := tt.balancer.GetUnschedulableVMs()
+			if len(stuck) != 1 {
+				t.Fatalf("Expected 1 unschedulable VM, got %v", stuck)
+			}
+			if stuck[0].VMID != 103 || stuck[0].Node != "node1" {
+				t.Errorf("Expected pinned VM 103 on node1, got %+v", stuck[0])
+			}
+			if !strings.Contains(stuck[0].Reason, "pinned") {
+				t.Errorf("Expected the reason to mention the pin, got %q", stuck[0].Reason)
+			}
+			since := stuck[0].Since
+
+			// Still stuck next cycle: the first time it got stuck is kept
+			tt.find()
+			if stuck = tt.balancer.GetUnschedulableVMs(); len(stuck) != 1 || !stuck[0].Since.Equal(since) {
+				t.Errorf("Expected VM 103 to stay unschedulable since %v, got %v", since, stuck)
+			}
+		})
+	}
+}
+
+func TestUnschedulableTrackerClears(t *testing.T) {
+	tracker := newUnschedulableTracker()
+	start := time.Now()
+
+	tracker.update([]models.UnschedulableVM{{VMID: 101}, {VMID: 100}}, start)
+	vms := tracker.list()
+	if len(vms) != 2 || vms[0].VMID != 100 || !vms[0].Since.Equal(start) {
+		t.Fatalf("Expected VMs 100 and 101 stuck since %v, got %v", start, vms)
+	}
+
+	tracker.update([]models.UnschedulableVM{{VMID: 101}}, start.Add(time.Minute))
+	vms = tracker.list()
+	if len(vms) != 1 || vms[0].VMID != 101 || !vms[0].Since.Equal(start) {
+		t.Errorf("Expected only VM 101 stuck since %v, got %v", start, vms)
+	}
+
+	tracker.update(nil, start.Add(2*time.Minute))
+	if vms = tracker.list(); len(vms) != 0 {
+		t.Errorf("Expected no unschedulable VMs, got %v", vms)
+	}
+}
+
+func TestZeroFootprintVMsRelocatedForRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		cpu       float32
+		status    string
+		wantMoved bool
+	}{
+		{"ignored by default", config.ZeroFootprintIgnore, 0, "running", false},
+		{"idle VM moved", config.ZeroFootprintRules, 0, "running", true},
+		{"stopped VM moved", config.ZeroFootprintRules, 0, "stopped", true},
+		{"busy VM left to the load pass", config.ZeroFootprintRules, 0.5, "running", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.ZeroFootprint = tt.mode
+
+			// VM 100 is split from its affinity group on node2
+			nodes := createTestNodes()
+			nodes[0].VMs[0].CPU = tt.cpu
+			nodes[0].VMs[0].Status = tt.status
+			allVMs := []models.VM{}
+			for _, node := range nodes {
+				allVMs = append(allVMs, node.VMs...)
+			}
+
+			advanced := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+			_ = advanced.engine.ProcessVMs(allVMs)
+			nodeScores := advanced.calculateAdvancedNodeScores(nodes)
+			migrations := advanced.findOptimalMigrations(nodes, nodeScores, cfg.GetAggressivenessConfig(), false)
+
+			moved := false
+			for _, migration := range migrations {
+				if migration.VM.ID == 100 {
+					moved = true
+					if migration.ToNode != "node2" {
+						t.Errorf("Expected VM 100 to join its affinity group on node2, got %s", migration.ToNode)
+					}
+				}
+			}
+			if moved != tt.wantMoved {
+				t.Errorf("Expected VM 100 moved %v, got %v (%d migrations)", tt.wantMoved, moved, len(migrations))
+			}
+		})
+	}
+}
+
+func TestRuleCorrectionMinGain(t *testing.T) {
+	// VM 100 is busy and split from its affinity group on node2. With node2 fairly loaded,
+	// moving it gains the advanced balancer ~8.8 points, under the medium min_improvement of 10,
+	// and the threshold balancer 28 points.
+	tests := []struct {
+		name          string
+		corrections   config.RuleCorrectionsConfig
+		wantAdvanced  bool
+		wantThreshold bool
+	}{
+		{"disabled", config.RuleCorrectionsConfig{}, false, true},
+		{"low bound", config.RuleCorrectionsConfig{Enabled: true, MinGain: 5}, true, true},
+		{"bound above advanced gain", config.RuleCorrectionsConfig{Enabled: true, MinGain: 9}, false, true},
+		{"bound above threshold gain", config.RuleCorrectionsConfig{Enabled: true, MinGain: 30}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.RuleCorrections = tt.corrections
+
+			nodes := createTestNodes()
+			nodes[0].VMs[0].CPU = 0.5
+			nodes[1].CPU.Usage = 50
+			nodes[1].Memory.Usage = 70
+			allVMs := []models.VM{}
+			for _, node := range nodes {
+				allVMs = append(allVMs, node.VMs...)
+			}
+
+			movesVM100 := func(migrations []models.Migration) bool {
+				for _, migration := range migrations {
+					if migration.VM.ID == 100 && migration.ToNode == "node2" {
+						return true
+					}
+				}
+				return false
+			}
+
+			advanced := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+			_ = advanced.engine.ProcessVMs(allVMs)
+			migrations := advanced.findOptimalMigrations(nodes, advanced.calculateAdvancedNodeScores(nodes), cfg.GetAggressivenessConfig(), false)
+			if moved := movesVM100(migrations); moved != tt.wantAdvanced {
+				t.Errorf("Advanced: expected VM 100 moved %v, got %v (skipped %v)", tt.wantAdvanced, moved, advanced.GetSkippedVMs())
+			}
+
+			threshold := NewBalancer(&mockClient{nodes: nodes}, cfg)
+			_ = threshold.engine.ProcessVMs(allVMs)
+			migrations = threshold.findMigrations(nodes, threshold.calculateNodeScores(nodes), false)
+			if moved := movesVM100(migrations); moved != tt.wantThreshold {
+				t.Errorf("Threshold: expected VM 100 moved %v, got %v (skipped %v)", tt.wantThreshold, moved, threshold.GetSkippedVMs())
+			}
+		})
+	}
+}
+
+func TestFreedResources(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	source := &models.Node{Name: "node1", CPU: models.CPUInfo{Cores: 8}, Memory: models.MemoryInfo{Total: 16 * gib}}
+	vm := &models.VM{ID: 100, CPU: 0.5, CPUs: 4, Memory: 4 * gib}
+
+	freed := freedResources(vm, source)
+	if math.Abs(freed.CPU-25) > 0.001 || math.Abs(freed.Memory-25) > 0.001 {
+		t.Errorf("Expected 2 of 8 cores and 4 of 16 GiB freed (25%%/25%%), got %+v", freed)
+	}
+
+	// Unknown memory size frees nothing measurable
+	if freed := freedResources(vm, &models.Node{CPU: models.CPUInfo{Cores: 8}}); freed.Memory != 0 {
+		t.Errorf("Expected no memory share without a node total, got %+v", freed)
+	}
+}
+
+func TestWeightedVMContributesLess(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	source := &models.Node{Name: "node1", CPU: models.CPUInfo{Cores: 8}, Memory: models.MemoryInfo{Total: 16 * gib}}
+	plain := &models.VM{ID: 100, Name: "steady", CPU: 0.5, CPUs: 4, Memory: 4 * gib}
+	bursty := &models.VM{ID: 101, Name: "bursty", CPU: 0.5, CPUs: 4, Memory: 4 * gib, Tags: []string{"plb_weight_0.5"}}
+
+	full, weighted := freedResources(plain, source), freedResources(bursty, source)
+	if math.Abs(weighted.CPU-full.CPU/2) > 0.001 || math.Abs(weighted.Memory-full.Memory/2) > 0.001 {
+		t.Errorf("Expected the down-weighted VM to count for half of %+v, got %+v", full, weighted)
+	}
+	if got := projectedTargetCPU(bursty, source); math.Abs(got-12.5) > 0.001 {
+		t.Errorf("Expected the down-weighted VM to add 12.5%% to its target, got %.2f", got)
+	}
+
+	// The steady VM now relieves the source most, so it is tried first
+	client := &mockClient{nodes: createTestNodes()}
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	b := NewAdvancedBalancer(client, cfg)
+	source.VMs = []models.VM{*bursty, *plain}
+	if candidates := b.orderMigrationCandidates(source); candidates[0].ID != plain.ID {
+		t.Errorf("Expected the unweighted VM first, got %d", candidates[0].ID)
+	}
+}
+
+func TestMigrationResultsReportComputedGain(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	nodes := createTestNodes()
+	nodes[0].Memory.Total = 32 * gib
+	nodes[0].VMs[0].CPU, nodes[0].VMs[0].CPUs, nodes[0].VMs[0].Memory = 0.5, 4, 8*gib
+	nodes[0].VMs[1].CPU, nodes[0].VMs[1].CPUs, nodes[0].VMs[1].Memory = 0.25, 2, 2*gib
+	allVMs := []models.VM{}
+	for _, node := range nodes {
+		allVMs = append(allVMs, node.VMs...)
+	}
+
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+
+	t.Run("advanced", func(t *testing.T) {
+		balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+		_ = balancer.engine.ProcessVMs(allVMs)
+		nodeScores := balancer.calculateAdvancedNodeScores(nodes)
+		migrations := balancer.findOptimalMigrations(nodes, nodeScores, cfg.GetAggressivenessConfig(), true)
+		if len(migrations) == 0 {
+			t.Fatal("Expected migrations off the overloaded node")
+		}
+
+		for i := range migrations {
+			migration := &migrations[i]
+			expected := balancer.calculateResourceGain(migration.FromNode, migration.ToNode, nodeScores)
+			result := balancer.executeMigration(migration)
+			if result.ResourceGain != expected || result.ResourceGain == 10.0 {
+				t.Errorf("VM %d: expected reported gain %.3f, got %.3f", migration.VM.ID, expected, result.ResourceGain)
+			}
+			if result.Freed != freedResources(&migration.VM, &nodes[0]) || result.Freed.CPU == 0 || result.Freed.Memory == 0 {
+				t.Errorf("VM %d: expected freed resources on node1, got %+v", migration.VM.ID, result.Freed)
+			}
+		}
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		balancer := NewBalancer(&mockClient{nodes: nodes}, cfg)
+		results, err := balancer.Run(false)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(results) == 0 {
+			t.Fatal("Expected migrations off the overloaded node")
+		}
+
+		nodeScores := balancer.calculateNodeScores(nodes)
+		for _, result := range results {
+			expected := balancer.calculateResourceGain(result.SourceNode, result.TargetNode, nodeScores)
+			if math.Abs(result.ResourceGain-expected) > 1e-9 || result.ResourceGain == 0 {
+				t.Errorf("VM %d: expected reported gain %.3f, got %.3f", result.VM.ID, expected, result.ResourceGain)
+			}
+			if result.Freed.CPU == 0 {
+				t.Errorf("VM %d: expected freed CPU to be reported, got %+v", result.VM.ID, result.Freed)
+			}
+		}
+	})
+}
+
+func TestRuleComplianceMigrationsSkipsPlannedAndCompliantVMs(t *testing.T) {
+	nodes := createTestNodes()
+	allVMs := []models.VM{}
+	for _, node := range nodes {
+		allVMs = append(allVMs, node.VMs...)
+	}
+	engine := newRulesEngine(createTestConfig())
+	_ = engine.ProcessVMs(allVMs)
+	targets := []models.NodeScore{{Node: "node3", Score: 0.2}, {Node: "node2", Score: 0.3}, {Node: "node1", Score: 0.8}}
+
+	// VM 101 complies with its rules, only VM 100 needs to move
+	migrations := ruleComplianceMigrations(createTestConfig(), engine, nodes, nodes[:1], targets, nil, nil, nil)
+	if len(migrations) != 1 || migrations[0].VM.ID != 100 || migrations[0].ToNode != "node2" {
+		t.Fatalf("Expected VM 100 moved to node2, got %v", migrations)
+	}
+
+	if migrations = ruleComplianceMigrations(createTestConfig(), engine, nodes, nodes[:1], targets, nil, migrations, nil); len(migrations) != 0 {
+		t.Errorf("Expected an already planned VM to be skipped, got %v", migrations)
+	}
+}
+
+func TestAffinityConsolidationRespectsCapacity(t *testing.T) {
+	const gib = int64(1) << 30
+	newNodes := func(node1Memory float32) []models.Node {
+		newNode := func(name string, memory float32, vm models.VM) models.Node {
+			vm.Node, vm.Status, vm.Tags = name, "stopped", []string{"plb_affinity_app"}
+			return models.Node{Name: name, Status: "online", CPU: models.CPUInfo{Cores: 16, Usage: 20},
+				Memory: models.MemoryInfo{Total: 64 * gib, Usage: memory}, VMs: []models.VM{vm}}
+		}
+		return []models.Node{
+			newNode("node1", node1Memory, models.VM{ID: 301, Name: "app1", Memory: 16 * gib}),
+			newNode("node2", 80, models.VM{ID: 302, Name: "app2", Memory: 8 * gib}),
+			newNode("node3", 75, models.VM{ID: 303, Name: "app3", Memory: 4 * gib}),
+		}
+	}
+	// node3 scores best, but holds the least of the group
+	targets := []models.NodeScore{{Node: "node3", Score: 0.2}, {Node: "node2", Score: 0.3}, {Node: "node1", Score: 0.4}}
+
+	tests := []struct {
+		name        string
+		node1Memory float32
+		moves       map[int]string
+	}{
+		// app3 (+6%) and app2 (+12.5%) both fit on node1
+		{"whole group fits", 60, map[int]string{302: "node1", 303: "node1"}},
+		// No node holds all three: node1 still takes app3, app2 would cross the memory threshold there
+		{"partial consolidation", 70, map[int]string{303: "node1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := newNodes(tt.node1Memory)
+			allVMs := []models.VM{}
+			for _, node := range nodes {
+				allVMs = append(allVMs, node.VMs...)
+			}
+			engine := newRulesEngine(createTestConfig())
+			_ = engine.ProcessVMs(allVMs)
+
+			migrations := ruleComplianceMigrations(createTestConfig(), engine, nodes, nodes, targets, nil, nil, nil)
+			moves := make(map[int]string)
+			for _, migration := range migrations {
+				moves[migration.VM.ID] = migration.ToNode
+			}
+			if len(moves) != len(tt.moves) {
+				t.Fatalf("Expected moves %v, got %v", tt.moves, moves)
+			}
+			for id, target := range tt.moves {
+				if moves[id] != target {
+					t.Errorf("Expected VM %d moved to %s, got %v", id, target, moves)
+				}
+			}
+		})
+	}
+}
+
+func TestNodeObservations(t *testing.T) {
+	now := time.Now()
+	observations := make(nodeObservations)
+	observations.record([]models.BalancingResult{
+		{SourceNode: "node1", TargetNode: "node2", Success: true},
+		{SourceNode: "node1", TargetNode: "node3", Success: false},
+		{SourceNode: "node4", TargetNode: "node3", Success: true, DryRun: true},
+	}, 10*time.Minute, now)
+
+	sources := []models.Node{{Name: "node1"}, {Name: "node3"}, {Name: "node4"}}
+	targets := []models.NodeScore{{Node: "node2"}, {Node: "node3"}, {Node: "node4"}}
+
+	tests := []struct {
+		name        string
+		at          time.Time
+		wantSources []string
+		wantTargets []string
+	}{
+		{"during window", now.Add(5 * time.Minute), []string{"node3", "node4"}, []string{"node3", "node4"}},
+		{"after window", now.Add(11 * time.Minute), []string{"node1", "node3", "node4"}, []string{"node2", "node3", "node4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keptSources, keptTargets := observations.exclude(sources, targets, tt.at)
+			var gotSources, gotTargets []string
+			for i := range keptSources {
+				gotSources = append(gotSources, keptSources[i].Name)
+			}
+			for _, score := range keptTargets {
+				gotTargets = append(gotTargets, score.Node)
+			}
+			if strings.Join(gotSources, ",") != strings.Join(tt.wantSources, ",") {
+				t.Errorf("Expected sources %v, got %v", tt.wantSources, gotSources)
+			}
+			if strings.Join(gotTargets, ",") != strings.Join(tt.wantTargets, ",") {
+				t.Errorf("Expected targets %v, got %v", tt.wantTargets, gotTargets)
+			}
+		})
+	}
+}
+
+func TestObservationWindowQuiescesMigratedNodes(t *testing.T) {
+	tests := []struct {
+		name           string
+		window         string
+		wantSecondMove bool
+	}{
+		{"disabled", "", true},
+		{"observing", "10m", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.ObservationWindow = tt.window
+
+			// The mock doesn't apply migrations, so node1 still looks overloaded on the next cycle
+			balancer := NewBalancer(&mockClient{nodes: createTestNodes()}, cfg)
+			results, err := balancer.Run(false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(results) == 0 {
+				t.Fatal("Expected the first cycle to migrate off node1")
+			}
+
+			results, err = balancer.Run(false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := len(results) > 0; got != tt.wantSecondMove {
+				t.Errorf("Expected second cycle migrations %v, got %d", tt.wantSecondMove, len(results))
+			}
+		})
+	}
+}
+
+func TestBuildMigrationPlanKeepsNetPositiveMigrations(t *testing.T) {
+	cfg := createTestConfig()
+	nodes := createTestNodes()
+	balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+
+	// node2 is barely better than the overloaded node1, node3 is much better
+	nodeScores := []models.NodeScore{
+		{Node: "node3", Score: 60.0},
+		{Node: "node2", Score: 78.0},
+		{Node: "node1", Score: 80.0},
+	}
+	vm := func(id int) models.VM {
+		return models.VM{ID: id, Name: fmt.Sprintf("vm-%d", id), Node: "node1", Status: "running", Memory: 2 << 30}
+	}
+	migrations := []models.Migration{
+		{VM: vm(100), FromNode: "node1", ToNode: "node3"},
+		{VM: vm(101), FromNode: "node1", ToNode: "node2"},
+	}
+
+	tests := []struct {
+		name    string
+		horizon time.Duration
+		wantIDs []int
+	}{
+		{"marginal migration rejected", time.Hour, []int{100}},
+		{"long horizon pays off both", 24 * time.Hour, []int{100, 101}},
+		{"short horizon pays off none", 10 * time.Minute, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := balancer.buildMigrationPlan(migrations, nodes, nodeScores, tt.horizon)
+			if len(plan.Migrations) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d migrations, got %d", len(tt.wantIDs), len(plan.Migrations))
+			}
+			for i, id := range tt.wantIDs {
+				if plan.Migrations[i].VM.ID != id {
+					t.Errorf("Expected migration %d to move VM %d, got %d", i, id, plan.Migrations[i].VM.ID)
+				}
+			}
+			if math.Abs(plan.NetBenefit-(plan.TotalGain-plan.TotalCost)) > 1e-9 {
+				t.Errorf("Expected net benefit %.2f - %.2f, got %.2f", plan.TotalGain, plan.TotalCost, plan.NetBenefit)
+			}
+			if len(plan.Migrations) > 0 && plan.NetBenefit <= 0 {
+				t.Errorf("Expected a positive net benefit, got %.2f", plan.NetBenefit)
+			}
+		})
+	}
+}
+
+// createDailySeasonalSeries returns a week of hourly CPU samples, busy during business hours.
+func createDailySeasonalSeries(start time.Time, busy, idle float64) []proxmox.HistoricalMetric {
+	var metrics []proxmox.HistoricalMetric
+	for h := 0; h < 7*24; h++ {
+		timestamp := start.Add(time.Duration(h) * time.Hour)
+		cpu := idle
+		if timestamp.Hour() >= 9 && timestamp.Hour() < 17 {
+			cpu = busy
+		}
+		metrics = append(metrics, proxmox.HistoricalMetric{Timestamp: timestamp, CPU: cpu})
+	}
+	return metrics
+}
+
+func TestSplitPeriodLoad(t *testing.T) {
+	hours := config.BusinessHoursConfig{Start: "09:00", End: "17:00"}
+	series := createDailySeasonalSeries(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), 80, 5)
+
+	load, ok := splitPeriodLoad(series, hours)
+	if !ok {
+		t.Fatal("Expected samples in both periods")
+	}
+	if load.Business != 80 || load.OffHours != 5 {
+		t.Errorf("Expected 80%% business and 5%% off-hours load, got %+v", load)
+	}
+
+	seasonality := seasonalityFor(load, hours)
+	if seasonality == nil || seasonality.Type != "daily" || seasonality.PeakTime != "09:00" {
+		t.Errorf("Expected a daily pattern peaking at 09:00, got %+v", seasonality)
+	}
+
+	if seasonality := seasonalityFor(periodLoad{Business: 40, OffHours: 35}, hours); seasonality != nil {
+		t.Errorf("Expected no pattern for a flat load, got %+v", seasonality)
+	}
+
+	if _, ok := splitPeriodLoad(series[:8], hours); ok {
+		t.Error("Expected no profile without business hours samples")
+	}
+}
+
+func TestSeasonalProfileAvoidsConstrainedTarget(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.LoadProfiles.BusinessHours = config.BusinessHoursConfig{Start: "09:00", End: "17:00", Lookahead: "1h"}
+
+	// node3 has the best score but only 30 points of headroom
+	nodes := createTestNodes()
+	nodes[2].CPU.Usage = 50.0
+	vm := &nodes[0].VMs[0]
+	vm.CPUs = 4
+	vm.CPU = 0.05 // Idle right now
+
+	client := &mockClient{nodes: nodes, vmHistoricalData: map[string][]proxmox.HistoricalMetric{
+		"node1-100-qemu-week": createDailySeasonalSeries(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), 80, 5),
+	}}
+	balancer := NewAdvancedBalancer(client, cfg)
+	profile := &models.LoadProfile{}
+	balancer.updateSeasonalProfile(vm, "node1", profile)
+	if profile.Seasonality == nil {
+		t.Fatal("Expected a daily pattern from the seasonal history")
+	}
+
+	targets := []models.NodeScore{{Node: "node3", Score: 40.0}, {Node: "node2", Score: 45.0}}
+	tests := []struct {
+		name       string
+		now        time.Time
+		wantTarget string
+	}{
+		// 80% of 4 vCPUs on 8 cores is 40 points: too much for node3 at 50%
+		{"business hours ahead", time.Date(2025, 6, 9, 8, 30, 0, 0, time.UTC), "node2"},
+		{"night ahead", time.Date(2025, 6, 9, 2, 0, 0, 0, time.UTC), "node3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept := balancer.filterSeasonalTargets(vm, nodes, targets, tt.now)
+			if len(kept) == 0 || kept[0].Node != tt.wantTarget {
+				t.Errorf("Expected best target %s, got %v", tt.wantTarget, kept)
+			}
+		})
+	}
+}
+
+func TestPreferredNodeWinsUnlessOverloaded(t *testing.T) {
+	vm := models.VM{ID: 100, Name: "test-vm", Node: "node1", Status: "running", Tags: []string{"plb_prefer_node3"}}
+
+	tests := []struct {
+		name       string
+		node3CPU   float32
+		wantTarget string
+	}{
+		{"preferred node wins", 60.0, "node3"},
+		{"overloaded preferred node skipped", 95.0, "node2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+
+			// node2 scores better, node3 is preferred
+			advanced := NewAdvancedBalancer(&mockClient{}, cfg)
+			_ = advanced.engine.ProcessVMs([]models.VM{vm})
+			nodeScores := []models.NodeScore{
+				{Node: "node2", Score: 30.0, CPU: 30.0},
+				{Node: "node3", Score: 50.0, CPU: tt.node3CPU},
+				{Node: "node1", Score: 80.0, CPU: 90.0},
+			}
+			if target := advanced.findBestTargetNode(&vm, nodeScores, "node1"); target != tt.wantTarget {
+				t.Errorf("Advanced balancer: expected %s, got %s", tt.wantTarget, target)
+			}
+
+			// The threshold balancer scores as fractions of 1
+			threshold := NewBalancer(&mockClient{}, cfg)
+			_ = threshold.engine.ProcessVMs([]models.VM{vm})
+			for i := range nodeScores {
+				nodeScores[i].Score /= 100
+				nodeScores[i].CPU /= 100
+			}
+			if target := threshold.findBestTargetNode(&vm, nodeScores); target != tt.wantTarget {
+				t.Errorf("Threshold balancer: expected %s, got %s", tt.wantTarget, target)
+			}
+		})
+	}
+}
+
+func TestReplicaNodePreferred(t *testing.T) {
+	cfg := createTestConfig()
+
+	// node2 scores better, node3 holds the VM's replica
+	vm := models.VM{ID: 100, Name: "test-vm", Node: "node1", Status: "running", ReplicaNodes: []string{"node3"}}
+	nodeScores := []models.NodeScore{
+		{Node: "node2", Score: 30.0, CPU: 30.0},
+		{Node: "node3", Score: 50.0, CPU: 60.0},
+		{Node: "node1", Score: 80.0, CPU: 90.0},
+	}
+
+	advanced := NewAdvancedBalancer(&mockClient{}, cfg)
+	_ = advanced.engine.ProcessVMs([]models.VM{vm})
+	if target := advanced.findBestTargetNode(&vm, nodeScores, "node1"); target != "node3" {
+		t.Errorf("Expected replicated VM to go to its replica node3, got %s", target)
+	}
+
+	// An explicit plb_prefer_ tag wins over the replica
+	tagged := vm
+	tagged.Tags = []string{"plb_prefer_node2"}
+	threshold := NewBalancer(&mockClient{}, cfg)
+	_ = threshold.engine.ProcessVMs([]models.VM{tagged})
+	for i := range nodeScores {
+		nodeScores[i].Score /= 100
+		nodeScores[i].CPU /= 100
+	}
+	if target := threshold.findBestTargetNode(&tagged, nodeScores); target != "node2" {
+		t.Errorf("Expected preferred node2 to win over the replica, got %s", target)
+	}
+
+	// An overloaded replica node falls back to the best score
+	nodeScores[1].CPU = 0.95
+	untagged := NewBalancer(&mockClient{}, cfg)
+	_ = untagged.engine.ProcessVMs([]models.VM{vm})
+	if target := untagged.findBestTargetNode(&vm, nodeScores); target != "node2" {
+		t.Errorf("Expected overloaded replica node to be skipped, got %s", target)
+	}
+}
+
+func TestVMsOnPreferredNodeMoveLast(t *testing.T) {
+	node := models.Node{
+		Name: "node1",
+		CPU:  models.CPUInfo{Cores: 8, Usage: 90.0},
+		VMs: []models.VM{
+			{ID: 100, Node: "node1", Status: "running", CPU: 0.9, CPUs: 4, Tags: []string{"plb_prefer_node1"}},
+			{ID: 101, Node: "node1", Status: "running", CPU: 0.2, CPUs: 2},
+		},
+	}
+
+	balancer := NewAdvancedBalancer(&mockClient{}, createTestConfig())
+	_ = balancer.engine.ProcessVMs(node.VMs)
+
+	// VM 100 brings the most relief but prefers to stay
+	candidates := balancer.orderMigrationCandidates(&node)
+	if candidates[0].ID != 101 || candidates[1].ID != 100 {
+		t.Errorf("Expected VM 101 before VM 100, got %d then %d", candidates[0].ID, candidates[1].ID)
+	}
+}
+
+func TestClusterBalanceScore(t *testing.T) {
+	nodesWithLoad := func(loads ...float32) []models.Node {
+		nodes := make([]models.Node, len(loads))
+		for i, load := range loads {
+			nodes[i] = models.Node{
+				Name:   fmt.Sprintf("node%d", i+1),
+				Status: "online",
+				CPU:    models.CPUInfo{Usage: load},
+				Memory: models.MemoryInfo{Usage: load},
+			}
+		}
+		return nodes
+	}
+
+	tests := []struct {
+		name     string
+		nodes    []models.Node
+		minScore float64
+		maxScore float64
+	}{
+		{"perfectly even", nodesWithLoad(50, 50, 50), 99.9, 100},
+		{"nearly even", nodesWithLoad(48, 50, 52), 95, 100},
+		{"lopsided", nodesWithLoad(95, 5, 5), 0, 20},
+		{"idle", nodesWithLoad(0, 0, 0), 99.9, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := balanceScoreOf(tt.nodes)
+			if score < tt.minScore || score > tt.maxScore {
+				t.Errorf("Expected score between %.1f and %.1f, got %.1f", tt.minScore, tt.maxScore, score)
+			}
+		})
+	}
+}
+
+func TestClusterStatusIncludesBalanceScore(t *testing.T) {
+	cfg := createTestConfig()
+	client := &mockClient{nodes: createTestNodes()}
+
+	thresholdStatus, err := NewBalancer(client, cfg).GetClusterStatus()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	advancedStatus, err := NewAdvancedBalancer(client, cfg).GetClusterStatus()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// node1 runs much hotter than node2 and node3
+	if thresholdStatus.BalanceScore <= 0 || thresholdStatus.BalanceScore >= 90 {
+		t.Errorf("Expected an uneven balance score, got %.1f", thresholdStatus.BalanceScore)
+	}
+	if math.Abs(thresholdStatus.BalanceScore-advancedStatus.BalanceScore) > 0.01 {
+		t.Errorf("Expected both balancers to agree, got %.1f and %.1f", thresholdStatus.BalanceScore, advancedStatus.BalanceScore)
+	}
+}
+
+func TestFilterVersionTargets(t *testing.T) {
+	versions := map[string]string{
+		"node1": "8.1.4",
+		"node2": "7.4-17",
+		"node3": "8.2.2",
+		"node4": "",
+	}
+	targets := []models.NodeScore{{Node: "node1"}, {Node: "node2"}, {Node: "node3"}, {Node: "node4"}}
+
+	tests := []struct {
+		name     string
+		versions map[string]string
+		source   string
+		expected []string
+	}{
+		{"restriction disabled", nil, "node1", []string{"node1", "node2", "node3", "node4"}},
+		{"drops other major versions", versions, "node1", []string{"node1", "node3", "node4"}},
+		{"older source", versions, "node2", []string{"node2", "node4"}},
+		{"unknown source version", versions, "node4", []string{"node1", "node2", "node3", "node4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept := filterVersionTargets(tt.versions, tt.source, targets)
+			var got []string
+			for _, score := range kept {
+				got = append(got, score.Node)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected targets %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSameMajorVersionRestrictsMigrations(t *testing.T) {
+	tests := []struct {
+		name             string
+		restrict         bool
+		node2Version     string
+		node3Version     string
+		wantMigrations   bool
+		versionStuck     bool
+		forbiddenTargets []string
+	}{
+		{"mixed cluster unrestricted", false, "7.4.17", "7.4.17", true, false, nil},
+		{"no same-version target", true, "7.4.17", "7.4.17", false, true, []string{"node2", "node3"}},
+		{"same-version target available", true, "7.4.17", "8.2.2", true, false, []string{"node2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := createTestNodes()
+			nodes[0].Version = "8.1.4"
+			nodes[1].Version = tt.node2Version
+			nodes[2].Version = tt.node3Version
+
+			cfg := createTestConfig()
+			cfg.Balancing.SameMajorVersion = tt.restrict
+			balancer := NewBalancer(&mockClient{nodes: nodes}, cfg)
+
+			results, err := balancer.Run(false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := len(results) > 0; got != tt.wantMigrations {
+				t.Errorf("Expected migrations %v, got %d", tt.wantMigrations, len(results))
+			}
+			for _, result := range results {
+				for _, forbidden := range tt.forbiddenTargets {
+					if result.TargetNode == forbidden {
+						t.Errorf("Expected no migration to %s across major versions, got VM %d", forbidden, result.VM.ID)
+					}
+				}
+			}
+
+			if !tt.versionStuck {
+				return
+			}
+			unschedulable := balancer.GetUnschedulableVMs()
+			if len(unschedulable) == 0 {
+				t.Fatal("Expected VMs stuck on node1 to be reported as unschedulable")
+			}
+			for _, vm := range unschedulable {
+				if !strings.Contains(vm.Reason, "version") {
+					t.Errorf("Expected a version-mismatch reason, got %q", vm.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectedTargetCPUNormalizesByCoreCount(t *testing.T) {
+	// 8 vCPUs at 50% need 4 cores wherever the VM runs
+	vm := &models.VM{ID: 100, CPU: 0.5, CPUs: 8}
+
+	tests := []struct {
+		name     string
+		target   models.Node
+		expected float64
+	}{
+		{"16 cores", models.Node{Name: "small", CPU: models.CPUInfo{Cores: 16, Usage: 60}}, 85},
+		{"64 cores", models.Node{Name: "large", CPU: models.CPUInfo{Cores: 64, Usage: 70}}, 76.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := projectedTargetCPU(vm, &tt.target); math.Abs(got-tt.expected) > 0.001 {
+				t.Errorf("Expected projected CPU %.2f%%, got %.2f%%", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCPUFitPrefersLargerTarget(t *testing.T) {
+	nodes := []models.Node{
+		{
+			Name:   "node1",
+			Status: "online",
+			CPU:    models.CPUInfo{Cores: 8, Usage: 90},
+			VMs: []models.VM{
+				{ID: 100, Name: "big-vm", Node: "node1", Status: "running", CPU: 0.5, CPUs: 8},
+			},
+		},
+		// The least loaded node, but 4 more cores would push it over the threshold
+		{Name: "node2", Status: "online", CPU: models.CPUInfo{Cores: 16, Usage: 60}},
+		{Name: "node3", Status: "online", CPU: models.CPUInfo{Cores: 64, Usage: 70}},
+	}
+
+	targets := filterCPUFitTargets(&nodes[0].VMs[0], nodes, []models.NodeScore{{Node: "node2"}, {Node: "node3"}}, 80)
+	if len(targets) != 1 || targets[0].Node != "node3" {
+		t.Fatalf("Expected only node3 to fit the VM, got %v", targets)
+	}
+
+	balancer := NewBalancer(&mockClient{nodes: nodes}, createTestConfig())
+	results, err := balancer.Run(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].TargetNode != "node3" {
+		t.Fatalf("Expected VM 100 to move to the larger node3, got %v", results)
+	}
+}
+
+func TestStorageFitUsesThinDiskMaxSize(t *testing.T) {
+	const gib = int64(1) << 30
+	newNodes := func(vm models.VM) []models.Node {
+		return []models.Node{
+			{Name: "node1", Status: "online", CPU: models.CPUInfo{Cores: 8, Usage: 95},
+				Memory:  models.MemoryInfo{Total: 64 * gib, Usage: 60},
+				Storage: models.StorageInfo{Total: 100 * gib, Used: 60 * gib, Usage: 60}, VMs: []models.VM{vm}},
+			// The least loaded node, with 60G of storage left
+			{Name: "node2", Status: "online", CPU: models.CPUInfo{Cores: 16, Usage: 30},
+				Memory:  models.MemoryInfo{Total: 64 * gib, Usage: 10},
+				Storage: models.StorageInfo{Total: 100 * gib, Used: 40 * gib, Usage: 40}},
+			{Name: "node3", Status: "online", CPU: models.CPUInfo{Cores: 16, Usage: 50},
+				Memory:  models.MemoryInfo{Total: 64 * gib, Usage: 10},
+				Storage: models.StorageInfo{Total: 200 * gib, Used: 80 * gib, Usage: 40}},
+		}
+	}
+	// Both take 10G now, but the thin disk may grow to 60G
+	thin := models.VM{ID: 100, Name: "thin-vm", Node: "node1", Status: "running", CPU: 0.8, CPUs: 4, Disk: 10 * gib, MaxDisk: 60 * gib, ThinDisk: true}
+	thick := models.VM{ID: 100, Name: "thick-vm", Node: "node1", Status: "running", CPU: 0.8, CPUs: 4, Disk: 10 * gib, MaxDisk: 10 * gib}
+
+	targets := []models.NodeScore{{Node: "node2"}, {Node: "node3"}}
+	if kept := filterStorageFitTargets(&thick, newNodes(thick), targets, 90); len(kept) != 2 {
+		t.Errorf("Expected both targets to fit the thick VM, got %v", kept)
+	}
+	if kept := filterStorageFitTargets(&thin, newNodes(thin), targets, 90); len(kept) != 1 || kept[0].Node != "node3" {
+		t.Errorf("Expected only node3 to fit the thin VM at its full size, got %v", kept)
+	}
+
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		t.Run(balancerType, func(t *testing.T) {
+			for _, tt := range []struct {
+				vm     models.VM
+				target string
+			}{{thick, "node2"}, {thin, "node3"}} {
+				cfg := createTestConfig()
+				cfg.Balancing.BalancerType = balancerType
+				client := &mockClient{nodes: newNodes(tt.vm)}
+
+				var results []models.BalancingResult
+				var err error
+				if balancerType == "advanced" {
+					results, err = NewAdvancedBalancer(client, cfg).Run(false)
+				} else {
+					results, err = NewBalancer(client, cfg).Run(false)
+				}
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(results) != 1 || results[0].TargetNode != tt.target {
+					t.Errorf("Expected %s to move to %s, got %v", tt.vm.Name, tt.target, results)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryFitSkipsTargetsWithoutRoom(t *testing.T) {
+	const gib = int64(1) << 30
+	vm := models.VM{ID: 100, Name: "memory-vm", Node: "node1", Status: "running", CPU: 0.8, CPUs: 4, Memory: 12 * gib}
+	nodes := []models.Node{
+		{Name: "node1", Status: "online", CPU: models.CPUInfo{Cores: 8, Usage: 95},
+			Memory: models.MemoryInfo{Total: 64 * gib, Used: 40 * gib, Available: 24 * gib, Usage: 62}, VMs: []models.VM{vm}},
+		// The least loaded node, but a small one: with 12.5G available the 1G headroom is breached
+		{Name: "node2", Status: "online", CPU: models.CPUInfo{Cores: 16, Usage: 5},
+			Memory: models.MemoryInfo{Total: 16 * gib, Used: 3*gib + gib/2, Available: 12*gib + gib/2, Usage: 22}},
+		{Name: "node3", Status: "online", CPU: models.CPUInfo{Cores: 16, Usage: 20},
+			Memory: models.MemoryInfo{Total: 64 * gib, Used: 8 * gib, Available: 56 * gib, Usage: 12.5}},
+	}
+
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	cfg.Balancing.MemoryHeadroom = 1024
+	b := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+
+	if !b.canHostVM(&nodes[2], &vm) {
+		t.Error("Expected node3 to host the VM")
+	}
+	if b.canHostVM(&nodes[1], &vm) {
+		t.Error("Expected node2 to lack room for the VM and the headroom")
+	}
+	cfg.Balancing.MemoryHeadroom = 0
+	if !b.canHostVM(&nodes[1], &vm) {
+		t.Error("Expected node2 to host the VM without headroom")
+	}
+	cfg.Balancing.MemoryHeadroom = 1024
+
+	// Available memory is derived from total and used when not reported, unknown memory always fits
+	if b.canHostVM(&models.Node{Memory: models.MemoryInfo{Total: 16 * gib, Used: 8 * gib}}, &vm) {
+		t.Error("Expected 8G free to be too little for a 12G VM")
+	}
+	if !b.canHostVM(&models.Node{}, &vm) {
+		t.Error("Expected a node with unknown memory to be assumed to fit")
+	}
+
+	results, err := b.Run(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].TargetNode != "node3" {
+		t.Fatalf("Expected the VM to move to node3, which has room for it, got %v", results)
+	}
+}
+
+func TestSkippedVMsExplainDryRun(t *testing.T) {
+	nodes := createTestNodes()
+	nodes[0].VMs = append(nodes[0].VMs,
+		models.VM{ID: 103, Name: "stopped-vm", Node: "node1", Status: "stopped"},
+		models.VM{ID: 104, Name: "moved-vm", Node: "node1", Status: "running", LastMoved: time.Now().Add(-10 * time.Minute)},
+	)
+
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+	balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+
+	results, err := balancer.Run(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	reasons := make(map[int]string)
+	for _, vm := range balancer.GetSkippedVMs() {
+		if vm.Node != "node1" {
+			t.Errorf("Expected skipped VMs from the overloaded node1, got %s for VM %d", vm.Node, vm.VMID)
+		}
+		reasons[vm.VMID] = vm.Reason
+	}
+
+	if reasons[103] != skipNotRunning {
+		t.Errorf("Expected stopped VM 103 skipped as %q, got %q", skipNotRunning, reasons[103])
+	}
+	if reasons[104] != skipCooldown {
+		t.Errorf("Expected recently moved VM 104 skipped as %q, got %q", skipCooldown, reasons[104])
+	}
+	for i := range results {
+		if reason, exists := reasons[results[i].VM.ID]; exists {
+			t.Errorf("Expected planned VM %d not to be reported as skipped, got %q", results[i].VM.ID, reason)
+		}
+	}
+	if len(results)+len(reasons) != len(nodes[0].VMs) {
+		t.Errorf("Expected every VM on node1 to be planned or skipped, got %d planned and %d skipped", len(results), len(reasons))
+	}
+}
+
+func TestSkippedVMsThresholdReasons(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+	cfg.Balancing.ExcludeVMIDs = []int{101}
+	balancer := NewBalancer(&mockClient{nodes: createTestNodes()}, cfg)
+
+	if _, err := balancer.Run(false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	skipped := balancer.GetSkippedVMs()
+	found := false
+	for _, vm := range skipped {
+		if vm.VMID == 101 {
+			found = true
+			if vm.Reason != skipIgnored {
+				t.Errorf("Expected excluded VM 101 skipped as %q, got %q", skipIgnored, vm.Reason)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected excluded VM 101 among skipped VMs, got %v", skipped)
+	}
+
+	// A balanced cluster evaluates nothing
+	balanced := createTestNodes()
+	balanced[0].CPU.Usage = 40
+	balancer = NewBalancer(&mockClient{nodes: balanced}, cfg)
+	if _, err := balancer.Run(false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if skipped := balancer.GetSkippedVMs(); len(skipped) != 0 {
+		t.Errorf("Expected no skipped VMs in a balanced cluster, got %v", skipped)
+	}
+}
+
+func TestFreezeWindowBlocksMigration(t *testing.T) {
+	now := time.Now()
+	window := func(from, to time.Duration) string {
+		return "plb_freeze_" + now.Add(from).Format("1504") + "-" + now.Add(to).Format("1504")
+	}
+
+	tests := []struct {
+		name   string
+		tag    string
+		frozen bool
+	}{
+		{"inside window", window(-time.Hour, time.Hour), true},
+		{"outside window", window(2*time.Hour, 3*time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := createTestNodes()
+			nodes[0].VMs[1].Tags = append(nodes[0].VMs[1].Tags, tt.tag)
+
+			for _, b := range []interface {
+				Run(force bool) ([]models.BalancingResult, error)
+				GetSkippedVMs() []models.SkippedVM
+			}{
+				NewBalancer(&mockClient{nodes: nodes}, createTestConfig()),
+				NewAdvancedBalancer(&mockClient{nodes: nodes}, createTestConfig()),
+			} {
+				results, err := b.Run(false)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				for _, result := range results {
+					if result.VM.ID == 101 && tt.frozen {
+						t.Errorf("%T: expected frozen VM 101 to stay, got %+v", b, result)
+					}
+				}
+
+				frozen := false
+				for _, vm := range b.GetSkippedVMs() {
+					if vm.VMID == 101 && vm.Reason == skipFrozen {
+						frozen = true
+					}
+				}
+				if frozen != tt.frozen {
+					t.Errorf("%T: expected VM 101 frozen=%v, skipped VMs %v", b, tt.frozen, b.GetSkippedVMs())
+				}
+			}
+		})
+	}
+}
+
+func TestSuspendedVMHandling(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		handle  string
+		skipped bool
+	}{
+		{"paused VM skipped by default", models.VMStatusPaused, "", true},
+		{"suspended VM skipped", models.VMStatusSuspended, config.SuspendedVMsSkip, true},
+		{"paused VM moved offline", models.VMStatusPaused, config.SuspendedVMsOffline, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := createTestNodes()
+			nodes[0].VMs[1].Status = tt.status
+			cfg := createTestConfig()
+			cfg.Balancing.SuspendedVMs = tt.handle
+
+			for _, b := range []interface {
+				Run(force bool) ([]models.BalancingResult, error)
+				GetSkippedVMs() []models.SkippedVM
+			}{
+				NewBalancer(&mockClient{nodes: nodes}, cfg),
+				NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg),
+			} {
+				results, err := b.Run(false)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				for _, result := range results {
+					if result.VM.ID == 101 && tt.skipped {
+						t.Errorf("%T: expected %s VM 101 to stay, got %+v", b, tt.status, result)
+					}
+				}
+
+				skipped := false
+				for _, vm := range b.GetSkippedVMs() {
+					if vm.VMID == 101 && vm.Reason == skipSuspended {
+						skipped = true
+					}
+				}
+				if skipped != tt.skipped {
+					t.Errorf("%T: expected VM 101 skipped as suspended=%v, skipped VMs %v", b, tt.skipped, b.GetSkippedVMs())
+				}
+			}
+
+			// Drains leave held VMs behind, and move the others offline
+			plan, err := planDrain(cfg, newRulesEngine(cfg), nodes, nodes, "node1")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			drained := false
+			for _, migration := range plan.Migrations {
+				drained = drained || migration.VM.ID == 101
+			}
+			if drained == tt.skipped {
+				t.Errorf("Expected %s VM 101 drained=%v, got plan %+v", tt.status, !tt.skipped, plan.Migrations)
+			}
+		})
+	}
+}
+
+func TestBreakInCapsMigrationsUntilItEnds(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.BreakIn = config.BreakInConfig{Cycles: 2}
+	balancer := NewBalancer(&mockClient{nodes: createTestNodes()}, cfg)
+
+	run := func() []models.BalancingResult {
+		results, err := balancer.Run(false)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return results
+	}
+	deferred := func() bool {
+		for _, vm := range balancer.GetSkippedVMs() {
+			if vm.Reason == skipBreakIn {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Read-only cycles are capped, but don't count towards the break-in
+	cfg.ReadOnly = true
+	if results := run(); len(results) != 1 || !deferred() {
+		t.Fatalf("Expected a single read-only migration during break-in, got %d", len(results))
+	}
+	cfg.ReadOnly = false
+
+	for cycle := 1; cycle <= 2; cycle++ {
+		if results := run(); len(results) != 1 || !deferred() {
+			t.Errorf("Expected a single migration in break-in cycle %d, got %d (skipped %v)", cycle, len(results), balancer.GetSkippedVMs())
+		}
+	}
+	if results := run(); len(results) != 2 || deferred() {
+		t.Errorf("Expected the cap lifted after the break-in, got %d migrations (skipped %v)", len(results), balancer.GetSkippedVMs())
+	}
+}
+
+func TestBreakInDuration(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.BreakIn = config.BreakInConfig{Cycles: 1, Duration: "24h"}
+	migrations := []models.Migration{
+		{VM: models.VM{ID: 100, Name: "web"}, FromNode: "node1", ToNode: "node2"},
+		{VM: models.VM{ID: 101, Name: "db"}, FromNode: "node1", ToNode: "node3"},
+	}
+	results := []models.BalancingResult{{VM: migrations[0].VM, Success: true}}
+
+	state := &breakIn{}
+	start := time.Now()
+	kept, skipped := state.limit(cfg, migrations, start)
+	if len(kept) != 1 || kept[0].VM.ID != 100 || len(skipped) != 1 || skipped[0].VMID != 101 {
+		t.Fatalf("Expected VM 100 kept and VM 101 deferred, got %v and %v", kept, skipped)
+	}
+	state.completed(cfg, results, start)
+
+	// The cycles have run, but the break-in lasts until its duration passed too
+	if kept, _ := state.limit(cfg, migrations, start.Add(23*time.Hour)); len(kept) != 1 {
+		t.Errorf("Expected the cap within the break-in duration, got %d migrations", len(kept))
+	}
+	if kept, skipped := state.limit(cfg, migrations, start.Add(25*time.Hour)); len(kept) != 2 || len(skipped) != 0 {
+		t.Errorf("Expected the cap lifted after the break-in duration, got %d migrations", len(kept))
+	}
+
+	// Without a break-in, nothing is capped
+	if kept, _ := (&breakIn{}).limit(createTestConfig(), migrations, start); len(kept) != 2 {
+		t.Errorf("Expected no cap without a break-in, got %d migrations", len(kept))
+	}
+}
+
+func TestFilterOvercommitTargets(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	vm := &models.VM{ID: 100, Node: "node1", Status: "running", CPUs: 4, MaxMemory: 8 * gib}
+	nodes := []models.Node{
+		{Name: "node1", CPU: models.CPUInfo{Cores: 8}, Memory: models.MemoryInfo{Total: 64 * gib}, VMs: []models.VM{*vm}},
+		// 20 vCPUs on 8 cores: 24 with the VM is exactly 3:1
+		{Name: "node2", CPU: models.CPUInfo{Cores: 8}, Memory: models.MemoryInfo{Total: 64 * gib}, VMs: []models.VM{
+			{ID: 200, Status: "running", CPUs: 20, MaxMemory: 32 * gib},
+			{ID: 201, Status: "stopped", CPUs: 16, MaxMemory: 64 * gib},
+		}},
+		// 24 vCPUs on 8 cores: 28 with the VM is 3.5:1
+		{Name: "node3", CPU: models.CPUInfo{Cores: 8}, Memory: models.MemoryInfo{Total: 64 * gib}, VMs: []models.VM{
+			{ID: 300, Status: "running", CPUs: 24, MaxMemory: 16 * gib},
+		}},
+		// 72 GiB on 64 GiB: 80 GiB with the VM is 1.25:1
+		{Name: "node4", CPU: models.CPUInfo{Cores: 32}, Memory: models.MemoryInfo{Total: 64 * gib}, VMs: []models.VM{
+			{ID: 400, Status: "running", CPUs: 4, MaxMemory: 72 * gib},
+		}},
+	}
+	targets := []models.NodeScore{{Node: "node2"}, {Node: "node3"}, {Node: "node4"}}
+
+	tests := []struct {
+		name     string
+		limits   config.OvercommitConfig
+		expected []string
+	}{
+		{"disabled", config.OvercommitConfig{}, []string{"node2", "node3", "node4"}},
+		{"vcpu limit", config.OvercommitConfig{CPU: 3}, []string{"node2", "node4"}},
+		{"memory limit", config.OvercommitConfig{Memory: 1.2}, []string{"node2", "node3"}},
+		{"both limits", config.OvercommitConfig{CPU: 3, Memory: 1.2}, []string{"node2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, score := range filterOvercommitTargets(tt.limits, vm, nodes, targets) {
+				got = append(got, score.Node)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected targets %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestOvercommitLimitRejectsPlacement(t *testing.T) {
+	nodes := createTestNodes()
+	nodes[0].VMs[1].CPUs = 4
+	// node3 is the least loaded but already runs 28 vCPUs on 8 cores
+	nodes[2].VMs = []models.VM{{ID: 103, Name: "busy-vm", Node: "node3", Status: "running", CPUs: 28}}
+
+	tests := []struct {
+		name      string
+		limit     float64
+		wantNode3 bool
+	}{
+		{"no limit", 0, true},
+		{"3:1 vCPU limit", 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.Overcommit.CPU = tt.limit
+			balancer := NewBalancer(&mockClient{nodes: nodes}, cfg)
+
+			results, err := balancer.Run(false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			movedToNode3 := false
+			for i := range results {
+				if results[i].VM.ID == 101 && results[i].TargetNode == "node3" {
+					movedToNode3 = true
+				}
+			}
+			if movedToNode3 != tt.wantNode3 {
+				t.Errorf("Expected VM 101 moved to node3 %v, got results %v", tt.wantNode3, results)
+			}
+		})
+	}
+}
+
+func TestApplyKSMSavings(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	nodes := []models.Node{
+		{Name: "shared", Memory: models.MemoryInfo{Total: 100 * gib, Used: 90 * gib, Usage: 90}, KSMShared: 20 * gib},
+		{Name: "unshared", Memory: models.MemoryInfo{Total: 100 * gib, Used: 90 * gib, Usage: 90}},
+		{Name: "unknown size", Memory: models.MemoryInfo{Usage: 90}, KSMShared: 20 * gib},
+	}
+
+	applyKSMSavings(nodes)
+
+	expected := []float32{70, 90, 90}
+	for i := range nodes {
+		if math.Abs(float64(nodes[i].Memory.Usage-expected[i])) > 0.01 {
+			t.Errorf("Expected %s memory usage %.0f%%, got %.2f%%", nodes[i].Name, expected[i], nodes[i].Memory.Usage)
+		}
+	}
+}
+
+func TestKSMSavingsPreventEvacuation(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+
+	tests := []struct {
+		name           string
+		ksmShared      int64
+		wantMigrations bool
+	}{
+		{"raw usage over threshold", 0, true},
+		{"page sharing relieves pressure", 20 * gib, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := createTestNodes()
+			// Memory is node1's only pressure: 90% raw against an 85% threshold
+			nodes[0].CPU.Usage = 50
+			nodes[0].Memory = models.MemoryInfo{Total: 100 * gib, Used: 90 * gib, Usage: 90}
+			nodes[0].KSMShared = tt.ksmShared
+
+			for _, balancer := range []interface {
+				Run(force bool) ([]models.BalancingResult, error)
+			}{
+				NewBalancer(&mockClient{nodes: nodes}, createTestConfig()),
+				NewAdvancedBalancer(&mockClient{nodes: nodes}, createTestConfig()),
+			} {
+				results, err := balancer.Run(false)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if got := len(results) > 0; got != tt.wantMigrations {
+					t.Errorf("%T: expected migrations %v, got %d", balancer, tt.wantMigrations, len(results))
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeVMProfileWorkloadClassOverride(t *testing.T) {
+	client := &mockClient{nodes: createTestNodes()}
+	config := createTestConfig()
+	config.Balancing.BalancerType = "advanced"
+	balancer := NewAdvancedBalancer(client, config)
+
+	// Tag heuristics alone would classify this VM as a web workload
+	tagged := &models.VM{ID: 200, Name: "web-cache", Tags: []string{"web", "plb_class_db"}}
+	profile := balancer.AnalyzeVMProfile(tagged, "node1")
+	if profile.WorkloadType != "Database" || profile.CPUBuffer != 40.0 || profile.MemoryBuffer != 50.0 {
+		t.Errorf("Expected explicit db class to win over tag inference, got %+v", profile)
+	}
+
+	// Load profile analysis would classify this VM as CPU intensive
+	balancer.loadProfiles[201] = &models.LoadProfile{
+		CPUPattern:    models.CPUPattern{Type: "sustained", SustainedLevel: 90.0},
+		MemoryPattern: models.MemoryPattern{Type: "static", PeakUsage: 30.0},
+		Priority:      models.PriorityRealtime,
+	}
+	profiled := &models.VM{ID: 201, Name: "frontend", Tags: []string{"plb_class_web"}}
+	profile = balancer.AnalyzeVMProfile(profiled, "node1")
+	if profile.WorkloadType != "Web/Application" || profile.CPUBuffer != 50.0 || profile.MemoryBuffer != 40.0 {
+		t.Errorf("Expected explicit web class to win over load profile inference, got %+v", profile)
+	}
+
+	unknown := &models.VM{ID: 202, Name: "misc", Tags: []string{"database", "plb_class_gpu"}}
+	profile = balancer.AnalyzeVMProfile(unknown, "node1")
+	if profile.WorkloadType != "Database" {
+		t.Errorf("Expected unknown class to keep the inferred profile, got %+v", profile)
+	}
+}
+
+func TestAnalyzeVMProfilePartialHistory(t *testing.T) {
+	// Half a day of history for test-vm-1
+	history := make([]proxmox.HistoricalMetric, profileFullSamples/2)
+	client := &mockClient{
+		nodes:            createTestNodes(),
+		vmHistoricalData: map[string][]proxmox.HistoricalMetric{"node1-100-qemu-day": history},
+	}
+	config := createTestConfig()
+	config.Balancing.BalancerType = "advanced"
+	config.Balancing.LoadProfiles.Enabled = true
+	balancer := NewAdvancedBalancer(client, config)
+
+	nodes := createTestNodes()
+	balancer.updateLoadProfiles(nodes)
+	if samples := balancer.loadProfiles[100].Samples; samples != len(history) {
+		t.Errorf("Expected the load profile to count %d history samples, got %d", len(history), samples)
+	}
+	if profile := balancer.AnalyzeVMProfile(&nodes[0].VMs[0], "node1"); profile.Confidence != 0.5 {
+		t.Errorf("Expected medium confidence 0.5 with half a day of history, got %v", profile.Confidence)
+	}
+	if profile := balancer.AnalyzeVMProfile(&nodes[0].VMs[1], "node1"); profile.Confidence != 0 {
+		t.Errorf("Expected no confidence without history, got %v", profile.Confidence)
+	}
+
+	// History alone calls for 70% CPU and 30% memory buffers, the db tag for 40% and 50%
+	balancer.loadProfiles[300] = &models.LoadProfile{
+		CPUPattern:    models.CPUPattern{Type: "burst"},
+		MemoryPattern: models.MemoryPattern{Type: "static"},
+		Samples:       profileFullSamples / 2,
+	}
+	profile := balancer.AnalyzeVMProfile(&models.VM{ID: 300, Name: "orders", Tags: []string{"db"}}, "node1")
+	if profile.CPUBuffer != 55.0 || profile.MemoryBuffer != 40.0 {
+		t.Errorf("Expected buffers blended halfway to 55%% CPU and 40%% memory, got %v and %v", profile.CPUBuffer, profile.MemoryBuffer)
+	}
+	if profile.WorkloadType != "Burst" || profile.Pattern != "CPU Burst (partial history)" {
+		t.Errorf("Expected the history-based workload at medium confidence, got %s (%s)", profile.WorkloadType, profile.Pattern)
+	}
+
+	balancer.loadProfiles[300].Samples = profileFullSamples
+	profile = balancer.AnalyzeVMProfile(&models.VM{ID: 300, Name: "orders", Tags: []string{"db"}}, "node1")
+	if profile.Confidence != 1 || profile.CPUBuffer != 70.0 || profile.MemoryBuffer != 30.0 {
+		t.Errorf("Expected the history-based profile at full confidence, got %+v", profile)
+	}
+}
+
+func TestAdaptiveAggressiveness(t *testing.T) {
+	client := &mockClient{nodes: createTestNodes()}
+	cfg := createTestConfig()
+	cfg.Balancing.BalancerType = "advanced"
+	cfg.Balancing.Aggressiveness = "adaptive"
+	balancer := NewAdvancedBalancer(client, cfg)
+
+	nodes := createTestNodes()
+	now := time.Now()
+	churn := func(count int, age time.Duration) {
+		for i := 0; i < count; i++ {
+			balancer.migrationHistory = append(balancer.migrationHistory, models.MigrationHistory{
+				VMID: 100 + i, FromNode: "node1", ToNode: "node2", Timestamp: now.Add(-age),
+			})
+		}
+	}
+
+	// A stable cluster gets the high level, migrations older than the window don't count
+	churn(3*len(nodes), 2*adaptiveWindow)
+	balancer.adaptAggressiveness(nodes, now)
+	if got := balancer.aggressivenessConfig(); got != config.AggressivenessLevelConfig("high") {
+		t.Errorf("Expected high aggressiveness on a stable cluster, got %+v", got)
+	}
+
+	// One migration per node lately calls for caution
+	churn(len(nodes), time.Hour)
+	balancer.adaptAggressiveness(nodes, now)
+	if got := balancer.aggressivenessConfig(); got != config.AggressivenessLevelConfig("medium") {
+		t.Errorf("Expected medium aggressiveness with some churn, got %+v", got)
+	}
+
+	// Heavy churn lowers aggressiveness: a higher minimum gain and a longer cooldown
+	churn(2*len(nodes), time.Hour)
+	balancer.adaptAggressiveness(nodes, now)
+	got := balancer.aggressivenessConfig()
+	if got != config.AggressivenessLevelConfig("low") {
+		t.Errorf("Expected low aggressiveness during churn, got %+v", got)
+	}
+	if stable := config.AggressivenessLevelConfig("high"); got.MinImprovement <= stable.MinImprovement || got.CooldownPeriod <= stable.CooldownPeriod {
+		t.Errorf("Expected churn to raise the minimum gain and cooldown over %+v, got %+v", stable, got)
+	}
+
+	// A fixed level ignores the churn
+	cfg.Balancing.Aggressiveness = "high"
+	if got := balancer.aggressivenessConfig(); got != config.AggressivenessLevelConfig("high") {
+		t.Errorf("Expected the configured level without adaptive aggressiveness, got %+v", got)
+	}
+}
+
+func TestExecutePlanAtomicRollback(t *testing.T) {
+	client := &mockClient{
+		nodes:       createTestNodes(),
+		migrateErrs: map[int]error{102: fmt.Errorf("storage not available")},
+	}
+	config := createTestConfig()
+	config.Balancing.BalancerType = "advanced"
+	balancer := NewAdvancedBalancer(client, config)
+
+	// VMs 100 and 101 swap places with VM 102, which fails to migrate
+	plan := &models.MigrationPlan{
+		Atomic: true,
+		Migrations: []models.Migration{
+			{VM: models.VM{ID: 100, Name: "web1"}, FromNode: "node1", ToNode: "node2"},
+			{VM: models.VM{ID: 101, Name: "ntp1"}, FromNode: "node1", ToNode: "node3"},
+			{VM: models.VM{ID: 102, Name: "web2"}, FromNode: "node2", ToNode: "node1"},
+			{VM: models.VM{ID: 103, Name: "db1"}, FromNode: "node3", ToNode: "node1"},
+		},
+	}
+	results := balancer.ExecutePlan(plan)
+
+	want := []string{"100:node1->node2", "101:node1->node3", "102:node2->node1", "101:node3->node1", "100:node2->node1"}
+	if strings.Join(client.migrated, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected migrations %v, got %v", want, client.migrated)
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(results))
+	}
+	if results[2].Success || results[2].ErrorMessage != "storage not available" {
+		t.Errorf("Expected the failing step to be reported, got %+v", results[2])
+	}
+	for _, rollback := range results[3:] {
+		if !rollback.Success || rollback.Reason != reasonRollback {
+			t.Errorf("Expected a successful rollback, got %+v", rollback)
+		}
+	}
+}
+
+func TestExecutePlanNonAtomicContinues(t *testing.T) {
+	client := &mockClient{
+		nodes:       createTestNodes(),
+		migrateErrs: map[int]error{100: fmt.Errorf("vm locked")},
+	}
+	balancer := NewBalancer(client, createTestConfig())
+
+	plan := &models.MigrationPlan{
+		Migrations: []models.Migration{
+			{VM: models.VM{ID: 100, Name: "web1"}, FromNode: "node1", ToNode: "node2"},
+			{VM: models.VM{ID: 101, Name: "ntp1"}, FromNode: "node1", ToNode: "node3"},
+		},
+	}
+	results := balancer.ExecutePlan(plan)
+
+	if len(results) != 2 || results[0].Success || !results[1].Success {
+		t.Errorf("Expected the second migration to run despite the first failing, got %+v", results)
+	}
+	if client.migrateCalls != 2 {
+		t.Errorf("Expected no rollback for a non-atomic plan, got %d migrate calls", client.migrateCalls)
+	}
+}
+
+func TestValidatePlan(t *testing.T) {
+	type move struct {
+		vmID int
+		node string
+	}
+	tests := []struct {
+		name    string
+		setup   func(cfg *config.Config, nodes []models.Node)
+		moves   []move
+		wantErr bool
+	}{
+		{"valid", nil, []move{{101, "node3"}}, false},
+		{"maintenance target", func(cfg *config.Config, nodes []models.Node) {
+			cfg.Cluster.MaintenanceNodes = []string{"node3"}
+		}, []move{{101, "node3"}}, true},
+		{"ignored VM", func(cfg *config.Config, nodes []models.Node) {
+			nodes[0].VMs[1].Tags = []string{"plb_ignore_dev"}
+		}, []move{{101, "node3"}}, true},
+		{"protected VM", func(cfg *config.Config, nodes []models.Node) {
+			cfg.Balancing.ProtectedMinGain = 10
+			nodes[0].VMs[1].Protected = true
+		}, []move{{101, "node3"}}, true},
+		{"affinity peer in place", nil, []move{{102, "node1"}}, false},
+		// VM 102 joins VM 100 on node1 first, so VM 100 leaving for node2 breaks their affinity
+		{"affinity peer moved away", nil, []move{{102, "node1"}, {100, "node2"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			nodes := createTestNodes()
+			if tt.setup != nil {
+				tt.setup(cfg, nodes)
+			}
+
+			vms := make(map[int]models.VM)
+			for i := range nodes {
+				for _, vm := range nodes[i].VMs {
+					vms[vm.ID] = vm
+				}
+			}
+			plan := &models.MigrationPlan{}
+			for _, move := range tt.moves {
+				vm := vms[move.vmID]
+				plan.Migrations = append(plan.Migrations, models.Migration{VM: vm, FromNode: vm.Node, ToNode: move.node})
+			}
+
+			err := NewBalancer(&mockClient{nodes: nodes}, cfg).ValidatePlan(plan)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPanicThresholdBypassesCooldowns(t *testing.T) {
+	tests := []struct {
+		name     string
+		panic    int
+		wantMove bool
+	}{
+		{"panic disabled", 0, false},
+		{"below panic threshold", 99, false},
+		{"past panic threshold", 95, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = "advanced"
+			cfg.Balancing.PanicThreshold = tt.panic
+
+			// node1 is critically loaded, right after a cycle and a migration of VM 101
+			nodes := createTestNodes()
+			nodes[0].CPU.Usage = 98
+			nodes[0].VMs[1].LastMoved = time.Now()
+
+			balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+			balancer.lastRun = time.Now()
+			results, err := balancer.Run(false)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			moved := false
+			for _, result := range results {
+				moved = moved || (result.VM.ID == 101 && result.Success)
+			}
+			if moved != tt.wantMove {
+				t.Errorf("Expected VM 101 moved %v despite cooldowns, got results %+v (skipped %+v)", tt.wantMove, results, balancer.GetSkippedVMs())
+			}
+
+			// The minimum improvement is waived too
+			balancer = NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+			_ = balancer.engine.ProcessVMs(append(append([]models.VM{}, nodes[0].VMs...), nodes[1].VMs...))
+			aggConfig := cfg.GetAggressivenessConfig()
+			aggConfig.MinImprovement = 1000
+			nodes[0].VMs[1].LastMoved = time.Time{}
+			migrations := balancer.findOptimalMigrations(nodes, balancer.calculateAdvancedNodeScores(nodes), aggConfig, false)
+			if (len(migrations) > 0) != tt.wantMove {
+				t.Errorf("Expected migrations despite the minimum improvement %v, got %+v", tt.wantMove, migrations)
+			}
+		})
+	}
+}
+
+// sessionClient is a mock client with guest agent session metrics; VMs without metrics have no agent.
+type sessionClient struct {
+	*mockClient
+	sessions map[int]*models.SessionMetrics
+}
+
+func (c *sessionClient) GetVMSessionMetrics(nodeName string, vmID int) (*models.SessionMetrics, error) {
+	metrics, exists := c.sessions[vmID]
+	if !exists {
+		return nil, fmt.Errorf("QEMU guest agent is not running")
+	}
+	return metrics, nil
+}
+
+func TestSessionMetricsOrderCandidates(t *testing.T) {
+	node := &models.Node{
+		Name: "node1",
+		CPU:  models.CPUInfo{Usage: 90, Cores: 8},
+		VMs: []models.VM{
+			{ID: 200, Name: "gateway", Node: "node1", Type: "qemu", Status: "running", CPU: 0.5, CPUs: 4},
+			{ID: 201, Name: "batch", Node: "node1", Type: "qemu", Status: "running", CPU: 0.4, CPUs: 4},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		enabled   bool
+		sessions  map[int]*models.SessionMetrics
+		wantFirst int
+	}{
+		{"disabled", false, map[int]*models.SessionMetrics{200: {Connections: 800}}, 200},
+		{"no agent", true, map[int]*models.SessionMetrics{}, 200},
+		{"few sessions", true, map[int]*models.SessionMetrics{200: {Processes: 100, Connections: 20}}, 200},
+		{"many connections", true, map[int]*models.SessionMetrics{200: {Processes: 100, Connections: 800}}, 201},
+		{"many processes", true, map[int]*models.SessionMetrics{200: {Processes: 2000, Connections: 20}}, 201},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.Sessions = config.SessionsConfig{Enabled: tt.enabled, HighConnections: 500, HighProcesses: 1000}
+			balancer := NewAdvancedBalancer(&sessionClient{mockClient: &mockClient{}, sessions: tt.sessions}, cfg)
+
+			if first := balancer.orderMigrationCandidates(node)[0].ID; first != tt.wantFirst {
+				t.Errorf("Expected VM %d considered first, got %d", tt.wantFirst, first)
+			}
+		})
+	}
+
+	// A client without guest agent support keeps the plain CPU relief order
+	cfg := createTestConfig()
+	cfg.Balancing.Sessions = config.SessionsConfig{Enabled: true, HighConnections: 500, HighProcesses: 1000}
+	if first := NewAdvancedBalancer(&mockClient{}, cfg).orderMigrationCandidates(node)[0].ID; first != 200 {
+		t.Errorf("Expected VM 200 considered first without agent support, got %d", first)
+	}
+}
+
+func TestNodeRoles(t *testing.T) {
+	// node1 is overloaded, node3 is the best target and node2 the next one
+	tests := []struct {
+		name       string
+		roles      map[string][]string
+		wantTarget string // Target of VM 101, "" when nothing leaves node1
+	}{
+		{"no roles", nil, "node3"},
+		{"explicit both", map[string][]string{config.NodeRoleBoth: {"node1", "node3"}}, "node3"},
+		{"source only source", map[string][]string{config.NodeRoleSourceOnly: {"node1"}}, "node3"},
+		{"target only source", map[string][]string{config.NodeRoleTargetOnly: {"node1"}}, ""},
+		{"excluded source", map[string][]string{config.NodeRoleNone: {"node1"}}, ""},
+		{"target only target", map[string][]string{config.NodeRoleTargetOnly: {"node3"}}, "node3"},
+		{"source only target", map[string][]string{config.NodeRoleSourceOnly: {"node3"}}, "node2"},
+		{"excluded target", map[string][]string{config.NodeRoleNone: {"node3"}}, "node2"},
+		{"no target left", map[string][]string{config.NodeRoleSourceOnly: {"node2", "node3"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Cluster.NodeRoles = tt.roles
+
+			nodes := createTestNodes()
+			allVMs := []models.VM{}
+			for _, node := range nodes {
+				allVMs = append(allVMs, node.VMs...)
+			}
+			targetOf101 := func(migrations []models.Migration) string {
+				for _, migration := range migrations {
+					if migration.VM.ID == 101 {
+						return migration.ToNode
+					}
+				}
+				return ""
+			}
+
+			advanced := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+			_ = advanced.engine.ProcessVMs(allVMs)
+			aggConfig := cfg.GetAggressivenessConfig()
+			aggConfig.MinImprovement = 0
+			migrations := advanced.findOptimalMigrations(nodes, advanced.calculateAdvancedNodeScores(nodes), aggConfig, false)
+			if target := targetOf101(migrations); target != tt.wantTarget {
+				t.Errorf("Advanced: expected VM 101 moved to %q, got %q (skipped %v)", tt.wantTarget, target, advanced.GetSkippedVMs())
+			}
+
+			threshold := NewBalancer(&mockClient{nodes: nodes}, cfg)
+			_ = threshold.engine.ProcessVMs(allVMs)
+			migrations = threshold.findMigrations(nodes, threshold.calculateNodeScores(nodes), false)
+			if target := targetOf101(migrations); target != tt.wantTarget {
+				t.Errorf("Threshold: expected VM 101 moved to %q, got %q (skipped %v)", tt.wantTarget, target, threshold.GetSkippedVMs())
+			}
+		})
+	}
+}
+
+func TestEstimateMigrationDuration(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	cfg := createTestConfig()
+	cfg.Balancing.MigrationBandwidth = 100
+
+	small := estimateMigrationDuration(cfg, &models.VM{Memory: 4 * gib})
+	large := estimateMigrationDuration(cfg, &models.VM{Memory: 1 * gib, MaxMemory: 16 * gib})
+	if small != 41*time.Second {
+		t.Errorf("Expected 4 GiB at 100 MiB/s to take 41s, got %v", small)
+	}
+	if large != 164*time.Second {
+		t.Errorf("Expected 16 GiB of configured memory to take 164s, got %v", large)
+	}
+	if ratio := float64(large) / float64(small); ratio < 3.9 || ratio > 4.1 {
+		t.Errorf("Expected a 4x larger VM to take about 4x longer, got %.2fx", ratio)
+	}
+
+	cfg.Balancing.MigrationBandwidth = 0
+	if estimate := estimateMigrationDuration(cfg, &models.VM{Memory: 4 * gib}); estimate != 0 {
+		t.Errorf("Expected no estimate without a bandwidth, got %v", estimate)
+	}
+}
+
+func TestDryRunReportsMigrationDurations(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	nodes := createTestNodes()
+	nodes[0].VMs[0].CPU, nodes[0].VMs[0].CPUs, nodes[0].VMs[0].Memory = 0.5, 4, 8*gib
+	nodes[0].VMs[1].CPU, nodes[0].VMs[1].CPUs, nodes[0].VMs[1].Memory = 0.25, 2, 2*gib
+
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+	cfg.Balancing.MigrationBandwidth = 100
+
+	results, err := NewBalancer(&mockClient{nodes: nodes}, cfg).Run(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	durations := make(map[int]time.Duration)
+	for _, result := range results {
+		durations[result.VM.ID] = result.EstimatedDuration
+	}
+	if durations[100] != 82*time.Second || durations[101] != 21*time.Second {
+		t.Errorf("Expected 82s for the 8 GiB VM and 21s for the 2 GiB one, got %v", durations)
+	}
+}
+
+func TestMigrateDowntimeHoldsBackBusyVMs(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.MigrationBandwidth = 100
+
+	// 4 vCPUs half busy dirty 64 MiB/s, copied at 100 MiB/s
+	busy := &models.VM{ID: 101, Status: "running", CPU: 0.5, CPUs: 4}
+	if estimate := estimateMigrationDowntime(cfg, busy); estimate != 640*time.Millisecond {
+		t.Errorf("Expected a 640ms downtime estimate, got %v", estimate)
+	}
+
+	tests := []struct {
+		name     string
+		downtime float64
+		held     bool
+	}{
+		{"no migrate_downtime", 0, false},
+		{"downtime within the limit", 1, false},
+		{"downtime above the limit", 0.3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := createTestNodes()
+			vm := &nodes[0].VMs[1]
+			vm.CPU, vm.CPUs, vm.MigrateDowntime = 0.5, 4, tt.downtime
+
+			for _, b := range []interface {
+				Run(force bool) ([]models.BalancingResult, error)
+				GetSkippedVMs() []models.SkippedVM
+			}{
+				NewBalancer(&mockClient{nodes: nodes}, cfg),
+				NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg),
+			} {
+				results, err := b.Run(true)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				for _, result := range results {
+					if result.VM.ID == 101 && tt.held {
+						t.Errorf("%T: expected VM 101 held back by its migrate_downtime, got %+v", b, result)
+					}
+				}
+
+				held := false
+				for _, skipped := range b.GetSkippedVMs() {
+					if skipped.VMID == 101 && strings.HasPrefix(skipped.Reason, "downtime (estimated 640ms") {
+						held = true
+					}
+				}
+				if held != tt.held {
+					t.Errorf("%T: expected VM 101 held=%v, skipped VMs %v", b, tt.held, b.GetSkippedVMs())
+				}
+			}
+		})
+	}
+
+	// Without a migration bandwidth there is no estimate to hold VMs back on
+	cfg.Balancing.MigrationBandwidth = 0
+	if _, exceeded := downtimeExceeded(cfg, &models.VM{Status: "running", CPU: 1, CPUs: 8, MigrateDowntime: 0.1}); exceeded {
+		t.Error("Expected no downtime check without a migration bandwidth")
+	}
+}
+
+func TestDryRunLabelsStorageMigrations(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	nodes := createTestNodes()
+	nodes[0].VMs[0].CPU, nodes[0].VMs[0].CPUs, nodes[0].VMs[0].Memory = 0.5, 4, 8*gib
+	nodes[0].VMs[1].CPU, nodes[0].VMs[1].CPUs, nodes[0].VMs[1].Memory = 0.25, 2, 2*gib
+	// VM 100 has a 2 GiB thick disk on local storage, VM 101 only shared disks
+	nodes[0].VMs[0].Disk, nodes[0].VMs[0].MaxDisk = 2*gib, 2*gib
+
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+	cfg.Balancing.MigrationBandwidth = 100
+
+	results, err := NewBalancer(&mockClient{nodes: nodes}, cfg).Run(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 planned migrations, got %d", len(results))
+	}
+	for _, result := range results {
+		switch result.VM.ID {
+		case 100:
+			if !result.StorageMigration || result.EstimatedDuration != 103*time.Second {
+				t.Errorf("Expected a 103s storage migration for VM 100, got storage=%v in %v", result.StorageMigration, result.EstimatedDuration)
+			}
+		case 101:
+			if result.StorageMigration || result.EstimatedDuration != 21*time.Second {
+				t.Errorf("Expected a 21s memory-only migration for VM 101, got storage=%v in %v", result.StorageMigration, result.EstimatedDuration)
+			}
+		}
+	}
+
+	// Copying local disks costs more than copying the same VM's memory alone
+	balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg)
+	local := nodes[0].VMs[0]
+	shared := local
+	shared.Disk, shared.MaxDisk = 0, 0
+	if localCost, sharedCost := balancer.migrationChurnCost(&local, &nodes[0], &nodes[1]), balancer.migrationChurnCost(&shared, &nodes[0], &nodes[1]); localCost-sharedCost != 0.5 {
+		t.Errorf("Expected a 2 GiB local disk to add 0.5 points of cost, got %.2f vs %.2f", localCost, sharedCost)
+	}
+}
+
+func TestHealthCheckExcludesFailingTarget(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.HealthCheck.Command = `test "$1" != node3`
+
+	nodes := createTestNodes()
+	client := &mockClient{nodes: nodes}
+	balancer := NewBalancer(client, cfg)
+
+	allVMs := []models.VM{}
+	for _, node := range nodes {
+		allVMs = append(allVMs, node.VMs...)
+	}
+	_ = balancer.engine.ProcessVMs(allVMs)
+
+	nodeScores := balancer.calculateNodeScores(nodes)
+	migrations := balancer.findMigrations(nodes, nodeScores, false)
+
+	if len(migrations) == 0 {
+		t.Fatal("Expected migrations to the node passing its health check")
+	}
+	for _, migration := range migrations {
+		if migration.ToNode == "node3" {
+			t.Errorf("Expected node3 failing its health check to be skipped, VM %d was sent there", migration.VM.ID)
+		}
+	}
+}
+
+func TestRunHealthCheck(t *testing.T) {
+	if err := runHealthCheck(`test "$1" = node1`, time.Second, "node1"); err != nil {
+		t.Errorf("Expected passing check, got %v", err)
+	}
+	if err := runHealthCheck(`echo "storage not mounted"; exit 1`, time.Second, "node1"); err == nil || !strings.Contains(err.Error(), "storage not mounted") {
+		t.Errorf("Expected failing check to report its output, got %v", err)
+	}
+	if err := runHealthCheck("sleep 5", 100*time.Millisecond, "node1"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected slow check to time out, got %v", err)
+	}
+}
+
+func TestWithinTolerance(t *testing.T) {
+	nodes := createTestNodes()
+	for i, usage := range []float32{58, 50, 54} {
+		nodes[i].CPU.Usage = usage
+		nodes[i].Memory.Usage = usage - 5
+	}
+
+	tests := []struct {
+		name      string
+		tolerance float64
+		want      bool
+	}{
+		{"disabled", 0, false},
+		{"inside band", 5, true},
+		{"outside band", 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.Tolerance = tt.tolerance
+			if got := withinTolerance(cfg, nodes); got != tt.want {
+				t.Errorf("withinTolerance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToleranceBandSuppressesForcedBalancing(t *testing.T) {
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		t.Run(balancerType, func(t *testing.T) {
+			for _, tolerance := range []float64{0, 10} {
+				cfg := createTestConfig()
+				cfg.Balancing.BalancerType = balancerType
+				cfg.Balancing.ForceMode = config.ForceModeAlways
+				cfg.Balancing.Tolerance = tolerance
+
+				nodes := createBalancedTestNodes()
+				for i, usage := range []float32{60, 52, 55} {
+					nodes[i].CPU.Usage = usage
+					nodes[i].Memory.Usage = usage
+				}
+				client := &mockClient{nodes: nodes}
+
+				var (
+					results []models.BalancingResult
+					err     error
+				)
+				if balancerType == "advanced" {
+					results, err = NewAdvancedBalancer(client, cfg).Run(true)
+				} else {
+					results, err = NewBalancer(client, cfg).Run(true)
+				}
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				if tolerance == 0 && len(results) == 0 {
+					t.Error("Expected a forced balance without tolerance to migrate off the most loaded node")
+				}
+				if tolerance > 0 && (len(results) != 0 || client.migrateCalls != 0) {
+					t.Errorf("Expected no migrations within the tolerance band, got %d results", len(results))
+				}
+			}
+		})
+	}
+}
+
+func TestPhaseTimingsAreRecorded(t *testing.T) {
+	phasesOf := func(timings []models.PhaseTiming) []string {
+		phases := make([]string, 0, len(timings))
+		for _, timing := range timings {
+			if timing.Duration < 0 {
+				t.Errorf("Expected a non-negative duration for phase %s, got %v", timing.Phase, timing.Duration)
+			}
+			phases = append(phases, timing.Phase)
+		}
+		return phases
+	}
+
+	t.Run("threshold", func(t *testing.T) {
+		balancer := NewBalancer(&mockClient{nodes: createTestNodes()}, createTestConfig())
+		if _, err := balancer.Run(false); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		want := []string{phaseGetNodes, phaseRules, phaseScoring, phasePlanning, phaseExecution}
+		if got := phasesOf(balancer.GetPhaseTimings()); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Expected phases %v, got %v", want, got)
+		}
+	})
+
+	t.Run("advanced", func(t *testing.T) {
+		cfg := createTestConfig()
+		cfg.Balancing.LoadProfiles.Enabled = true
+		cfg.Balancing.Capacity.Enabled = true
+		balancer := NewAdvancedBalancer(&mockClient{nodes: createTestNodes()}, cfg)
+		if _, err := balancer.Run(true); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		want := []string{phaseGetNodes, phaseRules, phaseLoadProfiles, phaseCapacityMetrics, phaseScoring, phasePlanning, phaseExecution}
+		if got := phasesOf(balancer.GetPhaseTimings()); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Expected phases %v, got %v", want, got)
+		}
+	})
+
+	t.Run("balanced cluster stops after rules", func(t *testing.T) {
+		balancer := NewBalancer(&mockClient{nodes: createBalancedTestNodes()}, createTestConfig())
+		if _, err := balancer.Run(false); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		want := []string{phaseGetNodes, phaseRules}
+		if got := phasesOf(balancer.GetPhaseTimings()); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Expected phases %v, got %v", want, got)
+		}
+	})
+}
+
+func TestFormatPhaseTimings(t *testing.T) {
+	got := formatPhaseTimings([]models.PhaseTiming{
+		{Phase: phaseGetNodes, Duration: 1500 * time.Millisecond},
+		{Phase: phaseScoring, Duration: 2 * time.Millisecond},
+	})
+	if want := "get_nodes=1.5s scoring=2ms"; got != want {
+		t.Errorf("formatPhaseTimings() = %q, want %q", got, want)
+	}
+}
+
+func TestPoolPoliciesOrderCandidates(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.PoolPolicies = map[string][]string{
+		config.PoolPolicyMovable:   {"scratch"},
+		config.PoolPolicyProtected: {"production"},
+	}
+
+	node := &models.Node{
+		Name: "node1",
+		CPU:  models.CPUInfo{Cores: 8, Usage: 90.0},
+		VMs: []models.VM{
+			{ID: 100, Name: "prod-db", CPU: 1.0, CPUs: 4, Pool: "production"},
+			{ID: 101, Name: "app", CPU: 0.8, CPUs: 4},
+			{ID: 102, Name: "scratch-build", CPU: 0.2, CPUs: 4, Pool: "scratch"},
+		},
+	}
+
+	// Advanced: scratch-pool VMs move first and production-pool VMs last, despite their CPU relief
+	candidates := NewAdvancedBalancer(&mockClient{}, cfg).orderMigrationCandidates(node)
+	for i, wantID := range []int{102, 101, 100} {
+		if candidates[i].ID != wantID {
+			t.Errorf("Advanced candidate %d: expected VM %d, got %d", i, wantID, candidates[i].ID)
+		}
+	}
+
+	// Threshold: the first migration off the overloaded node is the scratch-pool VM
+	nodes := createTestNodes()
+	nodes[0].VMs[0].Pool = "production"
+	nodes[0].VMs[1].Pool = "scratch"
+	balancer := NewBalancer(&mockClient{nodes: nodes}, cfg)
+	migrations := balancer.findMigrations(nodes, balancer.calculateNodeScores(nodes), false)
+	if len(migrations) == 0 {
+		t.Fatal("Expected migrations off the overloaded node")
+	}
+	if migrations[0].VM.ID != 101 {
+		t.Errorf("Expected scratch-pool VM 101 to move first, got %d", migrations[0].VM.ID)
+	}
+}
+
+func TestProtectedPoolNeedsLargerGain(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.ProtectedMinGain = 25.0
+	cfg.Balancing.PoolPolicies = map[string][]string{config.PoolPolicyProtected: {"production"}}
+
+	pooled := &models.VM{ID: 100, Pool: "production"}
+	unpooled := &models.VM{ID: 101, Pool: "scratch"}
+	if protectedGainMet(cfg, pooled, 20) {
+		t.Error("Expected a production-pool VM to need the protected minimum gain")
+	}
+	if !protectedGainMet(cfg, pooled, 30) {
+		t.Error("Expected a production-pool VM to move on a gain above the protected minimum")
+	}
+	if !protectedGainMet(cfg, unpooled, 20) {
+		t.Error("Expected a VM outside protected pools to move on a modest gain")
+	}
+}
+
+func TestMinSpreadRelocatesCrowdedGroup(t *testing.T) {
+	// Four stopped web VMs on three nodes, three of them crowded on node1: the group must span three nodes
+	nodes := []models.Node{
+		{Name: "node1", VMs: []models.VM{
+			{ID: 201, Name: "web1", Node: "node1", Status: "stopped", Tags: []string{"plb_anti_affinity_web", "plb_spread_3_web"}},
+			{ID: 202, Name: "web2", Node: "node1", Status: "stopped", Tags: []string{"plb_anti_affinity_web"}},
+			{ID: 203, Name: "web3", Node: "node1", Status: "stopped", Tags: []string{"plb_anti_affinity_web"}},
+		}},
+		{Name: "node2", VMs: []models.VM{
+			{ID: 204, Name: "web4", Node: "node2", Status: "stopped", Tags: []string{"plb_anti_affinity_web"}},
+		}},
+		{Name: "node3"},
+	}
+	allVMs := []models.VM{}
+	for _, node := range nodes {
+		allVMs = append(allVMs, node.VMs...)
+	}
+	engine := newRulesEngine(createTestConfig())
+	_ = engine.ProcessVMs(allVMs)
+	targets := []models.NodeScore{{Node: "node2", Score: 0.2}, {Node: "node3", Score: 0.3}, {Node: "node1", Score: 0.8}}
+
+	migrations := ruleComplianceMigrations(createTestConfig(), engine, nodes, nodes[:1], targets, nil, nil, nil)
+	if len(migrations) == 0 {
+		t.Fatal("Expected a VM moved off the crowded node")
+	}
+	if migrations[0].ToNode != "node3" {
+		t.Errorf("Expected the first move to the empty node3, got %s", migrations[0].ToNode)
+	}
+}
+
+func TestDuplicateVMIDsAreSkipped(t *testing.T) {
+	nodes := createTestNodes()
+	// VM 100 also shows up on node3, e.g. after a botched restore
+	duplicate := nodes[0].VMs[0]
+	duplicate.Node = "node3"
+	nodes[2].VMs = append(nodes[2].VMs, duplicate)
+
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		t.Run(balancerType, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = balancerType
+			client := &mockClient{nodes: nodes}
+
+			var skipped []models.SkippedVM
+			if balancerType == "advanced" {
+				balancer := NewAdvancedBalancer(client, cfg)
+				if _, err := balancer.Run(true); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				skipped = balancer.GetSkippedVMs()
+			} else {
+				balancer := NewBalancer(client, cfg)
+				if _, err := balancer.Run(true); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				skipped = balancer.GetSkippedVMs()
+			}
+
+			for _, migrated := range client.migrated {
+				if strings.HasPrefix(migrated, "100:") {
+					t.Errorf("Expected the duplicate VM 100 to stay in place, got %s", migrated)
+				}
+			}
+			found := false
+			for _, vm := range skipped {
+				found = found || (vm.VMID == 100 && vm.Reason == skipDuplicate)
+			}
+			if !found {
+				t.Errorf("Expected VM 100 skipped as a duplicate, got %v", skipped)
+			}
+		})
+	}
+}
+
+func TestNewVMLandedOnWrongNodeIsRelocated(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  string
+		created time.Duration
+		want    bool
+	}{
+		{"new VM", "24h", time.Hour, true},
+		{"older VM", "24h", 48 * time.Hour, false},
+		{"window disabled", "", time.Hour, false},
+	}
+
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		for _, tt := range tests {
+			t.Run(balancerType+"/"+tt.name, func(t *testing.T) {
+				cfg := createTestConfig()
+				cfg.Balancing.BalancerType = balancerType
+				cfg.Balancing.NewVMWindow = tt.window
+
+				// The cluster is balanced, but VM 102 landed on node2 while pinned to node3
+				nodes := createBalancedTestNodes()
+				vm := &nodes[1].VMs[0]
+				vm.Tags = []string{"plb_pin_node3"}
+				vm.Created = time.Now().Add(-tt.created)
+				client := &mockClient{nodes: nodes}
+
+				var err error
+				if balancerType == "advanced" {
+					_, err = NewAdvancedBalancer(client, cfg).Run(false)
+				} else {
+					_, err = NewBalancer(client, cfg).Run(false)
+				}
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				moved := len(client.migrated) == 1 && client.migrated[0] == "102:node2->node3"
+				if tt.want && !moved {
+					t.Errorf("Expected the new VM to move to its pinned node, got %v", client.migrated)
+				}
+				if !tt.want && len(client.migrated) != 0 {
+					t.Errorf("Expected no migration on a balanced cluster, got %v", client.migrated)
+				}
+			})
+		}
+	}
+}
+
+func TestClusterOverCapacityRaisesAlert(t *testing.T) {
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		t.Run(balancerType, func(t *testing.T) {
+			flag := filepath.Join(t.TempDir(), "scale-out")
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = balancerType
+			cfg.Balancing.OverCapacity = config.OverCapacityConfig{
+				Action:  config.OverCapacityScaleOut,
+				Command: `echo "$1" >> ` + flag,
+			}
+
+			// Every node is above the CPU threshold
+			nodes := createTestNodes()
+			for i, usage := range []float32{95, 88, 85} {
+				nodes[i].CPU.Usage = usage
+			}
+			client := &mockClient{nodes: nodes}
+
+			var (
+				run    func(bool) ([]models.BalancingResult, error)
+				status func() (*models.ClusterStatus, error)
+				stuck  func() []models.UnschedulableVM
+			)
+			if balancerType == "advanced" {
+				b := NewAdvancedBalancer(client, cfg)
+				run, status, stuck = b.Run, b.GetClusterStatus, b.GetUnschedulableVMs
+			} else {
+				b := NewBalancer(client, cfg)
+				run, status, stuck = b.Run, b.GetClusterStatus, b.GetUnschedulableVMs
+			}
+
+			// The scale-out command runs when the cluster goes over capacity, not on every cycle
+			for cycle := 0; cycle < 2; cycle++ {
+				results, err := run(false)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(results) != 0 || client.migrateCalls != 0 {
+					t.Errorf("Expected no migrations on a cluster over capacity, got %d results", len(results))
+				}
+			}
+			if vms := stuck(); len(vms) != 0 {
+				t.Errorf("Expected the cluster alert instead of unschedulable VMs, got %v", vms)
+			}
+
+			output, err := os.ReadFile(flag)
+			if err != nil {
+				t.Fatalf("Expected the scale-out command to run, got %v", err)
+			}
+			if string(output) != "3\n" {
+				t.Errorf("Expected the scale-out command to run once with the node count, got %q", output)
+			}
+
+			clusterStatus, err := status()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !clusterStatus.OverCapacity {
+				t.Error("Expected the cluster status to flag it over capacity")
+			}
+
+			// Once a node has room again, the cluster is no longer over capacity
+			client.nodes[2].CPU.Usage = 20
+			if clusterStatus, _ = status(); clusterStatus.OverCapacity {
+				t.Error("Expected the over capacity flag to clear once a node has room")
+			}
+		})
+	}
+}
+
+func TestOverloadHysteresisPerResource(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Balancing.Thresholds = config.ResourceThresholds{CPU: 80, Memory: 85, Storage: 90}
+	cfg.Balancing.Hysteresis = config.ResourceHysteresis{CPU: 10, Memory: 0, Storage: 2}
+
+	tests := []struct {
+		name    string
+		cycles  [][3]float32 // CPU, memory, storage usage per cycle
+		overall []bool
+	}{
+		{"CPU stays overloaded inside its wide band", [][3]float32{{85, 50, 50}, {75, 50, 50}, {71, 50, 50}}, []bool{true, true, true}},
+		{"CPU re-arms below its band", [][3]float32{{85, 50, 50}, {70, 50, 50}, {75, 50, 50}}, []bool{true, false, false}},
+		{"memory without a margin clears at once", [][3]float32{{50, 90, 50}, {50, 84, 50}}, []bool{true, false}},
+		{"storage keeps its narrow band", [][3]float32{{50, 50, 92}, {50, 50, 89}, {50, 50, 87}}, []bool{true, true, false}},
+		{"usage under the threshold never overloads", [][3]float32{{75, 80, 89}}, []bool{false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newOverloadState()
+			for i, usage := range tt.cycles {
+				node := &models.Node{Name: "node1"}
+				node.CPU.Usage, node.Memory.Usage, node.Storage.Usage = usage[0], usage[1], usage[2]
+				if got := state.overloaded(cfg, node); got != tt.overall[i] {
+					t.Errorf("Cycle %d with usage %v: overloaded = %v, want %v", i+1, usage, got, tt.overall[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHysteresisKeepsBalancingNodeInBand(t *testing.T) {
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		t.Run(balancerType, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = balancerType
+			cfg.Balancing.Hysteresis.CPU = 10
+
+			client := &mockClient{nodes: createTestNodes()}
+			var needsBalancing func([]models.Node) bool
+			if balancerType == "advanced" {
+				needsBalancing = NewAdvancedBalancer(client, cfg).needsBalancing
+			} else {
+				needsBalancing = NewBalancer(client, cfg).needsBalancing
+			}
+
+			if !needsBalancing(client.nodes) {
+				t.Fatal("Expected node1 over the CPU threshold to need balancing")
+			}
+
+			// Just under the threshold, node1 is still inside its band
+			client.nodes[0].CPU.Usage = float32(cfg.Balancing.Thresholds.CPU) - 5
+			if !needsBalancing(client.nodes) {
+				t.Error("Expected node1 inside its CPU hysteresis band to still need balancing")
+			}
+
+			client.nodes[0].CPU.Usage = float32(cfg.Balancing.Thresholds.CPU) - 15
+			if needsBalancing(client.nodes) {
+				t.Error("Expected node1 below its CPU hysteresis band to no longer need balancing")
+			}
+		})
+	}
+}
+
+func TestDrainOrderRespectsDependencies(t *testing.T) {
+	tests := []struct {
+		name string
+		vms  []models.VM
+		want []int
+	}{
+		{
+			name: "dependencies first, then boot order",
+			vms: []models.VM{
+				{ID: 100, Name: "app", BootOrder: 2, Tags: []string{"plb_depends_on_102"}},
+				{ID: 101, Name: "web", BootOrder: 1, Tags: []string{"plb_depends_on_100"}},
+				{ID: 102, Name: "db", BootOrder: 3},
+				{ID: 103, Name: "misc"},
+				{ID: 104, Name: "dns", BootOrder: 1},
+			},
+			want: []int{104, 102, 100, 101, 103},
+		},
+		{
+			name: "dependencies on other nodes don't hold a VM back",
+			vms: []models.VM{
+				{ID: 100, Name: "app", BootOrder: 1, Tags: []string{"plb_depends_on_200"}},
+				{ID: 101, Name: "web", BootOrder: 2},
+			},
+			want: []int{100, 101},
+		},
+		{
+			name: "cycles fall back to boot order",
+			vms: []models.VM{
+				{ID: 100, Name: "a", BootOrder: 2, Tags: []string{"plb_depends_on_101"}},
+				{ID: 101, Name: "b", BootOrder: 1, Tags: []string{"plb_depends_on_100"}},
+				{ID: 102, Name: "c", BootOrder: 3},
+			},
+			want: []int{102, 101, 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newRulesEngine(createTestConfig())
+			if err := engine.ProcessVMs(tt.vms); err != nil {
+				t.Fatalf("Failed to process VMs: %v", err)
+			}
+
+			var got []int
+			for _, vm := range drainOrder(engine, tt.vms) {
+				got = append(got, vm.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected drain order %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDrainNodeMigratesInDependencyOrder(t *testing.T) {
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		t.Run(balancerType, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.BalancerType = balancerType
+
+			nodes := createTestNodes()
+			nodes[0].VMs = []models.VM{
+				{ID: 110, Name: "web", Node: "node1", Status: "running", BootOrder: 1, Tags: []string{"plb_depends_on_111"}},
+				{ID: 111, Name: "db", Node: "node1", Status: "running", BootOrder: 2},
+				{ID: 112, Name: "pinned", Node: "node1", Status: "running", Tags: []string{"plb_pin_node1"}},
+				{ID: 113, Name: "ignored", Node: "node1", Status: "running", Tags: []string{"plb_ignore_backup"}},
+			}
+			client := &mockClient{nodes: nodes}
+
+			var drainer interface {
+				PlanDrain(node string) (*models.MigrationPlan, error)
+				ExecutePlan(plan *models.MigrationPlan) []models.BalancingResult
+			}
+			if balancerType == "advanced" {
+				drainer = NewAdvancedBalancer(client, cfg)
+			} else {
+				drainer = NewBalancer(client, cfg)
+			}
+
+			plan, err := drainer.PlanDrain("node1")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			drainer.ExecutePlan(plan)
+
+			// The pinned and ignored VMs stay; the database moves before the web server using it
+			if len(client.migrated) != 2 ||
+				!strings.HasPrefix(client.migrated[0], "111:node1->") ||
+				!strings.HasPrefix(client.migrated[1], "110:node1->") {
+				t.Errorf("Expected VM 111 then VM 110 to leave node1, got %v", client.migrated)
+			}
+
+			if _, err := drainer.PlanDrain("node9"); err == nil {
+				t.Error("Expected an error draining an unknown node")
+			}
+		})
+	}
+}
+
+func TestSuddenNodeDropAbortsCycle(t *testing.T) {
+	for _, balancerType := range []string{"threshold", "advanced"} {
+		for _, maxDrop := range []float64{0, 0.3} {
+			t.Run(fmt.Sprintf("%s/max_drop_%.1f", balancerType, maxDrop), func(t *testing.T) {
+				cfg := createTestConfig()
+				cfg.Balancing.BalancerType = balancerType
+				cfg.Balancing.MaxNodeDrop = maxDrop
+
+				// The first cycle sees five balanced nodes
+				nodes := createBalancedTestNodes()
+				for _, name := range []string{"node4", "node5"} {
+					extra := nodes[2]
+					extra.Name = name
+					extra.VMs = nil
+					nodes = append(nodes, extra)
+				}
+				client := &mockClient{nodes: nodes}
+
+				var run func(bool) ([]models.BalancingResult, error)
+				if balancerType == "advanced" {
+					run = NewAdvancedBalancer(client, cfg).Run
+				} else {
+					run = NewBalancer(client, cfg).Run
+				}
+				if _, err := run(false); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				// Then two nodes vanish while node1 is overloaded
+				client.nodes = createTestNodes()
+				if _, err := run(false); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if maxDrop > 0 && client.migrateCalls != 0 {
+					t.Errorf("Expected no migrations after losing 40%% of the nodes, got %d", client.migrateCalls)
+				}
+				if maxDrop == 0 && client.migrateCalls == 0 {
+					t.Error("Expected migrations with the node drop check disabled")
+				}
+
+				// The smaller cluster is accepted on the next cycle
+				if _, err := run(true); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if client.migrateCalls == 0 {
+					t.Error("Expected the next cycle to balance the remaining nodes")
+				}
+			})
+		}
+	}
+}
+
+func TestNextRunAdvancesAfterEachCycle(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+	balancers := map[string]interface {
+		Run(force bool) ([]models.BalancingResult, error)
+		GetClusterStatus() (*models.ClusterStatus, error)
+	}{
+		"threshold": NewBalancer(&mockClient{nodes: createTestNodes()}, cfg),
+		"advanced":  NewAdvancedBalancer(&mockClient{nodes: createTestNodes()}, cfg),
+	}
+
+	for name, b := range balancers {
+		t.Run(name, func(t *testing.T) {
+			status, err := b.GetClusterStatus()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !status.LastRun.IsZero() || !status.NextRun.IsZero() {
+				t.Fatalf("Expected no schedule before the first cycle, got last %v next %v", status.LastRun, status.NextRun)
+			}
+
+			var previous time.Time
+			for cycle := 0; cycle < 2; cycle++ {
+				if _, err := b.Run(false); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				status, err := b.GetClusterStatus()
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if got := status.NextRun.Sub(status.LastRun); got != 5*time.Minute {
+					t.Errorf("Expected the next run an interval after the last, got %v", got)
+				}
+				if status.NextRun.Before(previous) || status.NextRun.Equal(previous) {
+					t.Errorf("Expected the next run to advance past %v, got %v", previous, status.NextRun)
+				}
+				previous = status.NextRun
+			}
+		})
+	}
+}
+
+func TestNextRunHonorsStartAfter(t *testing.T) {
+	last := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		startAfter string
+		want       time.Time
+	}{
+		{"no start date", "", last.Add(5 * time.Minute)},
+		{"start date passed", "2025-06-01T00:00:00Z", last.Add(5 * time.Minute)},
+		{"deferred, first cycle on the start date", "2025-06-02T11:00:00Z", last.Add(time.Hour)},
+		{"deferred, first cycle after the start date", "2025-06-02T11:02:00Z", last.Add(65 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Balancing.StartAfter = tt.startAfter
+			if got := nextRun(cfg, last); !got.Equal(tt.want) {
+				t.Errorf("Expected next run %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if got := nextRun(createTestConfig(), time.Time{}); !got.IsZero() {
+		t.Errorf("Expected no next run before the first cycle, got %v", got)
+	}
+}
+
+func TestBackupWindowHoldsVMs(t *testing.T) {
+	startedAgo := func(ago time.Duration) []models.BackupSchedule {
+		start := time.Now().Add(-ago)
+		return []models.BackupSchedule{{Start: start.Hour()*60 + start.Minute()}}
+	}
+	tests := []struct {
+		name      string
+		backingUp bool
+		backups   []models.BackupSchedule
+		window    string
+		skipped   bool
+	}{
+		{"backup lock held", true, nil, "", true},
+		{"backup started 30m ago", false, startedAgo(30 * time.Minute), "2h", true},
+		{"backup started 3h ago", false, startedAgo(3 * time.Hour), "2h", false},
+		{"backup window disabled", false, startedAgo(30 * time.Minute), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := createTestNodes()
+			nodes[0].VMs[1].BackingUp = tt.backingUp
+			nodes[0].VMs[1].Backups = tt.backups
+			cfg := createTestConfig()
+			cfg.Balancing.BackupWindow = tt.window
+
+			for _, b := range []interface {
+				Run(force bool) ([]models.BalancingResult, error)
+				GetSkippedVMs() []models.SkippedVM
+			}{
+				NewBalancer(&mockClient{nodes: nodes}, cfg),
+				NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg),
+			} {
+				results, err := b.Run(false)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				for _, result := range results {
+					if result.VM.ID == 101 && tt.skipped {
+						t.Errorf("%T: expected VM 101 to stay during its backup, got %+v", b, result)
+					}
+				}
+
+				skipped := false
+				for _, vm := range b.GetSkippedVMs() {
+					if vm.VMID == 101 && vm.Reason == skipBackup {
+						skipped = true
+					}
+				}
+				if skipped != tt.skipped {
+					t.Errorf("%T: expected VM 101 skipped for its backup=%v, skipped VMs %v", b, tt.skipped, b.GetSkippedVMs())
+				}
+			}
+
+			plan, err := planDrain(cfg, newRulesEngine(cfg), nodes, nodes, "node1")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			drained := false
+			for _, migration := range plan.Migrations {
+				drained = drained || migration.VM.ID == 101
+			}
+			if drained == tt.skipped {
+				t.Errorf("Expected VM 101 drained=%v, got plan %+v", !tt.skipped, plan.Migrations)
+			}
+		})
+	}
+}
+
+func TestLastBackupStart(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2025, 6, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule models.BackupSchedule
+		want     time.Time
+	}{
+		{"daily, started today", models.BackupSchedule{Start: 2 * 60}, time.Date(2025, 6, 4, 2, 0, 0, 0, time.UTC)},
+		{"daily, starts later today", models.BackupSchedule{Start: 21 * 60}, time.Date(2025, 6, 3, 21, 0, 0, 0, time.UTC)},
+		{"weekly on saturday", models.BackupSchedule{Days: []time.Weekday{time.Saturday}, Start: 60}, time.Date(2025, 5, 31, 1, 0, 0, 0, time.UTC)},
+		{"weekly on wednesday, later today", models.BackupSchedule{Days: []time.Weekday{time.Wednesday}, Start: 11 * 60}, time.Date(2025, 5, 28, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := lastBackupStart(tt.schedule, now)
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("Expected last start %v, got %v (found %v)", tt.want, got, ok)
+			}
+		})
+	}
+}
+
+func TestPassthroughVMsStayOnCapableNodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		passthrough []string
+		pinned      bool
+		handle      string
+		gpuNodes    []string
+		wantTargets []string // Nodes VM 101 may move to, nil when it stays
+		wantSkip    bool     // Skipped for its passthrough devices
+	}{
+		{"GPU on node3 only", []string{"gpu"}, false, "", []string{"node1", "node3"}, []string{"node3"}, false},
+		{"GPU nowhere else", []string{"gpu"}, false, config.PassthroughRestrict, []string{"node1"}, nil, false},
+		{"passthrough skip", []string{"gpu"}, false, config.PassthroughSkip, []string{"node1", "node2", "node3"}, nil, true},
+		{"raw host device", nil, true, "", []string{"node1", "node2", "node3"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := createTestNodes()
+			nodes[0].VMs[1].Passthrough = tt.passthrough
+			nodes[0].VMs[1].PassthroughPinned = tt.pinned
+			for i := range nodes {
+				for _, gpuNode := range tt.gpuNodes {
+					if nodes[i].Name == gpuNode {
+						nodes[i].PCIMappings = []string{"gpu"}
+					}
+				}
+			}
+			cfg := createTestConfig()
+			cfg.Balancing.Passthrough = tt.handle
+
+			for _, b := range []interface {
+				Run(force bool) ([]models.BalancingResult, error)
+				GetSkippedVMs() []models.SkippedVM
+			}{
+				NewBalancer(&mockClient{nodes: nodes}, cfg),
+				NewAdvancedBalancer(&mockClient{nodes: nodes}, cfg),
+			} {
+				results, err := b.Run(false)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				for _, result := range results {
+					if result.VM.ID != 101 {
+						continue
+					}
+					allowed := false
+					for _, target := range tt.wantTargets {
+						allowed = allowed || result.TargetNode == target
+					}
+					if !allowed {
+						t.Errorf("%T: expected VM 101 only on %v, got moved to %s", b, tt.wantTargets, result.TargetNode)
+					}
+				}
+
+				skipped := false
+				for _, vm := range b.GetSkippedVMs() {
+					if vm.VMID == 101 && vm.Reason == skipPassthrough {
+						skipped = true
+					}
+				}
+				if skipped != tt.wantSkip {
+					t.Errorf("%T: expected VM 101 skipped for passthrough=%v, skipped VMs %v", b, tt.wantSkip, b.GetSkippedVMs())
+				}
+			}
+
+			// Drains only move the VM to a node with its devices
+			engine := newRulesEngine(cfg)
+			engine.SetNodePCIMappings(nodes)
+			plan, err := planDrain(cfg, engine, nodes, nodes, "node1")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var drainedTo string
+			for _, migration := range plan.Migrations {
+				if migration.VM.ID == 101 {
+					drainedTo = migration.ToNode
+				}
+			}
+			switch {
+			case tt.wantTargets == nil && drainedTo != "":
+				t.Errorf("Expected VM 101 left on node1 by the drain, got moved to %s", drainedTo)
+			case tt.wantTargets != nil && drainedTo != tt.wantTargets[0]:
+				t.Errorf("Expected VM 101 drained to %s, got %q", tt.wantTargets[0], drainedTo)
+			}
+		})
+	}
+}
+
+// consolidationNodes returns three 16-core, 16GiB nodes: node1 lightly loaded with two small VMs,
+// node2 with one VM taking 37.5% of the memory and node3, the busiest, with room for node1's VMs only.
+func consolidationNodes() []models.Node {
+	node := func(name string, usage float32, vms ...models.VM) models.Node {
+		for i := range vms {
+			vms[i].Node = name
+			vms[i].Status = "running"
+		}
+		return models.Node{
+			Name:    name,
+			Status:  "online",
+			CPU:     models.CPUInfo{Cores: 16, Usage: usage},
+			Memory:  models.MemoryInfo{Total: 16 << 30, Usage: usage},
+			Storage: models.StorageInfo{Total: 100 << 30, Usage: 10},
+			VMs:     vms,
+		}
+	}
+	return []models.Node{
+		node("node1", 10,
+			models.VM{ID: 200, Name: "small-1", CPU: 0.5, CPUs: 2, Memory: 1 << 30},
+			models.VM{ID: 201, Name: "small-2", CPU: 0.5, CPUs: 2, Memory: 1 << 30}),
+		node("node2", 40, models.VM{ID: 202, Name: "large", CPU: 0.5, CPUs: 2, Memory: 6 << 30}),
+		node("node3", 50, models.VM{ID: 203, Name: "busy", CPU: 0.5, CPUs: 2, Memory: 1 << 30}),
+	}
+}
+
+func TestConsolidationEmptiesLeastLoadedNode(t *testing.T) {
+	client := &mockClient{nodes: consolidationNodes()}
+	b := NewConsolidationBalancer(client, createTestConfig())
+
+	results, err := b.Run(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// node1's VMs are packed onto node3, the busiest node with room; node2's VM fits nowhere
+	want := []string{"200:node1->node3", "201:node1->node3"}
+	if strings.Join(client.migrated, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected migrations %v, got %v", want, client.migrated)
+	}
+	for i := range results {
+		if results[i].Reason != reasonConsolidation {
+			t.Errorf("Expected reason %q, got %q", reasonConsolidation, results[i].Reason)
+		}
+	}
+	if emptied := b.GetEmptiedNodes(); len(emptied) != 1 || emptied[0] != "node1" {
+		t.Errorf("Expected node1 left empty, got %v", emptied)
+	}
+}
+
+func TestConsolidationKeepsNodeWithUnmovableVM(t *testing.T) {
+	nodes := consolidationNodes()
+	nodes[0].VMs[1].Tags = []string{"plb_pin_node1"}
+	cfg := createTestConfig()
+	cfg.ReadOnly = true
+	b := NewConsolidationBalancer(&mockClient{nodes: nodes}, cfg)
+
+	results, err := b.Run(false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Moving only some of node1's VMs saves nothing: they all stay
+	for i := range results {
+		if results[i].SourceNode == "node1" {
+			t.Errorf("Expected no migration off node1, got VM %d to %s", results[i].VM.ID, results[i].TargetNode)
+		}
+	}
+	skipped := 0
+	for _, vm := range b.GetSkippedVMs() {
+		if vm.Node == "node1" && strings.HasPrefix(vm.Reason, "consolidation") {
+			skipped++
+		}
+	}
+	if skipped != 2 {
+		t.Errorf("Expected both node1 VMs skipped for consolidation, got %v", b.GetSkippedVMs())
+	}
+	for _, name := range b.GetEmptiedNodes() {
+		if name == "node1" {
+			t.Error("Expected node1 not to be reported empty")
+		}
+	}
+}
+
+func TestConsolidationRespectsAntiAffinityBetweenPlannedMoves(t *testing.T) {
+	nodes := consolidationNodes()
+	nodes[0].VMs[0].Tags = []string{"plb_anti_affinity_dns"}
+	nodes[0].VMs[1].Tags = []string{"plb_anti_affinity_dns"}
+	cfg := createTestConfig()
+	engine := newRulesEngine(cfg)
+	var vms []models.VM
+	for i := range nodes {
+		vms = append(vms, nodes[i].VMs...)
+	}
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	migrations, _ := planConsolidation(cfg, engine, nodes, nodes, time.Now())
+
+	targets := make(map[string]int)
+	for i := range migrations {
+		targets[migrations[i].ToNode]++
+	}
+	if targets["node3"] > 1 || targets["node2"] > 1 {
+		t.Errorf("Expected anti-affinity peers on different nodes, got %v", migrations)
+	}
+}
+
+// slaNodes returns three 16-core, 16GiB nodes below the 80% CPU threshold: node1 at 70% CPU, 30 points
+// above the cluster mean, node2 at 40% and node3 at 10%. Each hosts two 2-core VMs.
+func slaNodes() []models.Node {
+	node := func(name string, usage float32, firstID int) models.Node {
+		vms := []models.VM{
+			{ID: firstID, Name: fmt.Sprintf("vm-%d", firstID), Node: name, Status: "running", CPU: 0.5, CPUs: 2, Memory: 1 << 30},
+			{ID: firstID + 1, Name: fmt.Sprintf("vm-%d", firstID+1), Node: name, Status: "running", CPU: 0.5, CPUs: 2, Memory: 1 << 30},
+		}
+		return models.Node{
+			Name:    name,
+			Status:  "online",
+			CPU:     models.CPUInfo{Cores: 16, Usage: usage},
+			Memory:  models.MemoryInfo{Total: 16 << 30, Usage: 30},
+			Storage: models.StorageInfo{Total: 100 << 30, Usage: 10},
+			VMs:     vms,
+		}
+	}
+	return []models.Node{node("node1", 70, 300), node("node2", 40, 310), node("node3", 10, 320)}
+}
+
+func TestSLABreaches(t *testing.T) {
+	cfg := createTestConfig()
+	if breaches := slaBreaches(cfg, slaNodes()); breaches != nil {
+		t.Errorf("Expected no breach without an SLA, got %v", breaches)
+	}
+
+	cfg.Balancing.ImbalanceSLA = 20
+	breaches := slaBreaches(cfg, slaNodes())
+	if len(breaches) != 1 {
+		t.Fatalf("Expected 1 breach, got %v", breaches)
+	}
+	if breaches[0].Node != "node1" || breaches[0].Resource != "cpu" || breaches[0].Mean != 40 || breaches[0].Excess != 30 {
+		t.Errorf("Expected node1 CPU 30 points above the mean of 40, got %+v", breaches[0])
+	}
+
+	cfg.Balancing.ImbalanceSLA = 30
+	if breaches := slaBreaches(cfg, slaNodes()); len(breaches) != 0 {
+		t.Errorf("Expected node1 exactly at the SLA to be within it, got %v", breaches)
+	}
+}
+
+func TestSLABreachDrivesMigrations(t *testing.T) {
+	type balancer interface {
+		Run(force bool) ([]models.BalancingResult, error)
+		GetClusterStatus() (*models.ClusterStatus, error)
+	}
+	constructors := map[string]func(client proxmox.ClientInterface, cfg *config.Config) balancer{
+		"threshold": func(client proxmox.ClientInterface, cfg *config.Config) balancer { return NewBalancer(client, cfg) },
+		"advanced": func(client proxmox.ClientInterface, cfg *config.Config) balancer {
+			return NewAdvancedBalancer(client, cfg)
+		},
+	}
+
+	for name, newTestBalancer := range constructors {
+		t.Run(name, func(t *testing.T) {
+			cfg := createTestConfig()
+
+			// Below its thresholds, node1 is left alone without an SLA
+			client := &mockClient{nodes: slaNodes()}
+			b := newTestBalancer(client, cfg)
+			if _, err := b.Run(false); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(client.migrated) != 0 {
+				t.Fatalf("Expected no migration without an SLA, got %v", client.migrated)
+			}
+
+			// With a 20 point SLA, node1's VMs move to relieve it, to the least loaded node
+			cfg.Balancing.ImbalanceSLA = 20
+			client = &mockClient{nodes: slaNodes()}
+			b = newTestBalancer(client, cfg)
+			if _, err := b.Run(false); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(client.migrated) == 0 {
+				t.Fatal("Expected migrations relieving node1")
+			}
+			for _, migration := range client.migrated {
+				if !strings.Contains(migration, ":node1->node3") {
+					t.Errorf("Expected only moves from node1 to node3, got %v", client.migrated)
+				}
+			}
+
+			status, err := b.GetClusterStatus()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(status.SLABreaches) != 1 || status.SLABreaches[0].Node != "node1" {
+				t.Errorf("Expected node1 reported in breach of the SLA, got %v", status.SLABreaches)
+			}
+		})
+	}
+}
+
+func TestMigrationsReportGoverningRules(t *testing.T) {
+	type balancer interface {
+		Run(force bool) ([]models.BalancingResult, error)
+		GetSkippedVMs() []models.SkippedVM
+	}
+	constructors := map[string]func(client proxmox.ClientInterface, cfg *config.Config) balancer{
+		"threshold": func(client proxmox.ClientInterface, cfg *config.Config) balancer { return NewBalancer(client, cfg) },
+		"advanced": func(client proxmox.ClientInterface, cfg *config.Config) balancer {
+			return NewAdvancedBalancer(client, cfg)
+		},
+	}
+
+	for name, newTestBalancer := range constructors {
+		t.Run(name, func(t *testing.T) {
+			// test-vm-2 must spread away from ntp-2, the pinned VM can't leave node1
+			nodes := createTestNodes()
+			nodes[0].VMs = append(nodes[0].VMs,
+				models.VM{ID: 103, Name: "pinned", Node: "node1", Status: "running", Tags: []string{"plb_pin_node1"}})
+			nodes[1].VMs = append(nodes[1].VMs,
+				models.VM{ID: 104, Name: "ntp-2", Node: "node2", Status: "running", Tags: []string{"plb_anti_affinity_ntp"}})
+
+			cfg := createTestConfig()
+			cfg.ReadOnly = true
+			b := newTestBalancer(&mockClient{nodes: nodes}, cfg)
+			results, err := b.Run(false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			spread := false
+			for i := range results {
+				if results[i].VM.ID == 101 {
+					spread = true
+					if results[i].TargetNode != "node3" || !reflect.DeepEqual(results[i].Rules, []string{"anti-affinity spread (ntp)"}) {
+						t.Errorf("Expected VM 101 spread to node3 by its anti-affinity rule, got %s %v", results[i].TargetNode, results[i].Rules)
+					}
+				}
+			}
+			if !spread {
+				t.Errorf("Expected VM 101 planned to move, got %+v", results)
+			}
+
+			pinned := false
+			for _, vm := range b.GetSkippedVMs() {
+				if vm.VMID == 103 {
+					pinned = true
+					if !reflect.DeepEqual(vm.Rules, []string{"pin (node1)"}) {
+						t.Errorf("Expected the pinned VM kept in place by its pin, got %q %v", vm.Reason, vm.Rules)
+					}
+				}
+			}
+			if !pinned {
+				t.Errorf("Expected the pinned VM reported as skipped, got %+v", b.GetSkippedVMs())
+			}
+		})
+	}
+}
+
+func TestMigrationHistoryRecordsGoverningRules(t *testing.T) {
+	nodes := createTestNodes()
+	nodes[1].VMs = append(nodes[1].VMs,
+		models.VM{ID: 104, Name: "ntp-2", Node: "node2", Status: "running", Tags: []string{"plb_anti_affinity_ntp"}})
+
+	balancer := NewAdvancedBalancer(&mockClient{nodes: nodes}, createTestConfig())
+	if _, err := balancer.Run(false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	recorded := false
+	for _, migration := range balancer.GetMigrationHistory() {
+		if migration.VMID == 101 {
+			recorded = true
+			if !reflect.DeepEqual(migration.Rules, []string{"anti-affinity spread (ntp)"}) {
+				t.Errorf("Expected VM 101's migration recorded with its anti-affinity rule, got %v", migration.Rules)
+			}
+		}
+	}
+	if !recorded {
+		t.Errorf("Expected VM 101's migration in the history, got %+v", balancer.GetMigrationHistory())
+	}
+}
@@ -0,0 +1,94 @@
+package balancer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// breakInLimit is how many migrations a cycle runs during the break-in.
+const breakInLimit = 1
+
+// breakIn tracks the break-in of a new deployment: the cycles that ran migrations so far,
+// when the first cycle planned, and whether the break-in ended.
+type breakIn struct {
+	mu      sync.Mutex
+	started time.Time
+	cycles  int
+	ended   bool
+}
+
+// active reports whether the break-in is still on: until both the configured cycles have run
+// and the configured duration has passed since the first cycle. The first call starts the
+// clock; the end of the break-in is logged once.
+func (s *breakIn) active(cfg *config.Config, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !cfg.Balancing.BreakIn.Enabled() || s.ended {
+		return false
+	}
+	if s.started.IsZero() {
+		s.started = now
+	}
+	duration, _ := cfg.GetBreakInDuration() //nolint:errcheck // validated at load time
+	if s.cycles < cfg.Balancing.BreakIn.Cycles || now.Sub(s.started) < duration {
+		return true
+	}
+	s.ended = true
+	logf("Break-in completed after %d cycle(s), balancing with the configured settings from now on\n", s.cycles)
+	return false
+}
+
+// limit caps the planned migrations to breakInLimit during the break-in, logging the plan and
+// the progress of the break-in. The deferred migrations are returned as skipped VMs.
+func (s *breakIn) limit(cfg *config.Config, migrations []models.Migration, now time.Time) ([]models.Migration, []models.SkippedVM) {
+	if !s.active(cfg, now) {
+		return migrations, nil
+	}
+
+	s.mu.Lock()
+	cycle, started := s.cycles+1, s.started
+	s.mu.Unlock()
+
+	breakInConfig := cfg.Balancing.BreakIn
+	progress := fmt.Sprintf("cycle %d", cycle)
+	if breakInConfig.Cycles > 0 {
+		progress += fmt.Sprintf(" of %d", breakInConfig.Cycles)
+	}
+	if duration, _ := cfg.GetBreakInDuration(); duration > 0 { //nolint:errcheck // validated at load time
+		progress += fmt.Sprintf(", %v left", max(duration-now.Sub(started), 0).Round(time.Second))
+	}
+	logf("Break-in (%s): %d migration(s) planned, running at most %d\n", progress, len(migrations), breakInLimit)
+	for i := range migrations {
+		migration := &migrations[i]
+		logf("Break-in: planned VM %s (%d) from %s to %s, gain %.2f\n",
+			migration.VM.Name, migration.VM.ID, migration.FromNode, migration.ToNode, migration.Gain)
+	}
+	if len(migrations) <= breakInLimit {
+		return migrations, nil
+	}
+
+	var skipped []models.SkippedVM
+	for i := breakInLimit; i < len(migrations); i++ {
+		migration := &migrations[i]
+		logf("Break-in: deferring VM %s (%d) to a later cycle\n", migration.VM.Name, migration.VM.ID)
+		skipped = append(skipped, skippedVM(&migration.VM, migration.FromNode, skipBreakIn))
+	}
+	return migrations[:breakInLimit], skipped
+}
+
+// completed counts a cycle that ran migrations towards the break-in. Read-only cycles and
+// cycles without migrations prove nothing and aren't counted.
+func (s *breakIn) completed(cfg *config.Config, results []models.BalancingResult, now time.Time) {
+	if cfg.ReadOnly || len(results) == 0 || !s.active(cfg, now) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cycles++
+}
@@ -0,0 +1,57 @@
+package balancer
+
+import (
+	"fmt"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// profileFullSamples is how many history samples give a load profile full confidence: most of the
+// ~70 Proxmox keeps for a day.
+const profileFullSamples = 60
+
+// historyVMType returns the guest type to query history for, Proxmox VMs by default.
+func historyVMType(vm *models.VM) string {
+	if vm.Type == "" {
+		return "qemu"
+	}
+	return vm.Type
+}
+
+// profileSamples counts the history samples the VM's load profile can go by. History that can't
+// be fetched counts as none.
+func (b *AdvancedBalancer) profileSamples(vm *models.VM, nodeName string) int {
+	metrics, err := b.client.GetVMHistoricalData(nodeName, vm.ID, historyVMType(vm), defaultTimeframe)
+	if err != nil {
+		logf("Warning: failed to get history for VM %d load profile: %v\n", vm.ID, err)
+		return 0
+	}
+	return len(metrics)
+}
+
+// profileConfidence returns how far history samples can be trusted, from 0 (none) to 1 (a full day).
+func profileConfidence(samples int) float64 {
+	return min(float64(max(samples, 0))/profileFullSamples, 1)
+}
+
+// blendProfiles weighs the history-based profile against the tag-based one by the confidence in
+// the history. Buffers are averaged by weight; the workload type comes from the likelier analysis,
+// and a critical tag is never outweighed.
+func blendProfiles(history, tags *VMProfile, confidence float64) VMProfile {
+	blended := *history
+	if confidence < 0.5 {
+		blended.WorkloadType = tags.WorkloadType
+	}
+	if tags.Criticality == criticalityLevelCritical {
+		blended.Criticality = criticalityLevelCritical
+	}
+	blended.Pattern = history.Pattern + " (partial history)"
+	blended.CPUBuffer = confidence*history.CPUBuffer + (1-confidence)*tags.CPUBuffer
+	blended.MemoryBuffer = confidence*history.MemoryBuffer + (1-confidence)*tags.MemoryBuffer
+	blended.Confidence = confidence
+
+	blended.Recommendations = append(append([]string(nil), history.Recommendations...), tags.Recommendations...)
+	blended.Recommendations = append(blended.Recommendations,
+		fmt.Sprintf("Partial historical data (%.0f%% confidence) - buffers blended with tag-based analysis", confidence*100))
+	return blended
+}
@@ -0,0 +1,360 @@
+package balancer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/proxmox"
+	"github.com/cblomart/GoProxLB/internal/rules"
+)
+
+// reasonConsolidation is the reason of the migrations emptying a node.
+const reasonConsolidation = "consolidation"
+
+// ConsolidationBalancer packs VMs onto as few nodes as the thresholds allow, emptying the least
+// loaded nodes so they can be powered down. It shares the threshold balancer's guards and execution.
+type ConsolidationBalancer struct {
+	*Balancer
+	mu      sync.Mutex
+	emptied []string // Nodes without VMs after the last cycle
+}
+
+// NewConsolidationBalancer creates a new consolidation balancer.
+func NewConsolidationBalancer(client proxmox.ClientInterface, cfg *config.Config) *ConsolidationBalancer {
+	return &ConsolidationBalancer{Balancer: NewBalancer(client, cfg)}
+}
+
+// Run performs a consolidation cycle: the least loaded nodes are evacuated onto the others, each
+// only when all of its VMs fit within the thresholds and rules. Packing isn't triggered by load,
+// so force changes nothing.
+func (b *ConsolidationBalancer) Run(force bool) ([]models.BalancingResult, error) {
+	b.lastCycle = time.Now()
+
+	// Balancing is disabled until the configured start date, even when forced
+	if balancingDeferred(b.config, time.Now()) {
+		return nil, nil
+	}
+
+	deadline := cycleDeadline(b.config, time.Now())
+	timer := newPhaseTimer()
+	defer b.phases.record(timer, b.config.Logging.Level == "debug")
+
+	// Get current cluster state
+	nodes, err := b.client.GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+	applyKSMSavings(nodes)
+	timer.mark(phaseGetNodes)
+
+	// Nodes vanishing all at once are more likely an API glitch than reality: don't act on it
+	if b.nodeCount.suddenDrop(b.config, len(nodes)) {
+		return nil, nil
+	}
+
+	availableNodes := b.filterAvailableNodes(nodes)
+	if len(availableNodes) < 2 {
+		return nil, fmt.Errorf("insufficient available nodes for balancing (need at least 2)")
+	}
+
+	var allVMs []models.VM
+	for i := range nodes {
+		allVMs = append(allVMs, nodes[i].VMs...)
+	}
+	b.engine.SetNodePCIMappings(nodes)
+	if err := b.engine.ProcessVMs(allVMs); err != nil {
+		return nil, fmt.Errorf("failed to process VM rules: %w", err)
+	}
+	logRuleConflicts(b.engine, availableNodes)
+	timer.mark(phaseRules)
+
+	// With every node above its thresholds there is nothing to pack onto
+	if b.capacityAlarm.check(b.config, availableNodes) {
+		b.skipped.set(nil)
+		b.setEmptied(emptiedNodes(availableNodes, nil))
+		return nil, nil
+	}
+
+	migrations, skipped := planConsolidation(b.config, b.engine, nodes, availableNodes, time.Now())
+	b.skipped.set(skipped)
+	estimateMigrationDurations(b.config, migrations)
+	timer.mark(phasePlanning)
+
+	var results []models.BalancingResult
+	for i := range migrations {
+		if i > 0 && !migrationPause(b.config, deadline, len(migrations)-i) {
+			break
+		}
+		if budgetExhausted(deadline, len(migrations)-i) {
+			break
+		}
+		result := b.executeMigration(&migrations[i])
+		result.Reason = reasonConsolidation
+		results = append(results, result)
+	}
+	timer.mark(phaseExecution)
+	window, _ := b.config.GetObservationWindow() //nolint:errcheck // validated at load time
+	b.observations.record(results, window, time.Now())
+	b.setEmptied(emptiedNodes(availableNodes, results))
+
+	if !b.config.ReadOnly {
+		b.lastRun = time.Now()
+	}
+	return results, nil
+}
+
+// GetEmptiedNodes returns the available nodes left without VMs by the last cycle, sorted by name.
+// In read-only mode, those the planned migrations would empty.
+func (b *ConsolidationBalancer) GetEmptiedNodes() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.emptied...)
+}
+
+// setEmptied records the nodes left without VMs by the cycle.
+func (b *ConsolidationBalancer) setEmptied(nodes []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.emptied = nodes
+}
+
+// emptiedNodes returns the nodes without VMs once the migrations that succeeded, or were only
+// planned in read-only mode, are done. Nodes that were already empty count too.
+func emptiedNodes(nodes []models.Node, results []models.BalancingResult) []string {
+	remaining := make(map[string]int, len(nodes))
+	for i := range nodes {
+		remaining[nodes[i].Name] = len(nodes[i].VMs)
+	}
+	for i := range results {
+		if results[i].Success || results[i].DryRun {
+			remaining[results[i].SourceNode]--
+		}
+	}
+
+	var emptied []string
+	for name, vms := range remaining {
+		if vms <= 0 {
+			emptied = append(emptied, name)
+		}
+	}
+	sort.Strings(emptied)
+	return emptied
+}
+
+// consolidationLoad returns the node's busiest resource, CPU or memory, in percent.
+func consolidationLoad(node *models.Node) float64 {
+	return math.Max(float64(node.CPU.Usage), float64(node.Memory.Usage))
+}
+
+// planConsolidation plans emptying nodes, least loaded first. A node is evacuated only when every one
+// of its VMs has a valid target staying within the CPU, memory and storage thresholds once the moves
+// planned before it are done; otherwise its VMs all stay and are logged as skipped. Each VM goes to the
+// busiest target it fits on, keeping the others free to be emptied. Nodes receiving VMs, and the busiest
+// node, are never evacuated.
+func planConsolidation(cfg *config.Config, engine *rules.Engine, nodes, availableNodes []models.Node, now time.Time) ([]models.Migration, []models.SkippedVM) {
+	order := make([]*models.Node, 0, len(availableNodes))
+	for i := range availableNodes {
+		node := availableNodes[i]
+		order = append(order, &node)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := consolidationLoad(order[i]), consolidationLoad(order[j])
+		if a != b {
+			return a < b
+		}
+		return order[i].Name < order[j].Name
+	})
+
+	versions := nodeVersions(cfg, nodes)
+	peers := antiAffinityPeers(engine)
+	evacuated := make(map[string]bool)
+	receiving := make(map[string]bool)
+	landed := make(map[int]string) // VMs planned to move, by target
+	var migrations []models.Migration
+	var skipped []models.SkippedVM
+	for i, source := range order[:len(order)-1] {
+		// Nodes already empty stay so
+		if len(source.VMs) == 0 {
+			evacuated[source.Name] = true
+			continue
+		}
+		// Nodes receiving VMs of this plan stay up, as do those VMs can't leave
+		if receiving[source.Name] || !cfg.Cluster.CanBeSource(source.Name) {
+			continue
+		}
+
+		// Targets are the nodes not being emptied, with the load of the moves planned so far
+		var candidates []models.NodeScore
+		for _, node := range order[i+1:] {
+			if cfg.Cluster.CanBeTarget(node.Name) {
+				candidates = append(candidates, models.NodeScore{Node: node.Name})
+			}
+		}
+		for _, node := range order[:i] {
+			if !evacuated[node.Name] && cfg.Cluster.CanBeTarget(node.Name) {
+				candidates = append(candidates, models.NodeScore{Node: node.Name})
+			}
+		}
+		candidates = filterVersionTargets(versions, source.Name, candidates)
+		targets := make(map[string]*models.Node, len(candidates))
+		names := make([]string, 0, len(candidates))
+		for _, score := range candidates {
+			for _, node := range order {
+				if node.Name == score.Node {
+					projected := *node
+					targets[node.Name] = &projected
+				}
+			}
+			names = append(names, score.Node)
+		}
+
+		planned, blocked := evacuationPlan(cfg, engine, source, targets, names, peers, landed, now)
+		if blocked != nil {
+			for j := range source.VMs {
+				skipped = append(skipped, skippedVM(&source.VMs[j], source.Name, skipConsolidation, blocked.vm.Name, blocked.vm.ID, blocked.reason))
+			}
+			continue
+		}
+
+		// The plan holds: commit the projected loads of its targets
+		for _, node := range order {
+			if projected, exists := targets[node.Name]; exists {
+				*node = *projected
+			}
+		}
+		for j := range planned {
+			landed[planned[j].VM.ID] = planned[j].ToNode
+			receiving[planned[j].ToNode] = true
+		}
+		evacuated[source.Name] = true
+		migrations = append(migrations, planned...)
+	}
+
+	return migrations, skipped
+}
+
+// blockedVM is a VM keeping its node from being emptied, and why.
+type blockedVM struct {
+	vm     *models.VM
+	reason string
+}
+
+// evacuationPlan places every VM of the source on the projected targets, updating their loads.
+// It returns the VM that can't move, if any, in which case the plan and loads must be dropped.
+func evacuationPlan(cfg *config.Config, engine *rules.Engine, source *models.Node, targets map[string]*models.Node, names []string, peers map[int]map[int]bool, landed map[int]string, now time.Time) ([]models.Migration, *blockedVM) {
+	thresholds := cfg.Balancing.Thresholds
+	placed := make(map[int]string, len(landed))
+	for id, node := range landed {
+		placed[id] = node
+	}
+
+	var planned []models.Migration
+	for _, vm := range drainOrder(engine, source.VMs) {
+		block := func(reason string) *blockedVM {
+			return &blockedVM{vm: &vm, reason: reason}
+		}
+		switch {
+		case engine.IsDuplicate(vm.ID):
+			return nil, block(skipDuplicate)
+		case engine.IsIgnored(vm.ID):
+			return nil, block(skipIgnored)
+		case engine.IsFrozen(vm.ID, now):
+			return nil, block(skipFrozen)
+		case heldSuspended(cfg, &vm):
+			return nil, block(skipSuspended)
+		case inBackupWindow(cfg, &vm, now):
+			return nil, block(skipBackup)
+		case heldPassthrough(cfg, &vm):
+			return nil, block(skipPassthrough)
+		}
+		if downtime, exceeded := downtimeExceeded(cfg, &vm); exceeded {
+			return nil, block(fmt.Sprintf(skipDowntime, downtime, vm.MigrateDowntime))
+		}
+
+		// The busiest valid target the VM fits on, ties to the first by name
+		var best *models.Node
+		bestLoad := math.Inf(-1)
+		for _, name := range engine.GetValidTargetNodes(&vm, names) {
+			target := targets[name]
+			if !consolidationFit(thresholds, &vm, target) || hostsPeer(peers[vm.ID], placed, name) {
+				continue
+			}
+			if load := consolidationLoad(target); load > bestLoad || (load == bestLoad && name < best.Name) {
+				best, bestLoad = target, load
+			}
+		}
+		if best == nil {
+			return nil, block("no target within the thresholds")
+		}
+
+		planned = append(planned, models.Migration{
+			VM:        vm,
+			FromNode:  source.Name,
+			ToNode:    best.Name,
+			Freed:     freedResources(&vm, source),
+			Status:    "pending",
+			StartTime: now,
+		})
+		placed[vm.ID] = best.Name
+
+		// Later VMs see the target with this one on it
+		best.CPU.Usage = float32(projectedTargetCPU(&vm, best))
+		if best.Memory.Total > 0 {
+			best.Memory.Usage += float32(float64(vm.Memory) / float64(best.Memory.Total) * 100)
+		}
+		best.Storage.Used += diskFootprint(&vm)
+	}
+	return planned, nil
+}
+
+// consolidationFit reports whether the target stays within the CPU, memory and storage thresholds
+// once the VM runs there. Resources of unknown size are assumed to fit.
+func consolidationFit(thresholds config.ResourceThresholds, vm *models.VM, target *models.Node) bool {
+	if target.CPU.Cores > 0 && projectedTargetCPU(vm, target) > float64(thresholds.CPU) {
+		return false
+	}
+	if target.Memory.Total > 0 &&
+		float64(target.Memory.Usage)+float64(vm.Memory)/float64(target.Memory.Total)*100 > float64(thresholds.Memory) {
+		return false
+	}
+	if footprint := diskFootprint(vm); footprint > 0 && target.Storage.Total > 0 &&
+		float64(target.Storage.Used+footprint)/float64(target.Storage.Total)*100 > float64(thresholds.Storage) {
+		return false
+	}
+	return true
+}
+
+// antiAffinityPeers maps each VM in an anti-affinity group to the other members of its groups.
+func antiAffinityPeers(engine *rules.Engine) map[int]map[int]bool {
+	peers := make(map[int]map[int]bool)
+	for _, group := range engine.GetAntiAffinityGroups() {
+		for i := range group.VMs {
+			for j := range group.VMs {
+				if i == j {
+					continue
+				}
+				if peers[group.VMs[i].ID] == nil {
+					peers[group.VMs[i].ID] = make(map[int]bool)
+				}
+				peers[group.VMs[i].ID][group.VMs[j].ID] = true
+			}
+		}
+	}
+	return peers
+}
+
+// hostsPeer reports whether a move planned earlier puts an anti-affinity peer on the node. The rules
+// engine only knows where VMs are now, not where the plan sends them.
+func hostsPeer(peers map[int]bool, placed map[int]string, node string) bool {
+	for id := range peers {
+		if placed[id] == node {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package balancer
+
+import (
+	"sort"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/rules"
+)
+
+// affinityConsolidation picks, for each affinity group split across nodes, the node to bring it
+// together on: a target already hosting members that has room for all the others within the
+// thresholds. When no node can hold the whole group, the one holding the most members wins, and
+// the members that don't fit stay where they are. Ties go to the node hosting more members, then
+// to the best target. It maps each member of a split group to its node: its own to stay, or the
+// node to move to.
+func affinityConsolidation(cfg *config.Config, engine *rules.Engine, nodes []models.Node, targets []models.NodeScore) map[int]string {
+	nodesByName := make(map[string]*models.Node, len(nodes))
+	hosts := make(map[int]string)
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
+		for j := range nodes[i].VMs {
+			hosts[nodes[i].VMs[j].ID] = nodes[i].Name
+		}
+	}
+
+	groups := engine.GetAffinityGroups()
+	tags := make([]string, 0, len(groups))
+	for tag := range groups {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	placement := make(map[int]string)
+	for _, tag := range tags {
+		// Members as currently seen on the nodes, by node
+		var members []*models.VM
+		byNode := make(map[string][]*models.VM)
+		for i := range groups[tag].VMs {
+			host, exists := hosts[groups[tag].VMs[i].ID]
+			if !exists {
+				continue
+			}
+			vm := findNodeVM(nodesByName[host], groups[tag].VMs[i].ID)
+			members = append(members, vm)
+			byNode[host] = append(byNode[host], vm)
+		}
+		if len(byNode) < 2 {
+			continue
+		}
+
+		// Targets are ordered best first, keep the first of equal candidates
+		bestNode, bestFit, bestHosted := "", []*models.VM(nil), 0
+		for _, score := range targets {
+			hosted := len(byNode[score.Node])
+			if hosted == 0 {
+				continue
+			}
+			fit := groupFit(cfg, nodesByName[score.Node], members)
+			if total := hosted + len(fit); bestNode == "" || total > bestHosted+len(bestFit) ||
+				(total == bestHosted+len(bestFit) && hosted > bestHosted) {
+				bestNode, bestFit, bestHosted = score.Node, fit, hosted
+			}
+		}
+		if bestNode == "" {
+			continue
+		}
+
+		for _, vm := range members {
+			placement[vm.ID] = hosts[vm.ID]
+		}
+		for _, vm := range bestFit {
+			placement[vm.ID] = bestNode
+		}
+		if moving := len(members) - bestHosted; len(bestFit) < moving {
+			logf("Warning: affinity group %s doesn't fit on a single node, consolidating %d of %d VMs on %s\n",
+				tag, bestHosted+len(bestFit), len(members), bestNode)
+		}
+	}
+	return placement
+}
+
+// findNodeVM returns the VM with the given ID on the node.
+func findNodeVM(node *models.Node, vmID int) *models.VM {
+	for i := range node.VMs {
+		if node.VMs[i].ID == vmID {
+			return &node.VMs[i]
+		}
+	}
+	return nil
+}
+
+// groupFit returns the group members from other nodes that the node can take without crossing its CPU,
+// memory or storage threshold, adding the smallest first so that as many fit as possible. Resources
+// the node doesn't report aren't checked.
+func groupFit(cfg *config.Config, node *models.Node, members []*models.VM) []*models.VM {
+	var movers []*models.VM
+	for _, vm := range members {
+		if findNodeVM(node, vm.ID) == nil {
+			movers = append(movers, vm)
+		}
+	}
+	sort.SliceStable(movers, func(i, j int) bool {
+		return movers[i].Memory < movers[j].Memory
+	})
+
+	thresholds := cfg.Balancing.Thresholds
+	cpu := float64(node.CPU.Usage)
+	memory := float64(node.Memory.Usage)
+	storage := node.Storage.Used
+
+	var fit []*models.VM
+	for _, vm := range movers {
+		projectedCPU := cpu
+		if node.CPU.Cores > 0 {
+			projectedCPU += estimateCPURelief(vm, node)
+			if projectedCPU > float64(thresholds.CPU) {
+				continue
+			}
+		}
+		projectedMemory := memory
+		if node.Memory.Total > 0 {
+			projectedMemory += float64(vm.Memory) / float64(node.Memory.Total) * 100
+			if projectedMemory > float64(thresholds.Memory) {
+				continue
+			}
+		}
+		projectedStorage := storage
+		if node.Storage.Total > 0 {
+			projectedStorage += diskFootprint(vm)
+			if float64(projectedStorage)/float64(node.Storage.Total)*100 > float64(thresholds.Storage) {
+				continue
+			}
+		}
+		cpu, memory, storage = projectedCPU, projectedMemory, projectedStorage
+		fit = append(fit, vm)
+	}
+	return fit
+}
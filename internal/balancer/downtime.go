@@ -0,0 +1,33 @@
+package balancer
+
+import (
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// dirtyRatePerCore is the rate, in MiB/s, at which a fully busy vCPU is assumed to dirty memory.
+const dirtyRatePerCore = 32.0
+
+// estimateMigrationDowntime estimates the pause at the end of a live migration: the memory the VM
+// dirties in a second, copied at the configured migration bandwidth. It is 0 without a bandwidth,
+// and for VMs that aren't running.
+func estimateMigrationDowntime(cfg *config.Config, vm *models.VM) time.Duration {
+	bandwidth := cfg.Balancing.MigrationBandwidth
+	if bandwidth <= 0 || vm.Status != "running" {
+		return 0
+	}
+	dirtyRate := float64(vm.CPU) * float64(max(vm.CPUs, 1)) * dirtyRatePerCore
+	return time.Duration(dirtyRate / bandwidth * float64(time.Second)).Round(time.Millisecond)
+}
+
+// downtimeExceeded reports whether the estimated downtime of migrating the VM is above its
+// migrate_downtime, along with the estimate. VMs without a migrate_downtime are never held back.
+func downtimeExceeded(cfg *config.Config, vm *models.VM) (time.Duration, bool) {
+	if vm.MigrateDowntime <= 0 {
+		return 0, false
+	}
+	estimate := estimateMigrationDowntime(cfg, vm)
+	return estimate, estimate > time.Duration(vm.MigrateDowntime*float64(time.Second))
+}
@@ -0,0 +1,200 @@
+package balancer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/proxmox"
+	"github.com/cblomart/GoProxLB/internal/rules"
+)
+
+// PlanDrain plans moving every VM off a node, in dependency and boot order.
+func (b *Balancer) PlanDrain(nodeName string) (*models.MigrationPlan, error) {
+	return loadDrainPlan(b.client, b.config, b.engine, b.filterAvailableNodes, nodeName)
+}
+
+// PlanDrain plans moving every VM off a node, in dependency and boot order.
+func (b *AdvancedBalancer) PlanDrain(nodeName string) (*models.MigrationPlan, error) {
+	return loadDrainPlan(b.client, b.config, b.engine, b.filterAvailableNodes, nodeName)
+}
+
+// loadDrainPlan reads the cluster state and its placement rules, then plans the drain of a node.
+func loadDrainPlan(client proxmox.ClientInterface, cfg *config.Config, engine *rules.Engine, available func([]models.Node) []models.Node, nodeName string) (*models.MigrationPlan, error) {
+	nodes, err := client.GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nodes: %w", err)
+	}
+
+	var allVMs []models.VM
+	for i := range nodes {
+		allVMs = append(allVMs, nodes[i].VMs...)
+	}
+	engine.SetNodePCIMappings(nodes)
+	if err := engine.ProcessVMs(allVMs); err != nil {
+		return nil, fmt.Errorf("failed to process VM rules: %w", err)
+	}
+
+	return planDrain(cfg, engine, nodes, available(nodes), nodeName)
+}
+
+// planDrain plans moving every VM off a node, in drainOrder. Each VM goes to the valid target
+// left least loaded by the moves planned before it. Ignored and frozen VMs stay, as do held
+// suspended or passthrough VMs and VMs without a valid target; they are logged.
+func planDrain(cfg *config.Config, engine *rules.Engine, nodes, availableNodes []models.Node, nodeName string) (*models.MigrationPlan, error) {
+	var source *models.Node
+	for i := range nodes {
+		if nodes[i].Name == nodeName {
+			source = &nodes[i]
+			break
+		}
+	}
+	if source == nil {
+		return nil, fmt.Errorf("node %s not found", nodeName)
+	}
+
+	// Targets carry their load as projected by the moves already planned
+	versions := nodeVersions(cfg, nodes)
+	targets := make(map[string]*models.Node)
+	var candidates []models.NodeScore
+	for i := range availableNodes {
+		node := availableNodes[i]
+		if node.Name == nodeName || !cfg.Cluster.CanBeTarget(node.Name) {
+			continue
+		}
+		targets[node.Name] = &node
+		candidates = append(candidates, models.NodeScore{Node: node.Name})
+	}
+	candidates = filterVersionTargets(versions, nodeName, candidates)
+	names := make([]string, 0, len(candidates))
+	for _, score := range candidates {
+		names = append(names, score.Node)
+	}
+
+	plan := &models.MigrationPlan{}
+	now := time.Now()
+	for _, vm := range drainOrder(engine, source.VMs) {
+		if engine.IsIgnored(vm.ID) || engine.IsFrozen(vm.ID, now) {
+			logf("Skipping VM %s (%d): ignored or frozen, it stays on %s\n", vm.Name, vm.ID, nodeName)
+			continue
+		}
+		if heldSuspended(cfg, &vm) {
+			logf("Skipping VM %s (%d): %s, it stays on %s\n", vm.Name, vm.ID, vm.Status, nodeName)
+			continue
+		}
+		if inBackupWindow(cfg, &vm, now) {
+			logf("Skipping VM %s (%d): being backed up, it stays on %s\n", vm.Name, vm.ID, nodeName)
+			continue
+		}
+		if heldPassthrough(cfg, &vm) {
+			logf("Skipping VM %s (%d): PCI passthrough devices, it stays on %s\n", vm.Name, vm.ID, nodeName)
+			continue
+		}
+
+		validNodes := engine.GetValidTargetNodes(&vm, names)
+		if len(validNodes) == 0 {
+			logf("Warning: VM %s (%d) has no valid target, it stays on %s\n", vm.Name, vm.ID, nodeName)
+			continue
+		}
+
+		target := leastLoadedTarget(&vm, validNodes, targets)
+		plan.Migrations = append(plan.Migrations, models.Migration{
+			VM:        vm,
+			FromNode:  nodeName,
+			ToNode:    target.Name,
+			Freed:     freedResources(&vm, source),
+			Status:    "pending",
+			StartTime: now,
+		})
+
+		// Later VMs see the target with this one on it
+		target.CPU.Usage = float32(projectedTargetCPU(&vm, target))
+		if target.Memory.Total > 0 {
+			target.Memory.Usage += float32(float64(vm.Memory) / float64(target.Memory.Total) * 100)
+		}
+	}
+
+	estimateMigrationDurations(cfg, plan.Migrations)
+	return plan, nil
+}
+
+// leastLoadedTarget returns the valid target whose busiest resource, CPU or memory, is the
+// least loaded once the VM runs there. Ties go to the first node by name.
+func leastLoadedTarget(vm *models.VM, validNodes []string, targets map[string]*models.Node) *models.Node {
+	sorted := append([]string{}, validNodes...)
+	sort.Strings(sorted)
+
+	var best *models.Node
+	bestLoad := math.Inf(1)
+	for _, name := range sorted {
+		node := targets[name]
+		load := projectedTargetCPU(vm, node)
+		if node.Memory.Total > 0 {
+			load = math.Max(load, float64(node.Memory.Usage)+float64(vm.Memory)/float64(node.Memory.Total)*100)
+		}
+		if load < bestLoad {
+			best, bestLoad = node, load
+		}
+	}
+	return best
+}
+
+// drainOrder orders the VMs of a drained node so that each moves after the VMs it depends on
+// (plb_depends_on_ tags) that are on the same node. Otherwise VMs follow their boot order,
+// those without one last, then their VMID. VMs caught in a dependency cycle follow in that order.
+func drainOrder(engine *rules.Engine, vms []models.VM) []models.VM {
+	pending := append([]models.VM{}, vms...)
+	sort.SliceStable(pending, func(i, j int) bool {
+		a, b := pending[i].BootOrder, pending[j].BootOrder
+		if (a == 0) != (b == 0) {
+			return a != 0
+		}
+		if a != b {
+			return a < b
+		}
+		return pending[i].ID < pending[j].ID
+	})
+
+	onNode := make(map[int]bool, len(vms))
+	for i := range vms {
+		onNode[vms[i].ID] = true
+	}
+
+	ordered := make([]models.VM, 0, len(vms))
+	placed := make(map[int]bool, len(vms))
+	for len(pending) > 0 {
+		next := -1
+		for i := range pending {
+			if dependenciesPlaced(engine.Dependencies(pending[i].ID), onNode, placed) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			ids := make([]int, 0, len(pending))
+			for i := range pending {
+				ids = append(ids, pending[i].ID)
+			}
+			logf("Warning: dependency cycle between VMs %v, draining them in boot order\n", ids)
+			return append(ordered, pending...)
+		}
+
+		ordered = append(ordered, pending[next])
+		placed[pending[next].ID] = true
+		pending = append(pending[:next], pending[next+1:]...)
+	}
+	return ordered
+}
+
+// dependenciesPlaced reports whether every dependency on the node was already placed in the order.
+func dependenciesPlaced(dependencies []int, onNode, placed map[int]bool) bool {
+	for _, dependency := range dependencies {
+		if onNode[dependency] && !placed[dependency] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package balancer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// healthCheckWaitDelay bounds how long a timed out check may keep its output open.
+const healthCheckWaitDelay = time.Second
+
+// runHealthCheck runs the readiness command for a node, passing its name as $1.
+// A non-zero exit status or running past the timeout fails the check.
+func runHealthCheck(command string, timeout time.Duration, node string) error {
+	return runCommand(command, timeout, node)
+}
+
+// runCommand runs a configured shell command with the given positional arguments.
+// A non-zero exit status or running past the timeout fails it, with its output in the error.
+func runCommand(command string, timeout time.Duration, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", append([]string{"-c", command, "sh"}, args...)...) //nolint:gosec // command comes from configuration
+	// Don't wait for children of the shell still holding its output once it is killed
+	cmd.WaitDelay = healthCheckWaitDelay
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %v", timeout)
+	}
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
+
+// filterHealthyTargets drops the target nodes whose health check fails. The checks run in parallel.
+func filterHealthyTargets(cfg *config.Config, nodeScores []models.NodeScore) []models.NodeScore {
+	command := cfg.Balancing.HealthCheck.Command
+	if command == "" {
+		return nodeScores
+	}
+	timeout, _ := cfg.GetHealthCheckTimeout() //nolint:errcheck // validated at load time
+
+	failures := make([]error, len(nodeScores))
+	var wg sync.WaitGroup
+	for i := range nodeScores {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			failures[i] = runHealthCheck(command, timeout, nodeScores[i].Node)
+		}(i)
+	}
+	wg.Wait()
+
+	targets := make([]models.NodeScore, 0, len(nodeScores))
+	for i, score := range nodeScores {
+		if failures[i] != nil {
+			logf("Skipping node %s as migration target: health check failed: %v\n", score.Node, failures[i])
+			continue
+		}
+		targets = append(targets, score)
+	}
+	return targets
+}
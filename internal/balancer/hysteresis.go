@@ -0,0 +1,47 @@
+package balancer
+
+import (
+	"sync"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// overloadState remembers, per node, which resources (CPU, memory, storage) went over their
+// threshold and haven't dropped below their hysteresis band since.
+type overloadState struct {
+	mu    sync.Mutex
+	nodes map[string][3]bool
+}
+
+// newOverloadState creates an empty overload state.
+func newOverloadState() *overloadState {
+	return &overloadState{nodes: make(map[string][3]bool)}
+}
+
+// overloaded reports whether a node is overloaded. A resource over its threshold overloads the
+// node, and keeps it overloaded until its usage drops to the threshold minus the resource's
+// hysteresis margin. Without margins, this is the plain threshold check.
+func (s *overloadState) overloaded(cfg *config.Config, node *models.Node) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	thresholds := cfg.Balancing.Thresholds
+	margins := cfg.Balancing.Hysteresis
+	usages := [3]float32{node.CPU.Usage, node.Memory.Usage, node.Storage.Usage}
+	limits := [3]float32{float32(thresholds.CPU), float32(thresholds.Memory), float32(thresholds.Storage)}
+	rearms := [3]float32{
+		limits[0] - float32(margins.CPU),
+		limits[1] - float32(margins.Memory),
+		limits[2] - float32(margins.Storage),
+	}
+
+	state := s.nodes[node.Name]
+	over := false
+	for i := range usages {
+		state[i] = usages[i] > limits[i] || (state[i] && usages[i] > rearms[i])
+		over = over || state[i]
+	}
+	s.nodes[node.Name] = state
+	return over
+}
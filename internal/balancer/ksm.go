@@ -0,0 +1,22 @@
+package balancer
+
+import (
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// applyKSMSavings lowers each node's memory usage by the memory KSM page sharing saves,
+// so nodes benefiting from sharing aren't evacuated on raw usage alone.
+func applyKSMSavings(nodes []models.Node) {
+	for i := range nodes {
+		node := &nodes[i]
+		if node.KSMShared <= 0 || node.Memory.Total <= 0 {
+			continue
+		}
+
+		used := node.Memory.Used - node.KSMShared
+		if used < 0 {
+			used = 0
+		}
+		node.Memory.Usage = float32(float64(used) / float64(node.Memory.Total) * 100)
+	}
+}
@@ -0,0 +1,16 @@
+package balancer
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// logOutput receives the balancer's diagnostics: warnings, skipped targets and plan summaries.
+// It is stderr, so commands printing a document on stdout, like a DOT or JUnit plan, stay parseable.
+var logOutput io.Writer = os.Stderr
+
+// logf writes a diagnostic line to the balancer's log output.
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(logOutput, format, args...)
+}
@@ -0,0 +1,159 @@
+package balancer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/raft"
+)
+
+const (
+	// migrationHistoryFile is the file in the data directory holding persisted migration history.
+	migrationHistoryFile = "migration_history.json"
+
+	// minPairAttempts is the number of migrations needed before a node pair's track record counts.
+	minPairAttempts = 2
+
+	// pairFailurePenalty is the score penalty applied to a target for a 100% failure rate.
+	pairFailurePenalty = 50.0
+)
+
+// persistedHistory is the on-disk format of the migration history.
+type persistedHistory struct {
+	Migrations []models.MigrationHistory   `json:"migrations"`
+	Pairs      []models.MigrationPairStats `json:"pairs"`
+}
+
+// pairKey returns the map key for a source/target node pair.
+func pairKey(fromNode, toNode string) string {
+	return fromNode + "->" + toNode
+}
+
+// recordMigrationOutcome updates the success/failure counters for the result's node pair.
+func (b *AdvancedBalancer) recordMigrationOutcome(result *models.BalancingResult) {
+	key := pairKey(result.SourceNode, result.TargetNode)
+	stats, exists := b.pairStats[key]
+	if !exists {
+		stats = &models.MigrationPairStats{FromNode: result.SourceNode, ToNode: result.TargetNode}
+		b.pairStats[key] = stats
+	}
+
+	if result.Success {
+		stats.Successes++
+	} else {
+		stats.Failures++
+		stats.LastFailure = result.Timestamp
+	}
+}
+
+// pairFailureRate returns the failure rate of migrations from sourceNode to targetNode,
+// or 0 if there aren't enough attempts to judge the pair.
+func (b *AdvancedBalancer) pairFailureRate(sourceNode, targetNode string) float64 {
+	stats, exists := b.pairStats[pairKey(sourceNode, targetNode)]
+	if !exists {
+		return 0
+	}
+
+	attempts := stats.Successes + stats.Failures
+	if attempts < minPairAttempts {
+		return 0
+	}
+	return float64(stats.Failures) / float64(attempts)
+}
+
+// rankTargetsByReliability reorders target candidates so pairs with a poor migration record are deprioritized.
+func (b *AdvancedBalancer) rankTargetsByReliability(sourceNode string, nodeScores []models.NodeScore) []models.NodeScore {
+	ranked := make([]models.NodeScore, len(nodeScores))
+	copy(ranked, nodeScores)
+
+	adjusted := make(map[string]float64, len(ranked))
+	for _, score := range ranked {
+		adjusted[score.Node] = score.Score + b.pairFailureRate(sourceNode, score.Node)*pairFailurePenalty
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return adjusted[ranked[i].Node] < adjusted[ranked[j].Node]
+	})
+
+	return ranked
+}
+
+// loadMigrationHistory restores persisted migration history, if any.
+func (b *AdvancedBalancer) loadMigrationHistory() error {
+	if b.historyPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(b.historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read migration history: %w", err)
+	}
+
+	var history persistedHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return fmt.Errorf("failed to decode migration history: %w", err)
+	}
+
+	b.migrationHistory = append(b.migrationHistory, history.Migrations...)
+	for i := range history.Pairs {
+		stats := history.Pairs[i]
+		b.pairStats[pairKey(stats.FromNode, stats.ToNode)] = &stats
+	}
+
+	return nil
+}
+
+// lockHistoryDir reports whether this process may save migration history in the data directory:
+// only while it holds the directory lock, taken by the app or its Raft node.
+func lockHistoryDir(dataDir string) bool {
+	if raft.HoldsDataDirLock(dataDir) {
+		return true
+	}
+	logf("Warning: data directory %s is not locked by this process, migration history is not saved\n", dataDir)
+	return false
+}
+
+// saveMigrationHistory persists migration history atomically.
+func (b *AdvancedBalancer) saveMigrationHistory() error {
+	if b.historyPath == "" || !b.historyLocked {
+		return nil
+	}
+
+	history := persistedHistory{
+		Migrations: b.migrationHistory,
+		Pairs:      make([]models.MigrationPairStats, 0, len(b.pairStats)),
+	}
+	for _, stats := range b.pairStats {
+		history.Pairs = append(history.Pairs, *stats)
+	}
+	sort.Slice(history.Pairs, func(i, j int) bool {
+		return pairKey(history.Pairs[i].FromNode, history.Pairs[i].ToNode) <
+			pairKey(history.Pairs[j].FromNode, history.Pairs[j].ToNode)
+	})
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode migration history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(b.historyPath), 0750); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	tmpPath := b.historyPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write migration history: %w", err)
+	}
+	if err := os.Rename(tmpPath, b.historyPath); err != nil {
+		return fmt.Errorf("failed to replace migration history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package balancer
+
+import (
+	"sync"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+)
+
+// nodeCountGuard remembers how many nodes the previous cycle saw, to catch sudden drops.
+type nodeCountGuard struct {
+	mu   sync.Mutex
+	last int
+}
+
+// suddenDrop reports whether more than the max_node_drop fraction of the nodes seen in the
+// previous cycle disappeared, logging a warning. Acting on such a partial view could pile VMs
+// onto the few nodes left, so the cycle should be aborted. The count is remembered either way:
+// a lasting drop is only reported once.
+func (g *nodeCountGuard) suddenDrop(cfg *config.Config, count int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	last := g.last
+	g.last = count
+	maxDrop := cfg.Balancing.MaxNodeDrop
+	if maxDrop <= 0 || last == 0 || count >= last {
+		return false
+	}
+
+	drop := float64(last-count) / float64(last)
+	if drop <= maxDrop {
+		return false
+	}
+	logf("Warning: visible nodes dropped from %d to %d (%.0f%%, more than max_node_drop %.0f%%), aborting the cycle: API glitch or partition?\n",
+		last, count, drop*100, maxDrop*100)
+	return true
+}
@@ -0,0 +1,60 @@
+package balancer
+
+import (
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// nodeObservations holds, per node, until when it is left alone after taking part in a migration.
+// Its metrics still reflect the pre-migration state, so decisions based on them would chain moves.
+type nodeObservations map[string]time.Time
+
+// record starts the observation window on both nodes of every executed migration.
+func (o nodeObservations) record(results []models.BalancingResult, window time.Duration, now time.Time) {
+	if window <= 0 {
+		return
+	}
+	for i := range results {
+		result := &results[i]
+		if !result.Success || result.DryRun {
+			continue
+		}
+		o[result.SourceNode] = now.Add(window)
+		o[result.TargetNode] = now.Add(window)
+	}
+}
+
+// exclude drops the nodes still under observation from the migration sources and targets.
+func (o nodeObservations) exclude(sources []models.Node, targets []models.NodeScore, now time.Time) ([]models.Node, []models.NodeScore) {
+	observing := make(map[string]bool)
+	for node, until := range o {
+		if now.Before(until) {
+			observing[node] = true
+		} else {
+			delete(o, node)
+		}
+	}
+	if len(observing) == 0 {
+		return sources, targets
+	}
+
+	var keptSources []models.Node
+	for i := range sources {
+		if observing[sources[i].Name] {
+			logf("Skipping node %s as migration source: observing a recent migration until %s\n",
+				sources[i].Name, o[sources[i].Name].Format(time.RFC3339))
+			continue
+		}
+		keptSources = append(keptSources, sources[i])
+	}
+
+	var keptTargets []models.NodeScore
+	for _, score := range targets {
+		if !observing[score.Node] {
+			keptTargets = append(keptTargets, score)
+		}
+	}
+
+	return keptSources, keptTargets
+}
@@ -0,0 +1,57 @@
+package balancer
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// overCapacity reports whether every node is above one of its thresholds, leaving no node to move VMs to.
+func overCapacity(cfg *config.Config, nodes []models.Node) bool {
+	if len(nodes) == 0 {
+		return false
+	}
+	for i := range nodes {
+		node := &nodes[i]
+		if !overThresholds(cfg, node.CPU.Usage, node.Memory.Usage, node.Storage.Usage) {
+			return false
+		}
+	}
+	return true
+}
+
+// capacityAlarm raises the cluster over capacity alert. It remembers whether the cluster was
+// already over capacity, so the scale-out command runs once each time it goes over.
+type capacityAlarm struct {
+	mu     sync.Mutex
+	raised bool
+}
+
+// check reports whether the cluster is over capacity, logging the alert every cycle it is.
+// With the scale_out action, the command runs when the cluster goes over capacity, with the
+// node count as $1; its failure is only logged.
+func (a *capacityAlarm) check(cfg *config.Config, nodes []models.Node) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	over := overCapacity(cfg, nodes)
+	wasRaised := a.raised
+	a.raised = over
+	if !over {
+		return false
+	}
+
+	logf("ALERT: cluster over capacity: all %d nodes are above their thresholds (CPU %d%%, memory %d%%, storage %d%%), no node can take VMs; add nodes or reduce load\n",
+		len(nodes), cfg.Balancing.Thresholds.CPU, cfg.Balancing.Thresholds.Memory, cfg.Balancing.Thresholds.Storage)
+
+	overCapacityConfig := cfg.Balancing.OverCapacity
+	if overCapacityConfig.Action == config.OverCapacityScaleOut && !wasRaised {
+		timeout, _ := cfg.GetOverCapacityTimeout() //nolint:errcheck // validated at load time
+		if err := runCommand(overCapacityConfig.Command, timeout, strconv.Itoa(len(nodes))); err != nil {
+			logf("Warning: scale-out command failed: %v\n", err)
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package balancer
+
+import (
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// configuredLoad sums the vCPUs and configured memory of the node's running VMs.
+func configuredLoad(node *models.Node) (vcpus int, memory int64) {
+	for i := range node.VMs {
+		vm := &node.VMs[i]
+		if vm.Status == "running" {
+			vcpus += vm.CPUs
+			memory += vm.MaxMemory
+		}
+	}
+	return vcpus, memory
+}
+
+// filterOvercommitTargets drops the target nodes whose configured-to-physical vCPU or memory ratio
+// would exceed the configured overcommit limit once the VM runs there.
+// Nodes with an unknown core count or memory size are kept for the matching resource.
+func filterOvercommitTargets(limits config.OvercommitConfig, vm *models.VM, nodes []models.Node, targets []models.NodeScore) []models.NodeScore {
+	if limits.CPU <= 0 && limits.Memory <= 0 {
+		return targets
+	}
+
+	nodesByName := make(map[string]*models.Node, len(nodes))
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
+	}
+
+	kept := make([]models.NodeScore, 0, len(targets))
+	for _, score := range targets {
+		node, exists := nodesByName[score.Node]
+		if !exists || score.Node == vm.Node {
+			kept = append(kept, score)
+			continue
+		}
+
+		vcpus, memory := configuredLoad(node)
+		if limits.CPU > 0 && node.CPU.Cores > 0 {
+			if ratio := float64(vcpus+vm.CPUs) / float64(node.CPU.Cores); ratio > limits.CPU {
+				logf("Skipping node %s as target for VM %d: vCPU overcommit would reach %.2f:1 (limit %.2f:1)\n",
+					node.Name, vm.ID, ratio, limits.CPU)
+				continue
+			}
+		}
+		if limits.Memory > 0 && node.Memory.Total > 0 {
+			if ratio := float64(memory+vm.MaxMemory) / float64(node.Memory.Total); ratio > limits.Memory {
+				logf("Skipping node %s as target for VM %d: memory overcommit would reach %.2f:1 (limit %.2f:1)\n",
+					node.Name, vm.ID, ratio, limits.Memory)
+				continue
+			}
+		}
+		kept = append(kept, score)
+	}
+	return kept
+}
@@ -0,0 +1,75 @@
+package balancer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// Phases of a balancing cycle, in the order they run.
+const (
+	phaseGetNodes        = "get_nodes"
+	phaseRules           = "rules"
+	phaseLoadProfiles    = "load_profiles"
+	phaseCapacityMetrics = "capacity_metrics"
+	phaseScoring         = "scoring"
+	phasePlanning        = "planning"
+	phaseExecution       = "execution"
+)
+
+// phaseTimer measures the time spent in each phase of one cycle.
+type phaseTimer struct {
+	last    time.Time
+	timings []models.PhaseTiming
+}
+
+// newPhaseTimer starts timing a cycle's first phase.
+func newPhaseTimer() *phaseTimer {
+	return &phaseTimer{last: time.Now()}
+}
+
+// mark ends phase, recording the time since the previous phase ended.
+func (t *phaseTimer) mark(phase string) {
+	now := time.Now()
+	t.timings = append(t.timings, models.PhaseTiming{Phase: phase, Duration: now.Sub(t.last)})
+	t.last = now
+}
+
+// phaseLog remembers the phase timings of the last cycle.
+type phaseLog struct {
+	mu      sync.Mutex
+	timings []models.PhaseTiming
+}
+
+// record replaces the logged timings with the cycle's, logging them at debug level.
+// Cycles ending early only record the phases they reached.
+func (l *phaseLog) record(timer *phaseTimer, debug bool) {
+	l.mu.Lock()
+	l.timings = timer.timings
+	l.mu.Unlock()
+
+	if debug && len(timer.timings) > 0 {
+		logf("DEBUG: cycle phases: %s\n", formatPhaseTimings(timer.timings))
+	}
+}
+
+// list returns a copy of the logged timings in phase order.
+func (l *phaseLog) list() []models.PhaseTiming {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	timings := make([]models.PhaseTiming, len(l.timings))
+	copy(timings, l.timings)
+	return timings
+}
+
+// formatPhaseTimings renders timings as "phase=duration" pairs.
+func formatPhaseTimings(timings []models.PhaseTiming) string {
+	parts := make([]string, 0, len(timings))
+	for _, timing := range timings {
+		parts = append(parts, fmt.Sprintf("%s=%v", timing.Phase, timing.Duration.Round(time.Microsecond)))
+	}
+	return strings.Join(parts, " ")
+}
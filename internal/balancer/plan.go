@@ -0,0 +1,152 @@
+package balancer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/proxmox"
+	"github.com/cblomart/GoProxLB/internal/rules"
+)
+
+// reasonRollback marks the migration moving a VM back after its atomic plan failed.
+const reasonRollback = "rollback"
+
+// executePlan runs the migrations of a plan one after the other, in order.
+// An atomic plan stops at the first failure, without starting the remaining migrations, and moves
+// the VMs it already migrated back to their source nodes, most recent first. The rollbacks are
+// reported as extra results.
+func executePlan(plan *models.MigrationPlan, execute func(*models.Migration) models.BalancingResult) []models.BalancingResult {
+	results := make([]models.BalancingResult, 0, len(plan.Migrations))
+
+	for i := range plan.Migrations {
+		result := execute(&plan.Migrations[i])
+		results = append(results, result)
+		if !plan.Atomic || result.Success || result.DryRun {
+			continue
+		}
+
+		logf("Atomic plan failed at VM %s (%d): %s, skipping %d remaining and rolling back %d migration(s)\n",
+			result.VM.Name, result.VM.ID, result.ErrorMessage, len(plan.Migrations)-i-1, i)
+		return append(results, rollbackPlan(plan.Migrations[:i], execute)...)
+	}
+
+	return results
+}
+
+// rollbackPlan migrates the VMs of the executed migrations back to their source nodes, newest first.
+func rollbackPlan(executed []models.Migration, execute func(*models.Migration) models.BalancingResult) []models.BalancingResult {
+	results := make([]models.BalancingResult, 0, len(executed))
+	for i := len(executed) - 1; i >= 0; i-- {
+		reverse := models.Migration{
+			VM:       executed[i].VM,
+			FromNode: executed[i].ToNode,
+			ToNode:   executed[i].FromNode,
+
+			StorageMigration: executed[i].StorageMigration,
+		}
+		result := execute(&reverse)
+		result.Reason = reasonRollback
+		if !result.Success {
+			logf("Warning: failed to roll back VM %s (%d) to %s: %s\n",
+				reverse.VM.Name, reverse.VM.ID, reverse.ToNode, result.ErrorMessage)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ExecutePlan executes a plan of dependent migrations, rolling an atomic plan back when one of them fails.
+func (b *Balancer) ExecutePlan(plan *models.MigrationPlan) []models.BalancingResult {
+	return executePlan(plan, b.executeMigration)
+}
+
+// ExecutePlan executes a plan of dependent migrations, rolling an atomic plan back when one of them fails.
+func (b *AdvancedBalancer) ExecutePlan(plan *models.MigrationPlan) []models.BalancingResult {
+	results := executePlan(plan, b.executeMigration)
+	b.updateMigrationHistory(results)
+	return results
+}
+
+// ValidatePlan checks every migration of a plan against the placement rules and the holds, each with
+// the VMs where the migrations before it leave them.
+func (b *Balancer) ValidatePlan(plan *models.MigrationPlan) error {
+	return loadPlanValidation(b.client, b.config, b.engine, b.filterAvailableNodes, plan)
+}
+
+// ValidatePlan checks every migration of a plan against the placement rules and the holds, each with
+// the VMs where the migrations before it leave them.
+func (b *AdvancedBalancer) ValidatePlan(plan *models.MigrationPlan) error {
+	return loadPlanValidation(b.client, b.config, b.engine, b.filterAvailableNodes, plan)
+}
+
+// loadPlanValidation reads the cluster state, then validates a plan against it.
+func loadPlanValidation(client proxmox.ClientInterface, cfg *config.Config, engine *rules.Engine, available func([]models.Node) []models.Node, plan *models.MigrationPlan) error {
+	nodes, err := client.GetNodes()
+	if err != nil {
+		return fmt.Errorf("failed to get nodes: %w", err)
+	}
+
+	engine.SetNodePCIMappings(nodes)
+	return validatePlan(cfg, engine, nodes, available(nodes), plan, time.Now())
+}
+
+// validatePlan checks the migrations of a plan in order. Targets must be available (online, not in
+// maintenance) and allowed as targets; VMs must not be held (frozen, suspended, backed up, passthrough
+// or protected); placement rules are evaluated on the placement projected by the previous migrations.
+func validatePlan(cfg *config.Config, engine *rules.Engine, nodes, availableNodes []models.Node, plan *models.MigrationPlan, now time.Time) error {
+	targets := make(map[string]bool, len(availableNodes))
+	for i := range availableNodes {
+		targets[availableNodes[i].Name] = cfg.Cluster.CanBeTarget(availableNodes[i].Name)
+	}
+
+	var allVMs []models.VM
+	for i := range nodes {
+		allVMs = append(allVMs, nodes[i].VMs...)
+	}
+
+	for i := range plan.Migrations {
+		migration := &plan.Migrations[i]
+
+		// Rules see the VMs where the previous migrations put them
+		if err := engine.ProcessVMs(allVMs); err != nil {
+			return fmt.Errorf("failed to process VM rules: %w", err)
+		}
+		if err := validatePlanStep(cfg, engine, &migration.VM, migration.ToNode, targets, now); err != nil {
+			return fmt.Errorf("plan step %d: %w", i+1, err)
+		}
+
+		for j := range allVMs {
+			if allVMs[j].ID == migration.VM.ID {
+				allVMs[j].Node = migration.ToNode
+			}
+		}
+	}
+	return nil
+}
+
+// validatePlanStep checks that a VM may move to a node. A plan has no gain to weigh, so protected
+// VMs only move when no minimum gain is configured for them.
+func validatePlanStep(cfg *config.Config, engine *rules.Engine, vm *models.VM, target string, targets map[string]bool, now time.Time) error {
+	vmName := fmt.Sprintf("VM %s (%d)", vm.Name, vm.ID)
+	switch {
+	case !targets[target]:
+		return fmt.Errorf("node %s can't receive VMs (offline, maintenance or role)", target)
+	case engine.IsFrozen(vm.ID, now):
+		return fmt.Errorf("%s is frozen", vmName)
+	case heldSuspended(cfg, vm):
+		return fmt.Errorf("%s is %s", vmName, vm.Status)
+	case inBackupWindow(cfg, vm, now):
+		return fmt.Errorf("%s is being backed up", vmName)
+	case heldPassthrough(cfg, vm):
+		return fmt.Errorf("%s has PCI passthrough devices", vmName)
+	case !protectedGainMet(cfg, vm, 0):
+		return fmt.Errorf("%s is protected", vmName)
+	}
+
+	if err := engine.ValidatePlacement(vm, target); err != nil {
+		return fmt.Errorf("%s can't move to %s: %w", vmName, target, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package balancer
+
+import (
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/proxmox"
+)
+
+const (
+	// seasonalTimeframe is the history used for time-of-day profiles: a week holds several daily cycles.
+	seasonalTimeframe = "week"
+
+	// seasonalRatio is how much busier one period must be than the other to count as a daily pattern.
+	seasonalRatio = 1.5
+)
+
+// periodLoad holds a VM's average CPU usage (percent of its vCPUs) in and out of business hours.
+type periodLoad struct {
+	Business float64
+	OffHours float64
+}
+
+// splitPeriodLoad averages CPU samples in and out of business hours.
+// It reports false when either period has no samples to go by.
+func splitPeriodLoad(metrics []proxmox.HistoricalMetric, hours config.BusinessHoursConfig) (periodLoad, bool) {
+	var business, offHours float64
+	var businessCount, offHoursCount int
+	for i := range metrics {
+		if hours.Contains(metrics[i].Timestamp) {
+			business += metrics[i].CPU
+			businessCount++
+		} else {
+			offHours += metrics[i].CPU
+			offHoursCount++
+		}
+	}
+
+	if businessCount == 0 || offHoursCount == 0 {
+		return periodLoad{}, false
+	}
+	return periodLoad{
+		Business: business / float64(businessCount),
+		OffHours: offHours / float64(offHoursCount),
+	}, true
+}
+
+// seasonalityFor describes a daily pattern peaking at the start of the busier period,
+// or returns nil when both periods carry a similar load.
+func seasonalityFor(load periodLoad, hours config.BusinessHoursConfig) *models.Seasonality {
+	switch {
+	case load.Business > load.OffHours*seasonalRatio:
+		return &models.Seasonality{Type: "daily", PeakTime: hours.Start}
+	case load.OffHours > load.Business*seasonalRatio:
+		return &models.Seasonality{Type: "daily", PeakTime: hours.End}
+	default:
+		return nil
+	}
+}
+
+// updateSeasonalProfile splits the VM's recent history by business hours and records its daily pattern.
+func (b *AdvancedBalancer) updateSeasonalProfile(vm *models.VM, nodeName string, profile *models.LoadProfile) {
+	hours := b.config.Balancing.LoadProfiles.BusinessHours
+
+	metrics, err := b.client.GetVMHistoricalData(nodeName, vm.ID, historyVMType(vm), seasonalTimeframe)
+	if err != nil {
+		logf("Warning: failed to get history for VM %d time-of-day profile: %v\n", vm.ID, err)
+		return
+	}
+
+	load, ok := splitPeriodLoad(metrics, hours)
+	if !ok {
+		delete(b.periodLoads, vm.ID)
+		return
+	}
+	b.periodLoads[vm.ID] = load
+	profile.Seasonality = seasonalityFor(load, hours)
+}
+
+// expectedVMCPU returns the CPU usage (percent of its vCPUs) expected from the VM in the period
+// starting one lookahead from now, falling back to its current usage without a time-of-day profile.
+func (b *AdvancedBalancer) expectedVMCPU(vm *models.VM, now time.Time) float64 {
+	current := float64(vm.CPU) * 100
+
+	load, exists := b.periodLoads[vm.ID]
+	if !exists {
+		return current
+	}
+
+	hours := b.config.Balancing.LoadProfiles.BusinessHours
+	lookahead, _ := hours.GetLookahead() //nolint:errcheck // validated at load time
+	if hours.Contains(now.Add(lookahead)) {
+		return load.Business
+	}
+	return load.OffHours
+}
+
+// filterSeasonalTargets drops the target nodes that would cross the CPU threshold once the VM
+// reaches its expected load for the upcoming period.
+func (b *AdvancedBalancer) filterSeasonalTargets(vm *models.VM, nodes []models.Node, targets []models.NodeScore, now time.Time) []models.NodeScore {
+	upcoming := *vm
+	upcoming.CPU = float32(b.expectedVMCPU(vm, now) / 100)
+	return filterCPUFitTargets(&upcoming, nodes, targets, float64(b.config.Balancing.Thresholds.CPU))
+}
@@ -0,0 +1,41 @@
+package balancer
+
+import (
+	"math"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/proxmox"
+)
+
+// sessionLoads reads the session load, from 0 (idle) to 1 (fully busy), of a node's running VMs
+// when session metrics are enabled and the client reads them. VMs without a running guest agent
+// are left out, which counts them as idle.
+func (b *AdvancedBalancer) sessionLoads(node *models.Node) map[int]float64 {
+	sessions := &b.config.Balancing.Sessions
+	source, ok := b.client.(proxmox.SessionMetricsSource)
+	if !sessions.Enabled || !ok {
+		return nil
+	}
+
+	loads := make(map[int]float64)
+	for i := range node.VMs {
+		vm := &node.VMs[i]
+		if vm.Status != vmStatusRunning || vm.Type == "lxc" {
+			continue
+		}
+		metrics, err := source.GetVMSessionMetrics(node.Name, vm.ID)
+		if err != nil {
+			continue
+		}
+		loads[vm.ID] = sessionLoad(sessions, metrics)
+	}
+	return loads
+}
+
+// sessionLoad scales a VM's connections and processes against the configured busy levels, capped at 1.
+func sessionLoad(cfg *config.SessionsConfig, metrics *models.SessionMetrics) float64 {
+	load := math.Max(float64(metrics.Connections)/float64(cfg.HighConnections),
+		float64(metrics.Processes)/float64(cfg.HighProcesses))
+	return math.Min(1, load)
+}
@@ -0,0 +1,109 @@
+package balancer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/rules"
+)
+
+// Reasons a VM considered for migration was left in place.
+const (
+	skipIgnored     = "ignored (plb_ignore tag or exclude_vmids)"
+	skipDuplicate   = "duplicate VMID (listed on several nodes)"
+	skipFrozen      = "frozen (inside its plb_freeze window)"
+	skipNotRunning  = "not running"
+	skipSuspended   = "paused or suspended (suspended_vms is skip)"
+	skipBackup      = "backup (backed up, or inside its backup window)"
+	skipPassthrough = "passthrough (PCI devices tied to the node, or passthrough is skip)"
+	skipCooldown    = "cooldown (migrated within the last hour)"
+	skipCooldownFor = "cooldown (migrated within the last %v)"
+	skipRules       = "rules (current placement breaks a rule)"
+	skipNoTarget    = "no valid target: %s"
+	skipNoGain      = "no gain"
+	skipLowGain     = "gain %.1f below the minimum improvement %.1f"
+	skipProtected   = "protected (gain below protected_min_gain)"
+	skipNetBenefit  = "net benefit (gain over the horizon doesn't outweigh the migration cost)"
+	skipCycleLimit  = "cycle limit (5 migrations already planned)"
+	skipBreakIn     = "break-in (1 migration per cycle until the break-in ends)"
+	skipDowntime    = "downtime (estimated %v above migrate_downtime %gs)"
+
+	skipConsolidation = "consolidation (VM %s (%d) on the same node can't move: %s)"
+)
+
+// skipLog remembers the VMs evaluated but left in place during the last cycle.
+type skipLog struct {
+	mu  sync.Mutex
+	vms []models.SkippedVM
+}
+
+// set replaces the logged VMs with this cycle's.
+func (l *skipLog) set(vms []models.SkippedVM) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.vms = vms
+}
+
+// add logs more VMs for the current cycle.
+func (l *skipLog) add(vms ...models.SkippedVM) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.vms = append(l.vms, vms...)
+}
+
+// list returns a copy of the logged VMs in evaluation order.
+func (l *skipLog) list() []models.SkippedVM {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	vms := make([]models.SkippedVM, len(l.vms))
+	copy(vms, l.vms)
+	return vms
+}
+
+// withoutPlanned drops the skipped VMs a later pass planned to migrate after all.
+func withoutPlanned(skipped []models.SkippedVM, migrations []models.Migration) []models.SkippedVM {
+	planned := plannedVMs(migrations)
+
+	kept := make([]models.SkippedVM, 0, len(skipped))
+	for _, vm := range skipped {
+		if !planned[vm.VMID] {
+			kept = append(kept, vm)
+		}
+	}
+	return kept
+}
+
+// skippedVM describes a VM on node left in place, formatting the reason with args.
+func skippedVM(vm *models.VM, node, reason string, args ...interface{}) models.SkippedVM {
+	if len(args) > 0 {
+		reason = fmt.Sprintf(reason, args...)
+	}
+	return models.SkippedVM{
+		VMID:   vm.ID,
+		Name:   vm.Name,
+		Node:   node,
+		Reason: reason,
+	}
+}
+
+// governedBy records the placement rules behind a skip, leaving out unnamed ones.
+func governedBy(skip models.SkippedVM, governing ...string) models.SkippedVM {
+	for _, rule := range governing {
+		if rule != "" {
+			skip.Rules = append(skip.Rules, rule)
+		}
+	}
+	return skip
+}
+
+// excludingRules returns the placement rules refusing the VM the candidate targets off its source node.
+func excludingRules(engine *rules.Engine, vm *models.VM, sourceNode string, candidates []models.NodeScore) []string {
+	var nodes []string
+	for _, score := range candidates {
+		if score.Node != sourceNode {
+			nodes = append(nodes, score.Node)
+		}
+	}
+	return engine.ExcludingRules(vm, nodes)
+}
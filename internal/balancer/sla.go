@@ -0,0 +1,102 @@
+package balancer
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// slaBreaches returns the node resources, CPU or memory, further above the cluster mean than the
+// imbalance SLA allows, worst first. Without an SLA, there are none.
+func slaBreaches(cfg *config.Config, nodes []models.Node) []models.SLABreach {
+	sla := cfg.Balancing.ImbalanceSLA
+	if sla <= 0 || len(nodes) == 0 {
+		return nil
+	}
+
+	var cpuTotal, memoryTotal float64
+	for i := range nodes {
+		cpuTotal += float64(nodes[i].CPU.Usage)
+		memoryTotal += float64(nodes[i].Memory.Usage)
+	}
+	cpuMean := cpuTotal / float64(len(nodes))
+	memoryMean := memoryTotal / float64(len(nodes))
+
+	var breaches []models.SLABreach
+	for i := range nodes {
+		resources := []struct {
+			name  string
+			usage float64
+			mean  float64
+		}{
+			{"cpu", float64(nodes[i].CPU.Usage), cpuMean},
+			{"memory", float64(nodes[i].Memory.Usage), memoryMean},
+		}
+		for _, resource := range resources {
+			if excess := resource.usage - resource.mean; excess > sla {
+				breaches = append(breaches, models.SLABreach{
+					Node:     nodes[i].Name,
+					Resource: resource.name,
+					Usage:    resource.usage,
+					Mean:     resource.mean,
+					Excess:   excess,
+				})
+			}
+		}
+	}
+	sort.SliceStable(breaches, func(i, j int) bool {
+		return breaches[i].Excess > breaches[j].Excess
+	})
+	return breaches
+}
+
+// slaFirst puts the nodes breaching the SLA, worst first, ahead of the other source nodes, so their
+// VMs are planned before the cycle limit is reached.
+func slaFirst(breaches []models.SLABreach, nodes, sources []models.Node) []models.Node {
+	nodesByName := make(map[string]*models.Node, len(nodes))
+	for i := range nodes {
+		nodesByName[nodes[i].Name] = &nodes[i]
+	}
+
+	ordered := make([]models.Node, 0, len(sources)+len(breaches))
+	seen := make(map[string]bool)
+	for _, breach := range breaches {
+		if node, exists := nodesByName[breach.Node]; exists && !seen[breach.Node] {
+			ordered = append(ordered, *node)
+			seen[breach.Node] = true
+		}
+	}
+	for i := range sources {
+		if !seen[sources[i].Name] {
+			ordered = append(ordered, sources[i])
+		}
+	}
+	return ordered
+}
+
+// slaAlarm reports imbalance SLA breaches. It remembers whether the SLA was breached, to report
+// once when the cluster is back within it.
+type slaAlarm struct {
+	mu       sync.Mutex
+	breached bool
+}
+
+// check returns the SLA breaches of the nodes, logging an alert for each every cycle they last.
+func (a *slaAlarm) check(cfg *config.Config, nodes []models.Node) []models.SLABreach {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	breaches := slaBreaches(cfg, nodes)
+	if len(breaches) == 0 && a.breached {
+		logf("Imbalance SLA restored: every node is within %g points of the cluster mean\n", cfg.Balancing.ImbalanceSLA)
+	}
+	a.breached = len(breaches) > 0
+
+	for _, breach := range breaches {
+		logf("ALERT: imbalance SLA breached: %s %s at %.1f%% is %.1f points above the cluster mean %.1f%% (SLA %g)\n",
+			breach.Node, breach.Resource, breach.Usage, breach.Excess, breach.Mean, cfg.Balancing.ImbalanceSLA)
+	}
+	return breaches
+}
@@ -0,0 +1,89 @@
+package balancer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/cblomart/GoProxLB/internal/rules"
+)
+
+// unschedulableTracker remembers the VMs that should move but have no valid target.
+// It is read by the status socket while a cycle runs, hence the lock.
+type unschedulableTracker struct {
+	mu  sync.Mutex
+	vms map[int]models.UnschedulableVM
+}
+
+// newUnschedulableTracker creates an empty tracker.
+func newUnschedulableTracker() *unschedulableTracker {
+	return &unschedulableTracker{vms: make(map[int]models.UnschedulableVM)}
+}
+
+// update replaces the tracked VMs with this cycle's findings, keeping when each VM first got stuck.
+// Newly stuck VMs are reported once, not on every cycle.
+func (t *unschedulableTracker) update(current []models.UnschedulableVM, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	vms := make(map[int]models.UnschedulableVM, len(current))
+	for _, vm := range current {
+		if previous, exists := t.vms[vm.VMID]; exists {
+			vm.Since = previous.Since
+		} else {
+			vm.Since = now
+			logf("Warning: VM %s (%d) on %s should move but is unschedulable: %s\n", vm.Name, vm.VMID, vm.Node, vm.Reason)
+		}
+		vms[vm.VMID] = vm
+	}
+
+	for id, vm := range t.vms {
+		if _, exists := vms[id]; !exists {
+			logf("VM %s (%d) is schedulable again\n", vm.Name, vm.VMID)
+		}
+	}
+
+	t.vms = vms
+}
+
+// list returns the tracked VMs ordered by VM ID.
+func (t *unschedulableTracker) list() []models.UnschedulableVM {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	vms := make([]models.UnschedulableVM, 0, len(t.vms))
+	for _, vm := range t.vms {
+		vms = append(vms, vm)
+	}
+	sort.Slice(vms, func(i, j int) bool {
+		return vms[i].VMID < vms[j].VMID
+	})
+	return vms
+}
+
+// newUnschedulableVM describes why a VM on sourceNode found no target among the candidate scores.
+func newUnschedulableVM(engine *rules.Engine, vm *models.VM, sourceNode string, candidates []models.NodeScore) models.UnschedulableVM {
+	eligible := 0
+	for _, score := range candidates {
+		if score.Node != sourceNode {
+			eligible++
+		}
+	}
+
+	reason := "placement rules exclude every target node"
+	switch {
+	case eligible == 0:
+		reason = "no eligible target node (maintenance, recent boot, observation, version or capacity)"
+	case engine.IsPinned(vm.ID):
+		reason = fmt.Sprintf("pinned to %v", engine.GetPinnedVMs()[vm.ID].Nodes)
+	}
+
+	return models.UnschedulableVM{
+		VMID:   vm.ID,
+		Name:   vm.Name,
+		Node:   sourceNode,
+		Reason: reason,
+	}
+}
@@ -0,0 +1,49 @@
+package balancer
+
+import (
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// nodeVersions maps each node to its Proxmox version when migrations are restricted to
+// same-major-version pairs, or returns nil when they aren't.
+func nodeVersions(cfg *config.Config, nodes []models.Node) map[string]string {
+	if !cfg.Balancing.SameMajorVersion {
+		return nil
+	}
+
+	versions := make(map[string]string, len(nodes))
+	for i := range nodes {
+		versions[nodes[i].Name] = nodes[i].Version
+	}
+	return versions
+}
+
+// majorVersion returns the major part of a Proxmox version (e.g., "8" for "8.1.4").
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
+
+// filterVersionTargets drops the targets running another Proxmox major version than the source node.
+// Nodes with an unknown version are kept, as are all targets when versions is nil.
+func filterVersionTargets(versions map[string]string, source string, targets []models.NodeScore) []models.NodeScore {
+	sourceMajor := majorVersion(versions[source])
+	if sourceMajor == "" {
+		return targets
+	}
+
+	kept := make([]models.NodeScore, 0, len(targets))
+	for _, score := range targets {
+		targetMajor := majorVersion(versions[score.Node])
+		if score.Node != source && targetMajor != "" && targetMajor != sourceMajor {
+			logf("Skipping migrations from %s to %s: version mismatch (Proxmox %s vs %s)\n",
+				source, score.Node, versions[source], versions[score.Node])
+			continue
+		}
+		kept = append(kept, score)
+	}
+	return kept
+}
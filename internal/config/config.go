@@ -3,6 +3,9 @@ package config
 
 import (
 	"fmt"
+	"math"
+	"net"
+	"os"
 	"strings"
 	"time"
 
@@ -17,6 +20,12 @@ type Config struct {
 	Balancing BalancingConfig `mapstructure:"balancing"`
 	Logging   LoggingConfig   `mapstructure:"logging"`
 	Raft      RaftConfig      `mapstructure:"raft"`
+	Status    StatusConfig    `mapstructure:"status"`
+	CSV       CSVConfig       `mapstructure:"csv"`
+	Metrics   MetricsConfig   `mapstructure:"metrics"`
+
+	// ReadOnly runs the full daemon as an observer: plans are computed and published but never executed
+	ReadOnly bool `mapstructure:"read_only"`
 }
 
 // ProxmoxConfig holds Proxmox connection settings.
@@ -26,28 +35,303 @@ type ProxmoxConfig struct {
 	Password string `mapstructure:"password"`
 	Token    string `mapstructure:"token"`
 	Insecure bool   `mapstructure:"insecure"`
+
+	// InsecureHosts are the hostnames, IPs or CIDRs, besides loopback, for which insecure may skip TLS verification
+	InsecureHosts []string `mapstructure:"insecure_hosts"`
+
+	// Retry retries API requests that fail transiently (connection errors, 5xx responses)
+	Retry RetryConfig `mapstructure:"retry"`
+
+	// Concurrency is how many nodes are fetched in parallel each cycle (0 or 1 fetches them one by one)
+	Concurrency int `mapstructure:"concurrency"`
+
+	// MigrationType is "secure" to encrypt migration traffic over SSH, or "insecure" for a faster
+	// plain stream; empty keeps the cluster default from datacenter.cfg
+	MigrationType string `mapstructure:"migration_type"`
+
+	// MigrationNetwork is the CIDR of the network migrations run over; empty keeps the cluster default
+	MigrationNetwork string `mapstructure:"migration_network"`
+}
+
+// RetryConfig holds the retries of Proxmox API requests, with a delay doubling from BaseDelay up
+// to MaxDelay. Reads are retried on connection errors and 5xx or 429 responses; migrations and
+// other writes only when the connection couldn't be made, so they never run twice.
+type RetryConfig struct {
+	MaxAttempts int    `mapstructure:"max_attempts"` // Attempts per request (0 or 1 disables retries)
+	BaseDelay   string `mapstructure:"base_delay"`   // Delay before the first retry (e.g., "500ms")
+	MaxDelay    string `mapstructure:"max_delay"`    // Longest delay between attempts (e.g., "5s", empty = unbounded)
+}
+
+// GetBaseDelay returns the delay before the first retry.
+// An empty setting retries right away.
+func (r RetryConfig) GetBaseDelay() (time.Duration, error) {
+	if r.BaseDelay == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(r.BaseDelay)
+}
+
+// GetMaxDelay returns the longest delay between attempts.
+// An empty setting doesn't bound the delay.
+func (r RetryConfig) GetMaxDelay() (time.Duration, error) {
+	if r.MaxDelay == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(r.MaxDelay)
 }
 
 // ClusterConfig holds cluster-specific settings.
 type ClusterConfig struct {
 	Name             string   `mapstructure:"name"`
 	MaintenanceNodes []string `mapstructure:"maintenance_nodes"`
+
+	// FallbackName is used, with a warning, when the cluster name can't be auto-detected at startup.
+	// Empty keeps failing startup instead.
+	FallbackName string `mapstructure:"fallback_name"`
+
+	// Zones groups nodes into fault domains (e.g., racks): zone name -> node names
+	Zones map[string][]string `mapstructure:"zones"`
+
+	// NodeRoles restricts the part nodes take in balancing: role -> node names. Unlisted nodes are "both".
+	NodeRoles map[string][]string `mapstructure:"node_roles"`
+}
+
+// Balancing roles of a node.
+const (
+	// NodeRoleBoth lets VMs move off and onto the node.
+	NodeRoleBoth = "both"
+	// NodeRoleSourceOnly lets VMs move off the node but never onto it.
+	NodeRoleSourceOnly = "source_only"
+	// NodeRoleTargetOnly lets VMs move onto the node but never off it.
+	NodeRoleTargetOnly = "target_only"
+	// NodeRoleNone keeps the node out of balancing.
+	NodeRoleNone = "none"
+)
+
+// NodeRole returns the balancing role of a node.
+func (c *ClusterConfig) NodeRole(node string) string {
+	for role, nodes := range c.NodeRoles {
+		for _, name := range nodes {
+			if name == node {
+				return role
+			}
+		}
+	}
+	return NodeRoleBoth
+}
+
+// CanBeSource reports whether VMs may be migrated off the node.
+func (c *ClusterConfig) CanBeSource(node string) bool {
+	role := c.NodeRole(node)
+	return role == NodeRoleBoth || role == NodeRoleSourceOnly
+}
+
+// CanBeTarget reports whether VMs may be migrated onto the node.
+func (c *ClusterConfig) CanBeTarget(node string) bool {
+	role := c.NodeRole(node)
+	return role == NodeRoleBoth || role == NodeRoleTargetOnly
+}
+
+// NodeZones returns the zone of each node listed in the cluster zones.
+func (c *ClusterConfig) NodeZones() map[string]string {
+	nodeZones := make(map[string]string)
+	for zone, nodes := range c.Zones {
+		for _, node := range nodes {
+			nodeZones[node] = zone
+		}
+	}
+	return nodeZones
 }
 
 // BalancingConfig holds load balancing configuration.
 type BalancingConfig struct {
 	Interval       string             `mapstructure:"interval"`
-	BalancerType   string             `mapstructure:"balancer_type"`  // "threshold" or "advanced"
-	Aggressiveness string             `mapstructure:"aggressiveness"` // low, medium, high
-	Cooldown       string             `mapstructure:"cooldown"`       // Duration string (e.g., "2h") - now linked to aggressiveness
+	BalancerType   string             `mapstructure:"balancer_type"`  // "threshold", "advanced" or "consolidate"
+	Aggressiveness string             `mapstructure:"aggressiveness"` // low, medium, high, or adaptive to follow recent migrations
+	Cooldown       string             `mapstructure:"cooldown"`       // Duration string (e.g., "2h"), empty for the aggressiveness default
+	ForceMode      string             `mapstructure:"force_mode"`     // How a forced balance behaves: "always" or "reevaluate"
 	Thresholds     ResourceThresholds `mapstructure:"thresholds"`
 	Weights        ResourceWeights    `mapstructure:"weights"`
+	ScoreWeights   ScoreWeights       `mapstructure:"score_weights"` // Advanced balancer scoring blend
+
+	// ExcludeVMIDs lists VMs that are never migrated, like the plb_ignore_ tag
+	ExcludeVMIDs []int `mapstructure:"exclude_vmids"`
+
+	// StartAfter disables balancing until this RFC 3339 timestamp (e.g., "2025-07-01T08:00:00Z")
+	StartAfter string `mapstructure:"start_after"`
+
+	// CycleBudget bounds how long a cycle keeps starting migrations (e.g., "4m", empty disables)
+	CycleBudget string `mapstructure:"cycle_budget"`
+
+	// MigrationDelay pauses between sequential migrations so each settles before the next (e.g., "30s", empty disables)
+	MigrationDelay string `mapstructure:"migration_delay"`
+
+	// BackupWindow is how long after a backup job starts its VMs stay in place (e.g., "2h", empty disables)
+	BackupWindow string `mapstructure:"backup_window"`
+
+	// MinTargetUptime excludes recently rebooted nodes as migration targets (e.g., "10m", empty disables)
+	MinTargetUptime string `mapstructure:"min_target_uptime"`
+
+	// HealthCheck runs a readiness command for each target node, excluding the nodes it fails for
+	HealthCheck HealthCheckConfig `mapstructure:"health_check"`
+
+	// BenefitHorizon weighs each migration's gain over this period against its cost, only net-positive
+	// migrations run (advanced balancer, e.g., "1h", empty disables)
+	BenefitHorizon string `mapstructure:"benefit_horizon"`
+
+	// ObservationWindow excludes both nodes of a migration from further decisions while metrics settle (e.g., "10m", empty disables)
+	ObservationWindow string `mapstructure:"observation_window"`
+
+	// ProtectedMinGain is the score gain (percentage points) needed to move a protected VM.
+	// Values above 100 never move them.
+	ProtectedMinGain float64 `mapstructure:"protected_min_gain"`
+
+	// SameMajorVersion restricts migrations to nodes running the same Proxmox major version,
+	// as live migration across major versions is unsafe during rolling upgrades
+	SameMajorVersion bool `mapstructure:"same_major_version"`
+
+	// ZeroFootprint sets how stopped or idle VMs are handled: "ignore" or "rules".
+	// With "rules" they are moved without any gain when their placement breaks a rule.
+	ZeroFootprint string `mapstructure:"zero_footprint"`
+
+	// SuspendedVMs sets how paused or suspended VMs are handled: "skip" leaves them in place,
+	// "offline" moves them like stopped VMs, as they can't be live-migrated
+	SuspendedVMs string `mapstructure:"suspended_vms"`
+
+	// Passthrough sets how VMs with PCI passthrough devices are handled: "restrict" moves those
+	// using resource mappings only to nodes with the mapped devices, "skip" leaves them all in place.
+	// VMs passing through a raw host device are always left in place
+	Passthrough string `mapstructure:"passthrough"`
+
+	// Concurrency caps simultaneous migrations per node; unset runs migrations one at a time
+	Concurrency MigrationConcurrencyConfig `mapstructure:"concurrency"`
+
+	// Overcommit caps the configured-to-physical resource ratio a migration may push a target to
+	Overcommit OvercommitConfig `mapstructure:"overcommit"`
+
+	// RuleCorrections moves running VMs whose placement breaks a rule on a lower gain than load balancing
+	RuleCorrections RuleCorrectionsConfig `mapstructure:"rule_corrections"`
+
+	// MigrationBandwidth is the assumed live migration throughput in MiB/s, used to estimate
+	// how long migrations take (0 disables estimates)
+	MigrationBandwidth float64 `mapstructure:"migration_bandwidth"`
+
+	// MemoryHeadroom is the memory in MiB a target must keep available once it hosts a migrated VM
+	// (advanced balancer)
+	MemoryHeadroom float64 `mapstructure:"memory_headroom"`
+
+	// PanicThreshold is the CPU or memory usage (percent) past which a node sheds load at once,
+	// ignoring cooldowns and the minimum improvement (advanced balancer, 0 disables)
+	PanicThreshold int `mapstructure:"panic_threshold"`
+
+	// PoolPolicies sets the balancing policy of VMs by resource pool: policy -> pool names.
+	// VMs in unlisted pools, or in none, are balanced as usual
+	PoolPolicies map[string][]string `mapstructure:"pool_policies"`
+
+	// Tolerance is the band (percentage points) around the cluster average CPU and memory usage
+	// within which nodes are balanced enough: no migration runs, even forced (0 disables)
+	Tolerance float64 `mapstructure:"tolerance"`
+
+	// ImbalanceSLA is how many percentage points a node's CPU or memory usage may sit above the
+	// cluster mean; nodes beyond it are reported and relieved first (0 disables)
+	ImbalanceSLA float64 `mapstructure:"imbalance_sla"`
+
+	// NewVMWindow treats VMs created within this period and never migrated as newly landed: those
+	// breaking a placement rule move on the next cycle, whatever the load (e.g., "24h", empty disables)
+	NewVMWindow string `mapstructure:"new_vm_window"`
+
+	// OverCapacity sets what happens when every node is above its thresholds, leaving no target
+	OverCapacity OverCapacityConfig `mapstructure:"over_capacity"`
+
+	// Hysteresis keeps an overloaded node overloaded until its usage drops this many percentage
+	// points below the threshold, per resource, so nodes hovering around a threshold don't flap
+	Hysteresis ResourceHysteresis `mapstructure:"hysteresis"`
+
+	// MaxNodeDrop aborts a cycle when more than this fraction of the nodes seen in the previous
+	// cycle disappeared, more likely an API glitch or a partition than reality (e.g., 0.5, 0 disables)
+	MaxNodeDrop float64 `mapstructure:"max_node_drop"`
+
+	// BreakIn caps a new deployment to a single migration per cycle while it earns trust
+	BreakIn BreakInConfig `mapstructure:"break_in"`
 
 	// Advanced features
 	LoadProfiles LoadProfilesConfig `mapstructure:"load_profiles"`
 	Capacity     CapacityConfig     `mapstructure:"capacity"`
+	Power        PowerConfig        `mapstructure:"power"`
+	Sessions     SessionsConfig     `mapstructure:"sessions"`
+}
+
+// Pool policies, set per resource pool in pool_policies.
+const (
+	// PoolPolicyMovable makes the pool's VMs the first to move off a loaded node.
+	PoolPolicyMovable = "movable"
+	// PoolPolicyProtected makes the pool's VMs the last to move, and only on protected_min_gain.
+	PoolPolicyProtected = "protected"
+)
+
+// PoolPolicy returns the balancing policy of a resource pool, or "" when it has none.
+func (b *BalancingConfig) PoolPolicy(pool string) string {
+	if pool == "" {
+		return ""
+	}
+	for policy, pools := range b.PoolPolicies {
+		for _, name := range pools {
+			if name == pool {
+				return policy
+			}
+		}
+	}
+	return ""
 }
 
+// Force modes for a forced balancing cycle.
+const (
+	// ForceModeAlways balances even an already balanced cluster, accepting any positive gain.
+	ForceModeAlways = "always"
+	// ForceModeReevaluate only skips the cooldown; balanced clusters are left alone.
+	ForceModeReevaluate = "reevaluate"
+)
+
+// Handling of VMs with zero resource footprint (stopped or idle).
+const (
+	// ZeroFootprintIgnore leaves them to the load-based pass, where they never bring a gain.
+	ZeroFootprintIgnore = "ignore"
+	// ZeroFootprintRules relocates them, without a gain, when they break a placement rule.
+	ZeroFootprintRules = "rules"
+)
+
+// Handling of paused or suspended VMs.
+const (
+	// SuspendedVMsSkip leaves them on their node, whatever the load or the rules.
+	SuspendedVMsSkip = "skip"
+	// SuspendedVMsOffline moves them like stopped VMs, with an offline migration.
+	SuspendedVMsOffline = "offline"
+)
+
+// Ways to take percentiles from the history.
+const (
+	// PercentilesNearest picks the sample of the nearest rank, the fast path.
+	PercentilesNearest = "nearest"
+	// PercentilesInterpolate interpolates linearly between the two closest ranks (type 7 quantiles).
+	PercentilesInterpolate = "interpolate"
+)
+
+// Handling of VMs with PCI passthrough devices.
+const (
+	// PassthroughRestrict moves VMs using resource mappings, only to nodes with the mapped devices.
+	PassthroughRestrict = "restrict"
+	// PassthroughSkip leaves every VM with a passthrough device on its node.
+	PassthroughSkip = "skip"
+)
+
+// Actions taken when the whole cluster is over capacity.
+const (
+	// OverCapacityAlert logs a cluster over capacity alert and skips the cycle.
+	OverCapacityAlert = "alert"
+	// OverCapacityScaleOut also runs the scale-out command, once each time the cluster goes over capacity.
+	OverCapacityScaleOut = "scale_out"
+)
+
 // ResourceThresholds defines when to trigger rebalancing.
 type ResourceThresholds struct {
 	CPU     int `mapstructure:"cpu"`
@@ -55,6 +339,13 @@ type ResourceThresholds struct {
 	Storage int `mapstructure:"storage"`
 }
 
+// ResourceHysteresis defines the re-arm margin of each resource threshold, in percentage points (0 disables).
+type ResourceHysteresis struct {
+	CPU     float64 `mapstructure:"cpu"`
+	Memory  float64 `mapstructure:"memory"`
+	Storage float64 `mapstructure:"storage"`
+}
+
 // ResourceWeights defines the importance of each resource type.
 type ResourceWeights struct {
 	CPU     float64 `mapstructure:"cpu"`
@@ -62,22 +353,185 @@ type ResourceWeights struct {
 	Storage float64 `mapstructure:"storage"`
 }
 
+// ScoreWeights defines how the advanced balancer blends its sub-scores.
+// Resource, stability, capacity and migration cost must sum to 1.0, as must current and predictive.
+type ScoreWeights struct {
+	Resource      float64 `mapstructure:"resource"`
+	Stability     float64 `mapstructure:"stability"`
+	Capacity      float64 `mapstructure:"capacity"`
+	MigrationCost float64 `mapstructure:"migration_cost"`
+	Current       float64 `mapstructure:"current"`    // Share of current usage in the resource score
+	Predictive    float64 `mapstructure:"predictive"` // Share of P90 prediction in the resource score
+}
+
+// DefaultScoreWeights returns the built-in advanced scoring blend.
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		Resource:      0.4,
+		Stability:     0.2,
+		Capacity:      0.3,
+		MigrationCost: 0.1,
+		Current:       0.7,
+		Predictive:    0.3,
+	}
+}
+
+// MigrationConcurrencyConfig limits how many migrations may touch a node at once.
+// Migrations beyond the limits are scheduled in subsequent waves.
+type MigrationConcurrencyConfig struct {
+	PerSource int `mapstructure:"per_source"` // Simultaneous migrations off a node (0 = unlimited)
+	PerTarget int `mapstructure:"per_target"` // Simultaneous migrations onto a node (0 = unlimited)
+}
+
+// OvercommitConfig holds the configured-to-physical ratios migration targets must stay within.
+// Only running VMs count, and a zero ratio leaves the resource unchecked.
+type OvercommitConfig struct {
+	CPU    float64 `mapstructure:"cpu"`    // vCPUs per physical core (e.g., 3 for 3:1)
+	Memory float64 `mapstructure:"memory"` // Configured VM memory per byte of node memory (e.g., 1.2 for 1.2:1)
+}
+
+// RuleCorrectionsConfig holds the gain bound for moves that fix a broken placement rule.
+type RuleCorrectionsConfig struct {
+	Enabled bool    `mapstructure:"enabled"`
+	MinGain float64 `mapstructure:"min_gain"` // Score gain (percentage points) needed, in place of min_improvement
+}
+
+// Enabled reports whether migrations may run concurrently.
+func (c MigrationConcurrencyConfig) Enabled() bool {
+	return c.PerSource > 0 || c.PerTarget > 0
+}
+
 // LoadProfilesConfig holds load profiling settings.
 type LoadProfilesConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
 	Window  string `mapstructure:"window"` // Duration string (e.g., "24h")
+
+	// BusinessHours splits VM profiles into business and off-hours loads
+	BusinessHours BusinessHoursConfig `mapstructure:"business_hours"`
+}
+
+// BusinessHoursConfig holds the daily period that time-of-day load profiles are split on.
+type BusinessHoursConfig struct {
+	Start     string   `mapstructure:"start"`     // "HH:MM", empty disables time-of-day profiles
+	End       string   `mapstructure:"end"`       // "HH:MM", may be before start for overnight hours
+	Days      []string `mapstructure:"days"`      // "mon" to "sun", empty means every day
+	Lookahead string   `mapstructure:"lookahead"` // How early the upcoming period's load is anticipated (e.g., "1h")
+}
+
+// weekdays maps day names to time.Weekday for business hours.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Enabled reports whether time-of-day load profiles are configured.
+func (c BusinessHoursConfig) Enabled() bool {
+	return c.Start != ""
+}
+
+// Contains reports whether t falls within business hours, in t's location.
+func (c BusinessHoursConfig) Contains(t time.Time) bool {
+	if len(c.Days) > 0 {
+		onDay := false
+		for _, day := range c.Days {
+			if weekdays[strings.ToLower(day)] == t.Weekday() {
+				onDay = true
+				break
+			}
+		}
+		if !onDay {
+			return false
+		}
+	}
+
+	start, _ := parseClock(c.Start) //nolint:errcheck // validated at load time
+	end, _ := parseClock(c.End)     //nolint:errcheck // validated at load time
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// GetLookahead returns how early the upcoming period's load is anticipated; 0 uses the current period.
+func (c BusinessHoursConfig) GetLookahead() (time.Duration, error) {
+	if c.Lookahead == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Lookahead)
+}
+
+// parseClock parses an "HH:MM" time of day into minutes since midnight.
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q (expected HH:MM): %w", clock, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
 }
 
 // CapacityConfig holds capacity planning settings.
 type CapacityConfig struct {
 	Enabled  bool   `mapstructure:"enabled"`
 	Forecast string `mapstructure:"forecast"` // Duration string (e.g., "7d")
+
+	// Percentiles sets how P50 to P99 are taken from the history: "nearest" picks the closest
+	// sample, "interpolate" interpolates between the two closest, more accurate for short histories
+	Percentiles string `mapstructure:"percentiles"`
+}
+
+// PowerConfig holds optional power/thermal telemetry settings.
+type PowerConfig struct {
+	Enabled bool    `mapstructure:"enabled"`
+	Source  string  `mapstructure:"source"`  // "file" or "command"
+	Path    string  `mapstructure:"path"`    // JSON file for the file source
+	Command string  `mapstructure:"command"` // Command printing JSON for the command source
+	Mode    string  `mapstructure:"mode"`    // "spread" avoids hotspots, "consolidate" reduces total power
+	Weight  float64 `mapstructure:"weight"`  // Score weight of the power bias
+}
+
+// HealthCheckConfig holds the optional custom readiness check of target nodes.
+type HealthCheckConfig struct {
+	Command string `mapstructure:"command"` // Shell command, the node name is passed as $1; empty disables the check
+	Timeout string `mapstructure:"timeout"` // Duration after which the check fails (e.g., "10s")
+}
+
+// OverCapacityConfig holds the handling of a cluster whose nodes are all above their thresholds.
+type OverCapacityConfig struct {
+	Action  string `mapstructure:"action"`  // "alert" or "scale_out"
+	Command string `mapstructure:"command"` // Shell command flagging the need for more nodes (scale_out), the node count is passed as $1
+	Timeout string `mapstructure:"timeout"` // Duration after which the command is stopped (e.g., "30s")
+}
+
+// BreakInConfig holds the break-in of a new deployment: until both its cycles and its duration
+// have passed, each cycle runs at most one migration and logs every deferred one. Break-in starts
+// over when the service restarts.
+type BreakInConfig struct {
+	Cycles   int    `mapstructure:"cycles"`   // Cycles running migrations before the break-in ends (0 = not bound by cycles)
+	Duration string `mapstructure:"duration"` // Time from the first cycle before the break-in ends (e.g., "24h", empty = not bound by time)
+}
+
+// Enabled reports whether a break-in is configured.
+func (b BreakInConfig) Enabled() bool {
+	return b.Cycles > 0 || b.Duration != ""
+}
+
+// SessionsConfig holds the optional guest agent session metrics, used to spare VMs with many
+// active sessions when choosing what to migrate (advanced balancer).
+type SessionsConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	HighConnections int  `mapstructure:"high_connections"` // Established TCP connections of a fully busy VM
+	HighProcesses   int  `mapstructure:"high_processes"`   // Process count of a fully busy VM
 }
 
 // LoggingConfig holds logging settings.
 type LoggingConfig struct {
 	Level  string `mapstructure:"level"`
 	Format string `mapstructure:"format"`
+
+	// DecisionMatrix is a file receiving one JSON line of node sub-scores per cycle.
+	// When empty, the matrix is logged at debug level instead.
+	DecisionMatrix string `mapstructure:"decision_matrix"`
 }
 
 // RaftConfig holds Raft leader election configuration.
@@ -89,6 +543,35 @@ type RaftConfig struct {
 	Peers        []string `mapstructure:"peers"`
 	AutoDiscover bool     `mapstructure:"auto_discover"` // Auto-discover peers from Proxmox cluster
 	Port         int      `mapstructure:"port"`          // Raft communication port
+
+	// ElectionTimeout is how long startup first waits for a leader (e.g., "30s").
+	// Each retry doubles the wait, up to ElectionMaxTimeout (e.g., "5m").
+	ElectionTimeout    string `mapstructure:"election_timeout"`
+	ElectionMaxTimeout string `mapstructure:"election_max_timeout"`
+}
+
+// Leader election waits used when the settings are empty.
+const (
+	defaultElectionTimeout    = 30 * time.Second
+	defaultElectionMaxTimeout = 5 * time.Minute
+)
+
+// StatusConfig holds settings for serving the status JSON beyond the local Unix socket.
+type StatusConfig struct {
+	TCPAddress string `mapstructure:"tcp_address"` // e.g. ":7947", empty keeps status local
+	Token      string `mapstructure:"token"`       // Optional bearer token required by TCP clients
+}
+
+// MetricsConfig holds the optional Prometheus metrics endpoint.
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Address string `mapstructure:"address"` // e.g. ":9808", metrics are served at /metrics
+}
+
+// CSVConfig holds the format of CSV reports, for spreadsheets expecting another locale.
+type CSVConfig struct {
+	Delimiter        string `mapstructure:"delimiter"`         // Single field separator character, e.g. ";"
+	DecimalSeparator string `mapstructure:"decimal_separator"` // "." or ","
 }
 
 // Load reads configuration from file.
@@ -143,6 +626,13 @@ func setDefaults() {
 	viper.SetDefault("proxmox.password", "")
 	viper.SetDefault("proxmox.token", "")
 	viper.SetDefault("proxmox.insecure", true) // Allow self-signed certs for localhost by default
+	viper.SetDefault("proxmox.insecure_hosts", []string{})
+	viper.SetDefault("proxmox.retry.max_attempts", 3)
+	viper.SetDefault("proxmox.retry.base_delay", "500ms")
+	viper.SetDefault("proxmox.retry.max_delay", "5s")
+	viper.SetDefault("proxmox.concurrency", 4)
+	viper.SetDefault("proxmox.migration_type", "")    // Cluster default
+	viper.SetDefault("proxmox.migration_network", "") // Cluster default
 
 	// Set cluster defaults
 	viper.SetDefault("cluster.name", "pve")
@@ -152,23 +642,80 @@ func setDefaults() {
 	viper.SetDefault("balancing.interval", "5m")
 	viper.SetDefault("balancing.balancer_type", "advanced") // Advanced by default
 	viper.SetDefault("balancing.aggressiveness", "low")     // LOW by default - trust must be earned
-	// Note: cooldown is now linked to aggressiveness level, not set here
+	viper.SetDefault("balancing.force_mode", ForceModeAlways)
+	viper.SetDefault("balancing.zero_footprint", ZeroFootprintIgnore)
+	viper.SetDefault("balancing.suspended_vms", SuspendedVMsSkip)
+	viper.SetDefault("balancing.passthrough", PassthroughRestrict)
+	// Note: cooldown defaults to the aggressiveness level's, not set here
 
 	// Set threshold defaults (for threshold balancer - kept for compatibility)
 	viper.SetDefault("balancing.thresholds.cpu", 80)
 	viper.SetDefault("balancing.thresholds.memory", 85)
 	viper.SetDefault("balancing.thresholds.storage", 90)
+	viper.SetDefault("balancing.hysteresis.cpu", 0)
+	viper.SetDefault("balancing.hysteresis.memory", 0)
+	viper.SetDefault("balancing.hysteresis.storage", 0)
+	viper.SetDefault("balancing.max_node_drop", 0.5)
+
+	viper.SetDefault("balancing.exclude_vmids", []int{})
+
+	viper.SetDefault("balancing.cycle_budget", "")
+	viper.SetDefault("balancing.migration_delay", "")
+	viper.SetDefault("balancing.backup_window", "2h")
+	viper.SetDefault("balancing.start_after", "")
+
+	// Freshly booted nodes may still be mounting storage or starting services
+	viper.SetDefault("balancing.min_target_uptime", "10m")
+	viper.SetDefault("balancing.health_check.command", "")
+	viper.SetDefault("balancing.health_check.timeout", "10s")
+	viper.SetDefault("balancing.over_capacity.action", OverCapacityAlert)
+	viper.SetDefault("balancing.over_capacity.command", "")
+	viper.SetDefault("balancing.over_capacity.timeout", "30s")
+	viper.SetDefault("balancing.observation_window", "")
+	viper.SetDefault("balancing.benefit_horizon", "")
+	viper.SetDefault("balancing.protected_min_gain", 25.0)
+	viper.SetDefault("balancing.tolerance", 0.0)
+	viper.SetDefault("balancing.imbalance_sla", 0.0)
+	viper.SetDefault("balancing.same_major_version", false)
+	viper.SetDefault("balancing.concurrency.per_source", 0)
+	viper.SetDefault("balancing.concurrency.per_target", 0)
+	viper.SetDefault("balancing.overcommit.cpu", 0.0)
+	viper.SetDefault("balancing.overcommit.memory", 0.0)
+	viper.SetDefault("balancing.rule_corrections.enabled", false)
+	viper.SetDefault("balancing.rule_corrections.min_gain", 0.0)
+	viper.SetDefault("balancing.panic_threshold", 0)
+	viper.SetDefault("balancing.migration_bandwidth", 100.0) // Roughly a dedicated 1 Gbit/s link
+	viper.SetDefault("balancing.memory_headroom", 1024.0)    // Room for the host and migration overhead
 
 	// Set weight defaults (for advanced balancer - SIMPLIFIED)
 	viper.SetDefault("balancing.weights.cpu", 1.0)
 	viper.SetDefault("balancing.weights.memory", 1.0)
 	viper.SetDefault("balancing.weights.storage", 0.5)
 
+	// Set score blend defaults (for advanced balancer)
+	defaultScoreWeights := DefaultScoreWeights()
+	viper.SetDefault("balancing.score_weights.resource", defaultScoreWeights.Resource)
+	viper.SetDefault("balancing.score_weights.stability", defaultScoreWeights.Stability)
+	viper.SetDefault("balancing.score_weights.capacity", defaultScoreWeights.Capacity)
+	viper.SetDefault("balancing.score_weights.migration_cost", defaultScoreWeights.MigrationCost)
+	viper.SetDefault("balancing.score_weights.current", defaultScoreWeights.Current)
+	viper.SetDefault("balancing.score_weights.predictive", defaultScoreWeights.Predictive)
+
 	// Set advanced features defaults - ENABLED by default
 	viper.SetDefault("balancing.load_profiles.enabled", true)
 	viper.SetDefault("balancing.load_profiles.window", "24h")
 	viper.SetDefault("balancing.capacity.enabled", true)
 	viper.SetDefault("balancing.capacity.forecast", "168h") // 7 days
+	viper.SetDefault("balancing.capacity.percentiles", PercentilesNearest)
+
+	// Power telemetry is optional and needs an external source
+	viper.SetDefault("balancing.power.enabled", false)
+	viper.SetDefault("balancing.power.source", "file")
+	viper.SetDefault("balancing.power.mode", "spread")
+	viper.SetDefault("balancing.power.weight", 0.1)
+	viper.SetDefault("balancing.sessions.enabled", false)
+	viper.SetDefault("balancing.sessions.high_connections", 500)
+	viper.SetDefault("balancing.sessions.high_processes", 1000)
 
 	// Set aggressiveness level defaults - CONSERVATIVE by default
 	viper.SetDefault("balancing.aggressiveness_levels.low.capacity_weight", 0.2)
@@ -183,10 +730,26 @@ func setDefaults() {
 	viper.SetDefault("raft.auto_discover", true)           // Enable auto-discovery by default
 	viper.SetDefault("raft.port", 7946)                    // Standard Serf port
 	viper.SetDefault("raft.peers", []string{})
+	viper.SetDefault("raft.election_timeout", "30s")
+	viper.SetDefault("raft.election_max_timeout", "5m")
+
+	// Observer mode is opt-in
+	viper.SetDefault("read_only", false)
+
+	// Status stays on the local Unix socket unless a TCP address is configured
+	viper.SetDefault("status.tcp_address", "")
+
+	// Prometheus metrics are opt-in
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.address", ":9808")
 
 	// Set logging defaults
+	viper.SetDefault("csv.delimiter", ",")
+	viper.SetDefault("csv.decimal_separator", ".")
+
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "text")
+	viper.SetDefault("logging.decision_matrix", "")
 }
 
 // validateConfig validates the configuration.
@@ -195,10 +758,112 @@ func validateConfig(config *Config) error {
 		return err
 	}
 
+	if err := validateClusterConfig(&config.Cluster); err != nil {
+		return err
+	}
+
 	if err := validateBalancingConfig(&config.Balancing); err != nil {
 		return err
 	}
 
+	if err := validateStatusConfig(&config.Status); err != nil {
+		return err
+	}
+
+	if err := validateMetricsConfig(&config.Metrics); err != nil {
+		return err
+	}
+
+	if err := validateElectionTimeouts(config); err != nil {
+		return err
+	}
+
+	if err := validateCSVConfig(&config.CSV); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateClusterConfig validates the cluster configuration.
+func validateClusterConfig(cluster *ClusterConfig) error {
+	seen := make(map[string]string)
+	for zone, nodes := range cluster.Zones {
+		for _, node := range nodes {
+			if other, exists := seen[node]; exists && other != zone {
+				return fmt.Errorf("node %s is listed in zones %s and %s", node, other, zone)
+			}
+			seen[node] = zone
+		}
+	}
+
+	roles := make(map[string]string)
+	for role, nodes := range cluster.NodeRoles {
+		switch role {
+		case NodeRoleBoth, NodeRoleSourceOnly, NodeRoleTargetOnly, NodeRoleNone:
+		default:
+			return fmt.Errorf("unknown node role %q: must be '%s', '%s', '%s' or '%s'",
+				role, NodeRoleBoth, NodeRoleSourceOnly, NodeRoleTargetOnly, NodeRoleNone)
+		}
+		for _, node := range nodes {
+			if other, exists := roles[node]; exists && other != role {
+				return fmt.Errorf("node %s has roles %s and %s", node, other, role)
+			}
+			roles[node] = role
+		}
+	}
+	return nil
+}
+
+// validateElectionTimeouts validates the leader election waits.
+func validateElectionTimeouts(config *Config) error {
+	timeout, err := config.GetElectionTimeout()
+	if err != nil || timeout <= 0 {
+		return fmt.Errorf("invalid raft election_timeout %q: must be a positive duration", config.Raft.ElectionTimeout)
+	}
+	maxTimeout, err := config.GetElectionMaxTimeout()
+	if err != nil || maxTimeout < timeout {
+		return fmt.Errorf("invalid raft election_max_timeout %q: must be a duration of at least election_timeout", config.Raft.ElectionMaxTimeout)
+	}
+	return nil
+}
+
+// validateCSVConfig validates the CSV report format. Empty settings keep the defaults.
+func validateCSVConfig(csvConfig *CSVConfig) error {
+	if csvConfig.Delimiter != "" {
+		delimiter := []rune(csvConfig.Delimiter)
+		if len(delimiter) != 1 || strings.ContainsRune("\"\r\n", delimiter[0]) {
+			return fmt.Errorf("invalid csv delimiter %q: must be a single character other than a quote or newline", csvConfig.Delimiter)
+		}
+	}
+	if csvConfig.DecimalSeparator != "" && csvConfig.DecimalSeparator != "." && csvConfig.DecimalSeparator != "," {
+		return fmt.Errorf("invalid csv decimal_separator %q: must be '.' or ','", csvConfig.DecimalSeparator)
+	}
+	if csvConfig.Delimiter != "" && csvConfig.Delimiter == csvConfig.DecimalSeparator {
+		return fmt.Errorf("csv delimiter and decimal_separator must differ")
+	}
+	return nil
+}
+
+// validateStatusConfig validates the status listener configuration.
+func validateStatusConfig(status *StatusConfig) error {
+	if status.TCPAddress == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(status.TCPAddress); err != nil {
+		return fmt.Errorf("invalid status tcp_address: %w", err)
+	}
+	return nil
+}
+
+// validateMetricsConfig validates the metrics endpoint configuration.
+func validateMetricsConfig(metrics *MetricsConfig) error {
+	if !metrics.Enabled {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(metrics.Address); err != nil {
+		return fmt.Errorf("invalid metrics address: %w", err)
+	}
 	return nil
 }
 
@@ -222,15 +887,152 @@ func (c *Config) GetCapacityForecast() (time.Duration, error) {
 	return time.ParseDuration(c.Balancing.Capacity.Forecast)
 }
 
+// GetElectionTimeout returns how long startup first waits for a leader.
+func (c *Config) GetElectionTimeout() (time.Duration, error) {
+	if c.Raft.ElectionTimeout == "" {
+		return defaultElectionTimeout, nil
+	}
+	return time.ParseDuration(c.Raft.ElectionTimeout)
+}
+
+// GetElectionMaxTimeout returns the longest wait for a leader between election retries.
+func (c *Config) GetElectionMaxTimeout() (time.Duration, error) {
+	if c.Raft.ElectionMaxTimeout == "" {
+		return defaultElectionMaxTimeout, nil
+	}
+	return time.ParseDuration(c.Raft.ElectionMaxTimeout)
+}
+
+// GetMinTargetUptime returns the minimum uptime a node needs to receive migrations.
+// An empty setting disables the check.
+func (c *Config) GetMinTargetUptime() (time.Duration, error) {
+	if c.Balancing.MinTargetUptime == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Balancing.MinTargetUptime)
+}
+
+// GetHealthCheckTimeout returns how long a target node health check may run, 10s when unset.
+func (c *Config) GetHealthCheckTimeout() (time.Duration, error) {
+	if c.Balancing.HealthCheck.Timeout == "" {
+		return 10 * time.Second, nil
+	}
+	return time.ParseDuration(c.Balancing.HealthCheck.Timeout)
+}
+
+// GetOverCapacityTimeout returns how long the scale-out command may run, 30s when unset.
+func (c *Config) GetOverCapacityTimeout() (time.Duration, error) {
+	if c.Balancing.OverCapacity.Timeout == "" {
+		return 30 * time.Second, nil
+	}
+	return time.ParseDuration(c.Balancing.OverCapacity.Timeout)
+}
+
+// GetNewVMWindow returns the period after creation during which a VM counts as newly landed.
+// An empty setting disables the early placement of new VMs.
+func (c *Config) GetNewVMWindow() (time.Duration, error) {
+	if c.Balancing.NewVMWindow == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Balancing.NewVMWindow)
+}
+
+// GetBenefitHorizon returns the period over which a migration's gain must outweigh its cost.
+// An empty setting disables the net benefit check.
+func (c *Config) GetBenefitHorizon() (time.Duration, error) {
+	if c.Balancing.BenefitHorizon == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Balancing.BenefitHorizon)
+}
+
+// GetObservationWindow returns how long nodes involved in a migration are left alone afterwards.
+// An empty setting disables the window.
+func (c *Config) GetObservationWindow() (time.Duration, error) {
+	if c.Balancing.ObservationWindow == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Balancing.ObservationWindow)
+}
+
+// GetBreakInDuration returns how long the break-in lasts from the first cycle.
+// An empty setting doesn't bound the break-in by time.
+func (c *Config) GetBreakInDuration() (time.Duration, error) {
+	if c.Balancing.BreakIn.Duration == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Balancing.BreakIn.Duration)
+}
+
+// GetScoreWeights returns the advanced scoring blend, falling back to defaults when unset.
+func (c *Config) GetScoreWeights() ScoreWeights {
+	if c.Balancing.ScoreWeights == (ScoreWeights{}) {
+		return DefaultScoreWeights()
+	}
+	return c.Balancing.ScoreWeights
+}
+
+// GetStartAfter returns the time before which balancing is disabled.
+// An empty setting returns the zero time.
+func (c *Config) GetStartAfter() (time.Time, error) {
+	if c.Balancing.StartAfter == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, c.Balancing.StartAfter)
+}
+
+// GetMigrationDelay returns the pause between sequential migrations.
+// An empty setting disables the pause.
+func (c *Config) GetMigrationDelay() (time.Duration, error) {
+	if c.Balancing.MigrationDelay == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Balancing.MigrationDelay)
+}
+
+// GetBackupWindow returns how long after a backup job starts its VMs aren't migrated.
+// An empty setting disables the window; a running backup still holds the VM.
+func (c *Config) GetBackupWindow() (time.Duration, error) {
+	if c.Balancing.BackupWindow == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Balancing.BackupWindow)
+}
+
+// GetCycleBudget returns how long a cycle may keep starting migrations.
+// An empty setting disables the budget.
+func (c *Config) GetCycleBudget() (time.Duration, error) {
+	if c.Balancing.CycleBudget == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.Balancing.CycleBudget)
+}
+
 // IsAdvancedBalancer returns true if advanced balancer is enabled.
 func (c *Config) IsAdvancedBalancer() bool {
 	return c.Balancing.BalancerType == "advanced"
 }
 
+// IsConsolidateBalancer returns true if the consolidation balancer, packing VMs to empty nodes, is enabled.
+func (c *Config) IsConsolidateBalancer() bool {
+	return c.Balancing.BalancerType == "consolidate"
+}
+
+// IsAdaptiveAggressiveness returns true if aggressiveness follows the cluster's recent migrations.
+func (c *Config) IsAdaptiveAggressiveness() bool {
+	return c.Balancing.Aggressiveness == "adaptive"
+}
+
 // GetAggressivenessConfig returns the aggressiveness configuration.
 // Cooldown is per-VM: "don't touch this VM because we already moved it less than X ago".
+// The adaptive setting gets the medium level here; balancers adapt it to the cluster.
 func (c *Config) GetAggressivenessConfig() AggressivenessConfig {
-	switch c.Balancing.Aggressiveness {
+	return AggressivenessLevelConfig(c.Balancing.Aggressiveness)
+}
+
+// AggressivenessLevelConfig returns the settings of an aggressiveness level, medium if unknown.
+func AggressivenessLevelConfig(level string) AggressivenessConfig {
+	switch level {
 	case "low":
 		return AggressivenessConfig{
 			CooldownPeriod:  4 * time.Hour, // 4h cooldown - very conservative
@@ -264,24 +1066,39 @@ type AggressivenessConfig struct {
 }
 
 // AutoDetectClusterName detects the cluster name from Proxmox API.
+// When detection fails and a fallback name is configured, it is used instead of failing.
 func (c *Config) AutoDetectClusterName(client interface{}) error {
 	if c.Cluster.Name != "" {
 		return nil // Already specified
 	}
 
-	// Try to get cluster info from Proxmox API
-	if proxmoxClient, ok := client.(interface {
-		GetClusterInfo() (*models.Cluster, error)
-	}); ok {
-		cluster, err := proxmoxClient.GetClusterInfo()
-		if err != nil {
-			return fmt.Errorf("failed to auto-detect cluster name: %w", err)
+	name, err := detectClusterName(client)
+	if err != nil {
+		if c.Cluster.FallbackName == "" {
+			return err
 		}
-		c.Cluster.Name = cluster.Name
-		return nil
+		fmt.Fprintf(os.Stderr, "Warning: %v, using fallback cluster name %q\n", err, c.Cluster.FallbackName)
+		name = c.Cluster.FallbackName
+	}
+
+	c.Cluster.Name = name
+	return nil
+}
+
+// detectClusterName asks the Proxmox API for the cluster name.
+func detectClusterName(client interface{}) (string, error) {
+	proxmoxClient, ok := client.(interface {
+		GetClusterInfo() (*models.Cluster, error)
+	})
+	if !ok {
+		return "", fmt.Errorf("cannot auto-detect cluster name: client does not support GetClusterInfo")
 	}
 
-	return fmt.Errorf("cannot auto-detect cluster name: client does not support GetClusterInfo")
+	cluster, err := proxmoxClient.GetClusterInfo()
+	if err != nil {
+		return "", fmt.Errorf("failed to auto-detect cluster name: %w", err)
+	}
+	return cluster.Name, nil
 }
 
 // validateProxmoxConfig validates the Proxmox configuration.
@@ -297,9 +1114,40 @@ func validateProxmoxConfig(proxmox *ProxmoxConfig) error {
 		}
 	}
 
+	for _, host := range proxmox.InsecureHosts {
+		if host == "" || strings.TrimSpace(host) != host || (strings.Contains(host, "/") && !isCIDR(host)) {
+			return fmt.Errorf("invalid proxmox insecure host %q: expected a hostname, IP or CIDR", host)
+		}
+	}
+
+	if proxmox.Retry.MaxAttempts < 0 {
+		return fmt.Errorf("proxmox retry max_attempts must be non-negative")
+	}
+	if delay, err := proxmox.Retry.GetBaseDelay(); err != nil || delay < 0 {
+		return fmt.Errorf("proxmox retry base_delay must be a non-negative duration")
+	}
+	if delay, err := proxmox.Retry.GetMaxDelay(); err != nil || delay < 0 {
+		return fmt.Errorf("proxmox retry max_delay must be a non-negative duration")
+	}
+	if proxmox.Concurrency < 0 {
+		return fmt.Errorf("proxmox concurrency must be non-negative")
+	}
+	if proxmox.MigrationType != "" && proxmox.MigrationType != "secure" && proxmox.MigrationType != "insecure" {
+		return fmt.Errorf("proxmox migration_type must be 'secure', 'insecure' or empty")
+	}
+	if proxmox.MigrationNetwork != "" && !isCIDR(proxmox.MigrationNetwork) {
+		return fmt.Errorf("invalid proxmox migration_network %q: expected a CIDR", proxmox.MigrationNetwork)
+	}
+
 	return nil
 }
 
+// isCIDR reports whether s is an IP network in CIDR notation.
+func isCIDR(s string) bool {
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
 // validateBalancingConfig validates the balancing configuration.
 func validateBalancingConfig(balancing *BalancingConfig) error {
 	if err := validateBalancerType(balancing.BalancerType); err != nil {
@@ -310,14 +1158,169 @@ func validateBalancingConfig(balancing *BalancingConfig) error {
 		return err
 	}
 
+	if err := ValidateForceMode(balancing.ForceMode); err != nil {
+		return err
+	}
+
 	if err := validateThresholds(&balancing.Thresholds); err != nil {
 		return err
 	}
 
+	if err := validateHysteresis(&balancing.Hysteresis, &balancing.Thresholds); err != nil {
+		return err
+	}
+
 	if err := validateWeights(&balancing.Weights); err != nil {
 		return err
 	}
 
+	if err := validateThresholdConsistency(&balancing.Thresholds, &balancing.Weights); err != nil {
+		return err
+	}
+
+	if err := validateScoreWeights(&balancing.ScoreWeights); err != nil {
+		return err
+	}
+
+	if balancing.StartAfter != "" {
+		if _, err := time.Parse(time.RFC3339, balancing.StartAfter); err != nil {
+			return fmt.Errorf("invalid start_after timestamp (expected RFC 3339): %w", err)
+		}
+	}
+
+	if balancing.Cooldown != "" {
+		if cooldown, err := time.ParseDuration(balancing.Cooldown); err != nil || cooldown < 0 {
+			return fmt.Errorf("cooldown must be a non-negative duration")
+		}
+	}
+
+	if balancing.MigrationDelay != "" {
+		if delay, err := time.ParseDuration(balancing.MigrationDelay); err != nil || delay < 0 {
+			return fmt.Errorf("migration delay must be a non-negative duration")
+		}
+	}
+
+	if balancing.BackupWindow != "" {
+		if window, err := time.ParseDuration(balancing.BackupWindow); err != nil || window < 0 {
+			return fmt.Errorf("backup window must be a non-negative duration")
+		}
+	}
+
+	if balancing.CycleBudget != "" {
+		if _, err := time.ParseDuration(balancing.CycleBudget); err != nil {
+			return fmt.Errorf("invalid cycle budget duration: %w", err)
+		}
+	}
+
+	if balancing.MinTargetUptime != "" {
+		if _, err := time.ParseDuration(balancing.MinTargetUptime); err != nil {
+			return fmt.Errorf("invalid min target uptime duration: %w", err)
+		}
+	}
+
+	if balancing.HealthCheck.Timeout != "" {
+		if timeout, err := time.ParseDuration(balancing.HealthCheck.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid health check timeout %q: must be a positive duration", balancing.HealthCheck.Timeout)
+		}
+	}
+
+	if balancing.BenefitHorizon != "" {
+		if _, err := time.ParseDuration(balancing.BenefitHorizon); err != nil {
+			return fmt.Errorf("invalid benefit horizon duration: %w", err)
+		}
+	}
+
+	if balancing.ObservationWindow != "" {
+		if _, err := time.ParseDuration(balancing.ObservationWindow); err != nil {
+			return fmt.Errorf("invalid observation window duration: %w", err)
+		}
+	}
+
+	if balancing.ProtectedMinGain < 0 {
+		return fmt.Errorf("protected VM minimum gain cannot be negative")
+	}
+
+	policies := make(map[string]string)
+	for policy, pools := range balancing.PoolPolicies {
+		if policy != PoolPolicyMovable && policy != PoolPolicyProtected {
+			return fmt.Errorf("unknown pool policy %q: must be '%s' or '%s'", policy, PoolPolicyMovable, PoolPolicyProtected)
+		}
+		for _, pool := range pools {
+			if other, exists := policies[pool]; exists && other != policy {
+				return fmt.Errorf("pool %s has policies %s and %s", pool, other, policy)
+			}
+			policies[pool] = policy
+		}
+	}
+
+	if balancing.Tolerance < 0 || balancing.Tolerance > 100 {
+		return fmt.Errorf("balancing tolerance must be between 0 and 100 percentage points")
+	}
+
+	if balancing.ImbalanceSLA < 0 || balancing.ImbalanceSLA > 100 {
+		return fmt.Errorf("balancing imbalance_sla must be between 0 and 100 percentage points")
+	}
+
+	if balancing.MaxNodeDrop < 0 || balancing.MaxNodeDrop >= 1 {
+		return fmt.Errorf("max_node_drop must be a fraction between 0 and 1 (got %.2f)", balancing.MaxNodeDrop)
+	}
+
+	if balancing.NewVMWindow != "" {
+		if window, err := time.ParseDuration(balancing.NewVMWindow); err != nil || window <= 0 {
+			return fmt.Errorf("new VM window must be a positive duration")
+		}
+	}
+
+	if zf := balancing.ZeroFootprint; zf != "" && zf != ZeroFootprintIgnore && zf != ZeroFootprintRules {
+		return fmt.Errorf("zero_footprint must be '%s' or '%s'", ZeroFootprintIgnore, ZeroFootprintRules)
+	}
+
+	if p := balancing.Capacity.Percentiles; p != "" && p != PercentilesNearest && p != PercentilesInterpolate {
+		return fmt.Errorf("capacity percentiles must be '%s' or '%s'", PercentilesNearest, PercentilesInterpolate)
+	}
+
+	if pt := balancing.Passthrough; pt != "" && pt != PassthroughRestrict && pt != PassthroughSkip {
+		return fmt.Errorf("passthrough must be '%s' or '%s'", PassthroughRestrict, PassthroughSkip)
+	}
+
+	if sv := balancing.SuspendedVMs; sv != "" && sv != SuspendedVMsSkip && sv != SuspendedVMsOffline {
+		return fmt.Errorf("suspended_vms must be '%s' or '%s'", SuspendedVMsSkip, SuspendedVMsOffline)
+	}
+
+	if balancing.Concurrency.PerSource < 0 || balancing.Concurrency.PerTarget < 0 {
+		return fmt.Errorf("migration concurrency limits cannot be negative")
+	}
+
+	if balancing.Overcommit.CPU < 0 || balancing.Overcommit.Memory < 0 {
+		return fmt.Errorf("overcommit ratios cannot be negative")
+	}
+
+	if balancing.RuleCorrections.MinGain < 0 || balancing.RuleCorrections.MinGain > 100 {
+		return fmt.Errorf("rule correction minimum gain must be between 0 and 100")
+	}
+
+	if balancing.MigrationBandwidth < 0 {
+		return fmt.Errorf("migration bandwidth cannot be negative")
+	}
+
+	if balancing.MemoryHeadroom < 0 {
+		return fmt.Errorf("memory headroom cannot be negative")
+	}
+
+	if threshold := balancing.PanicThreshold; threshold != 0 &&
+		(threshold > 100 || threshold <= balancing.Thresholds.CPU || threshold <= balancing.Thresholds.Memory) {
+		return fmt.Errorf("panic threshold must be above the CPU and memory thresholds and at most 100")
+	}
+
+	if balancing.BreakIn.Cycles < 0 {
+		return fmt.Errorf("break_in cycles cannot be negative")
+	}
+	if balancing.BreakIn.Duration != "" {
+		if duration, err := time.ParseDuration(balancing.BreakIn.Duration); err != nil || duration <= 0 {
+			return fmt.Errorf("break_in duration must be a positive duration")
+		}
+	}
+
 	if err := validateLoadProfiles(&balancing.LoadProfiles); err != nil {
 		return err
 	}
@@ -326,13 +1329,25 @@ func validateBalancingConfig(balancing *BalancingConfig) error {
 		return err
 	}
 
+	if err := validateOverCapacityConfig(&balancing.OverCapacity); err != nil {
+		return err
+	}
+
+	if err := validatePowerConfig(&balancing.Power); err != nil {
+		return err
+	}
+
+	if balancing.Sessions.Enabled && (balancing.Sessions.HighConnections <= 0 || balancing.Sessions.HighProcesses <= 0) {
+		return fmt.Errorf("session high_connections and high_processes must be positive")
+	}
+
 	return nil
 }
 
 // validateBalancerType validates the balancer type.
 func validateBalancerType(balancerType string) error {
-	if balancerType != "threshold" && balancerType != "advanced" {
-		return fmt.Errorf("balancer_type must be 'threshold' or 'advanced'")
+	if balancerType != "threshold" && balancerType != "advanced" && balancerType != "consolidate" {
+		return fmt.Errorf("balancer_type must be 'threshold', 'advanced' or 'consolidate'")
 	}
 	return nil
 }
@@ -341,8 +1356,17 @@ func validateBalancerType(balancerType string) error {
 func validateAggressiveness(aggressiveness string) error {
 	if aggressiveness != "low" &&
 		aggressiveness != "medium" &&
-		aggressiveness != "high" {
-		return fmt.Errorf("aggressiveness must be 'low', 'medium', or 'high'")
+		aggressiveness != "high" &&
+		aggressiveness != "adaptive" {
+		return fmt.Errorf("aggressiveness must be 'low', 'medium', 'high' or 'adaptive'")
+	}
+	return nil
+}
+
+// ValidateForceMode validates the force mode; empty means the default.
+func ValidateForceMode(forceMode string) error {
+	if forceMode != "" && forceMode != ForceModeAlways && forceMode != ForceModeReevaluate {
+		return fmt.Errorf("force_mode must be '%s' or '%s'", ForceModeAlways, ForceModeReevaluate)
 	}
 	return nil
 }
@@ -361,6 +1385,25 @@ func validateThresholds(thresholds *ResourceThresholds) error {
 	return nil
 }
 
+// validateHysteresis validates the re-arm margins, which can't reach below zero usage.
+func validateHysteresis(hysteresis *ResourceHysteresis, thresholds *ResourceThresholds) error {
+	margins := []struct {
+		name      string
+		margin    float64
+		threshold int
+	}{
+		{"CPU", hysteresis.CPU, thresholds.CPU},
+		{"memory", hysteresis.Memory, thresholds.Memory},
+		{"storage", hysteresis.Storage, thresholds.Storage},
+	}
+	for _, m := range margins {
+		if m.margin < 0 || m.margin > float64(m.threshold) {
+			return fmt.Errorf("%s hysteresis must be between 0 and the %s threshold (%d)", m.name, m.name, m.threshold)
+		}
+	}
+	return nil
+}
+
 // validateWeights validates the weight values.
 func validateWeights(weights *ResourceWeights) error {
 	if weights.CPU < 0 {
@@ -375,6 +1418,71 @@ func validateWeights(weights *ResourceWeights) error {
 	return nil
 }
 
+// validateThresholdConsistency checks that thresholds and weights agree with each other.
+// A resource that can trigger balancing must also count when choosing a target,
+// otherwise migrations started for it could never relieve it.
+func validateThresholdConsistency(thresholds *ResourceThresholds, weights *ResourceWeights) error {
+	if weights.CPU+weights.Memory+weights.Storage <= 0 {
+		return fmt.Errorf("at least one resource weight must be positive")
+	}
+
+	resources := []struct {
+		name      string
+		threshold int
+		weight    float64
+	}{
+		{"CPU", thresholds.CPU, weights.CPU},
+		{"memory", thresholds.Memory, weights.Memory},
+		{"storage", thresholds.Storage, weights.Storage},
+	}
+	for _, resource := range resources {
+		if resource.threshold < 100 && resource.weight == 0 {
+			return fmt.Errorf("%s threshold of %d%% can trigger balancing but %s weight is 0: "+
+				"give it a weight or set the threshold to 100 to disable it", resource.name, resource.threshold, resource.name)
+		}
+	}
+
+	return nil
+}
+
+// validateScoreWeights validates the advanced scoring blend.
+func validateScoreWeights(weights *ScoreWeights) error {
+	// Unset weights fall back to defaults
+	if *weights == (ScoreWeights{}) {
+		return nil
+	}
+
+	components := []struct {
+		name  string
+		value float64
+	}{
+		{"resource", weights.Resource},
+		{"stability", weights.Stability},
+		{"capacity", weights.Capacity},
+		{"migration_cost", weights.MigrationCost},
+		{"current", weights.Current},
+		{"predictive", weights.Predictive},
+	}
+	for _, component := range components {
+		if component.value < 0 || component.value > 1 {
+			return fmt.Errorf("score weight %s must be between 0 and 1", component.name)
+		}
+	}
+
+	const tolerance = 0.001
+	componentSum := weights.Resource + weights.Stability + weights.Capacity + weights.MigrationCost
+	if math.Abs(componentSum-1.0) > tolerance {
+		return fmt.Errorf("score weights resource, stability, capacity and migration_cost must sum to 1.0 (got %.3f)", componentSum)
+	}
+
+	blendSum := weights.Current + weights.Predictive
+	if math.Abs(blendSum-1.0) > tolerance {
+		return fmt.Errorf("score weights current and predictive must sum to 1.0 (got %.3f)", blendSum)
+	}
+
+	return nil
+}
+
 // validateLoadProfiles validates the load profiles configuration.
 func validateLoadProfiles(loadProfiles *LoadProfilesConfig) error {
 	if loadProfiles.Enabled {
@@ -382,6 +1490,34 @@ func validateLoadProfiles(loadProfiles *LoadProfilesConfig) error {
 			return fmt.Errorf("invalid load profiles window duration: %w", err)
 		}
 	}
+	return validateBusinessHours(&loadProfiles.BusinessHours)
+}
+
+// validateBusinessHours validates the business hours used by time-of-day load profiles.
+func validateBusinessHours(hours *BusinessHoursConfig) error {
+	if !hours.Enabled() {
+		return nil
+	}
+
+	if _, err := parseClock(hours.Start); err != nil {
+		return fmt.Errorf("invalid business hours start: %w", err)
+	}
+	if _, err := parseClock(hours.End); err != nil {
+		return fmt.Errorf("invalid business hours end: %w", err)
+	}
+	if hours.Start == hours.End {
+		return fmt.Errorf("business hours start and end must differ")
+	}
+
+	for _, day := range hours.Days {
+		if _, exists := weekdays[strings.ToLower(day)]; !exists {
+			return fmt.Errorf("invalid business day %q (expected mon to sun)", day)
+		}
+	}
+
+	if _, err := hours.GetLookahead(); err != nil {
+		return fmt.Errorf("invalid business hours lookahead duration: %w", err)
+	}
 	return nil
 }
 
@@ -394,3 +1530,53 @@ func validateCapacityConfig(capacity *CapacityConfig) error {
 	}
 	return nil
 }
+
+// validateOverCapacityConfig validates the handling of a cluster over capacity.
+func validateOverCapacityConfig(overCapacity *OverCapacityConfig) error {
+	switch overCapacity.Action {
+	case "", OverCapacityAlert:
+	case OverCapacityScaleOut:
+		if overCapacity.Command == "" {
+			return fmt.Errorf("over_capacity action '%s' requires a command", OverCapacityScaleOut)
+		}
+	default:
+		return fmt.Errorf("over_capacity action must be '%s' or '%s'", OverCapacityAlert, OverCapacityScaleOut)
+	}
+
+	if overCapacity.Timeout != "" {
+		if timeout, err := time.ParseDuration(overCapacity.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid over_capacity timeout %q: must be a positive duration", overCapacity.Timeout)
+		}
+	}
+	return nil
+}
+
+// validatePowerConfig validates the power telemetry configuration.
+func validatePowerConfig(power *PowerConfig) error {
+	if !power.Enabled {
+		return nil
+	}
+
+	switch power.Source {
+	case "file":
+		if power.Path == "" {
+			return fmt.Errorf("power telemetry file source requires a path")
+		}
+	case "command":
+		if power.Command == "" {
+			return fmt.Errorf("power telemetry command source requires a command")
+		}
+	default:
+		return fmt.Errorf("power telemetry source must be 'file' or 'command'")
+	}
+
+	if power.Mode != "spread" && power.Mode != "consolidate" {
+		return fmt.Errorf("power telemetry mode must be 'spread' or 'consolidate'")
+	}
+
+	if power.Weight < 0 {
+		return fmt.Errorf("power telemetry weight cannot be negative")
+	}
+
+	return nil
+}
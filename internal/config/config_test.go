@@ -1,9 +1,14 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -34,6 +39,8 @@ balancing:
 logging:
   level: "debug"
   format: "text"
+
+read_only: true
 `
 
 	tmpfile, err := os.CreateTemp("", "config-*.yaml")
@@ -92,6 +99,11 @@ logging:
 	if config.Logging.Format != "text" {
 		t.Errorf("Expected log format 'text', got '%s'", config.Logging.Format)
 	}
+
+	// Test observer mode
+	if !config.ReadOnly {
+		t.Error("Expected read_only to be true")
+	}
 }
 
 func TestLoadConfigWithDefaults(t *testing.T) {
@@ -139,6 +151,21 @@ cluster:
 	if config.Logging.Level != "info" {
 		t.Errorf("Expected default log level 'info', got '%s'", config.Logging.Level)
 	}
+	if config.ReadOnly {
+		t.Error("Expected read_only to be false by default")
+	}
+	if config.Balancing.ProtectedMinGain != 25.0 {
+		t.Errorf("Expected default protected min gain 25, got %v", config.Balancing.ProtectedMinGain)
+	}
+	if config.Balancing.ForceMode != ForceModeAlways {
+		t.Errorf("Expected default force mode '%s', got '%s'", ForceModeAlways, config.Balancing.ForceMode)
+	}
+	if config.Balancing.ZeroFootprint != ZeroFootprintIgnore {
+		t.Errorf("Expected default zero footprint handling '%s', got '%s'", ZeroFootprintIgnore, config.Balancing.ZeroFootprint)
+	}
+	if config.Balancing.SuspendedVMs != SuspendedVMsSkip {
+		t.Errorf("Expected default suspended VM handling '%s', got '%s'", SuspendedVMsSkip, config.Balancing.SuspendedVMs)
+	}
 }
 
 func TestValidateConfig(t *testing.T) {
@@ -306,6 +333,88 @@ func TestValidateProxmoxConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "trusted insecure hosts",
+			config: &ProxmoxConfig{
+				Host:          "https://10.0.0.5:8006",
+				Token:         "test@pve!test=secret",
+				InsecureHosts: []string{"10.0.0.0/24", "pve.lan", "10.0.1.7"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid insecure host CIDR",
+			config: &ProxmoxConfig{
+				Host:          "https://10.0.0.5:8006",
+				Token:         "test@pve!test=secret",
+				InsecureHosts: []string{"10.0.0.0/33"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "retry policy",
+			config: &ProxmoxConfig{
+				Host:  "https://10.0.0.5:8006",
+				Token: "test@pve!test=secret",
+				Retry: RetryConfig{MaxAttempts: 3, BaseDelay: "500ms", MaxDelay: "5s"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative retry attempts",
+			config: &ProxmoxConfig{
+				Host:  "https://10.0.0.5:8006",
+				Token: "test@pve!test=secret",
+				Retry: RetryConfig{MaxAttempts: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid retry delay",
+			config: &ProxmoxConfig{
+				Host:  "https://10.0.0.5:8006",
+				Token: "test@pve!test=secret",
+				Retry: RetryConfig{MaxAttempts: 3, BaseDelay: "soon"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative concurrency",
+			config: &ProxmoxConfig{
+				Host:        "https://10.0.0.5:8006",
+				Token:       "test@pve!test=secret",
+				Concurrency: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "secure migration over a dedicated network",
+			config: &ProxmoxConfig{
+				Host:             "https://10.0.0.5:8006",
+				Token:            "test@pve!test=secret",
+				MigrationType:    "secure",
+				MigrationNetwork: "10.10.0.0/24",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid migration type",
+			config: &ProxmoxConfig{
+				Host:          "https://10.0.0.5:8006",
+				Token:         "test@pve!test=secret",
+				MigrationType: "encrypted",
+			},
+			wantErr: true,
+		},
+		{
+			name: "migration network without prefix length",
+			config: &ProxmoxConfig{
+				Host:             "https://10.0.0.5:8006",
+				Token:            "test@pve!test=secret",
+				MigrationNetwork: "10.10.0.1",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -349,6 +458,42 @@ func TestValidateBalancingConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "consolidate balancer type",
+			config: &BalancingConfig{
+				BalancerType:   "consolidate",
+				Aggressiveness: "low",
+				Thresholds: ResourceThresholds{
+					CPU:     80,
+					Memory:  85,
+					Storage: 90,
+				},
+				Weights: ResourceWeights{
+					CPU:     1.0,
+					Memory:  1.0,
+					Storage: 0.5,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "adaptive aggressiveness",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "adaptive",
+				Thresholds: ResourceThresholds{
+					CPU:     80,
+					Memory:  85,
+					Storage: 90,
+				},
+				Weights: ResourceWeights{
+					CPU:     1.0,
+					Memory:  1.0,
+					Storage: 0.5,
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid aggressiveness",
 			config: &BalancingConfig{
@@ -357,177 +502,563 @@ func TestValidateBalancingConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := validateBalancingConfig(tt.config)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("validateBalancingConfig() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestValidateBalancerType(t *testing.T) {
-	tests := []struct {
-		balancerType string
-		wantErr      bool
-	}{
-		{"threshold", false},
-		{"advanced", false},
-		{"invalid", true},
-		{"", true},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.balancerType, func(t *testing.T) {
-			err := validateBalancerType(tt.balancerType)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("validateBalancerType() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestValidateAggressiveness(t *testing.T) {
-	tests := []struct {
-		aggressiveness string
-		wantErr        bool
-	}{
-		{"low", false},
-		{"medium", false},
-		{"high", false},
-		{"invalid", true},
-		{"", true},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.aggressiveness, func(t *testing.T) {
-			err := validateAggressiveness(tt.aggressiveness)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("validateAggressiveness() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestValidateThresholds(t *testing.T) {
-	tests := []struct {
-		name       string
-		thresholds *ResourceThresholds
-		wantErr    bool
-	}{
 		{
-			name: "valid thresholds",
-			thresholds: &ResourceThresholds{
-				CPU:     80,
-				Memory:  85,
-				Storage: 90,
+			name: "invalid min target uptime",
+			config: &BalancingConfig{
+				BalancerType:    "advanced",
+				Aggressiveness:  "low",
+				Thresholds:      ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				MinTargetUptime: "ten minutes",
 			},
-			wantErr: false,
+			wantErr: true,
 		},
 		{
-			name: "CPU threshold too low",
-			thresholds: &ResourceThresholds{
-				CPU:     0,
-				Memory:  85,
-				Storage: 90,
+			name: "invalid health check timeout",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				HealthCheck:    HealthCheckConfig{Command: "true", Timeout: "0s"},
 			},
 			wantErr: true,
 		},
 		{
-			name: "CPU threshold too high",
-			thresholds: &ResourceThresholds{
-				CPU:     150,
-				Memory:  85,
-				Storage: 90,
+			name: "negative tolerance",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Tolerance:      -5,
 			},
 			wantErr: true,
 		},
 		{
-			name: "Memory threshold invalid",
-			thresholds: &ResourceThresholds{
-				CPU:     80,
-				Memory:  -10,
-				Storage: 90,
+			name: "imbalance SLA above 100 points",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				ImbalanceSLA:   120,
 			},
 			wantErr: true,
 		},
 		{
-			name: "Storage threshold invalid",
-			thresholds: &ResourceThresholds{
-				CPU:     80,
-				Memory:  85,
-				Storage: 200,
+			name: "invalid new VM window",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				NewVMWindow:    "-1h",
 			},
 			wantErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := validateThresholds(tt.thresholds)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("validateThresholds() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestValidateWeights(t *testing.T) {
-	tests := []struct {
-		name    string
-		weights *ResourceWeights
-		wantErr bool
-	}{
 		{
-			name: "valid weights",
-			weights: &ResourceWeights{
-				CPU:     1.0,
-				Memory:  1.0,
-				Storage: 0.5,
+			name: "max node drop of every node",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				MaxNodeDrop:    1,
 			},
-			wantErr: false,
+			wantErr: true,
 		},
 		{
-			name: "zero weights (valid)",
-			weights: &ResourceWeights{
-				CPU:     0.0,
-				Memory:  0.0,
-				Storage: 0.0,
+			name: "valid max node drop",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				MaxNodeDrop:    0.3,
 			},
 			wantErr: false,
 		},
 		{
-			name: "negative CPU weight",
-			weights: &ResourceWeights{
-				CPU:     -1.0,
-				Memory:  1.0,
-				Storage: 0.5,
+			name: "negative hysteresis",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				Hysteresis:     ResourceHysteresis{Storage: -1},
 			},
 			wantErr: true,
 		},
 		{
-			name: "negative memory weight",
-			weights: &ResourceWeights{
-				CPU:     1.0,
-				Memory:  -0.5,
-				Storage: 0.5,
+			name: "hysteresis past the threshold",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				Hysteresis:     ResourceHysteresis{CPU: 81},
 			},
 			wantErr: true,
 		},
 		{
-			name: "negative storage weight",
-			weights: &ResourceWeights{
-				CPU:     1.0,
-				Memory:  1.0,
-				Storage: -2.0,
+			name: "per-resource hysteresis",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				Hysteresis:     ResourceHysteresis{CPU: 10, Memory: 5, Storage: 2},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown over capacity action",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				OverCapacity:   OverCapacityConfig{Action: "panic"},
 			},
 			wantErr: true,
 		},
-	}
-
+		{
+			name: "scale out without command",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				OverCapacity:   OverCapacityConfig{Action: OverCapacityScaleOut},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scale out with command",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				OverCapacity:   OverCapacityConfig{Action: OverCapacityScaleOut, Command: "request-node.sh", Timeout: "1m"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid new VM window",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				NewVMWindow:    "24h",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid benefit horizon",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				BenefitHorizon: "soon",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative overcommit ratio",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				Overcommit:     OvercommitConfig{CPU: 3, Memory: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rule correction minimum gain above 100",
+			config: &BalancingConfig{
+				BalancerType:    "advanced",
+				Aggressiveness:  "low",
+				Thresholds:      ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:         ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				RuleCorrections: RuleCorrectionsConfig{Enabled: true, MinGain: 150},
+			},
+			wantErr: true,
+		},
+		{
+			name: "panic threshold above thresholds",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				PanicThreshold: 95,
+			},
+			wantErr: false,
+		},
+		{
+			name: "panic threshold below memory threshold",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				PanicThreshold: 82,
+			},
+			wantErr: true,
+		},
+		{
+			name: "session metrics without busy levels",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				Sessions:       SessionsConfig{Enabled: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative migration bandwidth",
+			config: &BalancingConfig{
+				BalancerType:       "advanced",
+				Aggressiveness:     "low",
+				Thresholds:         ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:            ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				MigrationBandwidth: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative memory headroom",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				MemoryHeadroom: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid observation window",
+			config: &BalancingConfig{
+				BalancerType:      "advanced",
+				Aggressiveness:    "low",
+				Thresholds:        ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:           ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				ObservationWindow: "a while",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cycle budget",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				CycleBudget:    "forever",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid cooldown",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				Cooldown:       "90m",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid cooldown",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Cooldown:       "soon",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid migration delay",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				MigrationDelay: "-30s",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid capacity percentiles",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Capacity:       CapacityConfig{Percentiles: "average"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid backup window",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				BackupWindow:   "-1h",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid force mode",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				ForceMode:      "sometimes",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative protected min gain",
+			config: &BalancingConfig{
+				BalancerType:     "advanced",
+				Aggressiveness:   "low",
+				Thresholds:       ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:          ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				ProtectedMinGain: -5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid zero footprint handling",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				ZeroFootprint:  "always",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid break-in duration",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				BreakIn:        BreakInConfig{Cycles: 10, Duration: "a while"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid suspended VM handling",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				SuspendedVMs:   "resume",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative migration concurrency",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+				Concurrency:    MigrationConcurrencyConfig{PerSource: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid start after",
+			config: &BalancingConfig{
+				BalancerType:   "advanced",
+				Aggressiveness: "low",
+				Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+				StartAfter:     "next tuesday",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBalancingConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBalancingConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBalancerType(t *testing.T) {
+	tests := []struct {
+		balancerType string
+		wantErr      bool
+	}{
+		{"threshold", false},
+		{"advanced", false},
+		{"invalid", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.balancerType, func(t *testing.T) {
+			err := validateBalancerType(tt.balancerType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBalancerType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAggressiveness(t *testing.T) {
+	tests := []struct {
+		aggressiveness string
+		wantErr        bool
+	}{
+		{"low", false},
+		{"medium", false},
+		{"high", false},
+		{"invalid", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.aggressiveness, func(t *testing.T) {
+			err := validateAggressiveness(tt.aggressiveness)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateAggressiveness() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateThresholds(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds *ResourceThresholds
+		wantErr    bool
+	}{
+		{
+			name: "valid thresholds",
+			thresholds: &ResourceThresholds{
+				CPU:     80,
+				Memory:  85,
+				Storage: 90,
+			},
+			wantErr: false,
+		},
+		{
+			name: "CPU threshold too low",
+			thresholds: &ResourceThresholds{
+				CPU:     0,
+				Memory:  85,
+				Storage: 90,
+			},
+			wantErr: true,
+		},
+		{
+			name: "CPU threshold too high",
+			thresholds: &ResourceThresholds{
+				CPU:     150,
+				Memory:  85,
+				Storage: 90,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Memory threshold invalid",
+			thresholds: &ResourceThresholds{
+				CPU:     80,
+				Memory:  -10,
+				Storage: 90,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Storage threshold invalid",
+			thresholds: &ResourceThresholds{
+				CPU:     80,
+				Memory:  85,
+				Storage: 200,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateThresholds(tt.thresholds)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateThresholds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights *ResourceWeights
+		wantErr bool
+	}{
+		{
+			name: "valid weights",
+			weights: &ResourceWeights{
+				CPU:     1.0,
+				Memory:  1.0,
+				Storage: 0.5,
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero weights (valid)",
+			weights: &ResourceWeights{
+				CPU:     0.0,
+				Memory:  0.0,
+				Storage: 0.0,
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative CPU weight",
+			weights: &ResourceWeights{
+				CPU:     -1.0,
+				Memory:  1.0,
+				Storage: 0.5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative memory weight",
+			weights: &ResourceWeights{
+				CPU:     1.0,
+				Memory:  -0.5,
+				Storage: 0.5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative storage weight",
+			weights: &ResourceWeights{
+				CPU:     1.0,
+				Memory:  1.0,
+				Storage: -2.0,
+			},
+			wantErr: true,
+		},
+	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := validateWeights(tt.weights)
@@ -537,3 +1068,447 @@ func TestValidateWeights(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateThresholdConsistency(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds ResourceThresholds
+		weights    ResourceWeights
+		wantErr    bool
+	}{
+		{"consistent", ResourceThresholds{CPU: 80, Memory: 85, Storage: 90}, ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5}, false},
+		{"all weights zero", ResourceThresholds{CPU: 80, Memory: 85, Storage: 90}, ResourceWeights{}, true},
+		{"memory threshold without weight", ResourceThresholds{CPU: 80, Memory: 85, Storage: 90}, ResourceWeights{CPU: 1.0, Storage: 0.5}, true},
+		{"storage threshold without weight", ResourceThresholds{CPU: 80, Memory: 85, Storage: 90}, ResourceWeights{CPU: 1.0, Memory: 1.0}, true},
+		{"disabled storage threshold without weight", ResourceThresholds{CPU: 80, Memory: 85, Storage: 100}, ResourceWeights{CPU: 1.0, Memory: 1.0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateThresholdConsistency(&tt.thresholds, &tt.weights)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateThresholdConsistency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBalancingConfigRejectsInconsistentThresholds(t *testing.T) {
+	balancing := &BalancingConfig{
+		BalancerType:   "threshold",
+		Aggressiveness: "medium",
+		Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+		Weights:        ResourceWeights{CPU: 1.0, Memory: 0, Storage: 0.5},
+	}
+
+	err := validateBalancingConfig(balancing)
+	if err == nil {
+		t.Fatal("Expected error for memory threshold without memory weight")
+	}
+	if !strings.Contains(err.Error(), "memory threshold of 85%") {
+		t.Errorf("Expected error to name the inconsistent resource, got %v", err)
+	}
+}
+
+func TestValidateScoreWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights ScoreWeights
+		wantErr bool
+	}{
+		{"unset uses defaults", ScoreWeights{}, false},
+		{"defaults", DefaultScoreWeights(), false},
+		{"custom blend", ScoreWeights{Resource: 0.5, Stability: 0.1, Capacity: 0.3, MigrationCost: 0.1, Current: 0.5, Predictive: 0.5}, false},
+		{"components don't sum to one", ScoreWeights{Resource: 0.5, Stability: 0.5, Capacity: 0.3, MigrationCost: 0.1, Current: 0.7, Predictive: 0.3}, true},
+		{"blend doesn't sum to one", ScoreWeights{Resource: 0.4, Stability: 0.2, Capacity: 0.3, MigrationCost: 0.1, Current: 0.7, Predictive: 0.7}, true},
+		{"negative weight", ScoreWeights{Resource: 1.2, Stability: -0.2, Current: 1.0}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateScoreWeights(&tt.weights)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateScoreWeights() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetScoreWeightsDefaults(t *testing.T) {
+	config := &Config{}
+	if config.GetScoreWeights() != DefaultScoreWeights() {
+		t.Errorf("Expected default score weights when unset, got %+v", config.GetScoreWeights())
+	}
+
+	custom := ScoreWeights{Resource: 1.0, Current: 1.0}
+	config.Balancing.ScoreWeights = custom
+	if config.GetScoreWeights() != custom {
+		t.Errorf("Expected configured score weights, got %+v", config.GetScoreWeights())
+	}
+}
+
+func TestValidatePowerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		power   PowerConfig
+		wantErr bool
+	}{
+		{"disabled", PowerConfig{}, false},
+		{"file", PowerConfig{Enabled: true, Source: "file", Path: "/run/power.json", Mode: "spread", Weight: 0.1}, false},
+		{"command", PowerConfig{Enabled: true, Source: "command", Command: "ipmi-power", Mode: "consolidate", Weight: 0.1}, false},
+		{"file without path", PowerConfig{Enabled: true, Source: "file", Mode: "spread"}, true},
+		{"command without command", PowerConfig{Enabled: true, Source: "command", Mode: "spread"}, true},
+		{"unknown source", PowerConfig{Enabled: true, Source: "snmp", Mode: "spread"}, true},
+		{"unknown mode", PowerConfig{Enabled: true, Source: "file", Path: "/run/power.json", Mode: "eco"}, true},
+		{"negative weight", PowerConfig{Enabled: true, Source: "file", Path: "/run/power.json", Mode: "spread", Weight: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePowerConfig(&tt.power)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePowerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStatusConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  StatusConfig
+		wantErr bool
+	}{
+		{"disabled", StatusConfig{}, false},
+		{"port only", StatusConfig{TCPAddress: ":7947"}, false},
+		{"host and port with token", StatusConfig{TCPAddress: "10.0.0.5:7947", Token: "secret"}, false},
+		{"missing port", StatusConfig{TCPAddress: "10.0.0.5"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStatusConfig(&tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateStatusConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMetricsConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics MetricsConfig
+		wantErr bool
+	}{
+		{"disabled", MetricsConfig{}, false},
+		{"port only", MetricsConfig{Enabled: true, Address: ":9808"}, false},
+		{"host and port", MetricsConfig{Enabled: true, Address: "127.0.0.1:9808"}, false},
+		{"missing port", MetricsConfig{Enabled: true, Address: "10.0.0.5"}, true},
+		{"disabled ignores the address", MetricsConfig{Address: "10.0.0.5"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMetricsConfig(&tt.metrics)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMetricsConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateElectionTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		raft    RaftConfig
+		wantErr bool
+	}{
+		{"defaults", RaftConfig{}, false},
+		{"custom", RaftConfig{ElectionTimeout: "10s", ElectionMaxTimeout: "2m"}, false},
+		{"no backoff", RaftConfig{ElectionTimeout: "1m", ElectionMaxTimeout: "1m"}, false},
+		{"invalid timeout", RaftConfig{ElectionTimeout: "soon"}, true},
+		{"zero timeout", RaftConfig{ElectionTimeout: "0s"}, true},
+		{"max below timeout", RaftConfig{ElectionTimeout: "1m", ElectionMaxTimeout: "30s"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateElectionTimeouts(&Config{Raft: tt.raft})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateElectionTimeouts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCSVConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     CSVConfig
+		wantErr bool
+	}{
+		{"defaults", CSVConfig{Delimiter: ",", DecimalSeparator: "."}, false},
+		{"semicolon with comma decimals", CSVConfig{Delimiter: ";", DecimalSeparator: ","}, false},
+		{"tab", CSVConfig{Delimiter: "\t", DecimalSeparator: "."}, false},
+		{"multi-character delimiter", CSVConfig{Delimiter: ";;"}, true},
+		{"quote delimiter", CSVConfig{Delimiter: "\""}, true},
+		{"unknown decimal separator", CSVConfig{DecimalSeparator: "'"}, true},
+		{"same delimiter and decimal", CSVConfig{Delimiter: ",", DecimalSeparator: ","}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCSVConfig(&tt.csv)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCSVConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClusterZones(t *testing.T) {
+	cluster := ClusterConfig{Zones: map[string][]string{
+		"rack-a": {"node1", "node2"},
+		"rack-b": {"node3"},
+	}}
+
+	if err := validateClusterConfig(&cluster); err != nil {
+		t.Fatalf("Expected valid zones, got %v", err)
+	}
+
+	nodeZones := cluster.NodeZones()
+	expected := map[string]string{"node1": "rack-a", "node2": "rack-a", "node3": "rack-b"}
+	if len(nodeZones) != len(expected) {
+		t.Fatalf("Expected %d zoned nodes, got %v", len(expected), nodeZones)
+	}
+	for node, zone := range expected {
+		if nodeZones[node] != zone {
+			t.Errorf("Expected %s in %s, got %s", node, zone, nodeZones[node])
+		}
+	}
+
+	cluster.Zones["rack-c"] = []string{"node2"}
+	if err := validateClusterConfig(&cluster); err == nil {
+		t.Error("Expected error for a node listed in two zones")
+	}
+}
+
+func TestClusterNodeRoles(t *testing.T) {
+	cluster := ClusterConfig{NodeRoles: map[string][]string{
+		NodeRoleSourceOnly: {"node1"},
+		NodeRoleTargetOnly: {"node2"},
+		NodeRoleNone:       {"node3"},
+	}}
+
+	if err := validateClusterConfig(&cluster); err != nil {
+		t.Fatalf("Expected valid node roles, got %v", err)
+	}
+
+	tests := []struct {
+		node       string
+		wantSource bool
+		wantTarget bool
+	}{
+		{"node1", true, false},
+		{"node2", false, true},
+		{"node3", false, false},
+		{"node4", true, true},
+	}
+	for _, tt := range tests {
+		if got := cluster.CanBeSource(tt.node); got != tt.wantSource {
+			t.Errorf("CanBeSource(%s) = %v, want %v", tt.node, got, tt.wantSource)
+		}
+		if got := cluster.CanBeTarget(tt.node); got != tt.wantTarget {
+			t.Errorf("CanBeTarget(%s) = %v, want %v", tt.node, got, tt.wantTarget)
+		}
+	}
+
+	cluster.NodeRoles[NodeRoleBoth] = []string{"node2"}
+	if err := validateClusterConfig(&cluster); err == nil {
+		t.Error("Expected error for a node with two roles")
+	}
+
+	cluster = ClusterConfig{NodeRoles: map[string][]string{"receive_only": {"node1"}}}
+	if err := validateClusterConfig(&cluster); err == nil {
+		t.Error("Expected error for an unknown role")
+	}
+}
+
+// clusterInfoStub answers cluster info requests with a fixed name or error.
+type clusterInfoStub struct {
+	name string
+	err  error
+}
+
+// GetClusterInfo returns the stubbed cluster.
+func (s clusterInfoStub) GetClusterInfo() (*models.Cluster, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.Cluster{Name: s.name}, nil
+}
+
+func TestAutoDetectClusterName(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   interface{}
+		fallback string
+		wantName string
+		wantErr  bool
+	}{
+		{"detected", clusterInfoStub{name: "prod"}, "", "prod", false},
+		{"detected ignores fallback", clusterInfoStub{name: "prod"}, "pve", "prod", false},
+		{"API unreachable", clusterInfoStub{err: errors.New("connection refused")}, "", "", true},
+		{"API unreachable with fallback", clusterInfoStub{err: errors.New("connection refused")}, "pve", "pve", false},
+		{"unsupported client with fallback", struct{}{}, "lab", "lab", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Cluster: ClusterConfig{FallbackName: tt.fallback}}
+			err := cfg.AutoDetectClusterName(tt.client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if cfg.Cluster.Name != tt.wantName {
+				t.Errorf("Expected cluster name %q, got %q", tt.wantName, cfg.Cluster.Name)
+			}
+		})
+	}
+}
+
+func TestBusinessHours(t *testing.T) {
+	monday := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	saturday := time.Date(2025, 6, 7, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		hours BusinessHoursConfig
+		at    time.Time
+		want  bool
+	}{
+		{"inside", BusinessHoursConfig{Start: "08:00", End: "18:00"}, monday.Add(9 * time.Hour), true},
+		{"at end", BusinessHoursConfig{Start: "08:00", End: "18:00"}, monday.Add(18 * time.Hour), false},
+		{"weekend excluded", BusinessHoursConfig{Start: "08:00", End: "18:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}}, saturday.Add(9 * time.Hour), false},
+		{"overnight late", BusinessHoursConfig{Start: "22:00", End: "06:00"}, monday.Add(23 * time.Hour), true},
+		{"overnight early", BusinessHoursConfig{Start: "22:00", End: "06:00"}, monday.Add(5 * time.Hour), true},
+		{"overnight day", BusinessHoursConfig{Start: "22:00", End: "06:00"}, monday.Add(12 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hours.Contains(tt.at); got != tt.want {
+				t.Errorf("Expected Contains(%v) = %v, got %v", tt.at, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateBusinessHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   BusinessHoursConfig
+		wantErr bool
+	}{
+		{"disabled", BusinessHoursConfig{}, false},
+		{"valid", BusinessHoursConfig{Start: "08:00", End: "18:00", Days: []string{"Mon", "fri"}, Lookahead: "1h"}, false},
+		{"bad start", BusinessHoursConfig{Start: "8am", End: "18:00"}, true},
+		{"missing end", BusinessHoursConfig{Start: "08:00"}, true},
+		{"empty period", BusinessHoursConfig{Start: "08:00", End: "08:00"}, true},
+		{"bad day", BusinessHoursConfig{Start: "08:00", End: "18:00", Days: []string{"funday"}}, true},
+		{"bad lookahead", BusinessHoursConfig{Start: "08:00", End: "18:00", Lookahead: "soon"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBusinessHours(&tt.hours)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	cfg := &Config{
+		Proxmox: ProxmoxConfig{Host: "https://pve:8006", Username: "root@pam", Password: "hunter2", Token: "root@pam!plb=secret-uuid"},
+		Cluster: ClusterConfig{Name: "lab", Zones: map[string][]string{"rack-b": {"node3"}, "rack-a": {"node1", "node2"}}},
+		Balancing: BalancingConfig{
+			Thresholds: ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+		},
+		Status: StatusConfig{TCPAddress: ":7947", Token: "status-secret"},
+	}
+
+	var out bytes.Buffer
+	cfg.Dump(&out)
+	dump := out.String()
+
+	for _, secret := range []string{"hunter2", "secret-uuid", "status-secret"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("Expected %q to be redacted, got:\n%s", secret, dump)
+		}
+	}
+
+	expected := []string{
+		`proxmox.host: "https://pve:8006"`,
+		`proxmox.username: "root@pam"`,
+		`proxmox.password: "<redacted>"`,
+		`proxmox.token: "<redacted>"`,
+		`status.token: "<redacted>"`,
+		`balancing.thresholds.cpu: 80`,
+		`cluster.zones.rack-a: [node1 node2]`,
+		`raft.enabled: false`,
+		`read_only: false`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(dump, line+"\n") {
+			t.Errorf("Expected dump to contain %q, got:\n%s", line, dump)
+		}
+	}
+	if strings.Index(dump, "cluster.zones.rack-a") > strings.Index(dump, "cluster.zones.rack-b") {
+		t.Errorf("Expected map keys in sorted order, got:\n%s", dump)
+	}
+
+	// Unset secrets stay visibly empty, and the configuration itself is untouched
+	if cfg.Proxmox.Password != "hunter2" || cfg.Status.Token != "status-secret" {
+		t.Error("Expected Dump to leave the configuration unchanged")
+	}
+	cfg.Proxmox.Token = ""
+	if redacted := cfg.Redacted(); redacted.Proxmox.Token != "" || redacted.Proxmox.Password != redactedValue {
+		t.Errorf("Expected only set secrets to be redacted, got %+v", redacted.Proxmox)
+	}
+}
+
+func TestPoolPolicies(t *testing.T) {
+	balancing := BalancingConfig{
+		BalancerType:   "advanced",
+		Aggressiveness: "low",
+		Thresholds:     ResourceThresholds{CPU: 80, Memory: 85, Storage: 90},
+		Weights:        ResourceWeights{CPU: 1.0, Memory: 1.0, Storage: 0.5},
+		PoolPolicies: map[string][]string{
+			PoolPolicyMovable:   {"scratch"},
+			PoolPolicyProtected: {"production"},
+		},
+	}
+
+	if err := validateBalancingConfig(&balancing); err != nil {
+		t.Fatalf("Expected valid pool policies, got %v", err)
+	}
+
+	for pool, want := range map[string]string{"scratch": PoolPolicyMovable, "production": PoolPolicyProtected, "other": "", "": ""} {
+		if got := balancing.PoolPolicy(pool); got != want {
+			t.Errorf("PoolPolicy(%q) = %q, want %q", pool, got, want)
+		}
+	}
+
+	balancing.PoolPolicies[PoolPolicyMovable] = []string{"scratch", "production"}
+	if err := validateBalancingConfig(&balancing); err == nil {
+		t.Error("Expected error for a pool with two policies")
+	}
+
+	balancing.PoolPolicies = map[string][]string{"frozen": {"scratch"}}
+	if err := validateBalancingConfig(&balancing); err == nil {
+		t.Error("Expected error for an unknown pool policy")
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces secrets in configuration dumps.
+const redactedValue = "<redacted>"
+
+// Redacted returns a copy of the configuration with passwords and tokens masked.
+func (c *Config) Redacted() Config {
+	redacted := *c
+	for _, secret := range []*string{&redacted.Proxmox.Password, &redacted.Proxmox.Token, &redacted.Status.Token} {
+		if *secret != "" {
+			*secret = redactedValue
+		}
+	}
+	return redacted
+}
+
+// Dump writes every effective setting, defaults and environment overrides included, as
+// "key: value" lines named like the configuration file keys. Secrets are redacted.
+func (c *Config) Dump(w io.Writer) {
+	redacted := c.Redacted()
+	dumpValue(w, "", reflect.ValueOf(redacted))
+}
+
+// dumpValue writes a struct field by field, recursing into nested structs, and any other value on one line.
+func dumpValue(w io.Writer, key string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Tag.Get("mapstructure")
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if key != "" {
+				name = key + "." + name
+			}
+			dumpValue(w, name, value.Field(i))
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		if len(keys) == 0 {
+			fmt.Fprintf(w, "%s: {}\n", key)
+		}
+		for _, mapKey := range keys {
+			dumpValue(w, fmt.Sprintf("%s.%v", key, mapKey.Interface()), value.MapIndex(mapKey))
+		}
+	case reflect.String:
+		fmt.Fprintf(w, "%s: %q\n", key, value.String())
+	default:
+		fmt.Fprintf(w, "%s: %v\n", key, value.Interface())
+	}
+}
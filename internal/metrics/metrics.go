@@ -0,0 +1,150 @@
+// Package metrics exposes balancing metrics for Prometheus to scrape.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is where the metrics are served.
+const Path = "/metrics"
+
+// shutdownTimeout bounds how long in-flight scrapes may finish on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// Recorder records balancing activity and cluster state as Prometheus metrics. A nil Recorder
+// records nothing, so callers don't need to check whether metrics are enabled.
+type Recorder struct {
+	registry          *prometheus.Registry
+	migrations        prometheus.Counter
+	migrationFailures prometheus.Counter
+	cycleDuration     prometheus.Histogram
+	lastBalanced      prometheus.Gauge
+	balanceScore      prometheus.Gauge
+	nodeUsage         *prometheus.GaugeVec
+}
+
+// NewRecorder creates a recorder whose metrics carry the cluster name as a label, telling clusters
+// apart when one Prometheus scrapes several.
+func NewRecorder(cluster string) *Recorder {
+	r := &Recorder{
+		registry: prometheus.NewRegistry(),
+		migrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "goproxlb_migrations_total",
+			Help: "VM migrations executed successfully.",
+		}),
+		migrationFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "goproxlb_migration_failures_total",
+			Help: "VM migrations that failed.",
+		}),
+		cycleDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "goproxlb_balancing_cycle_duration_seconds",
+			Help:    "Duration of balancing cycles, migrations included.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 4, 8), // 100ms to ~27min
+		}),
+		lastBalanced: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "goproxlb_last_balanced_timestamp_seconds",
+			Help: "Unix time of the last cycle that could migrate VMs, 0 before the first.",
+		}),
+		balanceScore: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "goproxlb_balance_score",
+			Help: "Cluster balance score, from 0 (lopsided) to 100 (perfectly even).",
+		}),
+		nodeUsage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "goproxlb_node_usage_percent",
+			Help: "Node CPU, memory and storage usage in percent.",
+		}, []string{"node", "resource"}),
+	}
+
+	registerer := prometheus.WrapRegistererWith(prometheus.Labels{"cluster": cluster}, r.registry)
+	registerer.MustRegister(r.migrations, r.migrationFailures, r.cycleDuration, r.lastBalanced, r.balanceScore, r.nodeUsage)
+	r.registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return r
+}
+
+// ObserveCycle records a balancing cycle: its duration, and its migrations that ran. Migrations
+// only planned in read-only mode are not counted.
+func (r *Recorder) ObserveCycle(duration time.Duration, results []models.BalancingResult) {
+	if r == nil {
+		return
+	}
+
+	r.cycleDuration.Observe(duration.Seconds())
+	for i := range results {
+		switch {
+		case results[i].Success:
+			r.migrations.Inc()
+		case !results[i].DryRun:
+			r.migrationFailures.Inc()
+		}
+	}
+}
+
+// ObserveStatus records the cluster status: its last balancing and balance score.
+func (r *Recorder) ObserveStatus(status *models.ClusterStatus) {
+	if r == nil || status == nil {
+		return
+	}
+
+	if !status.LastBalanced.IsZero() {
+		r.lastBalanced.Set(float64(status.LastBalanced.Unix()))
+	}
+	r.balanceScore.Set(status.BalanceScore)
+}
+
+// ObserveNodes records the usage of the nodes. Nodes gone from the cluster are dropped.
+func (r *Recorder) ObserveNodes(nodes []models.Node) {
+	if r == nil {
+		return
+	}
+
+	r.nodeUsage.Reset()
+	for i := range nodes {
+		node := &nodes[i]
+		r.nodeUsage.WithLabelValues(node.Name, "cpu").Set(float64(node.CPU.Usage))
+		r.nodeUsage.WithLabelValues(node.Name, "memory").Set(float64(node.Memory.Usage))
+		r.nodeUsage.WithLabelValues(node.Name, "storage").Set(float64(node.Storage.Usage))
+	}
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus exposition format.
+func (r *Recorder) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
+
+// Serve serves the metrics on the address until the context is done. Listening errors are returned
+// at once; the listener address is returned to report the port picked for ":0".
+func (r *Recorder) Serve(ctx context.Context, address string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics on %s: %w", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, r.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Warning: metrics server stopped: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx) //nolint:errcheck // shutting down, error not actionable
+	}()
+
+	return listener.Addr(), nil
+}
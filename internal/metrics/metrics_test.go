@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveCycle(t *testing.T) {
+	recorder := NewRecorder("test-cluster")
+	recorder.ObserveCycle(2*time.Second, []models.BalancingResult{
+		{Success: true},
+		{Success: true},
+		{ErrorMessage: "VM is locked"},
+		{DryRun: true}, // Only planned, neither executed nor failed
+	})
+
+	if got := testutil.ToFloat64(recorder.migrations); got != 2 {
+		t.Errorf("Expected 2 migrations, got %v", got)
+	}
+	if got := testutil.ToFloat64(recorder.migrationFailures); got != 1 {
+		t.Errorf("Expected 1 migration failure, got %v", got)
+	}
+	if got := testutil.CollectAndCount(recorder.cycleDuration); got != 1 {
+		t.Errorf("Expected the cycle duration histogram, got %d metrics", got)
+	}
+}
+
+func TestObserveStatusAndNodes(t *testing.T) {
+	recorder := NewRecorder("test-cluster")
+	lastBalanced := time.Unix(1700000000, 0)
+	recorder.ObserveStatus(&models.ClusterStatus{LastBalanced: lastBalanced, BalanceScore: 87})
+
+	if got := testutil.ToFloat64(recorder.lastBalanced); got != 1700000000 {
+		t.Errorf("Expected last balanced at 1700000000, got %v", got)
+	}
+	if got := testutil.ToFloat64(recorder.balanceScore); got != 87 {
+		t.Errorf("Expected balance score 87, got %v", got)
+	}
+
+	recorder.ObserveNodes([]models.Node{
+		{Name: "node1", CPU: models.CPUInfo{Usage: 55}, Memory: models.MemoryInfo{Usage: 60}, Storage: models.StorageInfo{Usage: 20}},
+		{Name: "node2", CPU: models.CPUInfo{Usage: 10}},
+	})
+	if got := testutil.ToFloat64(recorder.nodeUsage.WithLabelValues("node1", "memory")); got != 60 {
+		t.Errorf("Expected node1 memory at 60%%, got %v", got)
+	}
+
+	// A node leaving the cluster leaves the metrics too
+	recorder.ObserveNodes([]models.Node{{Name: "node1"}})
+	if got := testutil.CollectAndCount(recorder.nodeUsage); got != 3 {
+		t.Errorf("Expected only node1's 3 resources, got %d metrics", got)
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	var recorder *Recorder
+	recorder.ObserveCycle(time.Second, []models.BalancingResult{{Success: true}})
+	recorder.ObserveStatus(&models.ClusterStatus{})
+	recorder.ObserveNodes([]models.Node{{Name: "node1"}})
+}
+
+func TestServe(t *testing.T) {
+	recorder := NewRecorder("test-cluster")
+	recorder.ObserveCycle(time.Second, []models.BalancingResult{{Success: true}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	address, err := recorder.Serve(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	resp, err := http.Get("http://" + address.String() + Path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, want := range []string{
+		`goproxlb_migrations_total{cluster="test-cluster"} 1`,
+		`goproxlb_balancing_cycle_duration_seconds_count{cluster="test-cluster"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %q in the scrape, got:\n%s", want, body)
+		}
+	}
+
+	if _, err := recorder.Serve(ctx, address.String()); err == nil {
+		t.Error("Expected an error listening on an address in use")
+	}
+}
@@ -14,6 +14,10 @@ type Node struct {
 	Storage       StorageInfo `json:"storage"`
 	VMs           []VM        `json:"vms"`
 	InMaintenance bool        `json:"in_maintenance"`
+	Uptime        int64       `json:"uptime"`                 // Seconds since boot, 0 = unknown
+	Version       string      `json:"version,omitempty"`      // Proxmox VE version (e.g., "8.1.4"), empty = unknown
+	KSMShared     int64       `json:"ksm_shared"`             // Bytes of memory KSM page sharing saves
+	PCIMappings   []string    `json:"pci_mappings,omitempty"` // PCI resource mappings with a device on the node
 }
 
 // VM represents a virtual machine or container.
@@ -21,17 +25,59 @@ type VM struct {
 	ID        int       `json:"id"`
 	Name      string    `json:"name"`
 	Node      string    `json:"node"`
-	Type      string    `json:"type"` // qemu or lxc
-	Status    string    `json:"status"`
+	Type      string    `json:"type"`   // qemu or lxc
+	Status    string    `json:"status"` // running, stopped, paused or suspended
 	CPU       float32   `json:"cpu"`
+	CPUs      int       `json:"cpus"`                // Allocated vCPUs
+	CPULimit  float64   `json:"cpu_limit,omitempty"` // cpulimit in cores, 0 = unlimited
+	CPUUnits  int       `json:"cpu_units,omitempty"` // cpuunits scheduler weight
+	Protected bool      `json:"protected,omitempty"` // protection flag or onboot with a startup order
 	Memory    int64     `json:"memory"`
+	MaxMemory int64     `json:"max_memory,omitempty"` // Configured memory in bytes, 0 = unknown
 	Tags      []string  `json:"tags"`
 	Created   time.Time `json:"created"`
 	LastMoved time.Time `json:"last_moved,omitempty"`
+	// ReplicaNodes hold a storage replica of the VM (replication jobs), so migrating there copies little data
+	ReplicaNodes []string `json:"replica_nodes,omitempty"`
+	// Pool is the Proxmox resource pool the VM belongs to, if any
+	Pool string `json:"pool,omitempty"`
+	// BootOrder is the startup order the VM boots in (startup order=N), 0 when unset
+	BootOrder int `json:"boot_order,omitempty"`
+	// Disk is the local storage the VM's disks take now, MaxDisk their configured size, in bytes
+	Disk    int64 `json:"disk,omitempty"`
+	MaxDisk int64 `json:"max_disk,omitempty"`
+	// ThinDisk is set when a local disk is thin provisioned, and may grow up to its configured size
+	ThinDisk bool `json:"thin_disk,omitempty"`
+	// MigrateDowntime is the max downtime of a live migration in seconds (migrate_downtime), 0 when unset.
+	// Proxmox applies it itself during the migration
+	MigrateDowntime float64 `json:"migrate_downtime,omitempty"`
+	// Passthrough are the PCI resource mappings of the VM's passthrough devices (hostpciN mapping=),
+	// only nodes with all of them can host it. PassthroughPinned is set when a device is a raw host
+	// PCI address, tying the VM to its node
+	Passthrough       []string `json:"passthrough,omitempty"`
+	PassthroughPinned bool     `json:"passthrough_pinned,omitempty"`
+	// Backups are the start times of the backup jobs (vzdump) that include the VM
+	Backups []BackupSchedule `json:"backups,omitempty"`
+	// BackingUp is set while a backup holds the VM's lock
+	BackingUp bool `json:"backing_up,omitempty"`
 	// Load profiling
 	LoadProfile *LoadProfile `json:"load_profile,omitempty"`
 }
 
+// VM statuses beside Proxmox's running and stopped, for QEMU VMs whose memory state is kept.
+const (
+	// VMStatusPaused is a VM whose QEMU process is paused, its memory still held on the node.
+	VMStatusPaused = "paused"
+	// VMStatusSuspended is a VM suspended to disk (hibernated), or being suspended.
+	VMStatusSuspended = "suspended"
+)
+
+// BackupSchedule is when a backup job starts, in the local time of the cluster.
+type BackupSchedule struct {
+	Days  []time.Weekday `json:"days,omitempty"` // Empty runs every day
+	Start int            `json:"start"`          // Minutes since midnight
+}
+
 // CPUInfo represents CPU information.
 type CPUInfo struct {
 	Usage   float32 `json:"usage"` // Percentage
@@ -70,10 +116,17 @@ type BalancingResult struct {
 	TargetNode   string    `json:"target_node"`
 	VM           VM        `json:"vm"`
 	Reason       string    `json:"reason"`
-	ResourceGain float64   `json:"resource_gain"`
+	Rules        []string  `json:"rules,omitempty"` // Placement rules that drew the VM to its target
+	ResourceGain float64   `json:"resource_gain"`   // Score gain the migration was planned on
+	Freed        Resources `json:"freed_resources"` // Share of the source node the VM frees
 	Timestamp    time.Time `json:"timestamp"`
 	Success      bool      `json:"success"`
+	DryRun       bool      `json:"dry_run,omitempty"` // Planned only, not executed
 	ErrorMessage string    `json:"error_message,omitempty"`
+	// EstimatedDuration is how long the migration was expected to take, 0 when unknown
+	EstimatedDuration time.Duration `json:"estimated_duration,omitempty"`
+	// StorageMigration is set when the migration copies the VM's local disks along with its memory
+	StorageMigration bool `json:"storage_migration,omitempty"`
 }
 
 // NodeScore represents a node's score for VM placement.
@@ -85,6 +138,30 @@ type NodeScore struct {
 	Storage float32 `json:"storage"`
 }
 
+// NodePower represents external power and thermal telemetry for a node.
+type NodePower struct {
+	Watts       float64 `json:"watts"`
+	Temperature float64 `json:"temperature,omitempty"` // Celsius
+}
+
+// NodeScoreBreakdown represents the sub-scores that make up a node's advanced score.
+type NodeScoreBreakdown struct {
+	Node          string  `json:"node"`
+	Resource      float64 `json:"resource"`
+	Stability     float64 `json:"stability"`
+	Capacity      float64 `json:"capacity"`
+	MigrationCost float64 `json:"migration_cost"`
+	Power         float64 `json:"power,omitempty"`
+	Final         float64 `json:"final"`
+}
+
+// DecisionMatrix represents every node's score breakdown for one balancing cycle.
+type DecisionMatrix struct {
+	Timestamp    time.Time            `json:"timestamp"`
+	BalanceScore float64              `json:"balance_score"`
+	Nodes        []NodeScoreBreakdown `json:"nodes"`
+}
+
 // AffinityGroup represents a group of VMs that should be kept together.
 type AffinityGroup struct {
 	Tag   string   `json:"tag"`
@@ -111,8 +188,42 @@ type IgnoredVM struct {
 	Tags []string `json:"tags"`
 }
 
+// RuleConflict represents a contradictory or unsatisfiable combination of placement rules.
+type RuleConflict struct {
+	Type    string `json:"type"` // pin_unavailable, affinity_pin, anti_affinity_capacity, anti_affinity_pin, affinity_anti_affinity
+	Group   string `json:"group,omitempty"`
+	VMIDs   []int  `json:"vm_ids"`
+	Message string `json:"message"`
+}
+
+// UnschedulableVM represents a VM that should move off its node but has no valid target.
+type UnschedulableVM struct {
+	VMID   int       `json:"vm_id"`
+	Name   string    `json:"name"`
+	Node   string    `json:"node"`
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+}
+
+// SkippedVM represents a VM evaluated for migration but left in place, and why.
+type SkippedVM struct {
+	VMID   int    `json:"vm_id"`
+	Name   string `json:"name"`
+	Node   string `json:"node"`
+	Reason string `json:"reason"`
+	// Rules are the placement rules that kept the VM in place, if any
+	Rules []string `json:"rules,omitempty"`
+}
+
+// PhaseTiming is the time a balancing cycle spent in one of its phases.
+type PhaseTiming struct {
+	Phase    string        `json:"phase"`
+	Duration time.Duration `json:"duration"`
+}
+
 // ClusterStatus represents the overall status of the cluster.
 type ClusterStatus struct {
+	Cluster          string    `json:"cluster"` // Configured or auto-detected cluster name, telling clusters apart when monitoring several
 	TotalNodes       int       `json:"total_nodes"`
 	ActiveNodes      int       `json:"active_nodes"`
 	TotalVMs         int       `json:"total_vms"`
@@ -121,7 +232,39 @@ type ClusterStatus struct {
 	AverageMemory    float32   `json:"average_memory"`
 	AverageStorage   float32   `json:"average_storage"`
 	LastBalanced     time.Time `json:"last_balanced"`
+	LastRun          time.Time `json:"last_run"` // Start of the last balancing cycle, balancing or not, zero before the first
+	NextRun          time.Time `json:"next_run"` // When the next cycle is due, zero before the first cycle
 	BalancingEnabled bool      `json:"balancing_enabled"`
+	BalanceScore     float64   `json:"balance_score"` // 0 (lopsided) to 100 (perfectly even)
+	OverCapacity     bool      `json:"over_capacity"` // Every node above its thresholds, more nodes are needed
+
+	SLABreaches []SLABreach `json:"sla_breaches,omitempty"` // Node resources beyond the imbalance SLA, worst first
+}
+
+// SLABreach is a node resource further above the cluster mean than the imbalance SLA allows.
+type SLABreach struct {
+	Node     string  `json:"node"`
+	Resource string  `json:"resource"` // "cpu" or "memory"
+	Usage    float64 `json:"usage"`    // Percent
+	Mean     float64 `json:"mean"`     // Cluster mean, percent
+	Excess   float64 `json:"excess"`   // Percentage points above the mean
+}
+
+// ClusterTotals sums node capacities and the resources allocated to running VMs across the cluster.
+type ClusterTotals struct {
+	CPUCores        int   `json:"cpu_cores"`
+	AllocatedVCPUs  int   `json:"allocated_vcpus"`
+	MemoryTotal     int64 `json:"memory_total"`     // Bytes
+	MemoryUsed      int64 `json:"memory_used"`      // Bytes
+	MemoryAllocated int64 `json:"memory_allocated"` // Bytes configured for running VMs
+	StorageTotal    int64 `json:"storage_total"`    // Bytes
+	StorageUsed     int64 `json:"storage_used"`     // Bytes
+}
+
+// Resources holds per-resource shares of a node's capacity, in percentage points.
+type Resources struct {
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
 }
 
 // Migration represents a VM migration operation.
@@ -129,10 +272,22 @@ type Migration struct {
 	VM        VM         `json:"vm"`
 	FromNode  string     `json:"from_node"`
 	ToNode    string     `json:"to_node"`
-	Status    string     `json:"status"` // pending, running, completed, failed
+	Gain      float64    `json:"gain"`            // Score gain expected from the move, on the balancer's scale
+	Freed     Resources  `json:"freed_resources"` // Share of the source node the VM frees
+	Status    string     `json:"status"`          // pending, running, completed, failed
 	StartTime time.Time  `json:"start_time"`
 	EndTime   *time.Time `json:"end_time,omitempty"`
 	Error     string     `json:"error,omitempty"`
+	// EstimatedDuration is how long copying the VM's memory, and its local disks, should take, 0 when unknown
+	EstimatedDuration time.Duration `json:"estimated_duration,omitempty"`
+	// StorageMigration is set when the VM has local disks, copied to the target along with its memory
+	StorageMigration bool `json:"storage_migration,omitempty"`
+}
+
+// SessionMetrics are a VM's session counts, read through its guest agent.
+type SessionMetrics struct {
+	Processes   int `json:"processes"`
+	Connections int `json:"connections"` // Established TCP connections
 }
 
 // LoadProfile represents the load characteristics of a VM.
@@ -149,6 +304,9 @@ type LoadProfile struct {
 	Predictability *Predictability `json:"predictability,omitempty"`
 	Seasonality    *Seasonality    `json:"seasonality,omitempty"`
 	Dependencies   []string        `json:"dependencies,omitempty"`
+
+	// Samples is the number of history samples the profile was built from
+	Samples int `json:"samples,omitempty"`
 }
 
 // CPUPattern represents CPU usage patterns.
@@ -258,6 +416,16 @@ type MigrationHistory struct {
 	ToNode    string    `json:"to_node"`
 	Timestamp time.Time `json:"timestamp"`
 	Reason    string    `json:"reason"`
+	Rules     []string  `json:"rules,omitempty"` // Placement rules that drew the VM to its target
+}
+
+// MigrationPairStats tracks migration outcomes between a source and target node.
+type MigrationPairStats struct {
+	FromNode    string    `json:"from_node"`
+	ToNode      string    `json:"to_node"`
+	Successes   int       `json:"successes"`
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"last_failure,omitempty"`
 }
 
 // MigrationPlan represents an optimized migration plan.
@@ -266,6 +434,9 @@ type MigrationPlan struct {
 	TotalGain  float64     `json:"total_gain"`
 	TotalCost  float64     `json:"total_cost"`
 	NetBenefit float64     `json:"net_benefit"`
+	Atomic     bool        `json:"atomic,omitempty"` // All migrations must succeed, or the executed ones are rolled back
+	// EstimatedDuration is how long the migrations should take one after the other
+	EstimatedDuration time.Duration `json:"estimated_duration,omitempty"`
 }
 
 // ResourceReservation represents resource reservations.
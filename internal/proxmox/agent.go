@@ -0,0 +1,113 @@
+package proxmox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// SessionMetricsSource reads session metrics through the QEMU guest agent.
+// It is optional: balancers check for it on their client.
+type SessionMetricsSource interface {
+	GetVMSessionMetrics(nodeName string, vmID int) (*models.SessionMetrics, error)
+}
+
+// sessionCommand prints the guest's process count and established TCP connections, one per line.
+const sessionCommand = `ls -d /proc/[0-9]* | wc -l; cat /proc/net/tcp /proc/net/tcp6 2>/dev/null | awk '$4 == "01"' | wc -l`
+
+// agentPollInterval and agentPollAttempts bound how long to wait for the guest command to finish.
+const (
+	agentPollInterval = 200 * time.Millisecond
+	agentPollAttempts = 10
+)
+
+// GetVMSessionMetrics runs a short command in a Linux guest through its agent to count its
+// processes and established TCP connections. It fails when the agent isn't running.
+func (c *Client) GetVMSessionMetrics(nodeName string, vmID int) (*models.SessionMetrics, error) {
+	data := url.Values{}
+	for _, arg := range []string{"sh", "-c", sessionCommand} {
+		data.Add("command", arg)
+	}
+
+	resp, err := c.request("POST", fmt.Sprintf("/api2/json/nodes/%s/qemu/%d/agent/exec", nodeName, vmID), strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to run guest agent command on VM %d: %w", vmID, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("guest agent of VM %d unavailable (status %d)", vmID, resp.StatusCode)
+	}
+
+	var execResp struct {
+		Data struct {
+			PID int `json:"pid"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&execResp); err != nil {
+		return nil, fmt.Errorf("failed to decode guest agent response: %w", err)
+	}
+
+	for attempt := 0; attempt < agentPollAttempts; attempt++ {
+		output, exited, err := c.agentExecStatus(nodeName, vmID, execResp.Data.PID)
+		if err != nil {
+			return nil, err
+		}
+		if exited {
+			return parseSessionOutput(output)
+		}
+		time.Sleep(agentPollInterval)
+	}
+
+	return nil, fmt.Errorf("guest agent command on VM %d did not finish in time", vmID)
+}
+
+// agentExecStatus returns the output of a guest agent command, and whether it has exited.
+func (c *Client) agentExecStatus(nodeName string, vmID, pid int) (string, bool, error) {
+	resp, err := c.request("GET", fmt.Sprintf("/api2/json/nodes/%s/qemu/%d/agent/exec-status?pid=%d", nodeName, vmID, pid), nil)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get guest agent command status on VM %d: %w", vmID, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("guest agent command status on VM %d failed with status %d", vmID, resp.StatusCode)
+	}
+
+	var statusResp struct {
+		Data struct {
+			Exited  int    `json:"exited"`
+			OutData string `json:"out-data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&statusResp); err != nil {
+		return "", false, fmt.Errorf("failed to decode guest agent command status: %w", err)
+	}
+
+	return statusResp.Data.OutData, statusResp.Data.Exited == 1, nil
+}
+
+// parseSessionOutput parses the process and connection counts printed by sessionCommand.
+func parseSessionOutput(output string) (*models.SessionMetrics, error) {
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("unexpected guest agent output %q", output)
+	}
+
+	processes, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected guest agent output %q", output)
+	}
+	connections, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected guest agent output %q", output)
+	}
+
+	return &models.SessionMetrics{Processes: processes, Connections: connections}, nil
+}
@@ -0,0 +1,62 @@
+package proxmox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+)
+
+func TestGetVMSessionMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api2/json/nodes/node1/qemu/100/agent/exec":
+			if err := r.ParseForm(); err != nil || len(r.PostForm["command"]) != 3 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"pid": 42}})
+		case r.URL.Path == "/api2/json/nodes/node1/qemu/100/agent/exec-status" && r.URL.Query().Get("pid") == "42":
+			writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"exited": 1, "exitcode": 0, "out-data": "187\n42\n"}})
+		case r.URL.Path == "/api2/json/nodes/node1/qemu/101/agent/exec":
+			// Proxmox answers 500 when the guest agent isn't running
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ProxmoxConfig{Host: server.URL, Token: "test@pve!test=secret", Insecure: true})
+
+	metrics, err := client.GetVMSessionMetrics("node1", 100)
+	if err != nil {
+		t.Fatalf("Expected session metrics, got %v", err)
+	}
+	if metrics.Processes != 187 || metrics.Connections != 42 {
+		t.Errorf("Expected 187 processes and 42 connections, got %+v", metrics)
+	}
+
+	if _, err := client.GetVMSessionMetrics("node1", 101); err == nil {
+		t.Error("Expected an error for a VM without a running guest agent")
+	}
+}
+
+func TestParseSessionOutput(t *testing.T) {
+	tests := []struct {
+		output  string
+		wantErr bool
+	}{
+		{"12\n3\n", false},
+		{"12\n", true},
+		{"twelve\n3\n", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if _, err := parseSessionOutput(tt.output); (err != nil) != tt.wantErr {
+			t.Errorf("parseSessionOutput(%q) error = %v, wantErr %v", tt.output, err, tt.wantErr)
+		}
+	}
+}
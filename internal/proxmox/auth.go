@@ -0,0 +1,93 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Proxmox ticket is valid for two hours, it is renewed ahead of its expiry.
+const (
+	ticketLifetime = 2 * time.Hour
+	ticketRenewal  = 10 * time.Minute
+)
+
+// ticketAuth holds the ticket and CSRF prevention token of a password login, shared by
+// concurrent requests.
+type ticketAuth struct {
+	mu        sync.Mutex
+	ticket    string
+	csrfToken string
+	issued    time.Time
+}
+
+// currentTicket returns the ticket and CSRF prevention token, logging in when there is none or the
+// ticket is about to expire.
+func (c *Client) currentTicket() (ticket, csrfToken string, err error) {
+	c.auth.mu.Lock()
+	defer c.auth.mu.Unlock()
+
+	if c.auth.ticket != "" && time.Since(c.auth.issued) < ticketLifetime-ticketRenewal {
+		return c.auth.ticket, c.auth.csrfToken, nil
+	}
+	ticket, csrfToken, err = c.login()
+	if err != nil {
+		return "", "", err
+	}
+	c.auth.ticket, c.auth.csrfToken, c.auth.issued = ticket, csrfToken, time.Now()
+	return ticket, csrfToken, nil
+}
+
+// dropTicket forgets a ticket the API rejected, so the next request logs in again.
+func (c *Client) dropTicket(ticket string) {
+	c.auth.mu.Lock()
+	defer c.auth.mu.Unlock()
+
+	// A concurrent request may already have renewed it
+	if c.auth.ticket == ticket {
+		c.auth.ticket, c.auth.csrfToken = "", ""
+	}
+}
+
+// login acquires a ticket and CSRF prevention token with the username and password.
+func (c *Client) login() (ticket, csrfToken string, err error) {
+	data := url.Values{}
+	data.Set("username", c.username)
+	data.Set("password", c.password)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, c.host+"/api2/json/access/ticket", strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to log in: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to log in: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("login as %s failed with status %d", c.username, resp.StatusCode)
+	}
+
+	var ticketResp struct {
+		Data struct {
+			Ticket    string `json:"ticket"`
+			CSRFToken string `json:"CSRFPreventionToken"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ticketResp); err != nil {
+		return "", "", fmt.Errorf("failed to decode login response: %w", err)
+	}
+	if ticketResp.Data.Ticket == "" {
+		return "", "", fmt.Errorf("login as %s returned no ticket", c.username)
+	}
+	return ticketResp.Data.Ticket, ticketResp.Data.CSRFToken, nil
+}
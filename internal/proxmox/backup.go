@@ -0,0 +1,185 @@
+package proxmox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// weekdays maps the day names of Proxmox calendar events.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// backupJob is a backup job (vzdump) of the cluster and the guests it covers.
+type backupJob struct {
+	schedule models.BackupSchedule
+	all      bool
+	vmids    map[int]bool
+	exclude  map[int]bool
+	pool     string
+	node     string
+}
+
+// includes reports whether the job backs up a VM, given its node and pool.
+func (j *backupJob) includes(vm *models.VM) bool {
+	if j.node != "" && j.node != vm.Node {
+		return false
+	}
+	switch {
+	case j.all:
+		return !j.exclude[vm.ID]
+	case j.pool != "":
+		return j.pool == vm.Pool
+	default:
+		return j.vmids[vm.ID]
+	}
+}
+
+// getBackupJobs retrieves the enabled backup jobs of the cluster. Jobs whose schedule can't be
+// read are left out with a warning.
+func (c *Client) getBackupJobs() ([]backupJob, error) {
+	resp, err := c.request("GET", "/api2/json/cluster/backup", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup jobs: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("backup jobs request failed with status %d", resp.StatusCode)
+	}
+
+	var backupResp struct {
+		Data []struct {
+			ID        string `json:"id"`
+			Enabled   *int   `json:"enabled"`
+			Schedule  string `json:"schedule"`
+			StartTime string `json:"starttime"`
+			DOW       string `json:"dow"`
+			VMID      string `json:"vmid"`
+			All       int    `json:"all"`
+			Exclude   string `json:"exclude"`
+			Pool      string `json:"pool"`
+			Node      string `json:"node"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&backupResp); err != nil {
+		return nil, fmt.Errorf("failed to decode backup jobs: %w", err)
+	}
+
+	var jobs []backupJob
+	for _, job := range backupResp.Data {
+		if job.Enabled != nil && *job.Enabled == 0 {
+			continue
+		}
+
+		// Older jobs carry a start time and days of the week instead of a schedule
+		spec := job.Schedule
+		if spec == "" {
+			spec = strings.TrimSpace(job.DOW + " " + job.StartTime)
+		}
+		schedule, err := parseBackupSchedule(spec)
+		if err != nil {
+			logf("Warning: backup job %s: %v, its window is not honored\n", job.ID, err)
+			continue
+		}
+
+		jobs = append(jobs, backupJob{
+			schedule: schedule,
+			all:      job.All != 0,
+			vmids:    parseVMIDList(job.VMID),
+			exclude:  parseVMIDList(job.Exclude),
+			pool:     job.Pool,
+			node:     job.Node,
+		})
+	}
+	return jobs, nil
+}
+
+// parseBackupSchedule parses the daily and weekly calendar events backups run on: "21:00",
+// "sat 02:00", "mon..fri 22:30", "mon,wed 1:00", "daily" or "weekly". Repeating events aren't
+// supported.
+func parseBackupSchedule(spec string) (models.BackupSchedule, error) {
+	switch spec {
+	case "daily":
+		return models.BackupSchedule{}, nil
+	case "weekly":
+		return models.BackupSchedule{Days: []time.Weekday{time.Monday}}, nil
+	}
+
+	var schedule models.BackupSchedule
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+	case 2:
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return models.BackupSchedule{}, fmt.Errorf("unsupported backup schedule %q: %w", spec, err)
+		}
+		schedule.Days = days
+		fields = fields[1:]
+	default:
+		return models.BackupSchedule{}, fmt.Errorf("unsupported backup schedule %q", spec)
+	}
+
+	hours, minutes, found := strings.Cut(fields[0], ":")
+	hour, err := strconv.Atoi(hours)
+	if !found || err != nil || hour < 0 || hour > 23 {
+		return models.BackupSchedule{}, fmt.Errorf("unsupported backup schedule %q", spec)
+	}
+	minute, err := strconv.Atoi(minutes)
+	if len(minutes) != 2 || err != nil || minute < 0 || minute > 59 {
+		return models.BackupSchedule{}, fmt.Errorf("unsupported backup schedule %q", spec)
+	}
+	schedule.Start = hour*60 + minute
+	return schedule, nil
+}
+
+// parseWeekdays parses a list of days and day ranges, e.g. "mon,wed" or "mon..fri".
+func parseWeekdays(spec string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, item := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(item, "..")
+		first, ok := weekdays[from]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", from)
+		}
+		if !isRange {
+			days = append(days, first)
+			continue
+		}
+		last, ok := weekdays[to]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", to)
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseVMIDList parses a comma separated list of VM IDs, ignoring invalid entries.
+func parseVMIDList(list string) map[int]bool {
+	ids := make(map[int]bool)
+	for _, item := range strings.Split(list, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(item)); err == nil {
+			ids[id] = true
+		}
+	}
+	return ids
+}
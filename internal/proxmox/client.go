@@ -2,11 +2,13 @@
 package proxmox
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/cblomart/GoProxLB/internal/config"
 	"github.com/cblomart/GoProxLB/internal/models"
+	"golang.org/x/sync/errgroup"
 )
 
 // Client represents a Proxmox API client.
@@ -25,19 +28,28 @@ type Client struct {
 	token    string
 	insecure bool
 	client   *http.Client
+	retry    retryPolicy
+	auth     *ticketAuth
+	workers  int // Nodes fetched in parallel
+
+	// Migration traffic settings, empty for the cluster defaults
+	migrationType    string
+	migrationNetwork string
 }
 
 // NewClient creates a new Proxmox API client.
 func NewClient(cfg *config.ProxmoxConfig) *Client {
-	// Only allow insecure connections for localhost/127.0.0.1 for security
-	allowInsecure := cfg.Insecure && (strings.Contains(cfg.Host, "localhost") ||
-		strings.Contains(cfg.Host, "127.0.0.1") || strings.Contains(cfg.Host, "::1"))
+	// Only allow insecure connections for loopback and explicitly trusted hosts for security
+	allowInsecure := cfg.Insecure && insecureAllowed(cfg.Host, cfg.InsecureHosts)
+	if cfg.Insecure && !allowInsecure {
+		logf("Warning: TLS verification stays enabled for %s: insecure is only honored for loopback and proxmox.insecure_hosts\n", cfg.Host)
+	}
 
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
-				//nolint:gosec // InsecureSkipVerify is conditionally allowed for localhost only
+				//nolint:gosec // InsecureSkipVerify is conditionally allowed for loopback and trusted hosts only
 				InsecureSkipVerify: allowInsecure,
 			},
 		},
@@ -50,9 +62,48 @@ func NewClient(cfg *config.ProxmoxConfig) *Client {
 		token:    cfg.Token,
 		insecure: cfg.Insecure,
 		client:   client,
+		retry:    newRetryPolicy(cfg.Retry),
+		auth:     &ticketAuth{},
+		workers:  max(cfg.Concurrency, 1),
+
+		migrationType:    cfg.MigrationType,
+		migrationNetwork: cfg.MigrationNetwork,
 	}
 }
 
+// insecureAllowed reports whether TLS verification may be skipped for the host URL:
+// only loopback addresses and the trusted hostnames, IPs or CIDRs qualify.
+func insecureAllowed(host string, trusted []string) bool {
+	hostname := host
+	if parsed, err := url.Parse(host); err == nil && parsed.Hostname() != "" {
+		hostname = parsed.Hostname()
+	}
+
+	ip := net.ParseIP(hostname)
+	if strings.EqualFold(hostname, "localhost") || (ip != nil && ip.IsLoopback()) {
+		return true
+	}
+
+	for _, entry := range trusted {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(entry, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetClusterInfo retrieves cluster information.
 func (c *Client) GetClusterInfo() (*models.Cluster, error) {
 	resp, err := c.request("GET", "/api2/json/cluster/status", nil)
@@ -116,20 +167,135 @@ func (c *Client) GetNodes() ([]models.Node, error) {
 		return nil, fmt.Errorf("failed to decode nodes response: %w", err)
 	}
 
-	var nodes []models.Node
-	for _, nodeData := range nodesResp.Data {
-		node, err := c.getNodeDetails(nodeData.Node)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get details for node %s: %w", nodeData.Node, err)
+	// Nodes are fetched in parallel, each into its own slot so the order follows the API
+	nodes := make([]models.Node, len(nodesResp.Data))
+	var group errgroup.Group
+	group.SetLimit(c.workers)
+	for i, nodeData := range nodesResp.Data {
+		group.Go(func() error {
+			node, err := c.getNodeDetails(nodeData.Node, nodeData.MaxCPU)
+			if err != nil {
+				return fmt.Errorf("failed to get details for node %s: %w", nodeData.Node, err)
+			}
+			nodes[i] = *node
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Replication and pools are hints, balancing goes on without them
+	replicas, err := c.getReplicationTargets()
+	if err != nil {
+		logf("Warning: failed to get replication jobs: %v\n", err)
+	}
+	pools, err := c.getPoolMembership()
+	if err != nil {
+		logf("Warning: failed to get pool membership: %v\n", err)
+	}
+	backups, err := c.getBackupJobs()
+	if err != nil {
+		logf("Warning: failed to get backup jobs: %v\n", err)
+	}
+	pciMappings, err := c.getPCIMappings()
+	if err != nil {
+		logf("Warning: failed to get PCI mappings: %v\n", err)
+	}
+	for i := range nodes {
+		nodes[i].PCIMappings = pciMappings[nodes[i].Name]
+		for j := range nodes[i].VMs {
+			vm := &nodes[i].VMs[j]
+			vm.ReplicaNodes = replicas[vm.ID]
+			vm.Pool = pools[vm.ID]
+			for k := range backups {
+				if backups[k].includes(vm) {
+					vm.Backups = append(vm.Backups, backups[k].schedule)
+				}
+			}
 		}
-		nodes = append(nodes, *node)
 	}
 
 	return nodes, nil
 }
 
-// getNodeDetails retrieves detailed information about a specific node.
-func (c *Client) getNodeDetails(nodeName string) (*models.Node, error) {
+// getReplicationTargets retrieves the storage replication jobs: VM ID -> replication target nodes.
+func (c *Client) getReplicationTargets() (map[int][]string, error) {
+	resp, err := c.request("GET", "/api2/json/cluster/replication", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replication jobs: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("replication jobs request failed with status %d", resp.StatusCode)
+	}
+
+	var replicationResp struct {
+		Data []struct {
+			Guest    int    `json:"guest"`
+			Target   string `json:"target"`
+			Disabled int    `json:"disable"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&replicationResp); err != nil {
+		return nil, fmt.Errorf("failed to decode replication jobs: %w", err)
+	}
+
+	replicas := make(map[int][]string)
+	for _, job := range replicationResp.Data {
+		if job.Disabled != 0 || job.Target == "" {
+			continue
+		}
+		replicas[job.Guest] = append(replicas[job.Guest], job.Target)
+	}
+	return replicas, nil
+}
+
+// getPoolMembership retrieves the resource pool of each pooled guest: VM ID -> pool name.
+func (c *Client) getPoolMembership() (map[int]string, error) {
+	resp, err := c.request("GET", "/api2/json/cluster/resources?type=vm", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster resources: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cluster resources request failed with status %d", resp.StatusCode)
+	}
+
+	var resourcesResp struct {
+		Data []struct {
+			VMID int    `json:"vmid"`
+			Pool string `json:"pool"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&resourcesResp); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster resources: %w", err)
+	}
+
+	pools := make(map[int]string)
+	for _, resource := range resourcesResp.Data {
+		if resource.Pool != "" {
+			pools[resource.VMID] = resource.Pool
+		}
+	}
+	return pools, nil
+}
+
+// cpuUsagePercent turns the CPU usage a node reports into a 0-100 percentage. Proxmox reports
+// a fraction of the whole node; a value above 1 can only be a count of busy cores, which is
+// weighed against the node's cores.
+func cpuUsagePercent(cpu float64, cores int) float32 {
+	if cpu > 1 && cores > 0 {
+		cpu /= float64(cores)
+	}
+	return float32(min(max(cpu, 0), 1) * 100)
+}
+
+// getNodeDetails retrieves detailed information about a specific node, maxCPU being its core
+// count from the nodes listing (0 when unknown).
+func (c *Client) getNodeDetails(nodeName string, maxCPU int) (*models.Node, error) {
 	// Get node status
 	statusResp, err := c.request("GET", fmt.Sprintf("/api2/json/nodes/%s/status", nodeName), nil)
 	if err != nil {
@@ -144,7 +310,16 @@ func (c *Client) getNodeDetails(nodeName string) (*models.Node, error) {
 				Total int64 `json:"total"`
 				Used  int64 `json:"used"`
 			} `json:"memory"`
-			LoadAvg []string `json:"loadavg"`
+			LoadAvg    []string `json:"loadavg"`
+			Uptime     int64    `json:"uptime"`
+			PVEVersion string   `json:"pveversion"`
+			KSM        struct {
+				Shared int64 `json:"shared"`
+			} `json:"ksm"`
+			CPUInfo struct {
+				CPUs  int    `json:"cpus"`
+				Model string `json:"model"`
+			} `json:"cpuinfo"`
 		} `json:"data"`
 	}
 
@@ -152,8 +327,14 @@ func (c *Client) getNodeDetails(nodeName string) (*models.Node, error) {
 		return nil, fmt.Errorf("failed to decode node status: %w", err)
 	}
 
+	// Storages are a hint for disk placement, the node is usable without them
+	storages, err := c.getNodeStorages(nodeName)
+	if err != nil {
+		logf("Warning: failed to get storages of node %s: %v\n", nodeName, err)
+	}
+
 	// Get VMs on this node
-	vms, err := c.getNodeVMs(nodeName)
+	vms, err := c.getNodeVMs(nodeName, storages)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get VMs for node %s: %w", nodeName, err)
 	}
@@ -161,25 +342,14 @@ func (c *Client) getNodeDetails(nodeName string) (*models.Node, error) {
 	// Calculate memory usage
 	memoryUsage := float64(statusData.Data.Memory.Used) / float64(statusData.Data.Memory.Total) * 100
 
-	// Get node info for CPU details
-	nodeInfoResp, err := c.request("GET", fmt.Sprintf("/api2/json/nodes/%s/status", nodeName), nil)
-	var cores int
-	var model string
-	if err == nil {
-		defer nodeInfoResp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
-		var nodeInfo struct {
-			Data struct {
-				CPUInfo string `json:"cpuinfo"`
-			} `json:"data"`
-		}
-		if json.NewDecoder(nodeInfoResp.Body).Decode(&nodeInfo) == nil {
-			// Extract cores from CPU info (simplified)
-			if strings.Contains(nodeInfo.Data.CPUInfo, "cores") {
-				// This is a simplified extraction - in production you'd parse this properly
-				cores = 4 // Default fallback
-			}
-			model = "CPU" // Default fallback
-		}
+	// Core counts differ between nodes, VM CPU demand is weighed against each node's own
+	cores := maxCPU
+	if cores <= 0 {
+		cores = statusData.Data.CPUInfo.CPUs
+	}
+	model := statusData.Data.CPUInfo.Model
+	if model == "" {
+		model = "CPU"
 	}
 
 	// Check if node is in maintenance mode by looking for maintenance tag
@@ -198,10 +368,13 @@ func (c *Client) getNodeDetails(nodeName string) (*models.Node, error) {
 	}
 
 	node := &models.Node{
-		Name:   nodeName,
-		Status: "online", // Assume online if we can get status
+		Name:      nodeName,
+		Status:    "online", // Assume online if we can get status
+		Uptime:    statusData.Data.Uptime,
+		Version:   parsePVEVersion(statusData.Data.PVEVersion),
+		KSMShared: statusData.Data.KSM.Shared,
 		CPU: models.CPUInfo{
-			Usage: float32(statusData.Data.CPU * 100),
+			Usage: cpuUsagePercent(statusData.Data.CPU, cores),
 			Cores: cores,
 			Model: model,
 			LoadAvg: func() float32 {
@@ -219,12 +392,7 @@ func (c *Client) getNodeDetails(nodeName string) (*models.Node, error) {
 			Available: statusData.Data.Memory.Total - statusData.Data.Memory.Used,
 			Usage:     float32(memoryUsage),
 		},
-		Storage: models.StorageInfo{
-			Total: 0, // Storage info would require additional API calls
-			Used:  0,
-			Free:  0,
-			Usage: 0,
-		},
+		Storage:       localStorageInfo(storages),
 		VMs:           vms,
 		InMaintenance: inMaintenance,
 	}
@@ -232,9 +400,19 @@ func (c *Client) getNodeDetails(nodeName string) (*models.Node, error) {
 	return node, nil
 }
 
+// parsePVEVersion extracts the version from a pveversion string (e.g., "pve-manager/8.1.4/ec5affc9e41f1d79").
+func parsePVEVersion(pveVersion string) string {
+	parts := strings.Split(pveVersion, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
 // getNodeVMs retrieves all VMs on a specific node.
-func (c *Client) getNodeVMs(nodeName string) ([]models.VM, error) {
-	resp, err := c.request("GET", fmt.Sprintf("/api2/json/nodes/%s/qemu", nodeName), nil)
+func (c *Client) getNodeVMs(nodeName string, storages map[string]nodeStorage) ([]models.VM, error) {
+	// The full listing carries the QEMU status, telling paused VMs apart from running ones
+	resp, err := c.request("GET", fmt.Sprintf("/api2/json/nodes/%s/qemu?full=1", nodeName), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get VMs: %w", err)
 	}
@@ -242,12 +420,17 @@ func (c *Client) getNodeVMs(nodeName string) ([]models.VM, error) {
 
 	var vmsResp struct {
 		Data []struct {
-			ID     int     `json:"vmid"`
-			Name   string  `json:"name"`
-			Status string  `json:"status"`
-			CPU    float64 `json:"cpu"`
-			Mem    int64   `json:"mem"`
-			Tags   string  `json:"tags"`
+			ID        int     `json:"vmid"`
+			Name      string  `json:"name"`
+			Status    string  `json:"status"`
+			QMPStatus string  `json:"qmpstatus"`
+			Lock      string  `json:"lock"`
+			CPU       float64 `json:"cpu"`
+			CPUs      int     `json:"cpus"`
+			Mem       int64   `json:"mem"`
+			MaxMem    int64   `json:"maxmem"`
+			Disk      int64   `json:"disk"`
+			Tags      string  `json:"tags"`
 		} `json:"data"`
 	}
 
@@ -263,20 +446,25 @@ func (c *Client) getNodeVMs(nodeName string) ([]models.VM, error) {
 		}
 
 		vm := models.VM{
-			ID:     vmData.ID,
-			Name:   vmData.Name,
-			Node:   nodeName,
-			Type:   "qemu",
-			Status: vmData.Status,
-			CPU:    float32(vmData.CPU),
-			Memory: vmData.Mem,
-			Tags:   tags,
+			ID:        vmData.ID,
+			Name:      vmData.Name,
+			Node:      nodeName,
+			Type:      "qemu",
+			Status:    qemuStatus(vmData.Status, vmData.QMPStatus, vmData.Lock),
+			CPU:       float32(vmData.CPU),
+			CPUs:      vmData.CPUs,
+			Memory:    vmData.Mem,
+			MaxMemory: vmData.MaxMem,
+			Disk:      vmData.Disk,
+			Tags:      tags,
+			BackingUp: vmData.Lock == "backup",
 		}
+		c.applyVMConfig(&vm, storages)
 		vms = append(vms, vm)
 	}
 
 	// Also get containers
-	containers, err := c.getNodeContainers(nodeName)
+	containers, err := c.getNodeContainers(nodeName, storages)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get containers: %w", err)
 	}
@@ -285,8 +473,21 @@ func (c *Client) getNodeVMs(nodeName string) ([]models.VM, error) {
 	return vms, nil
 }
 
+// qemuStatus maps the status of a QEMU VM, its QEMU status and its lock to the VM status. A VM
+// suspended to disk reads as stopped and a paused one as running, yet neither migrates like one.
+func qemuStatus(status, qmpStatus, lock string) string {
+	switch {
+	case lock == "suspended" || lock == "suspending" || qmpStatus == "suspended":
+		return models.VMStatusSuspended
+	case qmpStatus == "paused":
+		return models.VMStatusPaused
+	default:
+		return status
+	}
+}
+
 // getNodeContainers retrieves all containers on a specific node.
-func (c *Client) getNodeContainers(nodeName string) ([]models.VM, error) {
+func (c *Client) getNodeContainers(nodeName string, storages map[string]nodeStorage) ([]models.VM, error) {
 	resp, err := c.request("GET", fmt.Sprintf("/api2/json/nodes/%s/lxc", nodeName), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get containers: %w", err)
@@ -298,8 +499,12 @@ func (c *Client) getNodeContainers(nodeName string) ([]models.VM, error) {
 			ID     int     `json:"vmid"`
 			Name   string  `json:"name"`
 			Status string  `json:"status"`
+			Lock   string  `json:"lock"`
 			CPU    float64 `json:"cpu"`
+			CPUs   int     `json:"cpus"`
 			Mem    int64   `json:"mem"`
+			MaxMem int64   `json:"maxmem"`
+			Disk   int64   `json:"disk"`
 			Tags   string  `json:"tags"`
 		} `json:"data"`
 	}
@@ -316,25 +521,166 @@ func (c *Client) getNodeContainers(nodeName string) ([]models.VM, error) {
 		}
 
 		container := models.VM{
-			ID:     containerData.ID,
-			Name:   containerData.Name,
-			Node:   nodeName,
-			Type:   "lxc",
-			Status: containerData.Status,
-			CPU:    float32(containerData.CPU),
-			Memory: containerData.Mem,
-			Tags:   tags,
+			ID:        containerData.ID,
+			Name:      containerData.Name,
+			Node:      nodeName,
+			Type:      "lxc",
+			Status:    containerData.Status,
+			CPU:       float32(containerData.CPU),
+			CPUs:      containerData.CPUs,
+			Memory:    containerData.Mem,
+			MaxMemory: containerData.MaxMem,
+			Disk:      containerData.Disk,
+			Tags:      tags,
+			BackingUp: containerData.Lock == "backup",
 		}
+		c.applyVMConfig(&container, storages)
 		containers = append(containers, container)
 	}
 
 	return containers, nil
 }
 
+// vmConfig holds the VM configuration settings GoProxLB cares about.
+type vmConfig struct {
+	CPULimit  float64
+	CPUUnits  int
+	Protected bool
+	Created   time.Time
+	BootOrder int
+	Disks     []diskVolume
+	// MigrateDowntime is the max downtime of a live migration in seconds, 0 when unset
+	MigrateDowntime float64
+	// Passthrough are the PCI resource mappings used, PassthroughPinned set for raw host devices
+	Passthrough       []string
+	PassthroughPinned bool
+}
+
+// getVMConfig retrieves the configuration of a VM or container.
+func (c *Client) getVMConfig(nodeName, vmType string, vmID int) (*vmConfig, error) {
+	resp, err := c.request("GET", fmt.Sprintf("/api2/json/nodes/%s/%s/%d/config", nodeName, vmType, vmID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config for VM %d: %w", vmID, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	// Proxmox may return numeric config values either as numbers or strings
+	var configResp struct {
+		Data struct {
+			CPULimit   interface{} `json:"cpulimit"`
+			CPUUnits   interface{} `json:"cpuunits"`
+			Protection interface{} `json:"protection"`
+			OnBoot     interface{} `json:"onboot"`
+			Startup    string      `json:"startup"`
+			Meta       string      `json:"meta"`
+			Downtime   interface{} `json:"migrate_downtime"`
+		} `json:"data"`
+	}
+	// Disks sit under numbered keys, read them from the raw settings
+	var rawResp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VM config: %w", err)
+	}
+	if err := json.Unmarshal(body, &configResp); err != nil {
+		return nil, fmt.Errorf("failed to decode VM config: %w", err)
+	}
+	if err := json.Unmarshal(body, &rawResp); err != nil {
+		return nil, fmt.Errorf("failed to decode VM config: %w", err)
+	}
+
+	// A startup order only matters for VMs started on boot
+	protected := parseConfigNumber(configResp.Data.Protection) == 1 ||
+		(parseConfigNumber(configResp.Data.OnBoot) == 1 && strings.Contains(configResp.Data.Startup, "order="))
+	passthrough, pinned := parsePassthrough(rawResp.Data)
+
+	return &vmConfig{
+		CPULimit:  parseConfigNumber(configResp.Data.CPULimit),
+		CPUUnits:  int(parseConfigNumber(configResp.Data.CPUUnits)),
+		Protected: protected,
+		Created:   parseCreationTime(configResp.Data.Meta),
+		BootOrder: parseBootOrder(configResp.Data.Startup),
+		Disks:     parseDiskVolumes(rawResp.Data),
+
+		MigrateDowntime:   parseConfigNumber(configResp.Data.Downtime),
+		Passthrough:       passthrough,
+		PassthroughPinned: pinned,
+	}, nil
+}
+
+// parseBootOrder reads the order from a startup config property (e.g. "order=2,up=60"). It is 0 when unset.
+func parseBootOrder(startup string) int {
+	for _, field := range strings.Split(startup, ",") {
+		value, found := strings.CutPrefix(field, "order=")
+		if !found {
+			continue
+		}
+		if order, err := strconv.Atoi(value); err == nil && order > 0 {
+			return order
+		}
+	}
+	return 0
+}
+
+// parseCreationTime reads the creation time Proxmox records in the meta config property
+// (e.g. "creation-qemu=8.1.2,ctime=1700000000"). It is zero when missing.
+func parseCreationTime(meta string) time.Time {
+	for _, field := range strings.Split(meta, ",") {
+		value, found := strings.CutPrefix(field, "ctime=")
+		if !found {
+			continue
+		}
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds > 0 {
+			return time.Unix(seconds, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// applyVMConfig enriches a VM with settings from its configuration, and its disks with the node's storages.
+func (c *Client) applyVMConfig(vm *models.VM, storages map[string]nodeStorage) {
+	cfg, err := c.getVMConfig(vm.Node, vm.Type, vm.ID)
+	if err != nil {
+		// Configuration details are optional, keep defaults
+		return
+	}
+	vm.CPULimit = cfg.CPULimit
+	vm.CPUUnits = cfg.CPUUnits
+	vm.Protected = cfg.Protected
+	vm.Created = cfg.Created
+	vm.BootOrder = cfg.BootOrder
+	vm.MigrateDowntime = cfg.MigrateDowntime
+	vm.Passthrough = cfg.Passthrough
+	vm.PassthroughPinned = cfg.PassthroughPinned
+	applyDiskInfo(vm, cfg.Disks, storages)
+}
+
+// parseConfigNumber converts a numeric config value that may be encoded as a string.
+func parseConfigNumber(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			return parsed
+		}
+	}
+	return 0
+}
+
 // MigrateVM migrates a VM from one node to another.
 func (c *Client) MigrateVM(vmID int, sourceNode, targetNode string) error {
 	data := url.Values{}
 	data.Set("target", targetNode)
+	if c.migrationType != "" {
+		data.Set("migration_type", c.migrationType)
+	}
+	if c.migrationNetwork != "" {
+		data.Set("migration_network", c.migrationNetwork)
+	}
 
 	resp, err := c.request("POST", fmt.Sprintf("/api2/json/nodes/%s/qemu/%d/migrate", sourceNode, vmID), strings.NewReader(data.Encode()))
 	if err != nil {
@@ -361,10 +707,14 @@ func (c *Client) GetNodeHistoricalData(nodeName, timeframe string) ([]Historical
 
 	var rrdResp struct {
 		Data []struct {
-			Time   int64   `json:"time"`
-			CPU    float64 `json:"cpu"`
-			Memory float64 `json:"memory"`
-			Load   float64 `json:"loadavg"`
+			Time      int64   `json:"time"`
+			CPU       float64 `json:"cpu"`
+			Memory    float64 `json:"memory"`
+			Load      float64 `json:"loadavg"`
+			NetIn     float64 `json:"netin"`
+			NetOut    float64 `json:"netout"`
+			DiskRead  float64 `json:"diskread"`
+			DiskWrite float64 `json:"diskwrite"`
 		} `json:"data"`
 	}
 
@@ -379,6 +729,10 @@ func (c *Client) GetNodeHistoricalData(nodeName, timeframe string) ([]Historical
 			CPU:       data.CPU * 100, // Convert to percentage
 			Memory:    data.Memory,
 			LoadAvg:   data.Load,
+			NetIn:     data.NetIn,
+			NetOut:    data.NetOut,
+			DiskRead:  data.DiskRead,
+			DiskWrite: data.DiskWrite,
 		})
 	}
 
@@ -396,10 +750,14 @@ func (c *Client) GetVMHistoricalData(nodeName string, vmID int, vmType, timefram
 
 	var rrdResp struct {
 		Data []struct {
-			Time   int64   `json:"time"`
-			CPU    float64 `json:"cpu"`
-			Memory float64 `json:"memory"`
-			Disk   float64 `json:"disk"`
+			Time      int64   `json:"time"`
+			CPU       float64 `json:"cpu"`
+			Memory    float64 `json:"memory"`
+			Disk      float64 `json:"disk"`
+			NetIn     float64 `json:"netin"`
+			NetOut    float64 `json:"netout"`
+			DiskRead  float64 `json:"diskread"`
+			DiskWrite float64 `json:"diskwrite"`
 		} `json:"data"`
 	}
 
@@ -414,34 +772,171 @@ func (c *Client) GetVMHistoricalData(nodeName string, vmID int, vmType, timefram
 			CPU:       data.CPU * 100, // Convert to percentage
 			Memory:    data.Memory,
 			Disk:      data.Disk,
+			NetIn:     data.NetIn,
+			NetOut:    data.NetOut,
+			DiskRead:  data.DiskRead,
+			DiskWrite: data.DiskWrite,
 		})
 	}
 
 	return metrics, nil
 }
 
+// TimeRange bounds historical data; a zero Start or End leaves that side open.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t falls within the range, bounds included.
+func (r TimeRange) Contains(t time.Time) bool {
+	if !r.Start.IsZero() && t.Before(r.Start) {
+		return false
+	}
+	if !r.End.IsZero() && t.After(r.End) {
+		return false
+	}
+	return true
+}
+
+// TimeframeFor returns the smallest Proxmox RRD timeframe reaching back to start.
+func TimeframeFor(start, now time.Time) string {
+	if start.IsZero() {
+		return "year"
+	}
+
+	age := now.Sub(start)
+	switch {
+	case age <= time.Hour:
+		return "hour"
+	case age <= 24*time.Hour:
+		return "day"
+	case age <= 7*24*time.Hour:
+		return "week"
+	case age <= 31*24*time.Hour:
+		return "month"
+	default:
+		return "year"
+	}
+}
+
+// FilterHistoricalMetrics returns the metrics whose timestamp falls within the range.
+func FilterHistoricalMetrics(metrics []HistoricalMetric, r TimeRange) []HistoricalMetric {
+	filtered := make([]HistoricalMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		if r.Contains(metric.Timestamp) {
+			filtered = append(filtered, metric)
+		}
+	}
+	return filtered
+}
+
+// GetNodeHistoricalDataInRange retrieves a node's historical metrics within an arbitrary time range.
+func (c *Client) GetNodeHistoricalDataInRange(nodeName string, r TimeRange) ([]HistoricalMetric, error) {
+	metrics, err := c.GetNodeHistoricalData(nodeName, TimeframeFor(r.Start, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return FilterHistoricalMetrics(metrics, r), nil
+}
+
+// GetVMHistoricalDataInRange retrieves a VM's historical metrics within an arbitrary time range.
+func (c *Client) GetVMHistoricalDataInRange(nodeName string, vmID int, vmType string, r TimeRange) ([]HistoricalMetric, error) {
+	metrics, err := c.GetVMHistoricalData(nodeName, vmID, vmType, TimeframeFor(r.Start, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return FilterHistoricalMetrics(metrics, r), nil
+}
+
 // HistoricalMetric represents a historical metric data point.
 type HistoricalMetric struct {
 	Timestamp time.Time `json:"timestamp"`
-	CPU       float64   `json:"cpu"`     // Percentage
-	Memory    float64   `json:"memory"`  // Bytes
-	Disk      float64   `json:"disk"`    // Bytes
-	LoadAvg   float64   `json:"loadavg"` // System load average
+	CPU       float64   `json:"cpu"`       // Percentage
+	Memory    float64   `json:"memory"`    // Bytes
+	Disk      float64   `json:"disk"`      // Bytes
+	LoadAvg   float64   `json:"loadavg"`   // System load average
+	NetIn     float64   `json:"netin"`     // Bytes per second
+	NetOut    float64   `json:"netout"`    // Bytes per second
+	DiskRead  float64   `json:"diskread"`  // Bytes per second
+	DiskWrite float64   `json:"diskwrite"` // Bytes per second
 }
 
-// request makes an HTTP request to the Proxmox API.
+// request makes an HTTP request to the Proxmox API, retrying transient failures with exponential
+// backoff as the retry policy allows. A request failing all its attempts returns a *RetryError.
 func (c *Client) request(method, path string, body io.Reader) (*http.Response, error) {
+	// Each attempt sends the body anew
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	if c.retry.attempts <= 1 {
+		return c.send(method, path, payload)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(method, path, payload)
+		var failure error
+		switch {
+		case err != nil && (idempotent(method) || dialFailed(err)):
+			failure = err
+		case err != nil:
+			return nil, err
+		case idempotent(method) && retryableStatus(resp.StatusCode):
+			resp.Body.Close() //nolint:errcheck,gosec // response discarded for a retry
+			failure = fmt.Errorf("status %d", resp.StatusCode)
+		default:
+			return resp, nil
+		}
+
+		if attempt >= c.retry.attempts {
+			return nil, &RetryError{Method: method, Path: path, Attempts: attempt, Err: failure}
+		}
+		delay := c.retry.delay(attempt)
+		logf("Warning: %s %s failed (attempt %d of %d): %v, retrying in %v\n", method, path, attempt, c.retry.attempts, failure, delay)
+		time.Sleep(delay)
+	}
+}
+
+// send performs a single API request. With a password login, a request rejected as
+// unauthorized, its ticket expired or revoked, is sent once more with a new ticket.
+func (c *Client) send(method, path string, payload []byte) (*http.Response, error) {
+	resp, ticket, err := c.sendWithTicket(method, path, payload)
+	if err != nil || ticket == "" || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	resp.Body.Close() //nolint:errcheck,gosec // response discarded for a new login
+	c.dropTicket(ticket)
+	resp, _, err = c.sendWithTicket(method, path, payload)
+	return resp, err
+}
+
+// sendWithTicket performs an API request, returning the ticket it was authenticated with, if any.
+func (c *Client) sendWithTicket(method, path string, payload []byte) (*http.Response, string, error) {
 	url := c.host + path
-	req, err := http.NewRequestWithContext(context.Background(), method, url, body)
+	req, err := http.NewRequestWithContext(context.Background(), method, url, bytes.NewReader(payload))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	// Set authentication (skip if running locally as root)
+	// Set authentication (skip if running locally as root): an API token, or a ticket from a
+	// password login, with its CSRF prevention token for writes
+	var ticket string
 	if c.token != "" {
 		req.Header.Set("Authorization", "PVEAPIToken="+c.token)
 	} else if c.username != "" && c.password != "" {
-		req.SetBasicAuth(c.username, c.password)
+		var csrfToken string
+		if ticket, csrfToken, err = c.currentTicket(); err != nil {
+			return nil, "", err
+		}
+		req.AddCookie(&http.Cookie{Name: "PVEAuthCookie", Value: ticket})
+		if !idempotent(method) {
+			req.Header.Set("CSRFPreventionToken", csrfToken)
+		}
 	}
 	// If no authentication provided, assume local root access
 
@@ -450,8 +945,8 @@ func (c *Client) request(method, path string, body io.Reader) (*http.Response, e
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return resp, nil
+	return resp, ticket, nil
 }
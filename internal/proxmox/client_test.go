@@ -2,11 +2,18 @@ package proxmox
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
 )
 
 // Helper function for encoding JSON in tests.
@@ -81,6 +88,62 @@ func setupMockServer() (*httptest.Server, *config.ProxmoxConfig) {
 			return
 		}
 
+		// Mock replication jobs, VM 101's is disabled
+		if r.URL.Path == "/api2/json/cluster/replication" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			writeJSON(w, map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"id": "100-0", "guest": 100, "target": "node2", "type": "local"},
+					{"id": "101-0", "guest": 101, "target": "node2", "type": "local", "disable": 1},
+				},
+			})
+			return
+		}
+
+		// Mock backup jobs: a nightly one for VM 100, a weekly one for the production pool, a disabled one for all
+		if r.URL.Path == "/api2/json/cluster/backup" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			writeJSON(w, map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"id": "backup-nightly", "schedule": "21:00", "vmid": "100"},
+					{"id": "backup-weekly", "starttime": "02:30", "dow": "sat", "pool": "production", "enabled": 1},
+					{"id": "backup-all", "schedule": "mon..fri 01:00", "all": 1, "enabled": 0},
+				},
+			})
+			return
+		}
+
+		// Mock PCI mappings, the GPU is on node1 and node2, the NIC on node2 only
+		if r.URL.Path == "/api2/json/cluster/mapping/pci" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			writeJSON(w, map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"id": "nic", "map": []string{"node=node2,path=0000:03:00.0,id=8086:1572"}},
+					{"id": "gpu", "map": []string{
+						"node=node1,path=0000:01:00.0,id=10de:2204",
+						"node=node2,path=0000:02:00.0,id=10de:2204",
+					}},
+				},
+			})
+			return
+		}
+
+		// Mock pool membership, only VM 100 is pooled
+		if r.URL.Path == "/api2/json/cluster/resources" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			writeJSON(w, map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"id": "qemu/100", "vmid": 100, "node": "node1", "type": "qemu", "pool": "production"},
+					{"id": "qemu/101", "vmid": 101, "node": "node1", "type": "qemu"},
+				},
+			})
+			return
+		}
+
 		// Mock VMs for node1
 		if r.URL.Path == "/api2/json/nodes/node1/qemu" {
 			w.Header().Set("Content-Type", "application/json")
@@ -104,6 +167,7 @@ func setupMockServer() (*httptest.Server, *config.ProxmoxConfig) {
 						"mem":    2147483648,
 						"maxmem": 4294967296,
 						"tags":   "plb_anti_affinity_ntp",
+						"lock":   "backup",
 					},
 				},
 			})
@@ -130,6 +194,28 @@ func setupMockServer() (*httptest.Server, *config.ProxmoxConfig) {
 			return
 		}
 
+		// Mock VM config
+		if r.URL.Path == "/api2/json/nodes/node1/qemu/100/config" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			writeJSON(w, map[string]interface{}{
+				"data": map[string]interface{}{
+					"cores":            4,
+					"cpulimit":         "1.5",
+					"cpuunits":         512,
+					"onboot":           1,
+					"startup":          "order=1,up=30",
+					"meta":             "creation-qemu=8.1.2,ctime=1700000000",
+					"migrate_downtime": "0.5",
+					"scsi0":            "local-lvm:vm-100-disk-0,size=32G",
+					"scsi1":            "nfs:100/vm-100-disk-1.qcow2,size=100G",
+					"ide2":             "local:iso/debian.iso,media=cdrom",
+					"hostpci0":         "mapping=gpu,pcie=1",
+				},
+			})
+			return
+		}
+
 		// Mock storage info
 		if r.URL.Path == "/api2/json/nodes/node1/storage" {
 			w.Header().Set("Content-Type", "application/json")
@@ -139,10 +225,31 @@ func setupMockServer() (*httptest.Server, *config.ProxmoxConfig) {
 					{
 						"storage": "local",
 						"type":    "dir",
+						"content": "iso,backup",
+						"active":  1,
 						"avail":   8589934592,
 						"total":   10737418240,
 						"used":    2147483648,
 					},
+					{
+						"storage": "local-lvm",
+						"type":    "lvmthin",
+						"content": "images,rootdir",
+						"active":  1,
+						"avail":   75161927680,
+						"total":   107374182400,
+						"used":    32212254720,
+					},
+					{
+						"storage": "nfs",
+						"type":    "nfs",
+						"content": "images",
+						"shared":  1,
+						"active":  1,
+						"avail":   1073741824000,
+						"total":   2147483648000,
+						"used":    1073741824000,
+					},
 				},
 			})
 			return
@@ -171,11 +278,14 @@ func setupMockServer() (*httptest.Server, *config.ProxmoxConfig) {
 			w.WriteHeader(http.StatusOK)
 			writeJSON(w, map[string]interface{}{
 				"data": map[string]interface{}{
-					"cpu":     4,
-					"maxcpu":  8,
-					"mem":     4294967296,
-					"maxmem":  8589934592,
-					"loadavg": []string{"1.0", "1.0", "1.0"},
+					"cpu":        4,
+					"maxcpu":     8,
+					"mem":        4294967296,
+					"maxmem":     8589934592,
+					"loadavg":    []string{"1.0", "1.0", "1.0"},
+					"uptime":     3600,
+					"pveversion": "pve-manager/8.1.4/ec5affc9e41f1d79",
+					"ksm":        map[string]interface{}{"shared": 536870912},
 				},
 			})
 			return
@@ -346,14 +456,49 @@ func TestGetNodes(t *testing.T) {
 	if node1.Name != "node1" {
 		t.Errorf("Expected node name 'node1', got %s", node1.Name)
 	}
+	if node1.Uptime != 3600 {
+		t.Errorf("Expected node uptime 3600, got %d", node1.Uptime)
+	}
+	if node1.Version != "8.1.4" {
+		t.Errorf("Expected node version '8.1.4', got %q", node1.Version)
+	}
+	if nodes[1].Version != "" {
+		t.Errorf("Expected unknown version for node2, got %q", nodes[1].Version)
+	}
+	if node1.KSMShared != 536870912 || nodes[1].KSMShared != 0 {
+		t.Errorf("Expected KSM sharing of 536870912 bytes on node1 only, got %d and %d", node1.KSMShared, nodes[1].KSMShared)
+	}
 	if node1.Status != "online" {
 		t.Errorf("Expected status 'online', got %s", node1.Status)
 	}
-	if node1.CPU.Cores != 0 {
-		t.Errorf("Expected 0 CPU cores (not implemented in mock), got %d", node1.CPU.Cores)
+	if node1.CPU.Cores != 8 {
+		t.Errorf("Expected 8 CPU cores from maxcpu, got %d", node1.CPU.Cores)
+	}
+	if node1.CPU.Usage != 50.0 {
+		t.Errorf("Expected 50%% CPU usage (4 cores out of 8), got %.1f", node1.CPU.Usage)
+	}
+	for _, node := range nodes {
+		if node.CPU.Usage < 0 || node.CPU.Usage > 100 {
+			t.Errorf("Expected a CPU usage percentage on %s, got %.1f", node.Name, node.CPU.Usage)
+		}
+	}
+
+	if !reflect.DeepEqual(node1.PCIMappings, []string{"gpu"}) || !reflect.DeepEqual(nodes[1].PCIMappings, []string{"gpu", "nic"}) {
+		t.Errorf("Expected the GPU mapping on node1, the GPU and NIC on node2, got %v and %v", node1.PCIMappings, nodes[1].PCIMappings)
+	}
+	if !reflect.DeepEqual(node1.VMs[0].Passthrough, []string{"gpu"}) || node1.VMs[0].PassthroughPinned {
+		t.Errorf("Expected VM 100 passing the mapped GPU through, got %v (pinned %v)", node1.VMs[0].Passthrough, node1.VMs[0].PassthroughPinned)
+	}
+
+	wantBackups := []models.BackupSchedule{{Start: 21 * 60}, {Days: []time.Weekday{time.Saturday}, Start: 150}}
+	if !reflect.DeepEqual(node1.VMs[0].Backups, wantBackups) {
+		t.Errorf("Expected VM 100 backed up nightly and weekly with its pool, got %+v", node1.VMs[0].Backups)
+	}
+	if len(node1.VMs[1].Backups) != 0 {
+		t.Errorf("Expected VM 101 only in the disabled backup job, got %+v", node1.VMs[1].Backups)
 	}
-	if node1.CPU.Usage != 400.0 {
-		t.Errorf("Expected 400%% CPU usage (4 cores out of 8), got %.1f", node1.CPU.Usage)
+	if node1.VMs[0].BackingUp || !node1.VMs[1].BackingUp {
+		t.Errorf("Expected only VM 101 backing up (backup lock), got %v and %v", node1.VMs[0].BackingUp, node1.VMs[1].BackingUp)
 	}
 
 	// Check VMs
@@ -371,6 +516,256 @@ func TestGetNodes(t *testing.T) {
 	if vm1.Status != "running" {
 		t.Errorf("Expected VM status 'running', got %s", vm1.Status)
 	}
+	if vm1.MaxMemory != 2147483648 {
+		t.Errorf("Expected VM configured memory 2147483648, got %d", vm1.MaxMemory)
+	}
+	if vm1.CPULimit != 1.5 {
+		t.Errorf("Expected VM cpulimit 1.5, got %.1f", vm1.CPULimit)
+	}
+	if vm1.MigrateDowntime != 0.5 {
+		t.Errorf("Expected VM migrate_downtime 0.5, got %.2f", vm1.MigrateDowntime)
+	}
+	if vm1.CPUUnits != 512 {
+		t.Errorf("Expected VM cpuunits 512, got %d", vm1.CPUUnits)
+	}
+	if !vm1.Protected {
+		t.Error("Expected VM 100 with onboot and a startup order to be protected")
+	}
+	if vm1.BootOrder != 1 {
+		t.Errorf("Expected VM boot order 1, got %d", vm1.BootOrder)
+	}
+	if !vm1.Created.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected VM created at its meta ctime, got %v", vm1.Created)
+	}
+
+	// Only the thin local disk counts, the qcow2 disk sits on shared storage
+	if !vm1.ThinDisk || vm1.MaxDisk != 32<<30 || vm1.Disk != 0 {
+		t.Errorf("Expected a thin 32G local disk for VM 100, got thin %v max %d current %d", vm1.ThinDisk, vm1.MaxDisk, vm1.Disk)
+	}
+	if node1.Storage.Total != 107374182400 || node1.Storage.Used != 32212254720 || node1.Storage.Usage != 30 {
+		t.Errorf("Expected node1 storage from its local VM storage only, got %+v", node1.Storage)
+	}
+
+	// VMs without a readable config keep the defaults
+	if vm2 := node1.VMs[1]; vm2.CPULimit != 0 || vm2.Protected {
+		t.Errorf("Expected defaults for VM 101, got cpulimit %.1f protected %v", vm2.CPULimit, vm2.Protected)
+	}
+
+	// Only enabled replication jobs mark replica nodes
+	if len(vm1.ReplicaNodes) != 1 || vm1.ReplicaNodes[0] != "node2" {
+		t.Errorf("Expected VM 100 to be replicated to node2, got %v", vm1.ReplicaNodes)
+	}
+	if len(node1.VMs[1].ReplicaNodes) != 0 {
+		t.Errorf("Expected no replica for VM 101 with a disabled job, got %v", node1.VMs[1].ReplicaNodes)
+	}
+	if vm1.Pool != "production" || node1.VMs[1].Pool != "" {
+		t.Errorf("Expected VM 100 in pool production and VM 101 in none, got %q and %q", vm1.Pool, node1.VMs[1].Pool)
+	}
+}
+
+func TestGetVMConfigProtection(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   bool
+	}{
+		{"protection flag", map[string]interface{}{"protection": 1}, true},
+		{"protection flag as string", map[string]interface{}{"protection": "1"}, true},
+		{"onboot with startup order", map[string]interface{}{"onboot": 1, "startup": "order=2,up=60"}, true},
+		{"startup order without onboot", map[string]interface{}{"startup": "order=2"}, false},
+		{"onboot without order", map[string]interface{}{"onboot": 1, "startup": "up=60"}, false},
+		{"unprotected", map[string]interface{}{"cores": 2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api2/json/nodes/node1/qemu/100/config" {
+					writeJSON(w, map[string]interface{}{"data": tt.config})
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.ProxmoxConfig{Host: server.URL, Token: "test@pve!test=secret", Insecure: true})
+			cfg, err := client.getVMConfig("node1", "qemu", 100)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if cfg.Protected != tt.want {
+				t.Errorf("Expected protected %v, got %v", tt.want, cfg.Protected)
+			}
+		})
+	}
+}
+
+func TestGetNodeVMsMapsPausedAndSuspendedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api2/json/nodes/node1/qemu":
+			if r.URL.Query().Get("full") != "1" {
+				t.Errorf("Expected the full VM listing, got query %q", r.URL.RawQuery)
+			}
+			writeJSON(w, map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"vmid": 100, "name": "web", "status": "running", "qmpstatus": "running"},
+					{"vmid": 101, "name": "paused", "status": "running", "qmpstatus": "paused"},
+					{"vmid": 102, "name": "hibernated", "status": "stopped", "lock": "suspended"},
+					{"vmid": 103, "name": "hibernating", "status": "running", "qmpstatus": "running", "lock": "suspending"},
+					{"vmid": 104, "name": "off", "status": "stopped", "qmpstatus": "stopped"},
+				},
+			})
+		case "/api2/json/nodes/node1/lxc":
+			writeJSON(w, map[string]interface{}{"data": []map[string]interface{}{}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ProxmoxConfig{Host: server.URL, Token: "test@pve!test=secret", Insecure: true})
+	vms, err := client.getNodeVMs("node1", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := map[int]string{
+		100: "running",
+		101: models.VMStatusPaused,
+		102: models.VMStatusSuspended,
+		103: models.VMStatusSuspended,
+		104: "stopped",
+	}
+	if len(vms) != len(want) {
+		t.Fatalf("Expected %d VMs, got %d", len(want), len(vms))
+	}
+	for _, vm := range vms {
+		if vm.Status != want[vm.ID] {
+			t.Errorf("Expected VM %d status %q, got %q", vm.ID, want[vm.ID], vm.Status)
+		}
+	}
+}
+
+func TestParseCreationTime(t *testing.T) {
+	tests := []struct {
+		meta string
+		want time.Time
+	}{
+		{"creation-qemu=8.1.2,ctime=1700000000", time.Unix(1700000000, 0)},
+		{"ctime=1700000000", time.Unix(1700000000, 0)},
+		{"creation-qemu=8.1.2", time.Time{}},
+		{"ctime=invalid", time.Time{}},
+		{"", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseCreationTime(tt.meta); !got.Equal(tt.want) {
+			t.Errorf("parseCreationTime(%q) = %v, want %v", tt.meta, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStorageInfo(t *testing.T) {
+	const gib = 1 << 30
+	storages := map[string]nodeStorage{
+		"local":     {Type: "dir", Content: "images,iso", Total: 100 * gib, Used: 20 * gib, Avail: 80 * gib},
+		"local-lvm": {Type: "lvmthin", Content: "rootdir,images", Total: 300 * gib, Used: 60 * gib, Avail: 240 * gib},
+		// Neither holds VM disks of this node alone
+		"backup": {Type: "dir", Content: "backup", Total: 1000 * gib, Used: 900 * gib},
+		"ceph":   {Type: "rbd", Content: "images", Shared: true, Total: 5000 * gib, Used: 4000 * gib},
+		// Network storage without the shared flag is still shared
+		"nas": {Type: "nfs", Content: "images", Total: 2000 * gib, Used: 1500 * gib},
+	}
+
+	info := localStorageInfo(storages)
+	if info.Total != 400*gib || info.Used != 80*gib || info.Free != 320*gib {
+		t.Errorf("Expected 80 GiB used of 400 GiB across local VM storages, got %+v", info)
+	}
+	if info.Usage < 19.9 || info.Usage > 20.1 {
+		t.Errorf("Expected ~20%% storage usage, got %.1f%%", info.Usage)
+	}
+
+	if empty := localStorageInfo(nil); empty.Total != 0 || empty.Usage != 0 {
+		t.Errorf("Expected no storage without storages, got %+v", empty)
+	}
+}
+
+func TestParseDiskVolumes(t *testing.T) {
+	disks := parseDiskVolumes(map[string]interface{}{
+		"scsi0":   "local-lvm:vm-100-disk-0,size=32G,discard=on",
+		"virtio1": "local:100/vm-100-disk-1.qcow2,size=512M",
+		"ide2":    "local:iso/debian.iso,media=cdrom",
+		"ide3":    "none,media=cdrom",
+		"unused0": "local-lvm:vm-100-disk-2",
+		"cores":   4,
+		"name":    "scsi0",
+	})
+
+	expected := []diskVolume{
+		{Storage: "local-lvm", Volume: "vm-100-disk-0", Size: 32 << 30},
+		{Storage: "local-lvm", Volume: "vm-100-disk-2"},
+		{Storage: "local", Volume: "100/vm-100-disk-1.qcow2", Format: "qcow2", Size: 512 << 20},
+	}
+	if len(disks) != len(expected) {
+		t.Fatalf("Expected %d disks, got %+v", len(expected), disks)
+	}
+	for i := range expected {
+		if disks[i] != expected[i] {
+			t.Errorf("Expected disk %+v, got %+v", expected[i], disks[i])
+		}
+	}
+}
+
+func TestApplyDiskInfoThinAndThick(t *testing.T) {
+	storages := map[string]nodeStorage{
+		"local-lvm": {Type: "lvmthin", Content: "images,rootdir"},
+		"local":     {Type: "dir", Content: "images,iso"},
+		"lvm":       {Type: "lvm", Content: "images"},
+		"ceph":      {Type: "rbd", Content: "images", Shared: true},
+	}
+
+	tests := []struct {
+		name    string
+		disks   []diskVolume
+		current int64
+		thin    bool
+		maxDisk int64
+		disk    int64
+	}{
+		{"thick lvm is allocated in full", []diskVolume{{Storage: "lvm", Size: 40 << 30}}, 0, false, 40 << 30, 40 << 30},
+		{"thin lvm keeps its current usage", []diskVolume{{Storage: "local-lvm", Size: 40 << 30}}, 5 << 30, true, 40 << 30, 5 << 30},
+		{"qcow2 image is thin", []diskVolume{{Storage: "local", Format: "qcow2", Size: 20 << 30}}, 0, true, 20 << 30, 0},
+		{"raw image on a directory is thick", []diskVolume{{Storage: "local", Size: 20 << 30}}, 0, false, 20 << 30, 20 << 30},
+		{"shared storage doesn't move", []diskVolume{{Storage: "ceph", Size: 100 << 30}}, 0, false, 0, 0},
+		{"unknown storage is left out", []diskVolume{{Storage: "gone", Size: 100 << 30}}, 0, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := &models.VM{Disk: tt.current}
+			applyDiskInfo(vm, tt.disks, storages)
+			if vm.ThinDisk != tt.thin || vm.MaxDisk != tt.maxDisk || vm.Disk != tt.disk {
+				t.Errorf("Expected thin %v max %d current %d, got thin %v max %d current %d",
+					tt.thin, tt.maxDisk, tt.disk, vm.ThinDisk, vm.MaxDisk, vm.Disk)
+			}
+		})
+	}
+}
+
+func TestParseBootOrder(t *testing.T) {
+	tests := map[string]int{
+		"order=2,up=60":  2,
+		"up=60,order=10": 10,
+		"up=60":          0,
+		"order=first":    0,
+		"":               0,
+	}
+	for startup, want := range tests {
+		if got := parseBootOrder(startup); got != want {
+			t.Errorf("parseBootOrder(%q) = %d, want %d", startup, got, want)
+		}
+	}
 }
 
 func TestGetNodesWithMaintenance(t *testing.T) {
@@ -391,6 +786,118 @@ func TestGetNodesWithMaintenance(t *testing.T) {
 	}
 }
 
+// setupClusterServer serves a cluster of identical nodes node0..nodeN-1 with one VM each, delaying
+// each node's status by delay(i).
+func setupClusterServer(count int, delay func(i int) time.Duration) (*httptest.Server, *config.ProxmoxConfig) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api2/json/nodes" {
+			var nodes []map[string]interface{}
+			for i := 0; i < count; i++ {
+				nodes = append(nodes, map[string]interface{}{"node": fmt.Sprintf("node%d", i), "status": "online", "maxcpu": 8})
+			}
+			writeJSON(w, map[string]interface{}{"data": nodes})
+			return
+		}
+
+		var node int
+		var kind string
+		if _, err := fmt.Sscanf(r.URL.Path, "/api2/json/nodes/node%d/%s", &node, &kind); err != nil {
+			writeJSON(w, map[string]interface{}{"data": []interface{}{}})
+			return
+		}
+		switch kind {
+		case "status":
+			time.Sleep(delay(node))
+			writeJSON(w, map[string]interface{}{
+				"data": map[string]interface{}{"cpu": 0.5, "maxcpu": 8, "mem": 4294967296, "maxmem": 8589934592},
+			})
+		case "qemu":
+			writeJSON(w, map[string]interface{}{
+				"data": []map[string]interface{}{{"vmid": 100 + node, "name": fmt.Sprintf("vm-%d", node), "status": "running"}},
+			})
+		case "lxc", "storage":
+			writeJSON(w, map[string]interface{}{"data": []interface{}{}})
+		default:
+			writeJSON(w, map[string]interface{}{"data": map[string]interface{}{}})
+		}
+	}))
+
+	return server, &config.ProxmoxConfig{
+		Host:        server.URL,
+		Token:       "test@pve!test=secret",
+		Insecure:    true,
+		Concurrency: 4,
+	}
+}
+
+func TestGetNodesParallelOrdering(t *testing.T) {
+	// Earlier nodes answer last, so completion order is the reverse of the listing
+	const count = 8
+	server, cfg := setupClusterServer(count, func(i int) time.Duration {
+		return time.Duration(count-i) * 5 * time.Millisecond
+	})
+	defer server.Close()
+
+	for _, concurrency := range []int{0, 1, 4, count} {
+		cfg.Concurrency = concurrency
+		nodes, err := NewClient(cfg).GetNodes()
+		if err != nil {
+			t.Fatalf("Concurrency %d: expected no error, got %v", concurrency, err)
+		}
+		if len(nodes) != count {
+			t.Fatalf("Concurrency %d: expected %d nodes, got %d", concurrency, count, len(nodes))
+		}
+		for i, node := range nodes {
+			if node.Name != fmt.Sprintf("node%d", i) || len(node.VMs) != 1 || node.VMs[0].ID != 100+i {
+				t.Errorf("Concurrency %d: expected node%d with VM %d at %d, got %s with %v", concurrency, i, 100+i, i, node.Name, node.VMs)
+			}
+		}
+	}
+}
+
+func TestGetNodesParallelError(t *testing.T) {
+	server, cfg := setupClusterServer(4, func(int) time.Duration { return 0 })
+	defer server.Close()
+
+	// node2 has no status
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api2/json/nodes/node2/status" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer failing.Close()
+	cfg.Host = failing.URL
+
+	nodes, err := NewClient(cfg).GetNodes()
+	if err == nil || !strings.Contains(err.Error(), "failed to get details for node node2") {
+		t.Errorf("Expected node2's failure, got %v", err)
+	}
+	if nodes != nil {
+		t.Errorf("Expected no nodes on failure, got %d", len(nodes))
+	}
+}
+
+func BenchmarkGetNodes(b *testing.B) {
+	// 16 nodes whose status takes 2ms, as over a LAN
+	server, cfg := setupClusterServer(16, func(int) time.Duration { return 2 * time.Millisecond })
+	defer server.Close()
+
+	for _, concurrency := range []int{1, 4, 16} {
+		cfg.Concurrency = concurrency
+		client := NewClient(cfg)
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := client.GetNodes(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestMigrateVM(t *testing.T) {
 	server, cfg := setupMockServer()
 	defer server.Close()
@@ -427,32 +934,177 @@ func TestMigrateVMError(t *testing.T) {
 	}
 }
 
+func TestMigrateVMMigrationSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		migType     string
+		network     string
+		wantType    string
+		wantNetwork string
+	}{
+		{name: "cluster defaults"},
+		{name: "secure", migType: "secure", wantType: "secure"},
+		{name: "insecure on a dedicated network", migType: "insecure", network: "10.10.0.0/24", wantType: "insecure", wantNetwork: "10.10.0.0/24"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/migrate") {
+					if err := r.ParseForm(); err != nil {
+						t.Errorf("Expected a form body, got %v", err)
+					}
+					form = r.PostForm
+				}
+				writeJSON(w, map[string]interface{}{"data": "UPID:node1:migrate"})
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.ProxmoxConfig{
+				Host:             server.URL,
+				Token:            "test-user@pve!test=secret",
+				MigrationType:    tt.migType,
+				MigrationNetwork: tt.network,
+			})
+			if err := client.MigrateVM(100, "node1", "node2"); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if form.Get("target") != "node2" {
+				t.Errorf("Expected target node2, got %q", form.Get("target"))
+			}
+			// Unset settings aren't sent, so the cluster's datacenter.cfg applies
+			for param, want := range map[string]string{"migration_type": tt.wantType, "migration_network": tt.wantNetwork} {
+				if got, sent := form[param]; want == "" && sent {
+					t.Errorf("Expected no %s, got %v", param, got)
+				} else if form.Get(param) != want {
+					t.Errorf("Expected %s %q, got %q", param, want, form.Get(param))
+				}
+			}
+		})
+	}
+}
+
 func TestRequestWithAuth(t *testing.T) {
+	server, cfg := setupMockServer()
+	defer server.Close()
+
+	// Password logins authenticate with the ticket of the mock's ticket handler
+	checked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api2/json/access/ticket" {
+			if cookie, err := r.Cookie("PVEAuthCookie"); err != nil || cookie.Value != "test-ticket" {
+				t.Errorf("Expected the PVEAuthCookie ticket on %s, got %v", r.URL.Path, r.Cookies())
+			}
+			if _, _, basic := r.BasicAuth(); basic {
+				t.Errorf("Expected no basic auth on %s", r.URL.Path)
+			}
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer checked.Close()
+	cfg.Host = checked.URL
+
+	client := NewClient(cfg)
+	if _, err := client.GetClusterInfo(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestTicketAuthentication(t *testing.T) {
+	var logins, unauthorized int
+	ticket := "ticket-1"
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check for authentication header
-		auth := r.Header.Get("Authorization")
-		if auth == "" {
-			t.Error("Expected Authorization header")
+		if r.URL.Path == "/api2/json/access/ticket" {
+			logins++
+			if r.Method != http.MethodPost || r.FormValue("username") != "test-user@pve" || r.FormValue("password") != "test-password" {
+				t.Errorf("Expected a POST with the credentials, got %s %v", r.Method, r.Form)
+			}
+			w.WriteHeader(http.StatusOK)
+			writeJSON(w, map[string]interface{}{
+				"data": map[string]interface{}{"ticket": ticket, "CSRFPreventionToken": "csrf-" + ticket},
+			})
+			return
+		}
+
+		cookie, err := r.Cookie("PVEAuthCookie")
+		if err != nil || cookie.Value != ticket {
+			unauthorized++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		csrf := r.Header.Get("CSRFPreventionToken")
+		if r.Method == http.MethodGet && csrf != "" {
+			t.Errorf("Expected no CSRF prevention token on reads, got %q", csrf)
+		}
+		if r.Method == http.MethodPost && csrf != "csrf-"+ticket {
+			t.Errorf("Expected CSRF prevention token %q on writes, got %q", "csrf-"+ticket, csrf)
 		}
 		w.WriteHeader(http.StatusOK)
-		writeJSON(w, map[string]interface{}{
-			"data": "test",
-		})
+		writeJSON(w, map[string]interface{}{"data": nil})
 	}))
 	defer server.Close()
 
-	cfg := &config.ProxmoxConfig{
+	client := NewClient(&config.ProxmoxConfig{
 		Host:     server.URL,
 		Username: "test-user@pve",
 		Password: "test-password",
 		Insecure: true,
+	})
+
+	// The ticket is acquired once and reused
+	for i := 0; i < 3; i++ {
+		resp, err := client.request("GET", "/api2/json/version", nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close() //nolint:errcheck // test cleanup
+	}
+	if err := client.MigrateVM(100, "node1", "node2"); err != nil {
+		t.Fatalf("Expected the migration to carry the CSRF token, got %v", err)
+	}
+	if logins != 1 {
+		t.Errorf("Expected a single login, got %d", logins)
 	}
 
-	client := NewClient(cfg)
-	_, err := client.request("GET", "/test", nil)
+	// A rejected ticket logs in again and resends the request
+	ticket = "ticket-2"
+	resp, err := client.request("GET", "/api2/json/version", nil)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
+	resp.Body.Close() //nolint:errcheck // test cleanup
+	if resp.StatusCode != http.StatusOK || logins != 2 || unauthorized != 1 {
+		t.Errorf("Expected status 200 after a new login, got %d with %d logins and %d rejections", resp.StatusCode, logins, unauthorized)
+	}
+
+	// Ahead of its expiry the ticket is renewed
+	client.auth.issued = time.Now().Add(-ticketLifetime + ticketRenewal/2)
+	resp, err = client.request("GET", "/api2/json/version", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close() //nolint:errcheck // test cleanup
+	if logins != 3 || unauthorized != 1 {
+		t.Errorf("Expected the ticket renewed before expiry, got %d logins and %d rejections", logins, unauthorized)
+	}
+}
+
+func TestTicketLoginFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ProxmoxConfig{
+		Host:     server.URL,
+		Username: "test-user@pve",
+		Password: "wrong",
+		Insecure: true,
+	})
+	if _, err := client.GetClusterInfo(); err == nil || !strings.Contains(err.Error(), "login as test-user@pve failed with status 401") {
+		t.Errorf("Expected the failed login reported, got %v", err)
+	}
 }
 
 func TestRequestWithToken(t *testing.T) {
@@ -523,3 +1175,389 @@ func TestRequestError(t *testing.T) {
 		t.Fatal("Expected error, got nil")
 	}
 }
+
+func TestRequestRetriesTransientFailures(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		writeJSON(w, map[string]interface{}{
+			"data": map[string]interface{}{"version": "8.1.4"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ProxmoxConfig{
+		Host:     server.URL,
+		Token:    "test@pve!test=secret",
+		Insecure: true,
+		Retry:    config.RetryConfig{MaxAttempts: 3, BaseDelay: "1ms", MaxDelay: "2ms"},
+	})
+	resp, err := client.request("GET", "/api2/json/version", nil)
+	if err != nil {
+		t.Fatalf("Expected the request to succeed on its third attempt, got %v", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // test cleanup
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("Expected status 200 after 3 calls, got %d after %d", resp.StatusCode, calls)
+	}
+}
+
+func TestRequestRetryExhaustion(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ProxmoxConfig{
+		Host:     server.URL,
+		Token:    "test@pve!test=secret",
+		Insecure: true,
+		Retry:    config.RetryConfig{MaxAttempts: 2, BaseDelay: "1ms"},
+	})
+	_, err := client.GetNodes()
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("Expected a RetryError, got %v", err)
+	}
+	if retryErr.Attempts != 2 || calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d (%d calls)", retryErr.Attempts, calls)
+	}
+}
+
+func TestMigrateVMIsNotRetriedOnServerErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ProxmoxConfig{
+		Host:     server.URL,
+		Token:    "test@pve!test=secret",
+		Insecure: true,
+		Retry:    config.RetryConfig{MaxAttempts: 3, BaseDelay: "1ms"},
+	})
+	if err := client.MigrateVM(100, "node1", "node2"); err == nil {
+		t.Fatal("Expected the migration to fail")
+	}
+	// The migration may have started, running it again could migrate twice
+	if calls != 1 {
+		t.Errorf("Expected a single migration request, got %d", calls)
+	}
+
+	// A migration that never reached the server is safe to retry
+	server.Close()
+	client = NewClient(&config.ProxmoxConfig{
+		Host:     server.URL,
+		Token:    "test@pve!test=secret",
+		Insecure: true,
+		Retry:    config.RetryConfig{MaxAttempts: 3, BaseDelay: "1ms"},
+	})
+	err := client.MigrateVM(100, "node1", "node2")
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 {
+		t.Errorf("Expected the unreachable migration retried 3 times, got %v", err)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := retryPolicy{attempts: 5, baseDelay: 100 * time.Millisecond, maxDelay: 300 * time.Millisecond}
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 4: 300 * time.Millisecond} {
+		if got := policy.delay(retry); got != want {
+			t.Errorf("delay(%d) = %v, want %v", retry, got, want)
+		}
+	}
+}
+
+func TestFilterHistoricalMetrics(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	metrics := []HistoricalMetric{
+		{Timestamp: base.Add(-2 * time.Hour), CPU: 10},
+		{Timestamp: base.Add(-1 * time.Hour), CPU: 20},
+		{Timestamp: base, CPU: 30},
+		{Timestamp: base.Add(1 * time.Hour), CPU: 40},
+	}
+
+	tests := []struct {
+		name    string
+		r       TimeRange
+		wantCPU []float64
+	}{
+		{"unbounded", TimeRange{}, []float64{10, 20, 30, 40}},
+		{"closed range includes bounds", TimeRange{Start: base.Add(-time.Hour), End: base}, []float64{20, 30}},
+		{"open end", TimeRange{Start: base}, []float64{30, 40}},
+		{"open start", TimeRange{End: base.Add(-time.Hour)}, []float64{10, 20}},
+		{"no overlap", TimeRange{Start: base.Add(2 * time.Hour)}, []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterHistoricalMetrics(metrics, tt.r)
+			if len(filtered) != len(tt.wantCPU) {
+				t.Fatalf("Expected %d metrics, got %d", len(tt.wantCPU), len(filtered))
+			}
+			for i, metric := range filtered {
+				if metric.CPU != tt.wantCPU[i] {
+					t.Errorf("Expected metric %d to have CPU %.0f, got %.0f", i, tt.wantCPU[i], metric.CPU)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeframeFor(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		start time.Time
+		want  string
+	}{
+		{now.Add(-30 * time.Minute), "hour"},
+		{now.Add(-12 * time.Hour), "day"},
+		{now.Add(-3 * 24 * time.Hour), "week"},
+		{now.Add(-20 * 24 * time.Hour), "month"},
+		{now.Add(-90 * 24 * time.Hour), "year"},
+		{time.Time{}, "year"},
+	}
+
+	for _, tt := range tests {
+		if got := TimeframeFor(tt.start, now); got != tt.want {
+			t.Errorf("TimeframeFor(%v ago) = %s, want %s", now.Sub(tt.start), got, tt.want)
+		}
+	}
+}
+
+func TestCPUUsagePercent(t *testing.T) {
+	tests := []struct {
+		cpu   float64
+		cores int
+		want  float32
+	}{
+		{0.25, 8, 25},
+		{1, 8, 100},
+		{4, 8, 50},
+		{12, 8, 100},
+		{2, 0, 100},
+		{-0.1, 8, 0},
+	}
+
+	for _, tt := range tests {
+		if got := cpuUsagePercent(tt.cpu, tt.cores); got != tt.want {
+			t.Errorf("cpuUsagePercent(%v, %d) = %.1f, want %.1f", tt.cpu, tt.cores, got, tt.want)
+		}
+	}
+}
+
+func TestParsePassthrough(t *testing.T) {
+	tests := []struct {
+		name         string
+		settings     map[string]interface{}
+		wantMappings []string
+		wantPinned   bool
+	}{
+		{"no passthrough", map[string]interface{}{"scsi0": "local-lvm:vm-100-disk-0"}, nil, false},
+		{"mapped devices", map[string]interface{}{"hostpci1": "mapping=nic", "hostpci0": "mapping=gpu,pcie=1", "hostpci2": "mapping=gpu"}, []string{"gpu", "nic"}, false},
+		{"raw host device", map[string]interface{}{"hostpci0": "0000:01:00.0,pcie=1,x-vga=1"}, nil, true},
+		{"raw and mapped", map[string]interface{}{"hostpci0": "host=01:00.0", "hostpci1": "mapping=gpu"}, []string{"gpu"}, true},
+	}
+
+	for _, tt := range tests {
+		mappings, pinned := parsePassthrough(tt.settings)
+		if !reflect.DeepEqual(mappings, tt.wantMappings) || pinned != tt.wantPinned {
+			t.Errorf("%s: got %v (pinned %v), want %v (pinned %v)", tt.name, mappings, pinned, tt.wantMappings, tt.wantPinned)
+		}
+	}
+}
+
+func TestParseBackupSchedule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    models.BackupSchedule
+		wantErr bool
+	}{
+		{spec: "21:00", want: models.BackupSchedule{Start: 21 * 60}},
+		{spec: "1:05", want: models.BackupSchedule{Start: 65}},
+		{spec: "sat 02:30", want: models.BackupSchedule{Days: []time.Weekday{time.Saturday}, Start: 150}},
+		{spec: "mon,wed 01:00", want: models.BackupSchedule{Days: []time.Weekday{time.Monday, time.Wednesday}, Start: 60}},
+		{spec: "fri..mon 23:00", want: models.BackupSchedule{Days: []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}, Start: 23 * 60}},
+		{spec: "daily", want: models.BackupSchedule{}},
+		{spec: "weekly", want: models.BackupSchedule{Days: []time.Weekday{time.Monday}}},
+		{spec: "*/2:00", wantErr: true},
+		{spec: "someday 02:00", wantErr: true},
+		{spec: "25:00", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseBackupSchedule(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBackupSchedule(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseBackupSchedule(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestGetHistoricalDataInRange(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	rrd := map[string]interface{}{
+		"data": []map[string]interface{}{
+			{"time": now.Add(-5 * time.Hour).Unix(), "cpu": 0.10},
+			{"time": now.Add(-3 * time.Hour).Unix(), "cpu": 0.20},
+			{"time": now.Add(-1 * time.Hour).Unix(), "cpu": 0.30},
+		},
+	}
+
+	var timeframes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api2/json/nodes/node1/rrddata" || r.URL.Path == "/api2/json/nodes/node1/qemu/100/rrddata" {
+			timeframes = append(timeframes, r.URL.Query().Get("timeframe"))
+			writeJSON(w, rrd)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ProxmoxConfig{Host: server.URL, Token: "test@pve!test=secret", Insecure: true})
+	window := TimeRange{Start: now.Add(-4 * time.Hour), End: now.Add(-2 * time.Hour)}
+
+	nodeMetrics, err := client.GetNodeHistoricalDataInRange("node1", window)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	vmMetrics, err := client.GetVMHistoricalDataInRange("node1", 100, "qemu", window)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, metrics := range [][]HistoricalMetric{nodeMetrics, vmMetrics} {
+		if len(metrics) != 1 {
+			t.Fatalf("Expected only the sample inside the window, got %d", len(metrics))
+		}
+		if !metrics[0].Timestamp.Equal(now.Add(-3 * time.Hour)) {
+			t.Errorf("Expected the sample from 3h ago, got %v", metrics[0].Timestamp)
+		}
+	}
+
+	for _, timeframe := range timeframes {
+		if timeframe != "day" {
+			t.Errorf("Expected the day timeframe to cover a 4h window, got %s", timeframe)
+		}
+	}
+}
+
+func TestGetHistoricalDataIOFields(t *testing.T) {
+	rrd := map[string]interface{}{
+		"data": []map[string]interface{}{
+			{
+				"time":      1700000000,
+				"cpu":       0.25,
+				"memory":    4294967296,
+				"loadavg":   1.5,
+				"disk":      1073741824,
+				"netin":     125000.5,
+				"netout":    250000.25,
+				"diskread":  1048576,
+				"diskwrite": 2097152,
+			},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api2/json/nodes/node1/rrddata" || r.URL.Path == "/api2/json/nodes/node1/qemu/100/rrddata" {
+			writeJSON(w, rrd)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ProxmoxConfig{Host: server.URL, Token: "test@pve!test=secret", Insecure: true})
+
+	nodeMetrics, err := client.GetNodeHistoricalData("node1", "hour")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	vmMetrics, err := client.GetVMHistoricalData("node1", 100, "qemu", "hour")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for name, metrics := range map[string][]HistoricalMetric{"node": nodeMetrics, "vm": vmMetrics} {
+		if len(metrics) != 1 {
+			t.Fatalf("Expected 1 %s sample, got %d", name, len(metrics))
+		}
+		metric := metrics[0]
+		if metric.CPU != 25 {
+			t.Errorf("Expected %s CPU 25%%, got %v", name, metric.CPU)
+		}
+		if metric.NetIn != 125000.5 || metric.NetOut != 250000.25 {
+			t.Errorf("Expected %s network 125000.5/250000.25 B/s, got %v/%v", name, metric.NetIn, metric.NetOut)
+		}
+		if metric.DiskRead != 1048576 || metric.DiskWrite != 2097152 {
+			t.Errorf("Expected %s disk IO 1048576/2097152 B/s, got %v/%v", name, metric.DiskRead, metric.DiskWrite)
+		}
+	}
+
+	if nodeMetrics[0].LoadAvg != 1.5 {
+		t.Errorf("Expected node load average 1.5, got %v", nodeMetrics[0].LoadAvg)
+	}
+	if vmMetrics[0].Disk != 1073741824 {
+		t.Errorf("Expected VM disk 1073741824, got %v", vmMetrics[0].Disk)
+	}
+}
+
+func TestInsecureAllowed(t *testing.T) {
+	trusted := []string{"10.0.0.0/24", "pve.lan", "fd00::5"}
+	tests := []struct {
+		host    string
+		trusted []string
+		want    bool
+	}{
+		{"https://localhost:8006", nil, true},
+		{"https://127.0.0.1:8006", nil, true},
+		{"https://[::1]:8006", nil, true},
+		{"https://10.0.0.12:8006", nil, false},
+		{"https://localhost.example.com:8006", nil, false},
+		{"https://10.0.0.12:8006", trusted, true},
+		{"https://10.0.1.12:8006", trusted, false},
+		{"https://PVE.lan:8006", trusted, true},
+		{"https://pve.lan.example.com:8006", trusted, false},
+		{"https://[fd00:0::5]:8006", trusted, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := insecureAllowed(tt.host, tt.trusted); got != tt.want {
+				t.Errorf("insecureAllowed(%q, %v) = %v, want %v", tt.host, tt.trusted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientTrustedInsecureCIDR(t *testing.T) {
+	skipsVerify := func(client *Client) bool {
+		return client.client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify
+	}
+
+	cfg := &config.ProxmoxConfig{Host: "https://192.168.10.5:8006", Token: "test@pve!test=secret", Insecure: true}
+	if skipsVerify(NewClient(cfg)) {
+		t.Error("Expected TLS verification for an untrusted LAN address")
+	}
+
+	cfg.InsecureHosts = []string{"192.168.10.0/24"}
+	if !skipsVerify(NewClient(cfg)) {
+		t.Error("Expected TLS verification to be skipped for an address in a trusted CIDR")
+	}
+}
@@ -137,7 +137,7 @@ func (d *DiscoveryService) GetCurrentNodeID() (string, error) {
 	for i := range proxmoxNodes {
 		node := &proxmoxNodes[i]
 		if node.Status == nodeStatusOnline {
-			fmt.Printf("Warning: Could not match hostname '%s' to any node, using first online node '%s'\n", hostname, node.Name)
+			logf("Warning: Could not match hostname '%s' to any node, using first online node '%s'\n", hostname, node.Name)
 			return node.Name, nil
 		}
 	}
@@ -271,12 +271,12 @@ func (d *DiscoveryService) ValidateClusterTopology() error {
 
 	// Warn if only one node has GoProxLB (no redundancy)
 	if goproxlbNodes == 1 {
-		fmt.Println("⚠️  Warning: Only one node has GoProxLB running - no redundancy")
+		logf("⚠️  Warning: Only one node has GoProxLB running - no redundancy\n")
 	}
 
 	// Warn if even number of nodes (split-brain risk)
 	if goproxlbNodes%2 == 0 {
-		fmt.Printf("⚠️  Warning: Even number of GoProxLB nodes (%d) - consider adding one more for optimal quorum\n", goproxlbNodes)
+		logf("⚠️  Warning: Even number of GoProxLB nodes (%d) - consider adding one more for optimal quorum\n", goproxlbNodes)
 	}
 
 	return nil
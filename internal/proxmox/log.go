@@ -0,0 +1,16 @@
+package proxmox
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// logOutput receives the client's diagnostics, like skipped lookups and retried requests.
+// It is stderr, so commands printing a document on stdout stay parseable.
+var logOutput io.Writer = os.Stderr
+
+// logf writes a diagnostic line to the client's log output.
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(logOutput, format, args...)
+}
@@ -0,0 +1,78 @@
+package proxmox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hostPCIKeyPattern matches the configuration keys holding PCI passthrough devices.
+var hostPCIKeyPattern = regexp.MustCompile(`^hostpci\d+$`)
+
+// parsePassthrough reads the PCI passthrough devices of a VM configuration: the resource mappings
+// they use (e.g. "hostpci0": "mapping=gpu,pcie=1"), and whether any is a raw host PCI address
+// (e.g. "0000:01:00.0,pcie=1"), which pins the VM to its node. Mappings are sorted.
+func parsePassthrough(settings map[string]interface{}) (mappings []string, pinned bool) {
+	seen := make(map[string]bool)
+	for key, setting := range settings {
+		value, ok := setting.(string)
+		if !ok || !hostPCIKeyPattern.MatchString(key) {
+			continue
+		}
+		device, _, _ := strings.Cut(value, ",")
+		if mapping, found := strings.CutPrefix(device, "mapping="); found {
+			if !seen[mapping] {
+				seen[mapping] = true
+				mappings = append(mappings, mapping)
+			}
+			continue
+		}
+		pinned = true
+	}
+	sort.Strings(mappings)
+	return mappings, pinned
+}
+
+// getPCIMappings retrieves the cluster's PCI resource mappings: node name -> mappings with a
+// device on the node, sorted.
+func (c *Client) getPCIMappings() (map[string][]string, error) {
+	resp, err := c.request("GET", "/api2/json/cluster/mapping/pci", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PCI mappings: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("PCI mappings request failed with status %d", resp.StatusCode)
+	}
+
+	var mappingsResp struct {
+		Data []struct {
+			ID  string   `json:"id"`
+			Map []string `json:"map"` // One entry per node, e.g. "node=pve1,path=0000:01:00.0,id=10de:2204"
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&mappingsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode PCI mappings: %w", err)
+	}
+
+	mappings := make(map[string][]string)
+	for _, mapping := range mappingsResp.Data {
+		nodes := make(map[string]bool)
+		for _, entry := range mapping.Map {
+			for _, field := range strings.Split(entry, ",") {
+				if node, found := strings.CutPrefix(field, "node="); found && !nodes[node] {
+					nodes[node] = true
+					mappings[node] = append(mappings[node], mapping.ID)
+				}
+			}
+		}
+	}
+	for node := range mappings {
+		sort.Strings(mappings[node])
+	}
+	return mappings, nil
+}
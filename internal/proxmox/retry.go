@@ -0,0 +1,71 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+)
+
+// RetryError is returned when a request still fails after all its attempts.
+type RetryError struct {
+	Method   string
+	Path     string
+	Attempts int
+	Err      error // Failure of the last attempt
+}
+
+// Error describes the exhausted request and its last failure.
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%s %s failed after %d attempts: %v", e.Method, e.Path, e.Attempts, e.Err)
+}
+
+// Unwrap returns the failure of the last attempt.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryPolicy is how often and how patiently the client retries a failed request.
+type retryPolicy struct {
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+// newRetryPolicy builds the retry policy from the configuration; delays are validated at load time.
+func newRetryPolicy(cfg config.RetryConfig) retryPolicy {
+	baseDelay, _ := cfg.GetBaseDelay() //nolint:errcheck // validated at load time
+	maxDelay, _ := cfg.GetMaxDelay()   //nolint:errcheck // validated at load time
+	return retryPolicy{attempts: max(cfg.MaxAttempts, 1), baseDelay: baseDelay, maxDelay: maxDelay}
+}
+
+// delay returns the wait before the given retry (1 for the first), doubling from the base delay.
+func (p retryPolicy) delay(retry int) time.Duration {
+	delay := p.baseDelay
+	for i := 1; i < retry && (p.maxDelay <= 0 || delay < p.maxDelay); i++ {
+		delay *= 2
+	}
+	if p.maxDelay > 0 {
+		delay = min(delay, p.maxDelay)
+	}
+	return delay
+}
+
+// idempotent reports whether a request may run twice without harm.
+func idempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// retryableStatus reports whether a response status is worth retrying: server errors and rate limiting.
+func retryableStatus(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
+
+// dialFailed reports whether the request failed before reaching the server, so it surely didn't run.
+func dialFailed(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,205 @@
+package proxmox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// thinStorageTypes are the storage types whose volumes are thin provisioned.
+var thinStorageTypes = map[string]bool{
+	"lvmthin": true,
+}
+
+// sharedStorageTypes are the storage types reachable from every node, whatever their shared flag.
+var sharedStorageTypes = map[string]bool{
+	"nfs":         true,
+	"cifs":        true,
+	"glusterfs":   true,
+	"cephfs":      true,
+	"rbd":         true,
+	"iscsi":       true,
+	"iscsidirect": true,
+}
+
+// diskKeyPattern matches the configuration keys holding VM and container disks.
+var diskKeyPattern = regexp.MustCompile(`^((ide|sata|scsi|virtio|efidisk|tpmstate|mp|unused)\d+|rootfs)$`)
+
+// nodeStorage is a storage as seen from a node.
+type nodeStorage struct {
+	Type    string
+	Shared  bool
+	Content string
+	Total   int64
+	Used    int64
+	Avail   int64
+}
+
+// holdsLocalDisks reports whether VM disks on the storage are local to the node, and so are
+// copied to the target when the VM migrates. Network storages count as shared by their type.
+func (s nodeStorage) holdsLocalDisks() bool {
+	shared := s.Shared || sharedStorageTypes[s.Type]
+	return !shared && (strings.Contains(s.Content, "images") || strings.Contains(s.Content, "rootdir"))
+}
+
+// diskVolume is a disk of a VM configuration.
+type diskVolume struct {
+	Storage string
+	Volume  string
+	Format  string
+	Size    int64
+}
+
+// getNodeStorages retrieves the active storages of a node, by name.
+func (c *Client) getNodeStorages(nodeName string) (map[string]nodeStorage, error) {
+	resp, err := c.request("GET", fmt.Sprintf("/api2/json/nodes/%s/storage", nodeName), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storages: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body cleanup, error not actionable
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("storages request failed with status %d", resp.StatusCode)
+	}
+
+	var storagesResp struct {
+		Data []struct {
+			Storage string `json:"storage"`
+			Type    string `json:"type"`
+			Shared  int    `json:"shared"`
+			Content string `json:"content"`
+			Active  int    `json:"active"`
+			Total   int64  `json:"total"`
+			Used    int64  `json:"used"`
+			Avail   int64  `json:"avail"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&storagesResp); err != nil {
+		return nil, fmt.Errorf("failed to decode storages: %w", err)
+	}
+
+	storages := make(map[string]nodeStorage)
+	for _, storage := range storagesResp.Data {
+		if storage.Active == 0 {
+			continue
+		}
+		storages[storage.Storage] = nodeStorage{
+			Type:    storage.Type,
+			Shared:  storage.Shared != 0,
+			Content: storage.Content,
+			Total:   storage.Total,
+			Used:    storage.Used,
+			Avail:   storage.Avail,
+		}
+	}
+	return storages, nil
+}
+
+// localStorageInfo sums the storages holding local VM disks, the space migrations take on a node.
+func localStorageInfo(storages map[string]nodeStorage) models.StorageInfo {
+	var info models.StorageInfo
+	for _, storage := range storages {
+		if !storage.holdsLocalDisks() {
+			continue
+		}
+		info.Total += storage.Total
+		info.Used += storage.Used
+		info.Free += storage.Avail
+	}
+	if info.Total > 0 {
+		info.Usage = float32(float64(info.Used) / float64(info.Total) * 100)
+	}
+	return info
+}
+
+// parseDiskVolumes reads the disks of a VM configuration (e.g. "scsi0": "local-lvm:vm-100-disk-0,size=32G").
+// CD-ROM drives and empty drives are left out. Disks are sorted by configuration key.
+func parseDiskVolumes(settings map[string]interface{}) []diskVolume {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		if diskKeyPattern.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var disks []diskVolume
+	for _, key := range keys {
+		value, ok := settings[key].(string)
+		if !ok {
+			continue
+		}
+		fields := strings.Split(value, ",")
+		storage, volume, found := strings.Cut(fields[0], ":")
+		if !found || fields[0] == "none" {
+			continue
+		}
+
+		disk := diskVolume{Storage: storage, Volume: volume}
+		cdrom := false
+		for _, field := range fields[1:] {
+			option, optionValue, _ := strings.Cut(field, "=")
+			switch option {
+			case "media":
+				cdrom = optionValue == "cdrom"
+			case "size":
+				disk.Size = parseDiskSize(optionValue)
+			case "format":
+				disk.Format = optionValue
+			}
+		}
+		if cdrom {
+			continue
+		}
+		if disk.Format == "" && strings.HasSuffix(volume, ".qcow2") {
+			disk.Format = "qcow2"
+		}
+		disks = append(disks, disk)
+	}
+	return disks
+}
+
+// parseDiskSize converts a disk size property (e.g. "32G", "512M", "1T" or bytes) to bytes. It is 0 when invalid.
+func parseDiskSize(size string) int64 {
+	units := map[byte]int64{'K': 1 << 10, 'M': 1 << 20, 'G': 1 << 30, 'T': 1 << 40}
+	multiplier := int64(1)
+	if len(size) > 0 {
+		if unit, ok := units[size[len(size)-1]]; ok {
+			multiplier = unit
+			size = size[:len(size)-1]
+		}
+	}
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return int64(value * float64(multiplier))
+}
+
+// applyDiskInfo sets the size and provisioning of the VM's disks on local storages; disks on
+// shared storages don't move with the VM. Thick disks are allocated in full; thin disks (on
+// thin storages, or qcow2 images) may grow to their configured size.
+func applyDiskInfo(vm *models.VM, disks []diskVolume, storages map[string]nodeStorage) {
+	var allocated int64
+	for _, disk := range disks {
+		storage, known := storages[disk.Storage]
+		if !known || !storage.holdsLocalDisks() {
+			continue
+		}
+		vm.MaxDisk += disk.Size
+		if thinStorageTypes[storage.Type] || disk.Format == "qcow2" {
+			vm.ThinDisk = true
+		} else {
+			allocated += disk.Size
+		}
+	}
+	if allocated > vm.Disk {
+		vm.Disk = allocated
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package raft
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errLocked is returned when the lock is held by another process.
+var errLocked = errors.New("lock held by another process")
+
+// lockFile opens path and takes an exclusive advisory lock without blocking.
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600) //nolint:gosec // path is built from the configured data directory
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = file.Close() //nolint:errcheck // cleanup operation, error not actionable
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// unlockFile releases the advisory lock on file.
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
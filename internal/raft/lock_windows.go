@@ -0,0 +1,44 @@
+//go:build windows
+
+package raft
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errLocked is returned when the lock is held by another process.
+var errLocked = errors.New("lock held by another process")
+
+// errorSharingViolation is the Windows ERROR_SHARING_VIOLATION code.
+const errorSharingViolation syscall.Errno = 32
+
+// lockFile opens path without sharing, which Windows enforces as an exclusive lock.
+func lockFile(path string) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+		0, // no sharing
+		nil,
+		syscall.OPEN_ALWAYS,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0)
+	if err != nil {
+		if errors.Is(err, errorSharingViolation) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(handle), path), nil
+}
+
+// unlockFile is a no-op on Windows, the lock is dropped when the handle is closed.
+func unlockFile(_ *os.File) error {
+	return nil
+}
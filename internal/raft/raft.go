@@ -29,6 +29,7 @@ type RaftNode struct {
 	peers      []RaftPeer
 	leaderChan chan bool
 	shutdownCh chan struct{}
+	dirLock    *DataDirLock
 }
 
 // NewRaftNode creates a new Raft node for leader election (backward compatibility).
@@ -45,11 +46,17 @@ func NewRaftNode(nodeID, address, dataDir string, peers []string) (*RaftNode, er
 }
 
 // NewRaftNodeWithPeers creates a new Raft node with proper peer information.
-func NewRaftNodeWithPeers(nodeID, address, dataDir string, peers []RaftPeer) (*RaftNode, error) {
-	// Create data directory if it doesn't exist
-	if err := os.MkdirAll(dataDir, 0750); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %w", err)
+func NewRaftNodeWithPeers(nodeID, address, dataDir string, peers []RaftPeer) (node *RaftNode, err error) {
+	// Lock the data directory (creating it if needed) so a second instance fails fast
+	dirLock, err := AcquireDataDirLock(dataDir)
+	if err != nil {
+		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			_ = dirLock.Release() //nolint:errcheck // cleanup operation, error not actionable
+		}
+	}()
 
 	// Create Raft configuration
 	config := raft.DefaultConfig()
@@ -153,6 +160,7 @@ func NewRaftNodeWithPeers(nodeID, address, dataDir string, peers []RaftPeer) (*R
 		peers:      peers,
 		leaderChan: make(chan bool, 1),
 		shutdownCh: make(chan struct{}),
+		dirLock:    dirLock,
 	}, nil
 }
 
@@ -194,12 +202,18 @@ func (r *RaftNode) Stop() error {
 	}()
 
 	// Wait for shutdown to complete or timeout
+	var err error
 	select {
-	case err := <-errChan:
-		return err
+	case err = <-errChan:
 	case <-ctx.Done():
-		return fmt.Errorf("raft shutdown timeout")
+		err = fmt.Errorf("raft shutdown timeout")
+	}
+
+	// Release the data directory for the next instance
+	if releaseErr := r.dirLock.Release(); releaseErr != nil && err == nil {
+		err = releaseErr
 	}
+	return err
 }
 
 // IsLeader returns true if this node is the current leader.
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -502,3 +503,67 @@ func TestRaftNodeDataDirectoryCreation(t *testing.T) {
 		t.Errorf("Data directory was not created: %s", dataDir)
 	}
 }
+
+func TestDataDirLock(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "locked")
+
+	lock, err := AcquireDataDirLock(dataDir)
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+
+	// A second instance must fail fast with a clear message
+	if _, err := AcquireDataDirLock(dataDir); err == nil {
+		t.Fatal("Expected second lock acquisition to fail")
+	} else if !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("Expected 'already in use' error, got: %v", err)
+	}
+
+	if !HoldsDataDirLock(dataDir) {
+		t.Error("Expected the process to hold the data directory lock")
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Failed to release lock: %v", err)
+	}
+	if HoldsDataDirLock(dataDir) {
+		t.Error("Expected the released lock not to be held")
+	}
+
+	// Releasing twice is harmless
+	if err := lock.Release(); err != nil {
+		t.Errorf("Expected second release to be a no-op, got: %v", err)
+	}
+
+	// Once released the directory can be locked again
+	relock, err := AcquireDataDirLock(dataDir)
+	if err != nil {
+		t.Fatalf("Expected lock to be acquirable after release: %v", err)
+	}
+	_ = relock.Release()
+}
+
+func TestRaftNodeDataDirLocked(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "shared")
+
+	node, err := NewRaftNode("node1", "127.0.0.1:8096", dataDir, []string{})
+	if err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+
+	// A second instance on the same data directory must not start
+	if _, err := NewRaftNode("node2", "127.0.0.1:8097", dataDir, []string{}); err == nil {
+		t.Fatal("Expected second node on the same data directory to fail")
+	}
+
+	// Stopping the first node releases the lock
+	if err := node.Stop(); err != nil {
+		t.Fatalf("Failed to stop node: %v", err)
+	}
+
+	lock, err := AcquireDataDirLock(dataDir)
+	if err != nil {
+		t.Fatalf("Expected lock to be released on shutdown: %v", err)
+	}
+	_ = lock.Release()
+}
@@ -0,0 +1,21 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// classTagPrefix sets a VM's workload class explicitly, e.g. plb_class_db, instead of inferring it.
+const classTagPrefix = "plb_class_"
+
+// WorkloadClass returns the class named by a VM's plb_class_ tag, or "" when it has none.
+// The first class tag wins.
+func WorkloadClass(vm *models.VM) string {
+	for _, tag := range vm.Tags {
+		if strings.HasPrefix(tag, classTagPrefix) {
+			return strings.ToLower(strings.TrimPrefix(tag, classTagPrefix))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// dependsTagPrefix declares that a VM depends on another one, by VMID: plb_depends_on_101 on an
+// application server using database VM 101. When draining a node, dependencies move first.
+const dependsTagPrefix = "plb_depends_on_"
+
+// addDependencyRule records the VM a dependency tag points to.
+func (e *Engine) addDependencyRule(vm *models.VM, tag string) {
+	dependency, err := strconv.Atoi(strings.TrimPrefix(tag, dependsTagPrefix))
+	if err != nil || dependency <= 0 || dependency == vm.ID {
+		e.tagConflicts = append(e.tagConflicts, models.RuleConflict{
+			Type:    ConflictInvalidDependency,
+			VMIDs:   []int{vm.ID},
+			Message: fmt.Sprintf("VM %s has tag %s: expected the VMID of another VM", vm.Name, tag),
+		})
+		return
+	}
+	e.dependencies[vm.ID] = append(e.dependencies[vm.ID], dependency)
+}
+
+// Dependencies returns the VMIDs a VM depends on (plb_depends_on_ tags).
+func (e *Engine) Dependencies(vmID int) []int {
+	return e.dependencies[vmID]
+}
@@ -3,7 +3,9 @@ package rules
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/cblomart/GoProxLB/internal/models"
 )
@@ -14,8 +16,23 @@ type Engine struct {
 	antiAffinityGroups map[string]*models.AntiAffinityGroup
 	pinnedVMs          map[int]*models.PinnedVM
 	ignoredVMs         map[int]*models.IgnoredVM
+	excludedVMIDs      map[int]bool
+	nodeZones          map[string]string // Fault domain of each node
+	preferredNodes     map[int][]string  // Soft placement hints, unlike pinning
+	frozenVMs          map[int][]freezeWindow
+	minSpread          map[string]int             // Minimum distinct nodes of relaxed anti-affinity groups
+	duplicateVMIDs     map[int][]string           // Nodes of VMIDs listed more than once
+	tagConflicts       []models.RuleConflict      // Freeze, spread, dependency and weight tags that failed to parse
+	dependencies       map[int][]int              // VMIDs each VM depends on
+	nodePCIMappings    map[string]map[string]bool // PCI resource mappings with a device on each node
 }
 
+// ExcludedByConfigTag is the ignore tag recorded for VMs excluded through configuration.
+const ExcludedByConfigTag = "config"
+
+// DuplicateVMIDTag is the ignore tag recorded for VMs whose VMID appears more than once.
+const DuplicateVMIDTag = "duplicate"
+
 // NewEngine creates a new rules engine.
 func NewEngine() *Engine {
 	return &Engine{
@@ -23,6 +40,31 @@ func NewEngine() *Engine {
 		antiAffinityGroups: make(map[string]*models.AntiAffinityGroup),
 		pinnedVMs:          make(map[int]*models.PinnedVM),
 		ignoredVMs:         make(map[int]*models.IgnoredVM),
+		excludedVMIDs:      make(map[int]bool),
+		nodeZones:          make(map[string]string),
+		preferredNodes:     make(map[int][]string),
+		frozenVMs:          make(map[int][]freezeWindow),
+		minSpread:          make(map[string]int),
+		duplicateVMIDs:     make(map[int][]string),
+		dependencies:       make(map[int][]int),
+	}
+}
+
+// SetExcludedVMIDs sets VM IDs that are ignored regardless of their tags.
+// It takes effect on the next ProcessVMs call.
+func (e *Engine) SetExcludedVMIDs(vmIDs []int) {
+	e.excludedVMIDs = make(map[int]bool, len(vmIDs))
+	for _, vmID := range vmIDs {
+		e.excludedVMIDs[vmID] = true
+	}
+}
+
+// SetNodeZones sets the fault domain (zone) of each node.
+// Anti-affinity groups are then spread across zones when possible, not just across nodes.
+func (e *Engine) SetNodeZones(nodeZones map[string]string) {
+	e.nodeZones = make(map[string]string, len(nodeZones))
+	for node, zone := range nodeZones {
+		e.nodeZones[node] = zone
 	}
 }
 
@@ -32,15 +74,55 @@ func (e *Engine) ProcessVMs(vms []models.VM) error {
 	e.antiAffinityGroups = make(map[string]*models.AntiAffinityGroup)
 	e.pinnedVMs = make(map[int]*models.PinnedVM)
 	e.ignoredVMs = make(map[int]*models.IgnoredVM)
+	e.preferredNodes = make(map[int][]string)
+	e.frozenVMs = make(map[int][]freezeWindow)
+	e.minSpread = make(map[string]int)
+	e.dependencies = make(map[int][]int)
+	e.tagConflicts = nil
+	e.duplicateVMIDs = findDuplicateVMIDs(vms)
 
 	for i := range vms {
 		vm := &vms[i]
+
+		// A VMID seen twice is ambiguous: its rules would collide, so its VMs are left alone
+		if e.IsDuplicate(vm.ID) {
+			e.addIgnoreRule(vm, "plb_ignore_"+DuplicateVMIDTag)
+			continue
+		}
+
 		e.processVM(vm)
+
+		// Merge config exclusions into the tag-based ignore set
+		if e.excludedVMIDs[vm.ID] {
+			e.addIgnoreRule(vm, "plb_ignore_"+ExcludedByConfigTag)
+		}
 	}
 
 	return nil
 }
 
+// findDuplicateVMIDs returns the nodes listing each VMID that appears more than once.
+func findDuplicateVMIDs(vms []models.VM) map[int][]string {
+	nodes := make(map[int][]string, len(vms))
+	for i := range vms {
+		nodes[vms[i].ID] = append(nodes[vms[i].ID], vms[i].Node)
+	}
+
+	duplicates := make(map[int][]string)
+	for vmID, vmNodes := range nodes {
+		if len(vmNodes) > 1 {
+			duplicates[vmID] = vmNodes
+		}
+	}
+	return duplicates
+}
+
+// IsDuplicate checks if a VMID appears more than once in the cluster, e.g. after a botched restore.
+func (e *Engine) IsDuplicate(vmID int) bool {
+	_, exists := e.duplicateVMIDs[vmID]
+	return exists
+}
+
 // processVM processes a single VM and extracts its rules.
 func (e *Engine) processVM(vm *models.VM) {
 	for _, tag := range vm.Tags {
@@ -55,6 +137,16 @@ func (e *Engine) processVM(vm *models.VM) {
 			e.addPinningRule(vm, tag)
 		case strings.HasPrefix(tag, "plb_ignore_"):
 			e.addIgnoreRule(vm, tag)
+		case strings.HasPrefix(tag, "plb_prefer_"):
+			e.addPreferenceRule(vm, tag)
+		case strings.HasPrefix(tag, freezeTagPrefix):
+			e.addFreezeRule(vm, tag)
+		case strings.HasPrefix(tag, spreadTagPrefix):
+			e.addSpreadRule(vm, tag)
+		case strings.HasPrefix(tag, dependsTagPrefix):
+			e.addDependencyRule(vm, tag)
+		case strings.HasPrefix(tag, weightTagPrefix):
+			e.addWeightRule(vm, tag)
 		}
 	}
 }
@@ -134,6 +226,32 @@ func (e *Engine) addPinningRule(vm *models.VM, tag string) {
 	}
 }
 
+// addPreferenceRule records a node the VM should preferably run on.
+func (e *Engine) addPreferenceRule(vm *models.VM, tag string) {
+	nodeName := strings.TrimPrefix(tag, "plb_prefer_")
+	for _, node := range e.preferredNodes[vm.ID] {
+		if node == nodeName {
+			return
+		}
+	}
+	e.preferredNodes[vm.ID] = append(e.preferredNodes[vm.ID], nodeName)
+}
+
+// addFreezeRule records a daily window during which the VM must not be migrated.
+// Malformed windows are reported by DetectConflicts rather than guessed at.
+func (e *Engine) addFreezeRule(vm *models.VM, tag string) {
+	window, err := parseFreezeWindow(strings.TrimPrefix(tag, freezeTagPrefix))
+	if err != nil {
+		e.tagConflicts = append(e.tagConflicts, models.RuleConflict{
+			Type:    ConflictInvalidFreeze,
+			VMIDs:   []int{vm.ID},
+			Message: fmt.Sprintf("VM %s has tag %s: %v", vm.Name, tag, err),
+		})
+		return
+	}
+	e.frozenVMs[vm.ID] = append(e.frozenVMs[vm.ID], window)
+}
+
 // addIgnoreRule adds a VM to the ignored VMs list.
 func (e *Engine) addIgnoreRule(vm *models.VM, tag string) {
 	ignoreTag := strings.TrimPrefix(tag, "plb_ignore_")
@@ -154,6 +272,16 @@ func (e *Engine) IsIgnored(vmID int) bool {
 	return exists
 }
 
+// IsFrozen checks if a VM is inside one of its freeze windows at the given time.
+func (e *Engine) IsFrozen(vmID int, now time.Time) bool {
+	for _, window := range e.frozenVMs[vmID] {
+		if window.contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsPinned checks if a VM is pinned to specific nodes.
 func (e *Engine) IsPinned(vmID int) bool {
 	_, exists := e.pinnedVMs[vmID]
@@ -183,6 +311,21 @@ func (e *Engine) GetPinnedVMs() map[int]*models.PinnedVM {
 	return e.pinnedVMs
 }
 
+// GetPreferredNodes returns the nodes a VM prefers, in tag order. Other nodes remain valid targets.
+func (e *Engine) GetPreferredNodes(vmID int) []string {
+	return e.preferredNodes[vmID]
+}
+
+// IsPreferredNode checks if a node is one of the VM's preferred nodes.
+func (e *Engine) IsPreferredNode(vmID int, nodeName string) bool {
+	for _, node := range e.preferredNodes[vmID] {
+		if node == nodeName {
+			return true
+		}
+	}
+	return false
+}
+
 // GetIgnoredVMs returns all ignored VMs.
 func (e *Engine) GetIgnoredVMs() map[int]*models.IgnoredVM {
 	return e.ignoredVMs
@@ -206,6 +349,10 @@ func (e *Engine) ValidatePlacement(vm *models.VM, targetNode string) error {
 		return err
 	}
 
+	if err := e.validatePassthroughRules(vm, targetNode); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -219,13 +366,33 @@ func (e *Engine) GetValidTargetNodes(vm *models.VM, availableNodes []string) []s
 		}
 	}
 
-	return validNodes
+	return e.preferSpreadZones(vm, e.preferSpreadNodes(vm, validNodes))
+}
+
+// preferSpreadZones narrows target nodes to zones holding no other member of the VM's
+// anti-affinity groups. When every zone is taken it falls back to the node-level spread.
+func (e *Engine) preferSpreadZones(vm *models.VM, nodes []string) []string {
+	if len(e.nodeZones) == 0 {
+		return nodes
+	}
+
+	var spread []string
+	for _, node := range nodes {
+		if !e.zoneHasAntiAffinityPeer(vm, node) {
+			spread = append(spread, node)
+		}
+	}
+
+	if len(spread) == 0 {
+		return nodes
+	}
+	return spread
 }
 
 // validateIgnoreRules validates if a VM is ignored.
 func (e *Engine) validateIgnoreRules(vm *models.VM) error {
 	if e.IsIgnored(vm.ID) {
-		return fmt.Errorf("VM %s is ignored and cannot be moved", vm.Name)
+		return &PlacementError{Rule: RuleIgnore, Message: fmt.Sprintf("VM %s is ignored and cannot be moved", vm.Name)}
 	}
 	return nil
 }
@@ -243,7 +410,11 @@ func (e *Engine) validatePinningRules(vm *models.VM, targetNode string) error {
 		}
 	}
 
-	return fmt.Errorf("VM %s is pinned to nodes %v, cannot move to %s", vm.Name, pinnedNodes, targetNode)
+	return &PlacementError{
+		Rule:    RulePin,
+		Subject: strings.Join(pinnedNodes, ", "),
+		Message: fmt.Sprintf("VM %s is pinned to nodes %v, cannot move to %s", vm.Name, pinnedNodes, targetNode),
+	}
 }
 
 // validateAffinityRules validates affinity rules for VM placement.
@@ -297,7 +468,11 @@ func (e *Engine) checkAffinityConstraints(vm *models.VM, targetNode string, grou
 	for k := range group.VMs {
 		otherVM := &group.VMs[k]
 		if otherVM.ID != vm.ID && otherVM.Node != targetNode {
-			return fmt.Errorf("VM %s is part of affinity group %s, but no other VMs in the group are on %s", vm.Name, group.Tag, targetNode)
+			return &PlacementError{
+				Rule:    RuleAffinity,
+				Subject: group.Tag,
+				Message: fmt.Sprintf("VM %s is part of affinity group %s, but no other VMs in the group are on %s", vm.Name, group.Tag, targetNode),
+			}
 		}
 	}
 
@@ -316,13 +491,276 @@ func (e *Engine) findVMInAntiAffinityGroup(vmID int, group *models.AntiAffinityG
 }
 
 // checkAntiAffinityConstraints checks if a VM can be placed on a target node based on anti-affinity rules.
+// A group with a minimum spread may share nodes as long as it still occupies enough of them.
 func (e *Engine) checkAntiAffinityConstraints(vm *models.VM, targetNode string, group *models.AntiAffinityGroup) error {
 	// Check if any other VM in the group is on the target node
 	for j := range group.VMs {
 		otherVM := &group.VMs[j]
 		if otherVM.ID != vm.ID && otherVM.Node == targetNode {
-			return fmt.Errorf("VM %s is part of anti-affinity group %s, but another VM in the group is already on %s", vm.Name, group.Tag, targetNode)
+			if minNodes := e.MinSpread(group.Tag); minNodes > 0 {
+				if spread := spreadWith(vm, targetNode, group); spread < minNodes {
+					return &PlacementError{
+						Rule:    RuleAntiAffinity,
+						Subject: group.Tag,
+						Message: fmt.Sprintf("VM %s is part of anti-affinity group %s, which would only span %d of at least %d nodes with it on %s",
+							vm.Name, group.Tag, spread, minNodes, targetNode),
+					}
+				}
+				return nil
+			}
+			return &PlacementError{
+				Rule:    RuleAntiAffinity,
+				Subject: group.Tag,
+				Message: fmt.Sprintf("VM %s is part of anti-affinity group %s, but another VM in the group is already on %s", vm.Name, group.Tag, targetNode),
+			}
 		}
 	}
 	return nil
 }
+
+// zoneHasAntiAffinityPeer reports whether the target node's zone already hosts another
+// member of one of the VM's anti-affinity groups. Nodes without a zone never match.
+func (e *Engine) zoneHasAntiAffinityPeer(vm *models.VM, targetNode string) bool {
+	zone, exists := e.nodeZones[targetNode]
+	if !exists {
+		return false
+	}
+
+	for _, group := range e.antiAffinityGroups {
+		if e.findVMInAntiAffinityGroup(vm.ID, group) == nil {
+			continue
+		}
+		for j := range group.VMs {
+			otherVM := &group.VMs[j]
+			if otherVM.ID != vm.ID && e.nodeZones[otherVM.Node] == zone {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Rule conflict types reported by DetectConflicts.
+const (
+	ConflictPinUnavailable       = "pin_unavailable"
+	ConflictAffinityPin          = "affinity_pin"
+	ConflictAntiAffinityCapacity = "anti_affinity_capacity"
+	ConflictAntiAffinityPin      = "anti_affinity_pin"
+	ConflictAffinityAntiAffinity = "affinity_anti_affinity"
+	ConflictInvalidFreeze        = "invalid_freeze"
+	ConflictInvalidSpread        = "invalid_spread"
+	ConflictDuplicateVMID        = "duplicate_vmid"
+	ConflictInvalidDependency    = "invalid_dependency"
+	ConflictInvalidWeight        = "invalid_weight"
+)
+
+// DetectConflicts checks the processed rules for contradictory or unsatisfiable combinations.
+// It must be called after ProcessVMs; availableNodes are the nodes VMs may be placed on.
+func (e *Engine) DetectConflicts(availableNodes []string) []models.RuleConflict {
+	var conflicts []models.RuleConflict
+
+	conflicts = append(conflicts, e.detectPinConflicts(availableNodes)...)
+	conflicts = append(conflicts, e.detectAffinityConflicts()...)
+	conflicts = append(conflicts, e.detectAntiAffinityConflicts(availableNodes)...)
+	conflicts = append(conflicts, e.detectMixedAffinityConflicts()...)
+	conflicts = append(conflicts, e.tagConflicts...)
+	conflicts = append(conflicts, e.detectDuplicateConflicts()...)
+
+	return conflicts
+}
+
+// detectDuplicateConflicts reports the VMIDs present more than once, whose VMs are left in place.
+func (e *Engine) detectDuplicateConflicts() []models.RuleConflict {
+	conflicts := make([]models.RuleConflict, 0, len(e.duplicateVMIDs))
+	for _, vmID := range sortedVMIDs(e.duplicateVMIDs) {
+		conflicts = append(conflicts, models.RuleConflict{
+			Type:    ConflictDuplicateVMID,
+			VMIDs:   []int{vmID},
+			Message: fmt.Sprintf("VMID %d is listed on nodes %v, its VMs are left in place until the duplicate is resolved", vmID, e.duplicateVMIDs[vmID]),
+		})
+	}
+	return conflicts
+}
+
+// detectPinConflicts finds VMs pinned only to nodes that are not available.
+func (e *Engine) detectPinConflicts(availableNodes []string) []models.RuleConflict {
+	var conflicts []models.RuleConflict
+
+	for _, vmID := range sortedVMIDs(e.pinnedVMs) {
+		pinned := e.pinnedVMs[vmID]
+		if len(intersectNodes(pinned.Nodes, availableNodes)) == 0 {
+			conflicts = append(conflicts, models.RuleConflict{
+				Type:    ConflictPinUnavailable,
+				VMIDs:   []int{vmID},
+				Message: fmt.Sprintf("VM %s is pinned to nodes %v, none of which are available", pinned.VM.Name, pinned.Nodes),
+			})
+		}
+	}
+
+	return conflicts
+}
+
+// detectAffinityConflicts finds affinity groups whose members are pinned to disjoint nodes.
+func (e *Engine) detectAffinityConflicts() []models.RuleConflict {
+	var conflicts []models.RuleConflict
+
+	for _, tag := range sortedGroupTags(e.affinityGroups) {
+		group := e.affinityGroups[tag]
+
+		var allowed []string
+		var pinnedIDs []int
+		for i := range group.VMs {
+			vm := &group.VMs[i]
+			if !e.IsPinned(vm.ID) {
+				continue
+			}
+			if pinnedIDs == nil {
+				allowed = e.GetPinnedNodes(vm.ID)
+			} else {
+				allowed = intersectNodes(allowed, e.GetPinnedNodes(vm.ID))
+			}
+			pinnedIDs = append(pinnedIDs, vm.ID)
+		}
+
+		if len(pinnedIDs) > 1 && len(allowed) == 0 {
+			conflicts = append(conflicts, models.RuleConflict{
+				Type:    ConflictAffinityPin,
+				Group:   tag,
+				VMIDs:   pinnedIDs,
+				Message: fmt.Sprintf("affinity group %s has members pinned to disjoint nodes, they can never be placed together", tag),
+			})
+		}
+	}
+
+	return conflicts
+}
+
+// detectAntiAffinityConflicts finds anti-affinity groups that cannot be spread over the available nodes.
+func (e *Engine) detectAntiAffinityConflicts(availableNodes []string) []models.RuleConflict {
+	var conflicts []models.RuleConflict
+
+	for _, tag := range sortedGroupTags(e.antiAffinityGroups) {
+		group := e.antiAffinityGroups[tag]
+
+		// A relaxed group only needs its minimum spread
+		if minNodes := e.MinSpread(tag); minNodes > 0 {
+			if minNodes > len(availableNodes) {
+				conflicts = append(conflicts, models.RuleConflict{
+					Type:  ConflictAntiAffinityCapacity,
+					Group: tag,
+					VMIDs: groupVMIDs(group.VMs),
+					Message: fmt.Sprintf("anti-affinity group %s must span %d nodes but only %d nodes are available",
+						tag, minNodes, len(availableNodes)),
+				})
+			}
+		} else if len(group.VMs) > len(availableNodes) {
+			conflicts = append(conflicts, models.RuleConflict{
+				Type:  ConflictAntiAffinityCapacity,
+				Group: tag,
+				VMIDs: groupVMIDs(group.VMs),
+				Message: fmt.Sprintf("anti-affinity group %s has %d VMs but only %d nodes are available",
+					tag, len(group.VMs), len(availableNodes)),
+			})
+		}
+
+		// Members pinned to the same single node can never be separated
+		singlePinned := make(map[string][]int)
+		for i := range group.VMs {
+			vm := &group.VMs[i]
+			if nodes := e.GetPinnedNodes(vm.ID); len(nodes) == 1 {
+				singlePinned[nodes[0]] = append(singlePinned[nodes[0]], vm.ID)
+			}
+		}
+		nodeNames := make([]string, 0, len(singlePinned))
+		for node := range singlePinned {
+			nodeNames = append(nodeNames, node)
+		}
+		sort.Strings(nodeNames)
+		for _, node := range nodeNames {
+			if vmIDs := singlePinned[node]; len(vmIDs) > 1 {
+				conflicts = append(conflicts, models.RuleConflict{
+					Type:    ConflictAntiAffinityPin,
+					Group:   tag,
+					VMIDs:   vmIDs,
+					Message: fmt.Sprintf("anti-affinity group %s has %d VMs pinned only to node %s", tag, len(vmIDs), node),
+				})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// detectMixedAffinityConflicts finds VM pairs that share both an affinity and an anti-affinity group.
+func (e *Engine) detectMixedAffinityConflicts() []models.RuleConflict {
+	var conflicts []models.RuleConflict
+
+	for _, affinityTag := range sortedGroupTags(e.affinityGroups) {
+		affinityGroup := e.affinityGroups[affinityTag]
+		for _, antiTag := range sortedGroupTags(e.antiAffinityGroups) {
+			antiGroup := e.antiAffinityGroups[antiTag]
+
+			var shared []int
+			for i := range affinityGroup.VMs {
+				if e.findVMInAntiAffinityGroup(affinityGroup.VMs[i].ID, antiGroup) != nil {
+					shared = append(shared, affinityGroup.VMs[i].ID)
+				}
+			}
+
+			if len(shared) > 1 {
+				conflicts = append(conflicts, models.RuleConflict{
+					Type:  ConflictAffinityAntiAffinity,
+					Group: affinityTag,
+					VMIDs: shared,
+					Message: fmt.Sprintf("VMs %v are in affinity group %s and anti-affinity group %s at the same time",
+						shared, affinityTag, antiTag),
+				})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// intersectNodes returns the nodes present in both lists.
+func intersectNodes(a, b []string) []string {
+	var result []string
+	for _, nodeA := range a {
+		for _, nodeB := range b {
+			if nodeA == nodeB {
+				result = append(result, nodeA)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// groupVMIDs returns the IDs of the VMs in a group.
+func groupVMIDs(vms []models.VM) []int {
+	ids := make([]int, 0, len(vms))
+	for i := range vms {
+		ids = append(ids, vms[i].ID)
+	}
+	return ids
+}
+
+// sortedVMIDs returns the keys of a VM map in ascending order.
+func sortedVMIDs[T any](m map[int]T) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// sortedGroupTags returns the keys of a group map in ascending order.
+func sortedGroupTags[T any](m map[string]T) []string {
+	tags := make([]string, 0, len(m))
+	for tag := range m {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -1,7 +1,11 @@
 package rules
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cblomart/GoProxLB/internal/models"
 )
@@ -117,6 +121,145 @@ func TestIsIgnored(t *testing.T) {
 	}
 }
 
+func TestExcludedVMIDs(t *testing.T) {
+	engine := NewEngine()
+	engine.SetExcludedVMIDs([]int{2, 3})
+
+	vms := []models.VM{
+		{ID: 1, Name: "vm1", Node: "node1"},
+		{ID: 2, Name: "vm2", Node: "node1"},
+		{ID: 3, Name: "vm3", Node: "node2", Tags: []string{"plb_ignore_dev"}},
+	}
+
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("ProcessVMs failed: %v", err)
+	}
+
+	if engine.IsIgnored(1) {
+		t.Error("Expected VM 1 not to be ignored")
+	}
+	if !engine.IsIgnored(2) {
+		t.Error("Expected VM 2 to be ignored through configuration")
+	}
+	if err := engine.ValidatePlacement(&vms[1], "node2"); err == nil {
+		t.Error("Expected placement of excluded VM 2 to be rejected")
+	}
+
+	// Tag and config exclusions are merged
+	ignored := engine.GetIgnoredVMs()[3]
+	if ignored == nil || len(ignored.Tags) != 2 || ignored.Tags[1] != ExcludedByConfigTag {
+		t.Errorf("Expected VM 3 to carry both tag and config ignore reasons, got %+v", ignored)
+	}
+}
+
+func TestAntiAffinitySpreadsAcrossZones(t *testing.T) {
+	vms := []models.VM{
+		{ID: 1, Name: "web1", Node: "node1", Tags: []string{"plb_anti_affinity_web"}},
+		{ID: 2, Name: "web2", Node: "node3", Tags: []string{"plb_anti_affinity_web"}},
+		{ID: 3, Name: "web3", Node: "node2", Tags: []string{"plb_anti_affinity_web"}},
+	}
+	candidates := []string{"node1", "node3", "node4", "node5", "node6"}
+
+	tests := []struct {
+		name      string
+		nodeZones map[string]string
+		want      []string
+	}{
+		{
+			name:      "no zones keeps node-level spread",
+			nodeZones: nil,
+			want:      []string{"node4", "node5", "node6"},
+		},
+		{
+			name: "prefers a zone without group members",
+			nodeZones: map[string]string{
+				"node1": "rack-a", "node2": "rack-a", "node4": "rack-a",
+				"node3": "rack-b", "node6": "rack-b",
+				"node5": "rack-c",
+			},
+			want: []string{"node5"},
+		},
+		{
+			name: "falls back to node-level spread when every zone is taken",
+			nodeZones: map[string]string{
+				"node1": "rack-a", "node2": "rack-a", "node4": "rack-a",
+				"node3": "rack-b", "node5": "rack-b", "node6": "rack-b",
+			},
+			want: []string{"node4", "node5", "node6"},
+		},
+		{
+			name: "nodes without a zone count as spread",
+			nodeZones: map[string]string{
+				"node1": "rack-a", "node2": "rack-a", "node4": "rack-a",
+				"node3": "rack-b", "node5": "rack-b",
+			},
+			want: []string{"node6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine()
+			engine.SetNodeZones(tt.nodeZones)
+			if err := engine.ProcessVMs(vms); err != nil {
+				t.Fatalf("ProcessVMs failed: %v", err)
+			}
+
+			got := engine.GetValidTargetNodes(&vms[2], candidates)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected targets %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestZonesDoNotAffectUnrelatedVMs(t *testing.T) {
+	engine := NewEngine()
+	engine.SetNodeZones(map[string]string{"node1": "rack-a", "node2": "rack-a", "node3": "rack-b"})
+
+	vms := []models.VM{
+		{ID: 1, Name: "web1", Node: "node1", Tags: []string{"plb_anti_affinity_web"}},
+		{ID: 2, Name: "batch", Node: "node3"},
+	}
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("ProcessVMs failed: %v", err)
+	}
+
+	got := engine.GetValidTargetNodes(&vms[1], []string{"node1", "node2"})
+	if len(got) != 2 {
+		t.Errorf("Expected VMs outside anti-affinity groups to keep every target, got %v", got)
+	}
+}
+
+func TestPassthroughRestrictsTargets(t *testing.T) {
+	engine := NewEngine()
+	engine.SetNodePCIMappings([]models.Node{
+		{Name: "node1", PCIMappings: []string{"gpu"}},
+		{Name: "node2"},
+		{Name: "node3", PCIMappings: []string{"gpu", "nic"}},
+	})
+
+	vms := []models.VM{
+		{ID: 1, Name: "render", Node: "node1", Passthrough: []string{"gpu"}},
+		{ID: 2, Name: "router", Node: "node3", Passthrough: []string{"gpu", "nic"}},
+		{ID: 3, Name: "web", Node: "node1"},
+	}
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("ProcessVMs failed: %v", err)
+	}
+
+	candidates := []string{"node1", "node2", "node3"}
+	if got := engine.GetValidTargetNodes(&vms[0], candidates); !reflect.DeepEqual(got, []string{"node1", "node3"}) {
+		t.Errorf("Expected the GPU VM restricted to GPU nodes, got %v", got)
+	}
+	if got := engine.GetValidTargetNodes(&vms[1], candidates); !reflect.DeepEqual(got, []string{"node3"}) {
+		t.Errorf("Expected the VM needing both devices to stay on node3, got %v", got)
+	}
+	if got := engine.GetValidTargetNodes(&vms[2], candidates); len(got) != 3 {
+		t.Errorf("Expected VMs without passthrough to keep every target, got %v", got)
+	}
+}
+
 func TestIsPinned(t *testing.T) {
 	engine := NewEngine()
 
@@ -272,6 +415,67 @@ func TestValidatePlacement(t *testing.T) {
 	}
 }
 
+func TestGoverningRules(t *testing.T) {
+	engine := NewEngine()
+	vms := []models.VM{
+		{ID: 1, Name: "web1", Node: "node1", Tags: []string{"plb_affinity_web"}},
+		{ID: 2, Name: "web2", Node: "node2", Tags: []string{"plb_affinity_web"}},
+		{ID: 3, Name: "ntp1", Node: "node1", Tags: []string{"plb_anti_affinity_ntp"}},
+		{ID: 4, Name: "ntp2", Node: "node2", Tags: []string{"plb_anti_affinity_ntp"}},
+		{ID: 5, Name: "pinned", Node: "node1", Tags: []string{"plb_pin_node1", "plb_pin_node3"}},
+		{ID: 6, Name: "preferring", Node: "node1", Tags: []string{"plb_prefer_node2"}},
+		{ID: 7, Name: "ignored", Node: "node1", Tags: []string{"plb_ignore_dev"}},
+	}
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	// The rules drawing a VM to its chosen target
+	chosen := []struct {
+		vm     int
+		target string
+		want   []string
+	}{
+		{0, "node2", []string{"affinity consolidation (web)"}},
+		{2, "node3", []string{"anti-affinity spread (ntp)"}},
+		{4, "node3", []string{"pin (node3)"}},
+		{5, "node2", []string{"preference (node2)"}},
+		{5, "node3", nil},
+	}
+	for _, tt := range chosen {
+		if got := engine.GoverningRules(&vms[tt.vm], tt.target); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %s moving to %s governed by %v, got %v", vms[tt.vm].Name, tt.target, tt.want, got)
+		}
+	}
+
+	// The rules refusing a VM its targets
+	rejected := []struct {
+		vm    int
+		nodes []string
+		want  []string
+	}{
+		{0, []string{"node3"}, []string{"affinity consolidation (web)"}},
+		{2, []string{"node2", "node3"}, []string{"anti-affinity spread (ntp)"}},
+		{4, []string{"node2", "node3"}, []string{"pin (node1, node3)"}},
+		{6, []string{"node2", "node3"}, []string{"ignore"}},
+		{5, []string{"node2", "node3"}, nil},
+	}
+	for _, tt := range rejected {
+		if got := engine.ExcludingRules(&vms[tt.vm], tt.nodes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %s refused %v by %v, got %v", vms[tt.vm].Name, tt.nodes, tt.want, got)
+		}
+	}
+
+	// The governing rule survives wrapping, other errors have none
+	err := fmt.Errorf("failed to plan: %w", engine.ValidatePlacement(&vms[2], "node2"))
+	if got := GoverningRule(err); got != "anti-affinity spread (ntp)" {
+		t.Errorf("Expected the anti-affinity rule behind a wrapped error, got %q", got)
+	}
+	if got := GoverningRule(fmt.Errorf("node unreachable")); got != "" {
+		t.Errorf("Expected no rule behind an unrelated error, got %q", got)
+	}
+}
+
 func TestGetValidTargetNodes(t *testing.T) {
 	engine := NewEngine()
 
@@ -310,3 +514,413 @@ func TestGetValidTargetNodes(t *testing.T) {
 		t.Errorf("Expected 0 valid nodes for ignored VM, got %d", len(validNodes))
 	}
 }
+
+func TestDetectConflicts(t *testing.T) {
+	engine := NewEngine()
+
+	vms := []models.VM{
+		// Affinity group members pinned to disjoint nodes
+		{ID: 1, Name: "web1", Node: "node1", Tags: []string{"plb_affinity_web", "plb_pin_node1"}},
+		{ID: 2, Name: "web2", Node: "node2", Tags: []string{"plb_affinity_web", "plb_pin_node2"}},
+		// Anti-affinity group larger than the node count, with two members pinned to the same node
+		{ID: 3, Name: "ntp1", Node: "node1", Tags: []string{"plb_anti_affinity_ntp", "plb_pin_node1"}},
+		{ID: 4, Name: "ntp2", Node: "node1", Tags: []string{"plb_anti_affinity_ntp", "plb_pin_node1"}},
+		{ID: 5, Name: "ntp3", Node: "node2", Tags: []string{"plb_anti_affinity_ntp"}},
+		// Pinned only to a node that doesn't exist
+		{ID: 6, Name: "lost", Node: "node2", Tags: []string{"plb_pin_node9"}},
+		// Same pair in both an affinity and an anti-affinity group
+		{ID: 7, Name: "db1", Node: "node1", Tags: []string{"plb_affinity_db", "plb_anti_affinity_db"}},
+		{ID: 8, Name: "db2", Node: "node2", Tags: []string{"plb_affinity_db", "plb_anti_affinity_db"}},
+	}
+
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	conflicts := engine.DetectConflicts([]string{"node1", "node2"})
+
+	found := make(map[string]bool)
+	for _, conflict := range conflicts {
+		found[conflict.Type] = true
+		if conflict.Message == "" {
+			t.Errorf("Expected conflict %s to have a message", conflict.Type)
+		}
+	}
+
+	expected := []string{
+		ConflictPinUnavailable,
+		ConflictAffinityPin,
+		ConflictAntiAffinityCapacity,
+		ConflictAntiAffinityPin,
+		ConflictAffinityAntiAffinity,
+	}
+	for _, conflictType := range expected {
+		if !found[conflictType] {
+			t.Errorf("Expected conflict of type %s to be detected", conflictType)
+		}
+	}
+}
+
+func TestDetectConflictsNone(t *testing.T) {
+	engine := NewEngine()
+
+	vms := []models.VM{
+		{ID: 1, Name: "web1", Node: "node1", Tags: []string{"plb_affinity_web", "plb_pin_node1", "plb_pin_node2"}},
+		{ID: 2, Name: "web2", Node: "node1", Tags: []string{"plb_affinity_web", "plb_pin_node1"}},
+		{ID: 3, Name: "ntp1", Node: "node1", Tags: []string{"plb_anti_affinity_ntp"}},
+		{ID: 4, Name: "ntp2", Node: "node2", Tags: []string{"plb_anti_affinity_ntp"}},
+	}
+
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	if conflicts := engine.DetectConflicts([]string{"node1", "node2"}); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", conflicts)
+	}
+}
+
+func TestPreferenceTags(t *testing.T) {
+	engine := NewEngine()
+	vms := []models.VM{
+		{ID: 1, Name: "vm1", Node: "node1", Tags: []string{"plb_prefer_node2", "plb_prefer_node3", "plb_prefer_node2"}},
+		{ID: 2, Name: "vm2", Node: "node1"},
+	}
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	preferred := engine.GetPreferredNodes(1)
+	if strings.Join(preferred, ",") != "node2,node3" {
+		t.Errorf("Expected preferred nodes [node2 node3], got %v", preferred)
+	}
+	if !engine.IsPreferredNode(1, "node3") || engine.IsPreferredNode(1, "node1") || engine.IsPreferredNode(2, "node2") {
+		t.Error("Expected only VM 1 to prefer node2 and node3")
+	}
+
+	// A preference is a hint: every node stays a valid target
+	valid := engine.GetValidTargetNodes(&vms[0], []string{"node2", "node3", "node4"})
+	if len(valid) != 3 {
+		t.Errorf("Expected all 3 nodes to remain valid, got %v", valid)
+	}
+}
+
+func TestParseFreezeWindow(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    freezeWindow
+		wantErr bool
+	}{
+		{"22:00-04:00", freezeWindow{start: 22 * 60, end: 4 * 60}, false},
+		{"0130-0245", freezeWindow{start: 90, end: 165}, false},
+		{"09:00-17:30", freezeWindow{start: 9 * 60, end: 17*60 + 30}, false},
+		{"22:00", freezeWindow{}, true},
+		{"24:00-04:00", freezeWindow{}, true},
+		{"22:60-04:00", freezeWindow{}, true},
+		{"9:00-17:00", freezeWindow{}, true},
+		{"22:00-22:00", freezeWindow{}, true},
+		{"night-day", freezeWindow{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseFreezeWindow(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFreezeWindow(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseFreezeWindow(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreezeTags(t *testing.T) {
+	engine := NewEngine()
+	vms := []models.VM{
+		{ID: 1, Name: "batch", Node: "node1", Tags: []string{"plb_freeze_22:00-04:00"}},
+		{ID: 2, Name: "report", Node: "node1", Tags: []string{"plb_freeze_1200-1300", "plb_freeze_0600-0700"}},
+		{ID: 3, Name: "broken", Node: "node1", Tags: []string{"plb_freeze_late"}},
+	}
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 7, 1, hour, minute, 0, 0, time.Local)
+	}
+	tests := []struct {
+		name   string
+		vmID   int
+		now    time.Time
+		frozen bool
+	}{
+		{"before window", 1, at(21, 59), false},
+		{"window start", 1, at(22, 0), true},
+		{"after midnight", 1, at(3, 59), true},
+		{"window end", 1, at(4, 0), false},
+		{"midday", 1, at(12, 0), false},
+		{"second window", 2, at(6, 30), true},
+		{"between windows", 2, at(9, 0), false},
+		{"invalid tag", 3, at(23, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := engine.IsFrozen(tt.vmID, tt.now); got != tt.frozen {
+				t.Errorf("IsFrozen(%d, %s) = %v, want %v", tt.vmID, tt.now.Format("15:04"), got, tt.frozen)
+			}
+		})
+	}
+
+	conflicts := engine.DetectConflicts([]string{"node1", "node2"})
+	if len(conflicts) != 1 || conflicts[0].Type != ConflictInvalidFreeze || conflicts[0].VMIDs[0] != 3 {
+		t.Errorf("Expected one invalid freeze conflict for VM 3, got %v", conflicts)
+	}
+}
+
+func TestWorkloadClass(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want string
+	}{
+		{"no class tag", []string{"web", "plb_affinity_web"}, ""},
+		{"database class", []string{"frontend", "plb_class_db"}, "db"},
+		{"first tag wins", []string{"plb_class_WEB", "plb_class_db"}, "web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := &models.VM{ID: 1, Tags: tt.tags}
+			if got := WorkloadClass(vm); got != tt.want {
+				t.Errorf("WorkloadClass(%v) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpreadTag(t *testing.T) {
+	tests := []struct {
+		spec      string
+		wantGroup string
+		wantNodes int
+		wantErr   bool
+	}{
+		{"3_web", "web", 3, false},
+		{"2_db_primary", "db_primary", 2, false},
+		{"web", "", 0, true},
+		{"x_web", "", 0, true},
+		{"0_web", "", 0, true},
+		{"3_", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		group, minNodes, err := parseSpreadTag(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSpreadTag(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if group != tt.wantGroup || minNodes != tt.wantNodes {
+			t.Errorf("parseSpreadTag(%q) = %q, %d, want %q, %d", tt.spec, group, minNodes, tt.wantGroup, tt.wantNodes)
+		}
+	}
+}
+
+func TestMinSpreadAntiAffinity(t *testing.T) {
+	// Five web VMs on three nodes can't all be separated, they must span at least three nodes
+	vms := []models.VM{
+		{ID: 1, Name: "web1", Node: "node1", Tags: []string{"plb_anti_affinity_web", "plb_spread_3_web"}},
+		{ID: 2, Name: "web2", Node: "node1", Tags: []string{"plb_anti_affinity_web"}},
+		{ID: 3, Name: "web3", Node: "node2", Tags: []string{"plb_anti_affinity_web"}},
+		{ID: 4, Name: "web4", Node: "node2", Tags: []string{"plb_anti_affinity_web"}},
+		{ID: 5, Name: "web5", Node: "node3", Tags: []string{"plb_anti_affinity_web"}},
+	}
+	engine := NewEngine()
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	if got := engine.MinSpread("web"); got != 3 {
+		t.Errorf("Expected minimum spread 3, got %d", got)
+	}
+
+	// Sharing a node is fine while the group still spans three nodes
+	if err := engine.ValidatePlacement(&vms[0], "node2"); err != nil {
+		t.Errorf("Expected web1 allowed on node2, got %v", err)
+	}
+	// Moving the only VM off node3 would shrink the group to two nodes
+	if err := engine.ValidatePlacement(&vms[4], "node1"); err == nil {
+		t.Error("Expected web5 refused on node1, below the minimum spread")
+	}
+
+	// A node without any member is still preferred
+	targets := engine.GetValidTargetNodes(&vms[0], []string{"node2", "node3", "node4"})
+	if len(targets) != 1 || targets[0] != "node4" {
+		t.Errorf("Expected the empty node4 preferred, got %v", targets)
+	}
+
+	// Three nodes satisfy the relaxed group, four are needed once it asks for four
+	for _, conflict := range engine.DetectConflicts([]string{"node1", "node2", "node3"}) {
+		if conflict.Type == ConflictAntiAffinityCapacity {
+			t.Errorf("Expected no capacity conflict for a satisfiable minimum spread, got %s", conflict.Message)
+		}
+	}
+	vms[1].Tags = append(vms[1].Tags, "plb_spread_4_web")
+	_ = engine.ProcessVMs(vms)
+	found := false
+	for _, conflict := range engine.DetectConflicts([]string{"node1", "node2", "node3"}) {
+		found = found || conflict.Type == ConflictAntiAffinityCapacity
+	}
+	if !found {
+		t.Error("Expected a capacity conflict for a minimum spread above the node count")
+	}
+}
+
+func TestStrictAntiAffinityWithoutSpread(t *testing.T) {
+	vms := []models.VM{
+		{ID: 1, Name: "web1", Node: "node1", Tags: []string{"plb_anti_affinity_web"}},
+		{ID: 2, Name: "web2", Node: "node2", Tags: []string{"plb_anti_affinity_web"}},
+		{ID: 3, Name: "web3", Node: "node3", Tags: []string{"plb_anti_affinity_web", "plb_spread_web"}},
+	}
+	engine := NewEngine()
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	if err := engine.ValidatePlacement(&vms[0], "node2"); err == nil {
+		t.Error("Expected a strict anti-affinity group to refuse sharing a node")
+	}
+
+	found := false
+	for _, conflict := range engine.DetectConflicts([]string{"node1", "node2", "node3"}) {
+		found = found || conflict.Type == ConflictInvalidSpread
+	}
+	if !found {
+		t.Error("Expected the malformed spread tag to be reported")
+	}
+}
+
+func TestParseWeightTag(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    float64
+		wantErr bool
+	}{
+		{"0.5", 0.5, false},
+		{"2", 2, false},
+		{"10", 10, false},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"11", 0, true},
+		{"NaN", 0, true},
+		{"half", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		weight, err := parseWeightTag(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseWeightTag(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if weight != tt.want {
+			t.Errorf("parseWeightTag(%q) = %v, want %v", tt.spec, weight, tt.want)
+		}
+	}
+}
+
+func TestLoadWeight(t *testing.T) {
+	vms := []models.VM{
+		{ID: 1, Name: "bursty", Node: "node1", Tags: []string{"plb_weight_0.5"}},
+		{ID: 2, Name: "plain", Node: "node1"},
+		{ID: 3, Name: "typo", Node: "node1", Tags: []string{"plb_weight_50", "plb_weight_2"}},
+	}
+	engine := NewEngine()
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	for i, want := range []float64{0.5, 1, 2} {
+		if got := LoadWeight(&vms[i]); got != want {
+			t.Errorf("LoadWeight(%s) = %v, want %v", vms[i].Name, got, want)
+		}
+	}
+
+	var invalid []models.RuleConflict
+	for _, conflict := range engine.DetectConflicts([]string{"node1", "node2"}) {
+		if conflict.Type == ConflictInvalidWeight {
+			invalid = append(invalid, conflict)
+		}
+	}
+	if len(invalid) != 1 || invalid[0].VMIDs[0] != 3 {
+		t.Errorf("Expected the out of range weight of VM 3 to be reported, got %v", invalid)
+	}
+}
+
+func TestDuplicateVMIDs(t *testing.T) {
+	engine := NewEngine()
+
+	vms := []models.VM{
+		{ID: 100, Name: "web1", Node: "node1", Tags: []string{"plb_pin_node1"}},
+		{ID: 100, Name: "web1-restored", Node: "node2", Tags: []string{"plb_pin_node2"}},
+		{ID: 101, Name: "db1", Node: "node1", Tags: []string{"plb_pin_node1"}},
+	}
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	if !engine.IsDuplicate(100) || engine.IsDuplicate(101) {
+		t.Error("Expected only VMID 100 to be detected as duplicate")
+	}
+	if !engine.IsIgnored(100) {
+		t.Error("Expected the duplicate VMs to be left alone")
+	}
+	if engine.IsPinned(100) {
+		t.Error("Expected the colliding pins of the duplicate VMs to be dropped")
+	}
+	if !engine.IsPinned(101) {
+		t.Error("Expected VM 101 to keep its pin")
+	}
+
+	var duplicates []models.RuleConflict
+	for _, conflict := range engine.DetectConflicts([]string{"node1", "node2"}) {
+		if conflict.Type == ConflictDuplicateVMID {
+			duplicates = append(duplicates, conflict)
+		}
+	}
+	if len(duplicates) != 1 || len(duplicates[0].VMIDs) != 1 || duplicates[0].VMIDs[0] != 100 {
+		t.Errorf("Expected one duplicate VMID conflict for VM 100, got %v", duplicates)
+	}
+}
+
+func TestDependencyTags(t *testing.T) {
+	engine := NewEngine()
+
+	vms := []models.VM{
+		{ID: 100, Name: "app", Node: "node1", Tags: []string{"plb_depends_on_101", "plb_depends_on_102"}},
+		{ID: 101, Name: "db", Node: "node1"},
+		{ID: 102, Name: "cache", Node: "node2", Tags: []string{"plb_depends_on_db"}},
+		{ID: 103, Name: "self", Node: "node2", Tags: []string{"plb_depends_on_103"}},
+	}
+	if err := engine.ProcessVMs(vms); err != nil {
+		t.Fatalf("Failed to process VMs: %v", err)
+	}
+
+	if deps := engine.Dependencies(100); len(deps) != 2 || deps[0] != 101 || deps[1] != 102 {
+		t.Errorf("Expected VM 100 to depend on VMs 101 and 102, got %v", deps)
+	}
+	if deps := engine.Dependencies(101); len(deps) != 0 {
+		t.Errorf("Expected VM 101 without dependencies, got %v", deps)
+	}
+
+	invalid := 0
+	for _, conflict := range engine.DetectConflicts([]string{"node1", "node2"}) {
+		if conflict.Type == ConflictInvalidDependency {
+			invalid++
+		}
+	}
+	if invalid != 2 {
+		t.Errorf("Expected the non-numeric and self dependencies reported as invalid, got %d conflicts", invalid)
+	}
+}
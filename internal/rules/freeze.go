@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// freezeTagPrefix marks a daily window during which a VM must not be migrated, e.g. plb_freeze_22:00-04:00.
+// Proxmox does not allow colons in tags, so plb_freeze_2200-0400 is accepted too.
+const freezeTagPrefix = "plb_freeze_"
+
+// freezeWindow is a daily time range in minutes since midnight. It wraps past midnight when end < start.
+type freezeWindow struct {
+	start int
+	end   int
+}
+
+// parseFreezeWindow parses a "HH:MM-HH:MM" (or "HHMM-HHMM") time range.
+func parseFreezeWindow(spec string) (freezeWindow, error) {
+	startSpec, endSpec, found := strings.Cut(spec, "-")
+	if !found {
+		return freezeWindow{}, fmt.Errorf("invalid freeze window %q: expected HH:MM-HH:MM", spec)
+	}
+
+	start, err := parseClock(startSpec)
+	if err != nil {
+		return freezeWindow{}, fmt.Errorf("invalid freeze window %q: %w", spec, err)
+	}
+	end, err := parseClock(endSpec)
+	if err != nil {
+		return freezeWindow{}, fmt.Errorf("invalid freeze window %q: %w", spec, err)
+	}
+	if start == end {
+		return freezeWindow{}, fmt.Errorf("invalid freeze window %q: start and end are equal", spec)
+	}
+
+	return freezeWindow{start: start, end: end}, nil
+}
+
+// parseClock parses "HH:MM" or "HHMM" into minutes since midnight.
+func parseClock(clock string) (int, error) {
+	digits := strings.Replace(clock, ":", "", 1)
+	if len(digits) != 4 {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+
+	hours, err := strconv.Atoi(digits[:2])
+	if err != nil || hours < 0 || hours > 23 {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	minutes, err := strconv.Atoi(digits[2:])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+
+	return hours*60 + minutes, nil
+}
+
+// contains reports whether the time of day of t falls within the window. The end is exclusive.
+func (w freezeWindow) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// Placement rules reported as governing a migration decision.
+const (
+	RuleIgnore       = "ignore"
+	RulePin          = "pin"
+	RuleAffinity     = "affinity consolidation"
+	RuleAntiAffinity = "anti-affinity spread"
+	RulePassthrough  = "passthrough"
+	RulePreference   = "preference"
+)
+
+// PlacementError is a placement refused by a rule.
+type PlacementError struct {
+	Rule    string // One of the Rule constants
+	Subject string // The group, nodes or PCI mapping the rule is about, if any
+	Message string
+}
+
+// Error returns the message explaining the refusal.
+func (e *PlacementError) Error() string {
+	return e.Message
+}
+
+// Governing describes the rule with its subject, e.g. "anti-affinity spread (ntp)".
+func (e *PlacementError) Governing() string {
+	return describeRule(e.Rule, e.Subject)
+}
+
+// GoverningRule returns the rule that refused a placement, or "" when err doesn't come from a rule.
+func GoverningRule(err error) string {
+	var placement *PlacementError
+	if errors.As(err, &placement) {
+		return placement.Governing()
+	}
+	return ""
+}
+
+// describeRule formats a rule with its subject.
+func describeRule(rule, subject string) string {
+	if subject == "" {
+		return rule
+	}
+	return fmt.Sprintf("%s (%s)", rule, subject)
+}
+
+// GoverningRules returns the rules that drew the VM to the target node: a pin or preference naming
+// it, affinity groups with a member on it and anti-affinity groups it keeps spread.
+func (e *Engine) GoverningRules(vm *models.VM, targetNode string) []string {
+	var governing []string
+	if e.IsPinned(vm.ID) && containsNode(e.GetPinnedNodes(vm.ID), targetNode) {
+		governing = append(governing, describeRule(RulePin, targetNode))
+	}
+	for _, tag := range sortedGroupTags(e.affinityGroups) {
+		group := e.affinityGroups[tag]
+		if e.findVMInAffinityGroup(vm.ID, group) != nil && affinityPeerOn(vm, targetNode, group) {
+			governing = append(governing, describeRule(RuleAffinity, tag))
+		}
+	}
+	for _, tag := range sortedGroupTags(e.antiAffinityGroups) {
+		group := e.antiAffinityGroups[tag]
+		if e.findVMInAntiAffinityGroup(vm.ID, group) != nil && e.checkAntiAffinityConstraints(vm, targetNode, group) == nil {
+			governing = append(governing, describeRule(RuleAntiAffinity, tag))
+		}
+	}
+	if e.IsPreferredNode(vm.ID, targetNode) {
+		governing = append(governing, describeRule(RulePreference, targetNode))
+	}
+	return governing
+}
+
+// ExcludingRules returns the distinct rules refusing the VM any of the nodes, in node order.
+func (e *Engine) ExcludingRules(vm *models.VM, nodes []string) []string {
+	var excluding []string
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		rule := GoverningRule(e.ValidatePlacement(vm, node))
+		if rule != "" && !seen[rule] {
+			excluding = append(excluding, rule)
+			seen[rule] = true
+		}
+	}
+	return excluding
+}
+
+// affinityPeerOn reports whether another member of the affinity group runs on the node.
+func affinityPeerOn(vm *models.VM, node string, group *models.AffinityGroup) bool {
+	for j := range group.VMs {
+		if group.VMs[j].ID != vm.ID && group.VMs[j].Node == node {
+			return true
+		}
+	}
+	return false
+}
+
+// containsNode reports whether the node is in the list.
+func containsNode(nodes []string, node string) bool {
+	for _, candidate := range nodes {
+		if candidate == node {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// SetNodePCIMappings records the PCI resource mappings with a device on each node. VMs passing
+// mapped devices through can then only be placed on nodes with all of their mappings.
+func (e *Engine) SetNodePCIMappings(nodes []models.Node) {
+	e.nodePCIMappings = make(map[string]map[string]bool, len(nodes))
+	for i := range nodes {
+		mappings := make(map[string]bool, len(nodes[i].PCIMappings))
+		for _, mapping := range nodes[i].PCIMappings {
+			mappings[mapping] = true
+		}
+		e.nodePCIMappings[nodes[i].Name] = mappings
+	}
+}
+
+// validatePassthroughRules validates that the target node has the mapped PCI devices the VM
+// passes through. The node the VM runs on evidently has them.
+func (e *Engine) validatePassthroughRules(vm *models.VM, targetNode string) error {
+	if targetNode == vm.Node {
+		return nil
+	}
+	for _, mapping := range vm.Passthrough {
+		if !e.nodePCIMappings[targetNode][mapping] {
+			return &PlacementError{
+				Rule:    RulePassthrough,
+				Subject: mapping,
+				Message: fmt.Sprintf("VM %s passes through PCI mapping %s, which node %s doesn't have", vm.Name, mapping, targetNode),
+			}
+		}
+	}
+	return nil
+}
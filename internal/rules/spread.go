@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// spreadTagPrefix relaxes an anti-affinity group to a minimum number of distinct nodes,
+// e.g. plb_spread_3_web spreads the plb_anti_affinity_web group over at least 3 nodes.
+const spreadTagPrefix = "plb_spread_"
+
+// parseSpreadTag parses the "K_GROUP" part of a spread tag.
+func parseSpreadTag(spec string) (string, int, error) {
+	count, group, found := strings.Cut(spec, "_")
+	if !found || group == "" {
+		return "", 0, fmt.Errorf("invalid spread %q: expected NODES_GROUP", spec)
+	}
+
+	minNodes, err := strconv.Atoi(count)
+	if err != nil || minNodes < 1 {
+		return "", 0, fmt.Errorf("invalid spread %q: node count must be a positive number", spec)
+	}
+
+	return group, minNodes, nil
+}
+
+// addSpreadRule sets the minimum spread of an anti-affinity group. When members disagree, the largest wins.
+func (e *Engine) addSpreadRule(vm *models.VM, tag string) {
+	group, minNodes, err := parseSpreadTag(strings.TrimPrefix(tag, spreadTagPrefix))
+	if err != nil {
+		e.tagConflicts = append(e.tagConflicts, models.RuleConflict{
+			Type:    ConflictInvalidSpread,
+			VMIDs:   []int{vm.ID},
+			Message: fmt.Sprintf("VM %s has tag %s: %v", vm.Name, tag, err),
+		})
+		return
+	}
+	if minNodes > e.minSpread[group] {
+		e.minSpread[group] = minNodes
+	}
+}
+
+// MinSpread returns the minimum number of distinct nodes an anti-affinity group must occupy,
+// or 0 when every member needs its own node. It never exceeds the group size.
+func (e *Engine) MinSpread(group string) int {
+	minNodes := e.minSpread[group]
+	if antiAffinity, exists := e.antiAffinityGroups[group]; exists && minNodes > len(antiAffinity.VMs) {
+		return len(antiAffinity.VMs)
+	}
+	return minNodes
+}
+
+// spreadWith returns the number of distinct nodes the group occupies once the VM is on targetNode.
+func spreadWith(vm *models.VM, targetNode string, group *models.AntiAffinityGroup) int {
+	nodes := map[string]bool{targetNode: true}
+	for i := range group.VMs {
+		if group.VMs[i].ID != vm.ID {
+			nodes[group.VMs[i].Node] = true
+		}
+	}
+	return len(nodes)
+}
+
+// preferSpreadNodes narrows target nodes to those holding no other member of the VM's
+// anti-affinity groups, so relaxed groups still spread fully when they can.
+func (e *Engine) preferSpreadNodes(vm *models.VM, nodes []string) []string {
+	var spread []string
+	for _, node := range nodes {
+		if !e.nodeHasAntiAffinityPeer(vm, node) {
+			spread = append(spread, node)
+		}
+	}
+
+	if len(spread) == 0 {
+		return nodes
+	}
+	return spread
+}
+
+// nodeHasAntiAffinityPeer reports whether the node hosts another member of one of the VM's anti-affinity groups.
+func (e *Engine) nodeHasAntiAffinityPeer(vm *models.VM, node string) bool {
+	for _, group := range e.antiAffinityGroups {
+		if e.findVMInAntiAffinityGroup(vm.ID, group) == nil {
+			continue
+		}
+		for j := range group.VMs {
+			if group.VMs[j].ID != vm.ID && group.VMs[j].Node == node {
+				return true
+			}
+		}
+	}
+	return false
+}
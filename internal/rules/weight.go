@@ -0,0 +1,51 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+// weightTagPrefix scales how much a VM's usage counts toward its node's modeled load,
+// e.g. plb_weight_0.5 halves the weight of a bursty VM whose peaks shouldn't dominate.
+const weightTagPrefix = "plb_weight_"
+
+// maxLoadWeight bounds load weights, so a typo can't make one VM outweigh a whole node.
+const maxLoadWeight = 10.0
+
+// parseWeightTag parses the weight part of a weight tag: a number above 0, up to maxLoadWeight.
+func parseWeightTag(spec string) (float64, error) {
+	weight, err := strconv.ParseFloat(spec, 64)
+	if err != nil || !(weight > 0 && weight <= maxLoadWeight) {
+		return 0, fmt.Errorf("invalid weight %q: expected a number above 0, up to %g", spec, maxLoadWeight)
+	}
+	return weight, nil
+}
+
+// addWeightRule validates a weight tag. Malformed weights are reported as conflicts, and ignored.
+func (e *Engine) addWeightRule(vm *models.VM, tag string) {
+	if _, err := parseWeightTag(strings.TrimPrefix(tag, weightTagPrefix)); err != nil {
+		e.tagConflicts = append(e.tagConflicts, models.RuleConflict{
+			Type:    ConflictInvalidWeight,
+			VMIDs:   []int{vm.ID},
+			Message: fmt.Sprintf("VM %s has tag %s: %v", vm.Name, tag, err),
+		})
+	}
+}
+
+// LoadWeight returns the factor applied to a VM's usage when modeling its node's load: the first
+// valid plb_weight_ tag, or 1.
+func LoadWeight(vm *models.VM) float64 {
+	for _, tag := range vm.Tags {
+		spec, found := strings.CutPrefix(strings.TrimSpace(tag), weightTagPrefix)
+		if !found {
+			continue
+		}
+		if weight, err := parseWeightTag(spec); err == nil {
+			return weight
+		}
+	}
+	return 1
+}
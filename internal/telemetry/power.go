@@ -0,0 +1,86 @@
+// Package telemetry provides optional external node telemetry such as power draw and temperature.
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+	"github.com/cblomart/GoProxLB/internal/models"
+)
+
+const (
+	// SourceFile reads power telemetry from a JSON file.
+	SourceFile = "file"
+	// SourceCommand reads power telemetry from the JSON output of a command.
+	SourceCommand = "command"
+
+	// commandTimeout bounds how long a telemetry command may run.
+	commandTimeout = 10 * time.Second
+)
+
+// PowerSource provides per-node power and thermal readings.
+type PowerSource interface {
+	NodePower() (map[string]models.NodePower, error)
+}
+
+// NewPowerSource creates the power source described by the configuration, or nil when disabled.
+func NewPowerSource(cfg *config.PowerConfig) (PowerSource, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Source {
+	case SourceFile:
+		return &FileSource{Path: cfg.Path}, nil
+	case SourceCommand:
+		return &CommandSource{Command: cfg.Command}, nil
+	default:
+		return nil, fmt.Errorf("unknown power telemetry source: %s", cfg.Source)
+	}
+}
+
+// FileSource reads telemetry from a JSON file mapping node names to readings,
+// e.g. {"node1": {"watts": 320, "temperature": 61}}.
+type FileSource struct {
+	Path string
+}
+
+// NodePower reads the telemetry file.
+func (s *FileSource) NodePower() (map[string]models.NodePower, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read power telemetry file: %w", err)
+	}
+	return parsePowerData(data)
+}
+
+// CommandSource runs a command whose standard output uses the same JSON format as FileSource.
+type CommandSource struct {
+	Command string
+}
+
+// NodePower runs the telemetry command through the shell.
+func (s *CommandSource) NodePower() (map[string]models.NodePower, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "/bin/sh", "-c", s.Command).Output() //nolint:gosec // command comes from configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to run power telemetry command: %w", err)
+	}
+	return parsePowerData(output)
+}
+
+// parsePowerData decodes telemetry JSON.
+func parsePowerData(data []byte) (map[string]models.NodePower, error) {
+	var readings map[string]models.NodePower
+	if err := json.Unmarshal(data, &readings); err != nil {
+		return nil, fmt.Errorf("failed to decode power telemetry: %w", err)
+	}
+	return readings, nil
+}
@@ -0,0 +1,96 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cblomart/GoProxLB/internal/config"
+)
+
+const samplePowerData = `{"node1": {"watts": 420, "temperature": 71}, "node2": {"watts": 180}}`
+
+func TestNewPowerSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.PowerConfig
+		wantNil  bool
+		wantType string
+		wantErr  bool
+	}{
+		{"disabled", config.PowerConfig{Enabled: false, Source: SourceFile}, true, "", false},
+		{"file", config.PowerConfig{Enabled: true, Source: SourceFile, Path: "/tmp/power.json"}, false, "file", false},
+		{"command", config.PowerConfig{Enabled: true, Source: SourceCommand, Command: "true"}, false, "command", false},
+		{"unknown", config.PowerConfig{Enabled: true, Source: "snmp"}, true, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewPowerSource(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPowerSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (source == nil) != tt.wantNil {
+				t.Fatalf("Expected nil source: %v, got %v", tt.wantNil, source)
+			}
+
+			switch tt.wantType {
+			case "file":
+				if _, ok := source.(*FileSource); !ok {
+					t.Errorf("Expected *FileSource, got %T", source)
+				}
+			case "command":
+				if _, ok := source.(*CommandSource); !ok {
+					t.Errorf("Expected *CommandSource, got %T", source)
+				}
+			}
+		})
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "power.json")
+	if err := os.WriteFile(path, []byte(samplePowerData), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	readings, err := (&FileSource{Path: path}).NodePower()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if readings["node1"].Watts != 420 || readings["node1"].Temperature != 71 {
+		t.Errorf("Unexpected node1 reading: %+v", readings["node1"])
+	}
+	if readings["node2"].Watts != 180 || readings["node2"].Temperature != 0 {
+		t.Errorf("Unexpected node2 reading: %+v", readings["node2"])
+	}
+}
+
+func TestFileSourceErrors(t *testing.T) {
+	if _, err := (&FileSource{Path: filepath.Join(t.TempDir(), "missing.json")}).NodePower(); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	path := filepath.Join(t.TempDir(), "power.json")
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&FileSource{Path: path}).NodePower(); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestCommandSource(t *testing.T) {
+	readings, err := (&CommandSource{Command: "echo '" + samplePowerData + "'"}).NodePower()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if readings["node1"].Watts != 420 {
+		t.Errorf("Expected node1 at 420W, got %+v", readings["node1"])
+	}
+
+	if _, err := (&CommandSource{Command: "exit 1"}).NodePower(); err == nil {
+		t.Error("Expected error for failing command")
+	}
+}